@@ -20,7 +20,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -50,38 +52,33 @@ func newPartitionedBoltStore(baseDir string, logger *log.Entry) *partitionedBolt
 type partitionedBoltStore struct {
 	baseDir    string
 	partitions *sync.Map
-	logger     *log.Entry
+	// all partitions keep older versions of their values
+	versioned bool
+	logger    *log.Entry
 }
 
 func (pbs *partitionedBoltStore) CreatePartition(partitionID int) (util.DataStoreTxnProvider, error) {
 	dir := fmt.Sprintf("%spartition-%d", pbs.baseDir, partitionID)
-	db, err := bolt.Open(dir+dbName, 0600, &bolt.Options{Timeout: time.Second, NoFreelistSync: true})
+	db, err := openBoltDB(dir + dbName)
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Update(func(tx *bolt.Tx) error {
-		var err error
-		if _, err = tx.CreateBucketIfNotExists([]byte(bucketName)); err != nil {
-			return err
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	bds := &boltDataStore{
+	var bds util.DataStoreTxnProvider = &boltDataStore{
 		db:          db,
+		lock:        &sync.RWMutex{},
 		dir:         dir,
 		partitionID: partitionID,
 		logger:      pbs.logger,
 	}
+	if pbs.versioned {
+		bds = &versionedBoltDataStore{bds.(*boltDataStore)}
+	}
 
 	v, loaded := pbs.partitions.LoadOrStore(partitionID, bds)
 	if loaded {
 		bds.Close()
-		bds = v.(*boltDataStore)
+		bds = v.(util.DataStoreTxnProvider)
 	}
 	return bds, nil
 }
@@ -94,26 +91,33 @@ func (pbs *partitionedBoltStore) GetPartition(partitionID int) (util.DataStoreTx
 	return v.(util.DataStoreTxnProvider), nil
 }
 
+// partitions are written in order of their ids
+// each partition is a consistent copy but partitions aren't consistent with each other
 func (pbs *partitionedBoltStore) Snapshot(w io.Writer) error {
-	var err error
-	ps := &calvinpb.PartitionedSnapshot{}
+	partitionIDs := make([]int, 0)
 	pbs.partitions.Range(func(key, value interface{}) bool {
-		partitionID := key.(uint64)
-		bds := value.(*boltDataStore)
+		partitionIDs = append(partitionIDs, key.(int))
+		return true
+	})
+	sort.Ints(partitionIDs)
+
+	ps := &calvinpb.PartitionedSnapshot{}
+	for _, partitionID := range partitionIDs {
+		v, ok := pbs.partitions.Load(partitionID)
+		if !ok {
+			// the partition was deleted in the meantime
+			continue
+		}
+
 		buf := new(bytes.Buffer)
-		err = bds.Snapshot(buf)
+		err := v.(util.DataStoreTxnProvider).Snapshot(buf)
 		if err != nil {
-			return false
+			pbs.logger.Errorf("can't collect partitioned snapshot: %s", err.Error())
+			return err
 		}
 
-		ps.PartitionIDs = append(ps.PartitionIDs, partitionID)
+		ps.PartitionIDs = append(ps.PartitionIDs, uint64(partitionID))
 		ps.Snapshots = append(ps.Snapshots, buf.Bytes())
-		return true
-	})
-
-	if err != nil {
-		pbs.logger.Errorf("can't collect partitioned snapshot: %s", err.Error())
-		return err
 	}
 
 	data, err := ps.Marshal()
@@ -131,20 +135,69 @@ func (pbs *partitionedBoltStore) Snapshot(w io.Writer) error {
 	return nil
 }
 
+func (pbs *partitionedBoltStore) Restore(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	ps := &calvinpb.PartitionedSnapshot{}
+	err = ps.Unmarshal(data)
+	if err != nil {
+		return err
+	} else if len(ps.PartitionIDs) != len(ps.Snapshots) {
+		return fmt.Errorf("partitioned snapshot has [%d] partition ids but [%d] snapshots", len(ps.PartitionIDs), len(ps.Snapshots))
+	}
+
+	for idx := range ps.PartitionIDs {
+		partitionID := int(ps.PartitionIDs[idx])
+		// opening the same bolt file twice would time out
+		bds, err := pbs.GetPartition(partitionID)
+		if err != nil {
+			bds, err = pbs.CreatePartition(partitionID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = bds.Restore(bytes.NewReader(ps.Snapshots[idx]))
+		if err != nil {
+			pbs.logger.Errorf("can't restore partition [%d]: %s", partitionID, err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (pbs *partitionedBoltStore) Close() {
 	pbs.partitions.Range(func(key, value interface{}) bool {
-		bds := value.(*boltDataStore)
+		bds := value.(util.DataStoreTxnProvider)
 		defer bds.Close()
 		return true
 	})
 }
 
 func newBoltDataStore(dir string, logger *log.Entry) *boltDataStore {
-	db, err := bolt.Open(dir+dbName, 0600, &bolt.Options{Timeout: time.Second, NoFreelistSync: true})
+	db, err := openBoltDB(dir + dbName)
 	if err != nil {
 		logger.Panicf("%s\n", err.Error())
 	}
 
+	return &boltDataStore{
+		db:     db,
+		lock:   &sync.RWMutex{},
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+func openBoltDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, NoFreelistSync: true})
+	if err != nil {
+		return nil, err
+	}
+
 	err = db.Update(func(tx *bolt.Tx) error {
 		var err error
 		if _, err = tx.CreateBucketIfNotExists([]byte(bucketName)); err != nil {
@@ -152,41 +205,167 @@ func newBoltDataStore(dir string, logger *log.Entry) *boltDataStore {
 		}
 		return nil
 	})
-
 	if err != nil {
-		logger.Panicf("%s\n", err.Error())
+		db.Close()
+		return nil, err
 	}
 
-	return &boltDataStore{
-		db:     db,
-		dir:    dir,
-		logger: logger,
-	}
+	return db, nil
 }
 
 type boltDataStore struct {
-	db          *bolt.DB
+	db *bolt.DB
+	// guards swapping out the db when restoring a snapshot
+	lock        *sync.RWMutex
 	dir         string
 	partitionID int
-	logger      *log.Entry
+	// set if a failed restore left the store without a db
+	failed error
+	logger *log.Entry
 }
 
 func (bds *boltDataStore) StartTxn(writable bool) (util.DataStoreTxn, error) {
+	bds.lock.RLock()
+	defer bds.lock.RUnlock()
+	if bds.failed != nil {
+		return nil, bds.failed
+	}
+
 	txn, err := bds.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+
 	t := &boltDataStoreTxn{
 		txn:    txn,
 		bucket: txn.Bucket([]byte(bucketName)),
 	}
-	return t, err
+	return t, nil
 }
 
+// the snapshot is a copy of the entire bolt file as seen by a read txn
+// writers aren't blocked while the snapshot is being written
 func (bds *boltDataStore) Snapshot(w io.Writer) error {
-	// TODO: implement this method
+	bds.lock.RLock()
+	defer bds.lock.RUnlock()
+	if bds.failed != nil {
+		return bds.failed
+	}
+
+	return bds.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// the snapshot is written next to the bolt file first
+// the bolt file is only replaced once the snapshot is on disk completely
+// the previous bolt file is kept until the restored one opened successfully
+// closing the db waits for all open txns to finish
+func (bds *boltDataStore) Restore(r io.Reader) error {
+	bds.lock.Lock()
+	defer bds.lock.Unlock()
+
+	if bds.failed != nil {
+		return bds.failed
+	}
+
+	path := bds.dir + dbName
+	restorePath := path + ".restore"
+	previousPath := path + ".previous"
+	err := writeFileSync(restorePath, r)
+	if err != nil {
+		os.Remove(restorePath)
+		return err
+	}
+
+	// make sure the copy is a valid database before the partition is replaced
+	restoredDB, err := openBoltDB(restorePath)
+	if err != nil {
+		os.Remove(restorePath)
+		return err
+	}
+	restoredDB.Close()
+
+	err = bds.db.Close()
+	if err != nil {
+		os.Remove(restorePath)
+		return err
+	}
+
+	err = os.Rename(path, previousPath)
+	if err != nil {
+		os.Remove(restorePath)
+		return bds.reopen(path, "", err)
+	}
+
+	err = os.Rename(restorePath, path)
+	if err != nil {
+		os.Remove(restorePath)
+		return bds.reopen(path, previousPath, err)
+	}
+
+	db, err := openBoltDB(path)
+	if err != nil {
+		return bds.reopen(path, previousPath, err)
+	}
+
+	bds.db = db
+	os.Remove(previousPath)
 	return nil
 }
 
+// gets back to the bolt file from before a failed restore
+// if that doesn't work either, the store is marked as failed
+// and all further calls return an error
+func (bds *boltDataStore) reopen(path string, previousPath string, cause error) error {
+	if previousPath != "" {
+		err := os.Rename(previousPath, path)
+		if err != nil {
+			bds.failed = fmt.Errorf("can't restore partition [%d]: %s and can't go back to the previous data: %s", bds.partitionID, cause.Error(), err.Error())
+			bds.logger.Errorf("%s", bds.failed.Error())
+			return bds.failed
+		}
+	}
+
+	db, err := openBoltDB(path)
+	if err != nil {
+		bds.failed = fmt.Errorf("can't restore partition [%d]: %s and can't reopen the previous data: %s", bds.partitionID, cause.Error(), err.Error())
+		bds.logger.Errorf("%s", bds.failed.Error())
+		return bds.failed
+	}
+
+	bds.db = db
+	return cause
+}
+
+func writeFileSync(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	err = f.Sync()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
 func (bds *boltDataStore) Close() {
-	bds.db.Close()
+	bds.lock.RLock()
+	defer bds.lock.RUnlock()
+	if bds.failed == nil {
+		bds.db.Close()
+	}
 }
 
 func (bds *boltDataStore) Delete() {
@@ -213,6 +392,18 @@ func (t *boltDataStoreTxn) Set(key []byte, value []byte) error {
 	return err
 }
 
+func (t *boltDataStoreTxn) Delete(key []byte) error {
+	return t.bucket.Delete(key)
+}
+
+func (t *boltDataStoreTxn) Iterate(start []byte, end []byte) util.DataStoreIterator {
+	return &boltDataStoreIterator{
+		c:     t.bucket.Cursor(),
+		start: start,
+		end:   end,
+	}
+}
+
 func (t *boltDataStoreTxn) Commit() error {
 	return t.txn.Commit()
 }
@@ -220,3 +411,41 @@ func (t *boltDataStoreTxn) Commit() error {
 func (t *boltDataStoreTxn) Rollback() error {
 	return t.txn.Rollback()
 }
+
+type boltDataStoreIterator struct {
+	c       *bolt.Cursor
+	start   []byte
+	end     []byte
+	started bool
+	key     []byte
+	value   []byte
+}
+
+func (it *boltDataStoreIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		if it.start == nil {
+			it.key, it.value = it.c.First()
+		} else {
+			it.key, it.value = it.c.Seek(it.start)
+		}
+	} else if it.key != nil {
+		it.key, it.value = it.c.Next()
+	}
+
+	if it.key != nil && it.end != nil && bytes.Compare(it.key, it.end) >= 0 {
+		it.key, it.value = nil, nil
+	}
+	return it.key != nil
+}
+
+func (it *boltDataStoreIterator) Key() []byte {
+	return it.key
+}
+
+func (it *boltDataStoreIterator) Value() []byte {
+	return it.value
+}
+
+// cursors go away with their txn
+func (it *boltDataStoreIterator) Close() {}
@@ -0,0 +1,170 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	calvinpb "github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoltStoreSnapshotRoundTrip(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	srcDir := fmt.Sprintf("./test-TestBoltStoreSnapshotRoundTrip-src-%d/", util.RandomRaftId())
+	dstDir := fmt.Sprintf("./test-TestBoltStoreSnapshotRoundTrip-dst-%d/", util.RandomRaftId())
+	defer os.RemoveAll(srcDir)
+	defer os.RemoveAll(dstDir)
+	assert.Nil(t, os.MkdirAll(srcDir, os.ModePerm))
+	assert.Nil(t, os.MkdirAll(dstDir, os.ModePerm))
+
+	src := newPartitionedBoltStore(srcDir, logger)
+	defer src.Close()
+	for _, partitionID := range []int{1, 2} {
+		bds, err := src.CreatePartition(partitionID)
+		assert.Nil(t, err)
+		writeBoltKey(t, bds, "narf", fmt.Sprintf("narf_%d", partitionID))
+	}
+
+	buf := new(bytes.Buffer)
+	err := src.Snapshot(buf)
+	assert.Nil(t, err)
+	ps := &calvinpb.PartitionedSnapshot{}
+	err = ps.Unmarshal(buf.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2}, ps.PartitionIDs)
+
+	// writes after the snapshot was taken don't show up in it
+	bds, err := src.GetPartition(1)
+	assert.Nil(t, err)
+	writeBoltKey(t, bds, "moep", "moep_1")
+
+	// partition 2 exists already and is replaced
+	// partition 1 is created
+	dst := newPartitionedBoltStore(dstDir, logger)
+	defer dst.Close()
+	bds, err = dst.CreatePartition(2)
+	assert.Nil(t, err)
+	writeBoltKey(t, bds, "moep", "moep_2")
+
+	err = dst.Restore(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err)
+	for _, partitionID := range []int{1, 2} {
+		bds, err = dst.GetPartition(partitionID)
+		assert.Nil(t, err)
+		txn, err := bds.StartTxn(false)
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf("narf_%d", partitionID), string(txn.Get([]byte("narf"))))
+		assert.Nil(t, txn.Get([]byte("moep")))
+		assert.Nil(t, txn.Rollback())
+	}
+
+	// restored partitions can be written to
+	bds, err = dst.GetPartition(1)
+	assert.Nil(t, err)
+	writeBoltKey(t, bds, "moep", "moep_1")
+
+	broken := &calvinpb.PartitionedSnapshot{PartitionIDs: []uint64{1}}
+	data, err := broken.Marshal()
+	assert.Nil(t, err)
+	err = dst.Restore(bytes.NewReader(data))
+	assert.NotNil(t, err)
+}
+
+func TestBoltStoreReopenAfterFailedRestore(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	baseDir := fmt.Sprintf("./test-TestBoltStoreReopenAfterFailedRestore-%d/", util.RandomRaftId())
+	defer os.RemoveAll(baseDir)
+	assert.Nil(t, os.MkdirAll(baseDir, os.ModePerm))
+
+	store := newPartitionedBoltStore(baseDir, logger)
+	defer store.Close()
+	txnProvider, err := store.CreatePartition(1)
+	assert.Nil(t, err)
+	writeBoltKey(t, txnProvider, "narf", "narf")
+	bds := txnProvider.(*boltDataStore)
+
+	// the previous bolt file is put back in place of a broken one
+	path := bds.dir + dbName
+	assert.Nil(t, bds.db.Close())
+	assert.Nil(t, os.Rename(path, path+".previous"))
+	assert.Nil(t, ioutil.WriteFile(path, []byte("broken"), 0600))
+	cause := fmt.Errorf("narf")
+	assert.Equal(t, cause, bds.reopen(path, path+".previous", cause))
+	assert.Nil(t, bds.failed)
+	txn, err := bds.StartTxn(false)
+	assert.Nil(t, err)
+	assert.Equal(t, "narf", string(txn.Get([]byte("narf"))))
+	assert.Nil(t, txn.Rollback())
+
+	// without a bolt file to go back to the store fails
+	assert.Nil(t, bds.db.Close())
+	assert.Nil(t, ioutil.WriteFile(path, []byte("broken"), 0600))
+	err = bds.reopen(path, "", cause)
+	assert.NotNil(t, err)
+	assert.Equal(t, err, bds.failed)
+	_, err = bds.StartTxn(false)
+	assert.NotNil(t, err)
+	assert.NotNil(t, bds.Restore(bytes.NewReader([]byte{})))
+}
+
+func TestBoltStoreIterate(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	baseDir := fmt.Sprintf("./test-TestBoltStoreIterate-%d/", util.RandomRaftId())
+	defer os.RemoveAll(baseDir)
+	assert.Nil(t, os.MkdirAll(baseDir, os.ModePerm))
+
+	store := newPartitionedBoltStore(baseDir, logger)
+	defer store.Close()
+	bds, err := store.CreatePartition(1)
+	assert.Nil(t, err)
+	for _, key := range []string{"narf/3", "narf/1", "narg", "moep", "narf/2"} {
+		writeBoltKey(t, bds, key, "value_"+key)
+	}
+
+	txn, err := bds.StartTxn(false)
+	assert.Nil(t, err)
+	defer txn.Rollback()
+	assertIteratedKeys(t, txn.Iterate([]byte("narf/"), util.PrefixEnd([]byte("narf/"))), []string{"narf/1", "narf/2", "narf/3"})
+	assertIteratedKeys(t, txn.Iterate([]byte("narf/2"), nil), []string{"narf/2", "narf/3", "narg"})
+	assertIteratedKeys(t, txn.Iterate(nil, []byte("narf/2")), []string{"moep", "narf/1"})
+	assertIteratedKeys(t, txn.Iterate([]byte("x"), nil), []string{})
+}
+
+func assertIteratedKeys(t *testing.T, it util.DataStoreIterator, keys []string) {
+	defer it.Close()
+	iteratedKeys := make([]string, 0)
+	for it.Next() {
+		iteratedKeys = append(iteratedKeys, string(it.Key()))
+		assert.Equal(t, "value_"+string(it.Key()), string(it.Value()))
+	}
+	assert.Equal(t, keys, iteratedKeys)
+	assert.False(t, it.Next())
+}
+
+func writeBoltKey(t *testing.T, bds util.DataStoreTxnProvider, key string, value string) {
+	txn, err := bds.StartTxn(true)
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Set([]byte(key), []byte(value)))
+	assert.Nil(t, txn.Commit())
+}
@@ -19,35 +19,46 @@ package calvin
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/mhelmich/calvin/execution"
 	"github.com/mhelmich/calvin/pb"
 	"github.com/mhelmich/calvin/scheduler"
 	"github.com/mhelmich/calvin/sequencer"
+	"github.com/mhelmich/calvin/ulid"
 	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 	"go.etcd.io/etcd/raft"
 	"google.golang.org/grpc"
 )
 
-const (
-	numWorkerThreads = 2
-	goodChannelSize  = numWorkerThreads*2 + 1
-)
+// ErrReplaying is returned for new work while a node executes the batches
+// it sequenced before it went down again.
+// Callers can wait for ReplayDone or try again later.
+var ErrReplaying = errors.New("node is still replaying its log")
 
 func NewCalvin(opts Options) *Calvin {
-	cc := util.NewConnectionCache(opts.clusterInfoProvider)
-
 	logger := log.WithFields(log.Fields{
 		"raftIdHex": hex.EncodeToString(util.Uint64ToBytes(opts.raftID)),
 		"raftId":    util.Uint64ToString(opts.raftID),
 	})
 
+	opts = opts.withDefaults()
+	err := opts.validate()
+	if err != nil {
+		logger.Panicf("invalid options: %s", err.Error())
+	}
+
+	cc := util.NewConnectionCache(opts.clusterInfoProvider)
+
 	srvr := grpc.NewServer()
 	// myAddress := fmt.Sprintf("%s:%d", util.OutboundIP().To4().String(), cfg.Port)
 	myAddress := fmt.Sprintf("%s:%d", opts.hostname, opts.port)
@@ -57,31 +68,29 @@ func NewCalvin(opts Options) *Calvin {
 		logger.Panicf("%s\n", err.Error())
 	}
 
-	txnBatchChan := make(chan *pb.TransactionBatch, goodChannelSize)
-	peers := []raft.Peer{raft.Peer{
-		ID:      opts.raftID,
-		Context: []byte(myAddress),
-	}}
-
-	for idx := range opts.peers {
-		addr := opts.clusterInfoProvider.GetAddressFor(opts.peers[idx])
-		peers = append(peers, raft.Peer{
-			ID:      opts.peers[idx],
+	txnBatchChan := make(chan *pb.TransactionBatch, opts.channelSize)
+	// txns the sequencer couldn't get into the log
+	droppedTxnChan := make(chan *pb.Transaction, opts.channelSize)
+	peers := make([]raft.Peer, 0)
+	// replicas that aren't leaders of their partitions join raft as learners
+	// they execute all batches but don't count towards the quorum
+	learners := make([]raft.Peer, 0)
+	addPeer := func(id uint64, addr string) {
+		peer := raft.Peer{
+			ID:      id,
 			Context: []byte(addr),
-		})
+		}
+		if opts.clusterInfoProvider.IsLearner(id) {
+			learners = append(learners, peer)
+		} else {
+			peers = append(peers, peer)
+		}
 	}
 
-	storeDir := fmt.Sprintf("%s%d", opts.storePath, opts.raftID)
-	if !strings.HasSuffix(storeDir, "/") {
-		storeDir = storeDir + "/"
+	addPeer(opts.raftID, myAddress)
+	for idx := range opts.peers {
+		addPeer(opts.peers[idx], opts.clusterInfoProvider.GetAddressFor(opts.peers[idx]))
 	}
-	seq := sequencer.NewSequencer(opts.raftID, txnBatchChan, peers, storeDir, cc, opts.clusterInfoProvider, srvr, opts.snapshotHandler, logger)
-
-	// releaser might be waiting to send on ready channel
-	readyTxnChan := make(chan *pb.Transaction, goodChannelSize)
-	// workers might be waiting to send on done channel
-	doneTxnChan := make(chan *pb.Transaction, goodChannelSize)
-	sched := scheduler.NewScheduler(txnBatchChan, readyTxnChan, doneTxnChan, srvr, logger)
 
 	// init partitions we know about now
 	for _, partitionID := range opts.clusterInfoProvider.MyPartitions() {
@@ -91,6 +100,54 @@ func NewCalvin(opts Options) *Calvin {
 		}
 	}
 
+	// the log is replayed from the last batch whose effects made it into all partitions
+	durableIndex, err := execution.ReadCheckpoint(opts.partitionedDataStore, opts.clusterInfoProvider.MyPartitions())
+	if err != nil {
+		logger.Panicf("can't read checkpoint: %s", err.Error())
+	}
+
+	// without a snapshot handler the log would grow forever
+	snapshotHandler := opts.snapshotHandler
+	if snapshotHandler == nil {
+		snapshotHandler = newDataStoreSnapshotHandler(opts.partitionedDataStore, opts.clusterInfoProvider, logger)
+	}
+
+	storeDir := fmt.Sprintf("%s%d", opts.storePath, opts.raftID)
+	if !strings.HasSuffix(storeDir, "/") {
+		storeDir = storeDir + "/"
+	}
+	seqOpts := sequencer.SequencerOpts{
+		RaftID:                 opts.raftID,
+		TxnBatchChan:           txnBatchChan,
+		DroppedTxnChan:         droppedTxnChan,
+		Peers:                  peers,
+		Learners:               learners,
+		StoreDir:               storeDir,
+		ConnCache:              cc,
+		Cip:                    opts.clusterInfoProvider,
+		Srvr:                   srvr,
+		SnapshotHandler:        snapshotHandler,
+		PartialSnapshotHandler: opts.partialSnapshotHandler,
+		BatchFrequency:         opts.batchFrequency,
+		MaxBatchTxns:           opts.maxBatchTxns,
+		MaxBatchBytes:          opts.maxBatchBytes,
+		RaftTickInterval:       opts.raftTickInterval,
+		DurableIndex:           durableIndex,
+		NumGroups:              opts.numSequencerGroups,
+		Logger:                 logger,
+	}
+	seq := sequencer.NewSequencer(seqOpts)
+
+	// releaser might be waiting to send on ready channel
+	readyTxnChan := make(chan *pb.Transaction, opts.channelSize)
+	// workers might be waiting to send on done channel
+	doneTxnChan := make(chan *pb.Transaction, opts.channelSize)
+	// the scheduler installs procedures in log order, workers read them
+	storedProcs := util.NewStoredProcedureRegistry()
+	// only the latest checkpoint is kept in here
+	checkpointChan := make(chan uint64, 1)
+	sched := scheduler.NewScheduler(txnBatchChan, readyTxnChan, doneTxnChan, checkpointChan, opts.numSequencerGroups, storedProcs, srvr, logger)
+
 	engineOpts := execution.EngineOpts{
 		ScheduledTxnChan: readyTxnChan,
 		DoneTxnChan:      doneTxnChan,
@@ -98,14 +155,28 @@ func NewCalvin(opts Options) *Calvin {
 		ConnCache:        cc,
 		Cip:              opts.clusterInfoProvider,
 		PartitionedStore: opts.partitionedDataStore,
-		NumWorkers:       numWorkerThreads,
+		NumWorkers:       opts.numWorkers,
+		NodeID:           opts.raftID,
+		StoredProcs:      storedProcs,
+		CheckpointChan:   checkpointChan,
+		DroppedTxnChan:   droppedTxnChan,
+		DedupWindow:      *opts.dedupWindow,
+		VersionHorizon:   *opts.versionHorizon,
 		Logger:           logger,
 	}
 	engine := execution.NewEngine(engineOpts)
 
-	go srvr.Serve(lis)
+	replayIndex := seq.ReplayIndex()
+	replayDone := engine.WaitForCheckpoint(replayIndex)
+	if replayIndex > 0 {
+		logger.Infof("replaying log from [%d] to [%d]", durableIndex, replayIndex)
+		go func() {
+			<-replayDone
+			logger.Infof("done replaying log up to [%d]", replayIndex)
+		}()
+	}
 
-	return &Calvin{
+	c := &Calvin{
 		cc:                 cc,
 		cip:                opts.clusterInfoProvider,
 		seq:                seq,
@@ -116,9 +187,17 @@ func NewCalvin(opts Options) *Calvin {
 		txnBatchChan:       txnBatchChan,
 		readyTxnChan:       readyTxnChan,
 		doneTxnChan:        doneTxnChan,
+		storedProcs:        storedProcs,
+		replayDone:         replayDone,
+		snapshotReader:     newSnapshotReader(sched, engine, opts.partitionedDataStore, opts.clusterInfoProvider, opts.snapshotViewMaxAge, logger),
 		logger:             logger,
 		myRaftID:           opts.raftID,
 	}
+
+	sched.SetReadBarrier(c.readBarrier)
+	pb.RegisterCalvinServer(srvr, newCalvinServer(c, logger))
+	go srvr.Serve(lis)
+	return c
 }
 
 type Calvin struct {
@@ -132,35 +211,404 @@ type Calvin struct {
 	txnBatchChan       chan *pb.TransactionBatch
 	readyTxnChan       chan *pb.Transaction
 	doneTxnChan        chan *pb.Transaction
+	storedProcs        *util.StoredProcedureRegistry
+	replayDone         <-chan struct{}
+	snapshotReader     *snapshotReader
 	logger             *log.Entry
 	myRaftID           uint64
 }
 
+// ReplayDone returns a channel that is closed once all batches this node
+// sequenced before it went down are executed again.
+// New work is only accepted after that. Until then it's refused with ErrReplaying.
+// Transactions spanning multiple nodes that this node applied before it went down
+// finish without the remote reads of the other nodes.
+// All other transactions spanning multiple nodes can only be replayed
+// if the other nodes execute them as well.
+func (c *Calvin) ReplayDone() <-chan struct{} {
+	return c.replayDone
+}
+
 func (c *Calvin) Stop() {
 	c.logger.Warningf("Shutting down calvin [%d]", c.myRaftID)
 	c.grpcSrvr.Stop()
 	c.seq.Stop()
 	time.Sleep(time.Second)
+	// open read txns keep the data store from closing
+	c.snapshotReader.stop()
 	c.partitionDataStore.Close()
 }
 
-func (c *Calvin) SubmitTransaction(txn *pb.Transaction) {
+// new work doesn't wait for the replay
+// replaying can take as long as executing everything the node missed
+func (c *Calvin) checkReplayDone() error {
+	select {
+	case <-c.replayDone:
+		return nil
+	default:
+		return ErrReplaying
+	}
+}
+
+// SubmitTransaction hands a transaction to the sequencer without waiting for its outcome.
+func (c *Calvin) SubmitTransaction(txn *pb.Transaction) error {
+	err := c.checkReplayDone()
+	if err != nil {
+		return err
+	}
+
 	c.seq.SubmitTransaction(txn)
+	return nil
 }
 
+// SubmitTransactionWithFuture submits a transaction and returns a future
+// that resolves once all nodes owning the write set of the transaction executed it.
+// If the transaction doesn't have an id yet, a new id is assigned. The outcome carries the id.
+// A transaction that is submitted again with the same id (for instance after the client timed out)
+// doesn't run twice. The retry resolves with the outcome of the first run.
+// The provided transaction isn't modified. A copy of it is handed to the sequencer.
+func (c *Calvin) SubmitTransactionWithFuture(txn *pb.Transaction) (*execution.TxnFuture, error) {
+	err := c.checkReplayDone()
+	if err != nil {
+		return nil, err
+	}
+
+	// the caller might still hold on to the txn or have submitted it before
+	// in that case the sequencer might be reading it at the same time
+	txn = proto.Clone(txn).(*pb.Transaction)
+	if txn.Id == nil {
+		id, err := ulid.NewId()
+		if err != nil {
+			return nil, err
+		}
+		txn.Id = id.ToProto()
+	}
+
+	txn.OriginNode = c.myRaftID
+	f, err := c.engine.TxnFutureFor(txn.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.seq.SubmitTransaction(txn)
+	return f, nil
+}
+
+// RegisterStoredProcedure validates a stored procedure and hands it to the sequencer.
+// The procedure is installed on all nodes at the same position in the log.
+// Registering an existing name installs a new version of the procedure.
+// It returns once the procedure is in the log or with an error if it didn't make it there.
+func (c *Calvin) RegisterStoredProcedure(ctx context.Context, name string, source string) error {
+	err := execution.ValidateStoredProcedure(name, source)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkReplayDone()
+	if err != nil {
+		return err
+	}
+
+	return c.seq.RegisterStoredProcedure(ctx, name, source)
+}
+
+// SetCurrentStoredProcedure makes an installed version of a stored procedure the current version.
+// Transactions that don't pin a version run with the current version.
+// This is how a bad deploy is rolled back.
+// The change is validated when it is applied in log order and ignored if the version doesn't exist.
+func (c *Calvin) SetCurrentStoredProcedure(ctx context.Context, name string, version uint64) error {
+	err := checkStoredProcedureVersion(name, version)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkReplayDone()
+	if err != nil {
+		return err
+	}
+
+	return c.seq.SetCurrentStoredProcedure(ctx, name, version)
+}
+
+// RemoveStoredProcedure removes a version of a stored procedure.
+// Transactions that were sequenced before the removal still run with that version.
+// The change is validated when it is applied in log order and ignored if the version
+// doesn't exist or is the current version.
+func (c *Calvin) RemoveStoredProcedure(ctx context.Context, name string, version uint64) error {
+	err := checkStoredProcedureVersion(name, version)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkReplayDone()
+	if err != nil {
+		return err
+	}
+
+	return c.seq.RemoveStoredProcedure(ctx, name, version)
+}
+
+// ListStoredProcedures returns all installed versions of all stored procedures on this node.
+func (c *Calvin) ListStoredProcedures() []*pb.StoredProcedure {
+	return c.storedProcs.List()
+}
+
+// AddNode adds a node to the cluster as a learner.
+// Once the change is committed, all nodes know the address of the new node.
+// A new node doesn't own any partitions.
+// AddNode returns once the change was applied on this node.
+func (c *Calvin) AddNode(ctx context.Context, nodeID uint64, address string) error {
+	if nodeID == 0 {
+		return fmt.Errorf("node id needs to be greater than zero")
+	} else if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid address [%s]: %s", address, err.Error())
+	}
+
+	return c.seq.AddNode(ctx, nodeID, address)
+}
+
+// PromoteLearner makes a node that was added as learner a voting member of the cluster.
+// PromoteLearner returns once the change was applied on this node and fails for nodes that aren't learners.
+func (c *Calvin) PromoteLearner(ctx context.Context, nodeID uint64) error {
+	if nodeID == 0 {
+		return fmt.Errorf("node id needs to be greater than zero")
+	}
+
+	return c.seq.PromoteLearner(ctx, nodeID)
+}
+
+// RemoveNode removes a node from the cluster and from the address book of all nodes.
+// RemoveNode returns once the change was applied on this node.
+func (c *Calvin) RemoveNode(ctx context.Context, nodeID uint64) error {
+	if nodeID == 0 {
+		return fmt.Errorf("node id needs to be greater than zero")
+	}
+
+	return c.seq.RemoveNode(ctx, nodeID)
+}
+
+func checkStoredProcedureVersion(name string, version uint64) error {
+	if name == "" {
+		return fmt.Errorf("stored procedure needs a name")
+	} else if version == 0 {
+		return fmt.Errorf("stored procedure version needs to be greater than zero")
+	}
+	return nil
+}
+
+// LowIsolationRead is served locally if this node holds a copy of the key.
+// That includes learners. Otherwise the owner of the key is asked.
 func (c *Calvin) LowIsolationRead(key []byte) ([]byte, error) {
-	ownerID := c.cip.FindOwnerForKey(key)
+	results := c.LowIsolationReadKeys(context.Background(), [][]byte{key})
+	return results[0].Value, results[0].Err
+}
+
+// A ReadResult is the outcome of reading a single key.
+type ReadResult struct {
+	Key   []byte
+	Value []byte
+	// Found is false if the key doesn't exist
+	Found bool
+	// Err is set if the key couldn't be read because its owner wasn't reachable
+	// reads that fail as a whole leave it unset and return an error instead
+	Err error
+}
+
+// LowIsolationReadKeys reads keys with the same guarantees as LowIsolationRead.
+// Keys are grouped by their owners and all owners are asked in parallel.
+// The results are in the order of the keys.
+// An owner that can't be reached only fails the keys it owns.
+func (c *Calvin) LowIsolationReadKeys(ctx context.Context, keys [][]byte) []ReadResult {
+	results := make([]ReadResult, len(keys))
+	// positions of the keys each owner is asked for
+	ownerToPositions := make(map[uint64][]int)
+	for idx := range keys {
+		results[idx].Key = keys[idx]
+		ownerID := c.myRaftID
+		if !c.cip.IsLocal(keys[idx]) {
+			ownerID = c.cip.FindOwnerForKey(keys[idx])
+		}
+		ownerToPositions[ownerID] = append(ownerToPositions[ownerID], idx)
+	}
+
+	wg := &sync.WaitGroup{}
+	for ownerID, positions := range ownerToPositions {
+		wg.Add(1)
+		go func(ownerID uint64, positions []int) {
+			defer wg.Done()
+			req := &pb.LowIsolationReadRequest{
+				Keys: make([][]byte, len(positions)),
+			}
+			for idx := range positions {
+				req.Keys[idx] = keys[positions[idx]]
+			}
+
+			resp, err := c.readFrom(ctx, ownerID, req)
+			if err == nil && (len(resp.Values) != len(positions) || len(resp.Found) != len(positions)) {
+				err = fmt.Errorf("node [%d] answered %d out of %d keys", ownerID, len(resp.Values), len(positions))
+			}
+
+			// every goroutine owns its positions
+			for idx := range positions {
+				result := &results[positions[idx]]
+				if err != nil {
+					result.Err = err
+				} else {
+					result.Value = resp.Values[idx]
+					result.Found = resp.Found[idx]
+				}
+			}
+		}(ownerID, positions)
+	}
+	wg.Wait()
+	return results
+}
+
+// LinearizableRead reads a key just like LowIsolationRead but the node serving the read
+// first catches up with everything the raft leader committed before the read.
+// The response carries index and term of the last batch the read reflects.
+func (c *Calvin) LinearizableRead(ctx context.Context, key []byte) (*pb.LowIsolationReadResponse, error) {
+	return c.read(ctx, &pb.LowIsolationReadRequest{
+		Keys:         [][]byte{key},
+		Linearizable: true,
+	})
+}
+
+// SnapshotRead reads local keys from a consistent view of this node's partitions as of a batch boundary.
+// minIndex is a lower bound. The view reflects all batches up to minIndex and possibly later ones
+// and is shared with other snapshot reads for a while.
+// Snapshot reads don't take locks and don't occupy workers.
+// With partitions that keep older versions of their values snapshot reads don't hold back any txns either.
+// The response carries index and term of the last batch the view reflects.
+// Use HistoricalRead to read as of exactly one batch.
+func (c *Calvin) SnapshotRead(ctx context.Context, keys [][]byte, minIndex uint64) (*pb.LowIsolationReadResponse, error) {
+	view, err := c.snapshotReader.acquireViewAtOrAfter(ctx, minIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer view.Release()
+
+	resp := &pb.LowIsolationReadResponse{
+		Keys:   keys,
+		Values: make([][]byte, len(keys)),
+		Found:  make([]bool, len(keys)),
+		Index:  view.Index(),
+		Term:   view.Term(),
+	}
+	for idx := range keys {
+		resp.Values[idx], err = view.Get(keys[idx])
+		if err != nil {
+			return nil, err
+		}
+		resp.Found[idx] = resp.Values[idx] != nil
+	}
+	return resp, nil
+}
+
+// HistoricalRead reads local keys as they were right after the batch with the provided index ran.
+// It only works with data stores that keep older versions of their values
+// and only as far back as the version horizon reaches.
+// The results are in the order of the keys.
+// Terms of past batches aren't kept which is why, unlike other reads, there is no term to go with the index.
+func (c *Calvin) HistoricalRead(ctx context.Context, keys [][]byte, index uint64) ([]ReadResult, error) {
+	// later batches might not be done yet
+	select {
+	case <-c.engine.WaitForCheckpoint(index):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	version := util.Version{
+		BatchIndex:  index,
+		TxnPosition: math.MaxUint64,
+	}
+	txns := make(map[int]util.DataStoreTxn)
+	defer func() {
+		for _, txn := range txns {
+			txn.Rollback()
+		}
+	}()
+
+	results := make([]ReadResult, len(keys))
+	for idx := range keys {
+		results[idx].Key = keys[idx]
+		if !c.cip.IsLocal(keys[idx]) {
+			return nil, fmt.Errorf("key [%s] isn't local", string(keys[idx]))
+		}
+
+		partitionID := c.cip.FindPartitionForKey(keys[idx])
+		txn, ok := txns[partitionID]
+		if !ok {
+			txnProvider, err := c.partitionDataStore.GetPartition(partitionID)
+			if err != nil {
+				return nil, err
+			}
+
+			versionedTxnProvider, ok := txnProvider.(util.VersionedDataStoreTxnProvider)
+			if !ok {
+				return nil, fmt.Errorf("partition [%d] doesn't keep older versions", partitionID)
+			}
+
+			txn, err = versionedTxnProvider.StartTxnAt(version)
+			if err != nil {
+				return nil, err
+			}
+			txns[partitionID] = txn
+		}
+
+		value := txn.Get(keys[idx])
+		if value != nil {
+			// values are only valid as long as the txn is open
+			results[idx].Value = append([]byte{}, value...)
+			results[idx].Found = true
+		}
+	}
+	return results, nil
+}
+
+func (c *Calvin) read(ctx context.Context, req *pb.LowIsolationReadRequest) (*pb.LowIsolationReadResponse, error) {
+	key := req.Keys[0]
+	ownerID := c.myRaftID
+	if !c.cip.IsLocal(key) {
+		ownerID = c.cip.FindOwnerForKey(key)
+	}
+	return c.readFrom(ctx, ownerID, req)
+}
+
+// all keys in the request need to belong to the node
+func (c *Calvin) readFrom(ctx context.Context, ownerID uint64, req *pb.LowIsolationReadRequest) (*pb.LowIsolationReadResponse, error) {
+	if ownerID == c.myRaftID {
+		return c.sched.LowIsolationRead(ctx, req)
+	}
+
 	client, err := c.cc.GetLowIsolationReadClient(ownerID)
 	if err != nil {
 		c.logger.Errorf("%s", err.Error())
+		return nil, err
+	} else if client == nil {
+		return nil, fmt.Errorf("no connection to node [%d]", ownerID)
 	}
+	return client.LowIsolationRead(ctx, req)
+}
 
-	ctx := context.Background()
-	req := &pb.LowIsolationReadRequest{
-		Keys: [][]byte{key},
+// waits for the read index of the sequencer to be executed on this node
+func (c *Calvin) readBarrier(ctx context.Context) (uint64, uint64, error) {
+	index, term, err := c.seq.ReadIndex(ctx)
+	if err != nil {
+		return 0, 0, err
 	}
-	resp, err := client.LowIsolationRead(ctx, req)
-	return resp.Values[0], err
+
+	select {
+	case <-c.engine.WaitForCheckpoint(index):
+		return index, term, nil
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	}
+}
+
+// BatchStats returns statistics about the batches this node sequenced.
+func (c *Calvin) BatchStats() sequencer.BatchStats {
+	return c.seq.BatchStats()
 }
 
 func (c *Calvin) LogToJSON(out io.Writer) error {
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"context"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	log "github.com/sirupsen/logrus"
+)
+
+func newCalvinServer(c *Calvin, logger *log.Entry) *calvinServer {
+	return &calvinServer{
+		c:      c,
+		logger: logger,
+	}
+}
+
+// the calvin server is the entry point for clients that live in a different process
+// it does the same thing as calling SubmitTransaction on a calvin object
+type calvinServer struct {
+	c      *Calvin
+	logger *log.Entry
+}
+
+func (cs *calvinServer) SubmitTransaction(ctx context.Context, req *pb.SubmitTransactionRequest) (*pb.SubmitTransactionResponse, error) {
+	if req.Txn == nil {
+		return &pb.SubmitTransactionResponse{
+			Accepted: false,
+			Error:    "request doesn't contain a transaction",
+		}, nil
+	}
+
+	// the request belongs to this call
+	// the id is assigned here so that it can be part of the response
+	txn := req.Txn
+	if txn.Id == nil {
+		id, err := ulid.NewId()
+		if err != nil {
+			return nil, err
+		}
+		txn.Id = id.ToProto()
+	}
+
+	if !req.WaitForOutcome {
+		err := cs.c.SubmitTransaction(txn)
+		if err != nil {
+			return &pb.SubmitTransactionResponse{
+				TxnId:    txn.Id,
+				Accepted: false,
+				Error:    err.Error(),
+			}, nil
+		}
+
+		return &pb.SubmitTransactionResponse{
+			TxnId:    txn.Id,
+			Accepted: true,
+		}, nil
+	}
+
+	f, err := cs.c.SubmitTransactionWithFuture(txn)
+	if err != nil {
+		return &pb.SubmitTransactionResponse{
+			TxnId:    txn.Id,
+			Accepted: false,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	outcome, err := f.Wait(ctx)
+	if err != nil {
+		// the txn was handed to the sequencer
+		// but the client gave up waiting for it
+		return &pb.SubmitTransactionResponse{
+			TxnId:    txn.Id,
+			Accepted: true,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	return &pb.SubmitTransactionResponse{
+		TxnId:    txn.Id,
+		Accepted: true,
+		Outcome:  outcome,
+	}, nil
+}
+
+func (cs *calvinServer) RegisterStoredProcedure(ctx context.Context, req *pb.RegisterStoredProcedureRequest) (*pb.RegisterStoredProcedureResponse, error) {
+	err := cs.c.RegisterStoredProcedure(ctx, req.Name, req.Source)
+	if err != nil {
+		return &pb.RegisterStoredProcedureResponse{
+			Accepted: false,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	return &pb.RegisterStoredProcedureResponse{
+		Accepted: true,
+	}, nil
+}
+
+func (cs *calvinServer) SetCurrentStoredProcedure(ctx context.Context, req *pb.SetCurrentStoredProcedureRequest) (*pb.SetCurrentStoredProcedureResponse, error) {
+	err := cs.c.SetCurrentStoredProcedure(ctx, req.Name, req.Version)
+	if err != nil {
+		return &pb.SetCurrentStoredProcedureResponse{
+			Accepted: false,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	return &pb.SetCurrentStoredProcedureResponse{
+		Accepted: true,
+	}, nil
+}
+
+func (cs *calvinServer) RemoveStoredProcedure(ctx context.Context, req *pb.RemoveStoredProcedureRequest) (*pb.RemoveStoredProcedureResponse, error) {
+	err := cs.c.RemoveStoredProcedure(ctx, req.Name, req.Version)
+	if err != nil {
+		return &pb.RemoveStoredProcedureResponse{
+			Accepted: false,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	return &pb.RemoveStoredProcedureResponse{
+		Accepted: true,
+	}, nil
+}
+
+func (cs *calvinServer) ListStoredProcedures(ctx context.Context, req *pb.ListStoredProceduresRequest) (*pb.ListStoredProceduresResponse, error) {
+	return &pb.ListStoredProceduresResponse{
+		Procedures: cs.c.ListStoredProcedures(),
+	}, nil
+}
+
+func (cs *calvinServer) AddNode(ctx context.Context, req *pb.AddNodeRequest) (*pb.AddNodeResponse, error) {
+	err := cs.c.AddNode(ctx, req.NodeId, req.Address)
+	if err != nil {
+		return &pb.AddNodeResponse{
+			Committed: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	return &pb.AddNodeResponse{
+		Committed: true,
+	}, nil
+}
+
+func (cs *calvinServer) RemoveNode(ctx context.Context, req *pb.RemoveNodeRequest) (*pb.RemoveNodeResponse, error) {
+	err := cs.c.RemoveNode(ctx, req.NodeId)
+	if err != nil {
+		return &pb.RemoveNodeResponse{
+			Committed: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	return &pb.RemoveNodeResponse{
+		Committed: true,
+	}, nil
+}
+
+func (cs *calvinServer) PromoteLearner(ctx context.Context, req *pb.PromoteLearnerRequest) (*pb.PromoteLearnerResponse, error) {
+	err := cs.c.PromoteLearner(ctx, req.NodeId)
+	if err != nil {
+		return &pb.PromoteLearnerResponse{
+			Committed: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	return &pb.PromoteLearnerResponse{
+		Committed: true,
+	}, nil
+}
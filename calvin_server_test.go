@@ -0,0 +1,79 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func TestCalvinServerSubmitTransaction(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinServerSubmitTransaction")
+	opts := defaultOptionsWithFilePaths(configBag.path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+
+	conn, err := grpc.Dial(c.cip.GetAddressFor(configBag.id), grpc.WithInsecure())
+	assert.Nil(t, err)
+	client := pb.NewCalvinClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	txn := &pb.Transaction{}
+	err = txn.AddSimpleSetterArg([]byte("narf"), []byte("narf_value"))
+	assert.Nil(t, err)
+	resp, err := client.SubmitTransaction(ctx, &pb.SubmitTransactionRequest{
+		Txn: txn,
+	})
+	assert.Nil(t, err)
+	assert.True(t, resp.Accepted)
+	assert.Equal(t, "", resp.Error)
+	assert.NotNil(t, resp.TxnId)
+
+	txn = &pb.Transaction{}
+	err = txn.AddSimpleSetterArg(findLocalKey(c), []byte("narf_value_2"))
+	assert.Nil(t, err)
+	resp, err = client.SubmitTransaction(ctx, &pb.SubmitTransactionRequest{
+		Txn:            txn,
+		WaitForOutcome: true,
+	})
+	assert.Nil(t, err)
+	assert.True(t, resp.Accepted)
+	assert.Equal(t, "", resp.Error)
+	assert.NotNil(t, resp.Outcome)
+	assert.Equal(t, pb.COMMITTED, resp.Outcome.Status)
+	assert.True(t, resp.TxnId.Equal(resp.Outcome.TxnId))
+
+	resp, err = client.SubmitTransaction(ctx, &pb.SubmitTransactionRequest{})
+	assert.Nil(t, err)
+	assert.False(t, resp.Accepted)
+	assert.NotEqual(t, "", resp.Error)
+
+	conn.Close()
+	c.Stop()
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+}
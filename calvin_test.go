@@ -17,8 +17,10 @@
 package calvin
 
 import (
+	"context"
 	"fmt"
 	"html/template"
+	"io/ioutil"
 	"os"
 	"runtime/pprof"
 	"strconv"
@@ -26,7 +28,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mhelmich/calvin/execution"
+	"github.com/mhelmich/calvin/mocks"
 	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/sequencer"
 	"github.com/mhelmich/calvin/ulid"
 	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
@@ -47,6 +52,60 @@ func TestCalvinStartStop(t *testing.T) {
 	defer os.RemoveAll(ciPath)
 }
 
+func TestCalvinOptions(t *testing.T) {
+	opts := DefaultOptions(nil, nil).withDefaults()
+	assert.True(t, opts.numWorkers > 0)
+	assert.Equal(t, opts.numWorkers*2+1, opts.channelSize)
+	assert.Equal(t, sequencer.DefaultBatchFrequency, opts.batchFrequency)
+	assert.Equal(t, sequencer.DefaultMaxBatchTxns, opts.maxBatchTxns)
+	assert.Equal(t, sequencer.DefaultMaxBatchBytes, opts.maxBatchBytes)
+	assert.Equal(t, sequencer.DefaultRaftTickInterval, opts.raftTickInterval)
+	assert.Equal(t, 1, opts.numSequencerGroups)
+	assert.Equal(t, execution.DefaultDedupWindow, *opts.dedupWindow)
+	assert.Equal(t, execution.DefaultVersionHorizon, *opts.versionHorizon)
+	assert.Equal(t, DefaultSnapshotViewMaxAge, opts.snapshotViewMaxAge)
+	// data store and cluster info are missing
+	assert.NotNil(t, opts.validate())
+
+	opts = DefaultOptions(new(mocks.PartitionedDataStore), new(mocks.ClusterInfoProvider)).
+		WithNumWorkers(32).
+		WithBatchFrequency(10 * time.Millisecond).
+		WithMaxBatchTxns(100).
+		WithMaxBatchBytes(4096).
+		WithRaftTickInterval(50 * time.Millisecond).
+		WithNumSequencerGroups(4).
+		WithDedupWindow(uint64(16)).
+		WithVersionHorizon(uint64(8)).
+		WithSnapshotViewMaxAge(20 * time.Millisecond).
+		withDefaults()
+	assert.Nil(t, opts.validate())
+	assert.Equal(t, 32, opts.numWorkers)
+	assert.Equal(t, 65, opts.channelSize)
+	assert.Equal(t, 10*time.Millisecond, opts.batchFrequency)
+	assert.Equal(t, 100, opts.maxBatchTxns)
+	assert.Equal(t, 4096, opts.maxBatchBytes)
+	assert.Equal(t, 50*time.Millisecond, opts.raftTickInterval)
+	assert.Equal(t, 4, opts.numSequencerGroups)
+	assert.Equal(t, uint64(16), *opts.dedupWindow)
+	assert.Equal(t, uint64(8), *opts.versionHorizon)
+	assert.Equal(t, 20*time.Millisecond, opts.snapshotViewMaxAge)
+
+	// zero turns dedup off and isn't replaced by the default
+	assert.Equal(t, uint64(0), *opts.WithDedupWindow(uint64(0)).withDefaults().dedupWindow)
+	// zero keeps all versions
+	assert.Equal(t, uint64(0), *opts.WithVersionHorizon(uint64(0)).withDefaults().versionHorizon)
+
+	assert.NotNil(t, opts.WithNumWorkers(-1).validate())
+	assert.NotNil(t, opts.WithChannelSize(-1).validate())
+	assert.NotNil(t, opts.WithBatchFrequency(time.Microsecond).validate())
+	assert.NotNil(t, opts.WithMaxBatchTxns(-1).validate())
+	assert.NotNil(t, opts.WithMaxBatchBytes(-1).validate())
+	assert.NotNil(t, opts.WithRaftTickInterval(-time.Second).validate())
+	assert.NotNil(t, opts.WithNumSequencerGroups(-1).validate())
+	assert.NotNil(t, opts.WithSnapshotViewMaxAge(-time.Second).validate())
+	assert.NotNil(t, opts.WithPartialSnapshotHandler(new(mocks.PartialSnapshotHandler)).validate())
+}
+
 func TestCalvinPushTxns(t *testing.T) {
 	configBags, ciPath := generateNConfigFiles(t, 1)
 	configBag := configBags[0]
@@ -57,12 +116,13 @@ func TestCalvinPushTxns(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		id, err := ulid.NewId()
 		assert.Nil(t, err)
-		c.SubmitTransaction(&pb.Transaction{
+		err = c.SubmitTransaction(&pb.Transaction{
 			Id:              id.ToProto(),
 			WriterNodes:     []uint64{configBag.id},
 			ReadWriteSet:    [][]byte{[]byte("narf")},
 			StoredProcedure: "__simple_setter__",
 		})
+		assert.Nil(t, err)
 	}
 
 	time.Sleep(3 * time.Second)
@@ -72,6 +132,675 @@ func TestCalvinPushTxns(t *testing.T) {
 	defer os.RemoveAll(ciPath)
 }
 
+func TestCalvinSubmitTransactionWithFuture(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinSubmitTransactionWithFuture")
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+
+	key := findLocalKey(c)
+	txn := NewTransaction()
+	err := txn.AddSimpleSetterArg(key, []byte("narf_value"))
+	assert.Nil(t, err)
+	f, err := c.SubmitTransactionWithFuture(txn)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	outcome, err := f.Wait(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+	assert.Equal(t, "", outcome.Error)
+	assert.True(t, outcome.BatchIndex > uint64(0))
+	assert.True(t, outcome.BatchTerm > uint64(0))
+	assert.True(t, txn.Id.Equal(outcome.TxnId))
+	// the txn of the caller is left alone
+	assert.Equal(t, uint64(0), txn.OriginNode)
+
+	txn = NewTransaction()
+	txn.StoredProcedure = "__does_not_exist__"
+	txn.ReadWriteSet = [][]byte{key}
+	f, err = c.SubmitTransactionWithFuture(txn)
+	assert.Nil(t, err)
+	outcome, err = f.Wait(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.FAILED, outcome.Status)
+	assert.NotEqual(t, "", outcome.Error)
+
+	c.Stop()
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+}
+
+func TestCalvinSubmitTransactionAgain(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinSubmitTransactionAgain")
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+
+	err := c.RegisterStoredProcedure(context.Background(), "increment", `
+		local v = tonumber(store:Get(KEYV[1])) or 0
+		store:Set(KEYV[1], tostring(v + 1))
+		return v + 1
+	`)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := findLocalKey(c)
+	txn := NewTransaction()
+	txn.StoredProcedure = "increment"
+	txn.ReadWriteSet = [][]byte{key}
+	first := submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.COMMITTED, first.Status)
+	assert.Equal(t, "1", string(first.Result))
+
+	// the retry doesn't increment again
+	retry := &pb.Transaction{
+		Id:              txn.Id,
+		StoredProcedure: "increment",
+		ReadWriteSet:    [][]byte{key},
+	}
+	outcome := submitAndWait(ctx, t, c, retry)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+	assert.Equal(t, "1", string(outcome.Result))
+	assert.Equal(t, first.BatchIndex, outcome.BatchIndex)
+
+	// retries that are still running share their future
+	retry = &pb.Transaction{
+		Id:              txn.Id,
+		StoredProcedure: "increment",
+		ReadWriteSet:    [][]byte{key},
+	}
+	f1, err := c.SubmitTransactionWithFuture(retry)
+	assert.Nil(t, err)
+	f2, err := c.SubmitTransactionWithFuture(retry)
+	assert.Nil(t, err)
+	assert.True(t, f1 == f2)
+	outcome, err = f1.Wait(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", string(outcome.Result))
+
+	txn = NewTransaction()
+	txn.StoredProcedure = "increment"
+	txn.ReadWriteSet = [][]byte{key}
+	outcome = submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, "2", string(outcome.Result))
+	c.Stop()
+}
+
+func TestCalvinLinearizableRead(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinLinearizableRead")
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := findLocalKey(c)
+	txn := NewTransaction()
+	err := txn.AddSimpleSetterArg(key, []byte("narf_value"))
+	assert.Nil(t, err)
+	outcome := submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+
+	resp, err := c.LinearizableRead(ctx, key)
+	assert.Nil(t, err)
+	assert.Equal(t, "narf_value", string(resp.Values[0]))
+	assert.True(t, resp.Index >= outcome.BatchIndex)
+	assert.True(t, resp.Term >= outcome.BatchTerm)
+
+	// low isolation reads don't know where in the log they are
+	value, err := c.LowIsolationRead(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "narf_value", string(value))
+	c.Stop()
+}
+
+func TestCalvinSnapshotRead(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinSnapshotRead")
+	// views are shared for longer than a txn takes
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds).WithSnapshotViewMaxAge(time.Second)
+	c := NewCalvin(opts)
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := findLocalKey(c)
+	txn := NewTransaction()
+	err := txn.AddSimpleSetterArg(key, []byte("narf_value"))
+	assert.Nil(t, err)
+	outcome := submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+
+	resp, err := c.SnapshotRead(ctx, [][]byte{key}, outcome.BatchIndex)
+	assert.Nil(t, err)
+	assert.Equal(t, "narf_value", string(resp.Values[0]))
+	assert.True(t, resp.Index >= outcome.BatchIndex)
+
+	// reads that are fine with an older view share it
+	txn = NewTransaction()
+	err = txn.AddSimpleSetterArg(key, []byte("moep_value"))
+	assert.Nil(t, err)
+	newOutcome := submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.COMMITTED, newOutcome.Status)
+	oldResp, err := c.SnapshotRead(ctx, [][]byte{key}, outcome.BatchIndex)
+	assert.Nil(t, err)
+	assert.Equal(t, resp.Index, oldResp.Index)
+	assert.Equal(t, "narf_value", string(oldResp.Values[0]))
+
+	newResp, err := c.SnapshotRead(ctx, [][]byte{key}, newOutcome.BatchIndex)
+	assert.Nil(t, err)
+	assert.Equal(t, "moep_value", string(newResp.Values[0]))
+	assert.True(t, newResp.Index >= newOutcome.BatchIndex)
+	c.Stop()
+}
+
+func TestCalvinHistoricalRead(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	baseDir := fmt.Sprintf("./test-TestCalvinHistoricalRead-%d/", util.RandomRaftId())
+	err := os.MkdirAll(baseDir, os.ModePerm)
+	assert.Nil(t, err)
+	pds := newPartitionedVersionedBoltStore(baseDir, log.WithFields(log.Fields{}))
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+	defer os.RemoveAll(baseDir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := findLocalKey(c)
+	outcomes := make([]*pb.TransactionOutcome, 2)
+	for idx, value := range []string{"narf_value", "moep_value"} {
+		txn := NewTransaction()
+		err = txn.AddSimpleSetterArg(key, []byte(value))
+		assert.Nil(t, err)
+		outcomes[idx] = submitAndWait(ctx, t, c, txn)
+		assert.Equal(t, pb.COMMITTED, outcomes[idx].Status)
+	}
+
+	results, err := c.HistoricalRead(ctx, [][]byte{key}, outcomes[0].BatchIndex-1)
+	assert.Nil(t, err)
+	assert.False(t, results[0].Found)
+	results, err = c.HistoricalRead(ctx, [][]byte{key}, outcomes[0].BatchIndex)
+	assert.Nil(t, err)
+	assert.Equal(t, key, results[0].Key)
+	assert.Equal(t, "narf_value", string(results[0].Value))
+	results, err = c.HistoricalRead(ctx, [][]byte{key}, outcomes[1].BatchIndex)
+	assert.Nil(t, err)
+	assert.Equal(t, "moep_value", string(results[0].Value))
+
+	// snapshot reads don't need a batch boundary with versioned partitions
+	resp, err := c.SnapshotRead(ctx, [][]byte{key}, outcomes[1].BatchIndex)
+	assert.Nil(t, err)
+	assert.True(t, resp.Index >= outcomes[1].BatchIndex)
+	assert.Equal(t, "moep_value", string(resp.Values[0]))
+
+	// all other reads see the latest version
+	value, err := c.LowIsolationRead(key)
+	assert.Nil(t, err)
+	assert.Equal(t, "moep_value", string(value))
+	c.Stop()
+}
+
+func TestCalvinRegisterStoredProcedure(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinRegisterStoredProcedure")
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+
+	err := c.RegisterStoredProcedure(context.Background(), "__simple_setter__", "return 1")
+	assert.NotNil(t, err)
+	err = c.RegisterStoredProcedure(context.Background(), "narf_proc", "this isn't lua")
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	key := findLocalKey(c)
+	for _, version := range []string{"v1", "v2"} {
+		err = c.RegisterStoredProcedure(ctx, "narf_proc", fmt.Sprintf("return \"%s\"", version))
+		assert.Nil(t, err)
+
+		txn := NewTransaction()
+		txn.StoredProcedure = "narf_proc"
+		txn.ReadWriteSet = [][]byte{key}
+		f, err := c.SubmitTransactionWithFuture(txn)
+		assert.Nil(t, err)
+		outcome, err := f.Wait(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, pb.COMMITTED, outcome.Status)
+		var result string
+		err = outcome.UnmarshalResult(&result)
+		assert.Nil(t, err)
+		assert.Equal(t, version, result)
+	}
+
+	// pinning an older version
+	txn := NewTransaction()
+	txn.StoredProcedure = "narf_proc"
+	txn.StoredProcedureVersion = uint64(1)
+	txn.ReadWriteSet = [][]byte{key}
+	outcome := submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, `"v1"`, string(outcome.Result))
+
+	// rolling back to version 1 and removing version 2
+	err = c.SetCurrentStoredProcedure(ctx, "narf_proc", uint64(0))
+	assert.NotNil(t, err)
+	err = c.SetCurrentStoredProcedure(ctx, "narf_proc", uint64(1))
+	assert.Nil(t, err)
+	err = c.RemoveStoredProcedure(ctx, "narf_proc", uint64(2))
+	assert.Nil(t, err)
+
+	txn = NewTransaction()
+	txn.StoredProcedure = "narf_proc"
+	txn.ReadWriteSet = [][]byte{key}
+	outcome = submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, `"v1"`, string(outcome.Result))
+
+	txn = NewTransaction()
+	txn.StoredProcedure = "narf_proc"
+	txn.StoredProcedureVersion = uint64(2)
+	txn.ReadWriteSet = [][]byte{key}
+	outcome = submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.FAILED, outcome.Status)
+
+	// removing the current version is ignored
+	err = c.RemoveStoredProcedure(ctx, "narf_proc", uint64(1))
+	assert.Nil(t, err)
+	txn = NewTransaction()
+	txn.StoredProcedure = "narf_proc"
+	txn.ReadWriteSet = [][]byte{key}
+	outcome = submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, `"v1"`, string(outcome.Result))
+
+	procs := c.ListStoredProcedures()
+	assert.Equal(t, 2, len(procs))
+	assert.Equal(t, "__simple_setter__", procs[0].Name)
+	assert.Equal(t, "narf_proc", procs[1].Name)
+	assert.Equal(t, uint64(1), procs[1].Version)
+	assert.True(t, procs[1].Current)
+
+	c.Stop()
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+}
+
+func TestCalvinStoredProcedureScan(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	pds := newPartitionedDataStore(t, "TestCalvinStoredProcedureScan")
+	opts := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds)
+	c := NewCalvin(opts)
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// scans only see keys in local partitions
+	isScannable := func(key string) bool {
+		partitionID := c.cip.FindPartitionForKey([]byte(key))
+		for _, localPartitionID := range c.cip.MyPartitions() {
+			if localPartitionID == partitionID {
+				return c.cip.IsLocal([]byte(key))
+			}
+		}
+		return false
+	}
+	keys := make([]string, 0)
+	for i := 0; len(keys) < 3; i++ {
+		key := fmt.Sprintf("scan/%d", i)
+		if isScannable(key) {
+			keys = append(keys, key)
+		}
+	}
+	otherKey := "scanx"
+	for i := 0; !isScannable(otherKey); i++ {
+		otherKey = fmt.Sprintf("scanx%d", i)
+	}
+
+	txn := NewTransaction()
+	for _, key := range []string{keys[2], keys[0], otherKey, keys[1]} {
+		err := txn.AddSimpleSetterArg([]byte(key), []byte("value_"+key))
+		assert.Nil(t, err)
+	}
+	outcome := submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+
+	// pages of two keys each
+	err := c.RegisterStoredProcedure(ctx, "scan_proc", fmt.Sprintf(`
+		local pages = {}
+		local from = ""
+		repeat
+			local keys, values, nextKey = store:ScanPrefix("scan/", from, 2)
+			local page = {}
+			for i = 1, #keys do
+				page[i] = keys[i] .. "=" .. values[i]
+			end
+			pages[#pages + 1] = table.concat(page, ",")
+			from = nextKey
+		until from == ""
+
+		local keys, values, nextKey = store:Scan("%s", "scanx", 0)
+		pages[#pages + 1] = table.concat({keys[1], keys[2], nextKey}, ",")
+
+		-- checkpoints and markers sort before all of these keys
+		keys, values, nextKey = store:Scan("", "scan/", 0)
+		pages[#pages + 1] = tostring(#keys)
+		return pages
+	`, keys[1]))
+	assert.Nil(t, err)
+
+	// only txns that say so can scan
+	txn = NewTransaction()
+	txn.StoredProcedure = "scan_proc"
+	txn.ReadWriteSet = [][]byte{[]byte(keys[0])}
+	outcome = submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.ABORTED, outcome.Status)
+
+	txn = NewTransaction()
+	txn.StoredProcedure = "scan_proc"
+	txn.ReadWriteSet = [][]byte{[]byte(keys[0])}
+	txn.Scans = true
+	outcome = submitAndWait(ctx, t, c, txn)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+
+	// partitions that were only scanned don't get a marker
+	markerKey := append([]byte("\x00calvin/applied/"), util.Uint64ToBytes(txn.Id.Upper)...)
+	markerKey = append(markerKey, util.Uint64ToBytes(txn.Id.Lower)...)
+	numMarkers := 0
+	for _, partitionID := range c.cip.MyPartitions() {
+		bds, err := pds.GetPartition(partitionID)
+		assert.Nil(t, err)
+		dsTxn, err := bds.StartTxn(false)
+		assert.Nil(t, err)
+		if dsTxn.Get(markerKey) != nil {
+			numMarkers++
+		}
+		assert.Nil(t, dsTxn.Rollback())
+	}
+	assert.Equal(t, 1, numMarkers)
+
+	var pages []string
+	err = outcome.UnmarshalResult(&pages)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		fmt.Sprintf("%s=value_%s,%s=value_%s", keys[0], keys[0], keys[1], keys[1]),
+		fmt.Sprintf("%s=value_%s", keys[2], keys[2]),
+		fmt.Sprintf("%s,%s,", keys[1], keys[2]),
+		"0",
+	}, pages)
+	c.Stop()
+}
+
+func TestCalvinReplayAfterRestart(t *testing.T) {
+	testCalvinReplayAfterRestart(t, 1)
+}
+
+// checkpoints are positions in the merged order of all groups
+// every group replays from there on its own
+func TestCalvinReplayAfterRestartWithSequencerGroups(t *testing.T) {
+	testCalvinReplayAfterRestart(t, 3)
+}
+
+func testCalvinReplayAfterRestart(t *testing.T, numSequencerGroups int) {
+	configBags, ciPath := generateNConfigFiles(t, 1)
+	configBag := configBags[0]
+	baseDir := fmt.Sprintf("./test-TestCalvinReplayAfterRestart-%d/", util.RandomRaftId())
+	backupDir := fmt.Sprintf("./test-TestCalvinReplayAfterRestart-backup-%d/", util.RandomRaftId())
+	defer os.RemoveAll(configBag.path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBag.id))
+	defer os.RemoveAll(ciPath)
+	defer os.RemoveAll(baseDir)
+	defer os.RemoveAll(backupDir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	logger := log.WithFields(log.Fields{})
+	increment := func(c *Calvin, key []byte) int {
+		txn := NewTransaction()
+		txn.StoredProcedure = "increment"
+		txn.ReadWriteSet = [][]byte{key}
+		outcome := submitAndWait(ctx, t, c, txn)
+		assert.Equal(t, pb.COMMITTED, outcome.Status)
+		var result int
+		err := outcome.UnmarshalResult(&result)
+		assert.Nil(t, err)
+		return result
+	}
+
+	err := os.MkdirAll(baseDir, os.ModePerm)
+	assert.Nil(t, err)
+	opts := defaultOptionsWithFilePaths(configBag.path, ciPath).
+		WithBatchFrequency(10 * time.Millisecond).
+		WithNumSequencerGroups(numSequencerGroups)
+	c := NewCalvin(opts.WithDataStore(newPartitionedBoltStore(baseDir, logger)))
+	err = c.RegisterStoredProcedure(ctx, "increment", `
+		local v = tonumber(store:Get(KEYV[1])) or 0
+		store:Set(KEYV[1], tostring(v + 1))
+		return v + 1
+	`)
+	assert.Nil(t, err)
+	key := findLocalKey(c)
+	assert.Equal(t, 1, increment(c, key))
+	c.Stop()
+
+	// keep the data store the way it was after the first increment
+	copyFiles(t, baseDir, backupDir)
+
+	c = NewCalvin(opts.WithDataStore(newPartitionedBoltStore(baseDir, logger)))
+	assert.Equal(t, 2, increment(c, key))
+	assert.Equal(t, 3, increment(c, key))
+	c.Stop()
+
+	// the data store lost the last two increments
+	// they are replayed from the log before new txns are accepted
+	err = os.RemoveAll(baseDir)
+	assert.Nil(t, err)
+	copyFiles(t, backupDir, baseDir)
+
+	c = NewCalvin(opts.WithDataStore(newPartitionedBoltStore(baseDir, logger)))
+	select {
+	case <-c.ReplayDone():
+	case <-ctx.Done():
+		assert.FailNow(t, "replay didn't finish")
+	}
+	assert.Equal(t, 4, increment(c, key))
+	procs := c.ListStoredProcedures()
+	assert.Equal(t, 2, len(procs))
+	assert.Equal(t, "increment", procs[1].Name)
+	c.Stop()
+}
+
+// a node that went down after it applied a multi-node txn but before its checkpoint moved past it
+// replays that txn after a restart
+// the other node executed the txn already and doesn't send its remote reads again
+func TestCalvinTwoNodesReplayAfterRestart(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 2)
+	baseDir1 := fmt.Sprintf("./test-TestCalvinTwoNodesReplayAfterRestart-%d/", util.RandomRaftId())
+	baseDir2 := fmt.Sprintf("./test-TestCalvinTwoNodesReplayAfterRestart-%d/", util.RandomRaftId())
+	defer os.RemoveAll(configBags[0].path)
+	defer os.RemoveAll(configBags[1].path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBags[0].id))
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBags[1].id))
+	defer os.RemoveAll(ciPath)
+	defer os.RemoveAll(baseDir1)
+	defer os.RemoveAll(baseDir2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	logger := log.WithFields(log.Fields{})
+	var keys [][]byte
+	newTxn := func(value string) *pb.Transaction {
+		args := make([][]byte, 0)
+		for _, key := range keys {
+			arg := &pb.SimpleSetterArg{
+				Key:   key,
+				Value: []byte(value),
+			}
+			bites, err := arg.Marshal()
+			assert.Nil(t, err)
+			args = append(args, bites)
+		}
+
+		txn := NewTransaction()
+		txn.ReadWriteSet = keys
+		txn.StoredProcedure = "__simple_setter__"
+		txn.StoredProcedureArgs = args
+		return txn
+	}
+
+	for _, baseDir := range []string{baseDir1, baseDir2} {
+		err := os.MkdirAll(baseDir, os.ModePerm)
+		assert.Nil(t, err)
+	}
+	opts1 := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithBatchFrequency(10 * time.Millisecond)
+	opts2 := defaultOptionsWithFilePaths(configBags[1].path, ciPath).WithBatchFrequency(10 * time.Millisecond)
+	c1 := NewCalvin(opts1.WithDataStore(newPartitionedBoltStore(baseDir1, logger)))
+	c2 := NewCalvin(opts2.WithDataStore(newPartitionedBoltStore(baseDir2, logger)))
+	// one key on each node
+	keys = [][]byte{findLocalKey(c1), findLocalKey(c2)}
+
+	outcome := submitAndWait(ctx, t, c1, newTxn("value_1"))
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+	partitionIDs := c1.cip.MyPartitions()
+	c1.Stop()
+
+	// move the checkpoint back to before the txn
+	// the effects and markers of the txn are still there
+	pds := newPartitionedBoltStore(baseDir1, logger)
+	for _, partitionID := range partitionIDs {
+		txnProvider, err := pds.CreatePartition(partitionID)
+		assert.Nil(t, err)
+		txn, err := txnProvider.StartTxn(true)
+		assert.Nil(t, err)
+		// the key the checkpoint of the execution engine lives under
+		err = txn.Set([]byte("\x00calvin/checkpoint"), util.Uint64ToBytes(outcome.BatchIndex-1))
+		assert.Nil(t, err)
+		err = txn.Commit()
+		assert.Nil(t, err)
+	}
+	pds.Close()
+
+	c1 = NewCalvin(opts1.WithDataStore(newPartitionedBoltStore(baseDir1, logger)))
+	select {
+	case <-c1.ReplayDone():
+	case <-ctx.Done():
+		assert.FailNow(t, "replay didn't finish")
+	}
+
+	outcome = submitAndWait(ctx, t, c1, newTxn("value_2"))
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+	c1.Stop()
+	c2.Stop()
+}
+
+func TestCalvinRefusesWorkDuringReplay(t *testing.T) {
+	c := &Calvin{
+		replayDone: make(chan struct{}),
+	}
+
+	assert.Equal(t, ErrReplaying, c.SubmitTransaction(&pb.Transaction{}))
+	_, err := c.SubmitTransactionWithFuture(&pb.Transaction{})
+	assert.Equal(t, ErrReplaying, err)
+	assert.Equal(t, ErrReplaying, c.RegisterStoredProcedure(context.Background(), "narf_proc", "return 1"))
+	assert.Equal(t, ErrReplaying, c.SetCurrentStoredProcedure(context.Background(), "narf_proc", uint64(1)))
+	assert.Equal(t, ErrReplaying, c.RemoveStoredProcedure(context.Background(), "narf_proc", uint64(1)))
+}
+
+func copyFiles(t *testing.T, srcDir string, dstDir string) {
+	err := os.MkdirAll(dstDir, os.ModePerm)
+	assert.Nil(t, err)
+	files, err := ioutil.ReadDir(srcDir)
+	assert.Nil(t, err)
+	for idx := range files {
+		bites, err := ioutil.ReadFile(srcDir + files[idx].Name())
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(dstDir+files[idx].Name(), bites, 0600)
+		assert.Nil(t, err)
+	}
+}
+
+func submitAndWait(ctx context.Context, t *testing.T, c *Calvin, txn *pb.Transaction) *pb.TransactionOutcome {
+	f, err := c.SubmitTransactionWithFuture(txn)
+	assert.Nil(t, err)
+	outcome, err := f.Wait(ctx)
+	assert.Nil(t, err)
+	return outcome
+}
+
+func TestCalvinLowIsolationReadKeys(t *testing.T) {
+	configBags, ciPath := generateNConfigFiles(t, 2)
+	pds1 := newPartitionedDataStore(t, "TestCalvinLowIsolationReadKeys")
+	opts1 := defaultOptionsWithFilePaths(configBags[0].path, ciPath).WithDataStore(pds1)
+	c1 := NewCalvin(opts1)
+	pds2 := newPartitionedDataStore(t, "TestCalvinLowIsolationReadKeys")
+	opts2 := defaultOptionsWithFilePaths(configBags[1].path, ciPath).WithDataStore(pds2)
+	c2 := NewCalvin(opts2)
+	defer os.RemoveAll(configBags[0].path)
+	defer os.RemoveAll(configBags[1].path)
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBags[0].id))
+	defer os.RemoveAll(fmt.Sprintf("./calvin-%d", configBags[1].id))
+	defer os.RemoveAll(ciPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	localKey := findLocalKey(c1)
+	remoteKey := findLocalKey(c2)
+	txn := NewTransaction()
+	txn.WriterNodes = []uint64{configBags[0].id, configBags[1].id}
+	err := txn.AddSimpleSetterArg(localKey, []byte("narf_value"))
+	assert.Nil(t, err)
+	err = txn.AddSimpleSetterArg(remoteKey, []byte("moep_value"))
+	assert.Nil(t, err)
+	outcome := submitAndWait(ctx, t, c1, txn)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+
+	// results come back in the order of the keys
+	missingKey := []byte("missing_key")
+	results := c1.LowIsolationReadKeys(ctx, [][]byte{remoteKey, missingKey, localKey})
+	assert.Equal(t, 3, len(results))
+	assert.Nil(t, results[0].Err)
+	assert.True(t, results[0].Found)
+	assert.Equal(t, "moep_value", string(results[0].Value))
+	assert.Nil(t, results[1].Err)
+	assert.False(t, results[1].Found)
+	assert.Equal(t, missingKey, results[1].Key)
+	assert.Nil(t, results[2].Err)
+	assert.True(t, results[2].Found)
+	assert.Equal(t, "narf_value", string(results[2].Value))
+
+	// keys of a node that's gone fail on their own
+	c2.Stop()
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), time.Second)
+	defer shortCancel()
+	results = c1.LowIsolationReadKeys(shortCtx, [][]byte{remoteKey, localKey})
+	assert.NotNil(t, results[0].Err)
+	assert.False(t, results[0].Found)
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, "narf_value", string(results[1].Value))
+	c1.Stop()
+}
+
 func TestCalvinTwoNodes(t *testing.T) {
 	configBags, ciPath := generateNConfigFiles(t, 2)
 	pds1 := newPartitionedDataStore(t, "TestCalvinTwoNodes")
@@ -391,6 +1120,17 @@ func removeIdx(ids []uint64, idx int) []uint64 {
 	return append(ids[:idx], ids[idx+1:]...)
 }
 
+// not all keys are owned by a node in a small cluster
+// this finds one that is
+func findLocalKey(c *Calvin) []byte {
+	for i := 0; ; i++ {
+		key := []byte(fmt.Sprintf("narf%d", i))
+		if c.cip.IsLocal(key) {
+			return key
+		}
+	}
+}
+
 func newPartitionedDataStore(t *testing.T, testName string) util.PartitionedDataStore {
 	baseDir := fmt.Sprintf("./test-%s-%d/", testName, util.RandomRaftId())
 	err := os.MkdirAll(baseDir, os.ModePerm)
@@ -0,0 +1,365 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"sync"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// these keys are written into every partition next to the user data
+// that way they are committed atomically with the effects of txns
+// all of them start with the internal key prefix
+var (
+	internalKeyPrefix = []byte("\x00calvin/")
+	// index of the last batch whose effects are durable in the partition
+	checkpointKey = []byte("\x00calvin/checkpoint")
+	// marks that a txn was committed in the partition and holds its outcome
+	// markers are deleted once the checkpoint moved past the batch of the txn and the dedup window
+	appliedTxnKeyPrefix = []byte("\x00calvin/applied/")
+)
+
+const (
+	// DefaultDedupWindow is the number of batches during which a txn that is submitted again
+	// with the same id is answered with the outcome of its first run.
+	DefaultDedupWindow = uint64(1024)
+	// DefaultVersionHorizon is the number of batches before the checkpoint
+	// whose versions versioned partitions keep around at least.
+	DefaultVersionHorizon = uint64(1024)
+)
+
+func appliedTxnKey(txnID *pb.Id128) []byte {
+	key := make([]byte, 0, len(appliedTxnKeyPrefix)+16)
+	key = append(key, appliedTxnKeyPrefix...)
+	key = append(key, util.Uint64ToBytes(txnID.Upper)...)
+	return append(key, util.Uint64ToBytes(txnID.Lower)...)
+}
+
+// ReadCheckpoint returns the index of the last batch whose effects are durable in all provided partitions.
+// Partitions that were never checkpointed report zero.
+func ReadCheckpoint(partitionedStore util.PartitionedDataStore, partitionIDs []int) (uint64, error) {
+	var checkpoint uint64
+	for idx := range partitionIDs {
+		txnProvider, err := partitionedStore.GetPartition(partitionIDs[idx])
+		if err != nil {
+			return 0, err
+		}
+
+		txn, err := txnProvider.StartTxn(false)
+		if err != nil {
+			return 0, err
+		}
+
+		var partitionCheckpoint uint64
+		bites := txn.Get(checkpointKey)
+		if bites != nil {
+			partitionCheckpoint = util.BytesToUint64(bites)
+		}
+
+		err = txn.Rollback()
+		if err != nil {
+			return 0, err
+		}
+
+		if idx == 0 || partitionCheckpoint < checkpoint {
+			checkpoint = partitionCheckpoint
+		}
+	}
+	return checkpoint, nil
+}
+
+type appliedTxnMarker struct {
+	batchIndex uint64
+	key        []byte
+	// only set while the txn runs
+	outcome *pb.TransactionOutcome
+}
+
+// the node and the writers are the ones of the txn that reads the marker
+func marshalAppliedTxnMarker(outcome *pb.TransactionOutcome) ([]byte, error) {
+	marker := &pb.TransactionOutcome{
+		Status:     outcome.Status,
+		Error:      outcome.Error,
+		BatchIndex: outcome.BatchIndex,
+		BatchTerm:  outcome.BatchTerm,
+		Result:     outcome.Result,
+	}
+	return marker.Marshal()
+}
+
+func unmarshalAppliedTxnMarker(bites []byte) (*pb.TransactionOutcome, error) {
+	outcome := &pb.TransactionOutcome{}
+	err := outcome.Unmarshal(bites)
+	if err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
+// markers that were written before a restart are deleted once the checkpoint moves past them
+// just like the ones written since
+func loadAppliedTxnMarkers(partitionedStore util.PartitionedDataStore, partitionIDs []int) (map[int][]appliedTxnMarker, error) {
+	markers := make(map[int][]appliedTxnMarker)
+	for _, partitionID := range partitionIDs {
+		txnProvider, err := partitionedStore.GetPartition(partitionID)
+		if err != nil {
+			return nil, err
+		}
+
+		txn, err := txnProvider.StartTxn(false)
+		if err != nil {
+			return nil, err
+		}
+
+		iter := txn.Iterate(appliedTxnKeyPrefix, util.PrefixEnd(appliedTxnKeyPrefix))
+		for iter.Next() {
+			outcome, err := unmarshalAppliedTxnMarker(iter.Value())
+			if err != nil {
+				iter.Close()
+				txn.Rollback()
+				return nil, err
+			}
+
+			// keys are only valid until the iterator moves on
+			key := make([]byte, len(iter.Key()))
+			copy(key, iter.Key())
+			markers[partitionID] = append(markers[partitionID], appliedTxnMarker{
+				batchIndex: outcome.BatchIndex,
+				key:        key,
+			})
+		}
+		iter.Close()
+
+		err = txn.Rollback()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return markers, nil
+}
+
+type checkpointWaiter struct {
+	index uint64
+	c     chan struct{}
+}
+
+func newCheckpointer(checkpointChan <-chan uint64, partitionedStore util.PartitionedDataStore, cip util.ClusterInfoProvider, dedupWindow uint64, versionHorizon uint64, logger *log.Entry) *checkpointer {
+	checkpoint, err := ReadCheckpoint(partitionedStore, cip.MyPartitions())
+	if err != nil {
+		logger.Panicf("can't read checkpoint: %s", err.Error())
+	}
+
+	markers, err := loadAppliedTxnMarkers(partitionedStore, cip.MyPartitions())
+	if err != nil {
+		logger.Panicf("can't read applied txn markers: %s", err.Error())
+	}
+
+	return &checkpointer{
+		checkpointChan:   checkpointChan,
+		partitionedStore: partitionedStore,
+		cip:              cip,
+		mutex:            &sync.Mutex{},
+		checkpoint:       checkpoint,
+		markers:          markers,
+		dedupWindow:      dedupWindow,
+		versionHorizon:   versionHorizon,
+		logger:           logger,
+	}
+}
+
+// the checkpointer receives the index of the last batch that is completely done on this node
+// from the scheduler and writes it into all local partitions
+// markers of txns up to that batch aren't needed anymore and are deleted in the same go
+// unless txns that are submitted again still need to find them
+// versioned partitions drop old versions every so often as well
+type checkpointer struct {
+	checkpointChan   <-chan uint64
+	partitionedStore util.PartitionedDataStore
+	cip              util.ClusterInfoProvider
+	mutex            *sync.Mutex
+	checkpoint       uint64
+	waiters          []checkpointWaiter
+	markers          map[int][]appliedTxnMarker
+	dedupWindow      uint64
+	versionHorizon   uint64
+	// the last horizon versions were collected at
+	collectedHorizon uint64
+	// only one collection runs at a time
+	collecting bool
+	logger     *log.Entry
+}
+
+func (cp *checkpointer) runCheckpointer() {
+	for {
+		checkpoint, ok := <-cp.checkpointChan
+		if !ok {
+			cp.logger.Warningf("Stopping checkpointer")
+			return
+		}
+
+		cp.advance(checkpoint)
+	}
+}
+
+func (cp *checkpointer) advance(checkpoint uint64) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	// after a restart the scheduler revisits batches before the checkpoint
+	if checkpoint <= cp.checkpoint {
+		return
+	}
+
+	for _, partitionID := range cp.cip.MyPartitions() {
+		err := cp.persist(partitionID, checkpoint)
+		if err != nil {
+			// the checkpoint is written again next time around
+			cp.logger.Errorf("can't write checkpoint [%d] into partition [%d]: %s", checkpoint, partitionID, err.Error())
+		}
+	}
+
+	cp.checkpoint = checkpoint
+	cp.collectGarbage()
+	waiters := cp.waiters[:0]
+	for idx := range cp.waiters {
+		if cp.waiters[idx].index <= checkpoint {
+			close(cp.waiters[idx].c)
+		} else {
+			waiters = append(waiters, cp.waiters[idx])
+		}
+	}
+	cp.waiters = waiters
+}
+
+// needs to be called with the lock held
+func (cp *checkpointer) persist(partitionID int, checkpoint uint64) error {
+	txnProvider, err := cp.partitionedStore.GetPartition(partitionID)
+	if err != nil {
+		return err
+	}
+
+	txn, err := txnProvider.StartTxn(true)
+	if err != nil {
+		return err
+	}
+
+	err = txn.Set(checkpointKey, util.Uint64ToBytes(checkpoint))
+	if err != nil {
+		txn.Rollback()
+		return err
+	}
+
+	markers := cp.markers[partitionID]
+	remaining := make([]appliedTxnMarker, 0)
+	for idx := range markers {
+		// a txn after the checkpoint might be a retry of this one
+		if markers[idx].batchIndex+cp.dedupWindow > checkpoint {
+			remaining = append(remaining, markers[idx])
+			continue
+		}
+
+		err = txn.Delete(markers[idx].key)
+		if err != nil {
+			txn.Rollback()
+			return err
+		}
+	}
+
+	err = txn.Commit()
+	if err != nil {
+		return err
+	}
+
+	cp.markers[partitionID] = remaining
+	return nil
+}
+
+// collecting walks all versions of a partition
+// that's why it only happens once the horizon moved by the horizon itself
+// it runs in the background so that checkpoints don't wait for it
+// needs to be called with the lock held
+func (cp *checkpointer) collectGarbage() {
+	if cp.versionHorizon == 0 || cp.checkpoint < cp.versionHorizon || cp.collecting {
+		return
+	}
+
+	horizon := cp.checkpoint - cp.versionHorizon
+	if horizon < cp.collectedHorizon+cp.versionHorizon {
+		return
+	}
+
+	cp.collecting = true
+	cp.collectedHorizon = horizon
+	go cp.collectVersions(horizon, cp.cip.MyPartitions())
+}
+
+func (cp *checkpointer) collectVersions(horizon uint64, partitionIDs []int) {
+	defer func() {
+		cp.mutex.Lock()
+		cp.collecting = false
+		cp.mutex.Unlock()
+	}()
+
+	for _, partitionID := range partitionIDs {
+		txnProvider, err := cp.partitionedStore.GetPartition(partitionID)
+		if err != nil {
+			cp.logger.Errorf("can't collect versions of partition [%d]: %s", partitionID, err.Error())
+			continue
+		}
+
+		versionedTxnProvider, ok := txnProvider.(util.VersionedDataStoreTxnProvider)
+		if !ok {
+			continue
+		}
+
+		err = versionedTxnProvider.CollectGarbage(util.Version{BatchIndex: horizon})
+		if err != nil {
+			// the versions are collected with the next horizon
+			cp.logger.Errorf("can't collect versions of partition [%d]: %s", partitionID, err.Error())
+		}
+	}
+}
+
+// remembers a marker that was committed into a partition
+// so that it can be deleted with the next checkpoint
+func (cp *checkpointer) addMarker(partitionID int, batchIndex uint64, key []byte) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.markers[partitionID] = append(cp.markers[partitionID], appliedTxnMarker{
+		batchIndex: batchIndex,
+		key:        key,
+	})
+}
+
+// returns a channel that is closed once all batches up to the provided index are done
+func (cp *checkpointer) waitFor(index uint64) <-chan struct{} {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	c := make(chan struct{})
+	if index <= cp.checkpoint {
+		close(c)
+		return c
+	}
+
+	cp.waiters = append(cp.waiters, checkpointWaiter{
+		index: index,
+		c:     c,
+	})
+	return c
+}
@@ -0,0 +1,319 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckpointerAdvance(t *testing.T) {
+	oldMarker := []byte("old_marker")
+	newMarker := []byte("new_marker")
+	// written before the restart
+	restoredMarker := []byte("restored_marker")
+	restoredOutcome, err := marshalAppliedTxnMarker(&pb.TransactionOutcome{BatchIndex: uint64(2)})
+	assert.Nil(t, err)
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("MyPartitions").Return([]int{1})
+	mockIter := new(mocks.DataStoreIterator)
+	mockIter.On("Next").Return(true).Once()
+	mockIter.On("Next").Return(false)
+	mockIter.On("Key").Return(restoredMarker)
+	mockIter.On("Value").Return(restoredOutcome)
+	mockIter.On("Close").Return()
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Get", checkpointKey).Return(util.Uint64ToBytes(3))
+	mockTxn.On("Iterate", appliedTxnKeyPrefix, util.PrefixEnd(appliedTxnKeyPrefix)).Return(mockIter)
+	mockTxn.On("Set", checkpointKey, mock.AnythingOfType("[]uint8")).Return(nil)
+	mockTxn.On("Delete", oldMarker).Return(nil)
+	mockTxn.On("Delete", restoredMarker).Return(nil)
+	mockTxn.On("Commit").Return(nil)
+	mockTxn.On("Rollback").Return(nil)
+	mockTxnProvider := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider.On("StartTxn", mock.AnythingOfType("bool")).Return(mockTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider, nil)
+
+	cp := newCheckpointer(make(chan uint64), mockStore, mockCIP, uint64(0), uint64(0), log.WithFields(log.Fields{}))
+	assert.Equal(t, uint64(3), cp.checkpoint)
+	assert.Equal(t, 1, len(cp.markers[1]))
+	assert.Equal(t, uint64(2), cp.markers[1][0].batchIndex)
+
+	done := cp.waitFor(uint64(7))
+	cp.addMarker(1, uint64(5), oldMarker)
+	cp.addMarker(1, uint64(9), newMarker)
+
+	cp.advance(uint64(7))
+	<-done
+	mockTxn.AssertCalled(t, "Set", checkpointKey, util.Uint64ToBytes(7))
+	mockTxn.AssertCalled(t, "Delete", restoredMarker)
+	mockTxn.AssertNotCalled(t, "Delete", newMarker)
+	assert.Equal(t, 1, len(cp.markers[1]))
+
+	// older checkpoints are ignored
+	cp.advance(uint64(6))
+	mockTxn.AssertNumberOfCalls(t, "Set", 1)
+	<-cp.waitFor(uint64(5))
+}
+
+func TestStoredProcDataStoreSkipsAppliedPartitions(t *testing.T) {
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	txn := &pb.Transaction{
+		Id:         id.ToProto(),
+		BatchIndex: uint64(5),
+	}
+	markerKey := appliedTxnKey(txn.Id)
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("IsLocal", mock.AnythingOfType("[]uint8")).Return(true)
+	mockCIP.On("FindPartitionForKey", []byte("narf")).Return(1)
+	mockCIP.On("FindPartitionForKey", []byte("moep")).Return(2)
+	mockCIP.On("FindPartitionForKey", []byte("zort")).Return(3)
+
+	// partition 1 contains the effects of this txn already
+	appliedTxn := new(mocks.DataStoreTxn)
+	appliedTxn.On("Get", markerKey).Return([]byte{1})
+	appliedTxn.On("Rollback").Return(nil)
+	// partition 3 was checkpointed past this txn and doesn't have the marker anymore
+	checkpointedTxn := new(mocks.DataStoreTxn)
+	checkpointedTxn.On("Get", markerKey).Return(nil)
+	checkpointedTxn.On("Get", checkpointKey).Return(util.Uint64ToBytes(5))
+	checkpointedTxn.On("Rollback").Return(nil)
+	pendingTxn := new(mocks.DataStoreTxn)
+	pendingTxn.On("Get", markerKey).Return(nil)
+	pendingTxn.On("Get", checkpointKey).Return(util.Uint64ToBytes(4))
+	pendingTxn.On("Set", mock.AnythingOfType("[]uint8"), mock.AnythingOfType("[]uint8")).Return(nil)
+	pendingTxn.On("Commit").Return(nil)
+
+	appliedProvider := new(mocks.DataStoreTxnProvider)
+	appliedProvider.On("StartTxn", true).Return(appliedTxn, nil)
+	checkpointedProvider := new(mocks.DataStoreTxnProvider)
+	checkpointedProvider.On("StartTxn", true).Return(checkpointedTxn, nil)
+	pendingProvider := new(mocks.DataStoreTxnProvider)
+	pendingProvider.On("StartTxn", true).Return(pendingTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(appliedProvider, nil)
+	mockStore.On("GetPartition", 2).Return(pendingProvider, nil)
+	mockStore.On("GetPartition", 3).Return(checkpointedProvider, nil)
+
+	cp := &checkpointer{
+		mutex:   &sync.Mutex{},
+		markers: make(map[int][]appliedTxnMarker),
+	}
+	lds := newStoredProcDataStore(mockStore, [][]byte{[]byte("narf"), []byte("moep"), []byte("zort")}, [][]byte{nil, nil, nil}, mockCIP)
+	lds.trackAppliedTxn(txn, cp)
+	lds.Set("narf", "narf_value")
+	lds.Set("moep", "moep_value")
+	lds.Set("zort", "zort_value")
+	err = lds.commit()
+	assert.Nil(t, err)
+
+	appliedTxn.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+	appliedTxn.AssertNotCalled(t, "Commit")
+	checkpointedTxn.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+	checkpointedTxn.AssertNotCalled(t, "Commit")
+	pendingTxn.AssertCalled(t, "Set", []byte("moep"), []byte("moep_value"))
+	markerValue, err := marshalAppliedTxnMarker(&pb.TransactionOutcome{BatchIndex: uint64(5)})
+	assert.Nil(t, err)
+	pendingTxn.AssertCalled(t, "Set", markerKey, markerValue)
+	pendingTxn.AssertCalled(t, "Commit")
+	// both markers are deleted with the next checkpoint
+	assert.Equal(t, 1, len(cp.markers[1]))
+	assert.Equal(t, 1, len(cp.markers[2]))
+	assert.Equal(t, 1, len(cp.markers[3]))
+}
+
+func TestStoredProcDataStoreFindsEarlierOutcome(t *testing.T) {
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	markerKey := appliedTxnKey(id.ToProto())
+	markerValue, err := marshalAppliedTxnMarker(&pb.TransactionOutcome{
+		Status:     pb.COMMITTED,
+		BatchIndex: uint64(5),
+		Result:     []byte("1"),
+		NodeId:     uint64(99),
+	})
+	assert.Nil(t, err)
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("IsLocal", mock.AnythingOfType("[]uint8")).Return(true)
+	mockCIP.On("FindPartitionForKey", []byte("narf")).Return(1)
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Get", markerKey).Return(markerValue)
+	mockTxn.On("Get", checkpointKey).Return(util.Uint64ToBytes(4))
+	mockTxn.On("Rollback").Return(nil)
+	mockTxnProvider := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider.On("StartTxn", true).Return(mockTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider, nil)
+	cp := &checkpointer{
+		mutex:   &sync.Mutex{},
+		markers: make(map[int][]appliedTxnMarker),
+	}
+
+	find := func(batchIndex uint64) *pb.TransactionOutcome {
+		lds := newStoredProcDataStore(mockStore, [][]byte{[]byte("narf")}, [][]byte{nil}, mockCIP)
+		lds.trackAppliedTxn(&pb.Transaction{
+			Id:           id.ToProto(),
+			BatchIndex:   batchIndex,
+			ReadWriteSet: [][]byte{[]byte("narf")},
+		}, cp)
+		outcome, err := lds.findEarlierOutcome(uint64(10))
+		assert.Nil(t, err)
+		assert.Nil(t, lds.rollback())
+		return outcome
+	}
+
+	// the marker was left by this txn
+	assert.Nil(t, find(uint64(5)))
+	// a retry within the window
+	outcome := find(uint64(15))
+	assert.NotNil(t, outcome)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+	assert.Equal(t, uint64(5), outcome.BatchIndex)
+	assert.Equal(t, []byte("1"), outcome.Result)
+	assert.Equal(t, uint64(0), outcome.NodeId)
+	// a txn outside the window runs again
+	assert.Nil(t, find(uint64(16)))
+}
+
+func TestStoredProcDataStoreTakesWritersInOrder(t *testing.T) {
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("IsLocal", mock.AnythingOfType("[]uint8")).Return(true)
+	mockCIP.On("FindPartitionForKey", []byte("narf")).Return(3)
+	mockCIP.On("FindPartitionForKey", []byte("moep")).Return(1)
+	mockCIP.On("FindPartitionForKey", []byte("zort")).Return(2)
+
+	// the order in which writers are taken
+	partitionIDs := make([]int, 0)
+	mockStore := new(mocks.PartitionedDataStore)
+	for partitionID := 1; partitionID <= 3; partitionID++ {
+		mockTxn := new(mocks.DataStoreTxn)
+		mockTxn.On("Set", mock.AnythingOfType("[]uint8"), mock.AnythingOfType("[]uint8")).Return(nil)
+		mockTxn.On("Rollback").Return(nil)
+		mockProvider := new(mocks.DataStoreTxnProvider)
+		mockProvider.On("StartTxn", true).Return(mockTxn, nil)
+		mockStore.On("GetPartition", partitionID).Return(mockProvider, nil).Run(func(args mock.Arguments) {
+			partitionIDs = append(partitionIDs, args.Int(0))
+		})
+	}
+
+	lds := newStoredProcDataStore(mockStore, [][]byte{[]byte("narf"), []byte("moep"), []byte("zort")}, [][]byte{nil, nil, nil}, mockCIP)
+	err := lds.beginWrites([][]byte{[]byte("narf"), []byte("moep")})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 3}, partitionIDs)
+
+	// writes outside of the read-write set would take another writer
+	lds.Set("narf", "narf_value")
+	assert.False(t, lds.aborted)
+	lds.Set("zort", "zort_value")
+	assert.True(t, lds.aborted)
+	assert.Equal(t, []int{1, 3}, partitionIDs)
+	assert.Nil(t, lds.rollback())
+}
+
+func TestCheckpointerKeepsMarkersForDedupWindow(t *testing.T) {
+	oldMarker := []byte("old_marker")
+	newMarker := []byte("new_marker")
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("MyPartitions").Return([]int{1})
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Get", checkpointKey).Return(nil)
+	mockTxn.On("Iterate", appliedTxnKeyPrefix, util.PrefixEnd(appliedTxnKeyPrefix)).Return(newEmptyMockIterator())
+	mockTxn.On("Set", checkpointKey, mock.AnythingOfType("[]uint8")).Return(nil)
+	mockTxn.On("Delete", oldMarker).Return(nil)
+	mockTxn.On("Commit").Return(nil)
+	mockTxn.On("Rollback").Return(nil)
+	mockTxnProvider := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider.On("StartTxn", mock.AnythingOfType("bool")).Return(mockTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider, nil)
+
+	cp := newCheckpointer(make(chan uint64), mockStore, mockCIP, uint64(10), uint64(0), log.WithFields(log.Fields{}))
+	cp.addMarker(1, uint64(2), oldMarker)
+	cp.addMarker(1, uint64(5), newMarker)
+
+	// retries up to batch 15 need to find the new marker
+	cp.advance(uint64(14))
+	mockTxn.AssertCalled(t, "Delete", oldMarker)
+	mockTxn.AssertNotCalled(t, "Delete", newMarker)
+	assert.Equal(t, 1, len(cp.markers[1]))
+}
+
+func TestCheckpointerCollectsVersions(t *testing.T) {
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("MyPartitions").Return([]int{1})
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Get", checkpointKey).Return(nil)
+	mockTxn.On("Iterate", appliedTxnKeyPrefix, util.PrefixEnd(appliedTxnKeyPrefix)).Return(newEmptyMockIterator())
+	mockTxn.On("Set", checkpointKey, mock.AnythingOfType("[]uint8")).Return(nil)
+	mockTxn.On("Commit").Return(nil)
+	mockTxn.On("Rollback").Return(nil)
+	mockTxnProvider := new(mocks.VersionedDataStoreTxnProvider)
+	mockTxnProvider.On("StartTxn", mock.AnythingOfType("bool")).Return(mockTxn, nil)
+	mockTxnProvider.On("CollectGarbage", mock.AnythingOfType("util.Version")).Return(nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider, nil)
+
+	cp := newCheckpointer(make(chan uint64), mockStore, mockCIP, uint64(0), uint64(10), log.WithFields(log.Fields{}))
+	cp.advance(uint64(15))
+	waitForCollection(cp)
+	mockTxnProvider.AssertNotCalled(t, "CollectGarbage", mock.Anything)
+
+	// versions are collected once the horizon moved by the horizon
+	cp.advance(uint64(20))
+	waitForCollection(cp)
+	mockTxnProvider.AssertCalled(t, "CollectGarbage", util.Version{BatchIndex: 10})
+	cp.advance(uint64(29))
+	waitForCollection(cp)
+	mockTxnProvider.AssertNumberOfCalls(t, "CollectGarbage", 1)
+	cp.advance(uint64(30))
+	waitForCollection(cp)
+	mockTxnProvider.AssertCalled(t, "CollectGarbage", util.Version{BatchIndex: 20})
+}
+
+func waitForCollection(cp *checkpointer) {
+	for {
+		cp.mutex.Lock()
+		collecting := cp.collecting
+		cp.mutex.Unlock()
+		if !collecting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newEmptyMockIterator() *mocks.DataStoreIterator {
+	mockIter := new(mocks.DataStoreIterator)
+	mockIter.On("Next").Return(false)
+	mockIter.On("Close").Return()
+	return mockIter
+}
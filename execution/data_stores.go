@@ -17,10 +17,20 @@
 package execution
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/mhelmich/calvin/pb"
 	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// MaxScanPageSize is the number of keys a scan in a stored procedure returns at most.
+	MaxScanPageSize = 1000
+)
+
 func newStoredProcDataStore(partitionedStore util.PartitionedDataStore, keys [][]byte, values [][]byte, cip util.ClusterInfoProvider) *storedProcDataStore {
 	m := make(map[string][]byte, len(keys))
 
@@ -33,6 +43,7 @@ func newStoredProcDataStore(partitionedStore util.PartitionedDataStore, keys [][
 	return &storedProcDataStore{
 		partitionedStore: partitionedStore,
 		txns:             make(map[int]util.DataStoreTxn),
+		scanTxns:         make(map[int]util.DataStoreTxn),
 		data:             m,
 		cip:              cip,
 	}
@@ -41,8 +52,123 @@ func newStoredProcDataStore(partitionedStore util.PartitionedDataStore, keys [][
 type storedProcDataStore struct {
 	partitionedStore util.PartitionedDataStore
 	txns             map[int]util.DataStoreTxn
-	data             map[string][]byte
-	cip              util.ClusterInfoProvider
+	// read-only txns of partitions that were scanned but not written to
+	scanTxns map[int]util.DataStoreTxn
+	// only txns that run behind a scan barrier in the scheduler can scan
+	scans       bool
+	data        map[string][]byte
+	cip         util.ClusterInfoProvider
+	aborted     bool
+	abortReason string
+	// marks the txn as committed in every partition it writes to
+	// nil if the txn doesn't leave markers
+	marker       *appliedTxnMarker
+	checkpointer *checkpointer
+	// partitions that contain the effects of this txn already
+	applied map[int]bool
+	// all local partitions of these keys get the marker
+	// whether the procedure writes into them or not
+	writeSet [][]byte
+	// writes into versioned partitions are stamped with this version
+	// nil if the txn wasn't sequenced
+	version *util.Version
+	// partitions this txn took the writer of up front
+	// nil if writers are taken as the procedure goes
+	writePartitions map[int]bool
+}
+
+// bolt allows one writer per partition at a time
+// workers that take the writers of several partitions in different orders can deadlock
+// all writers of a txn are taken before the procedure runs and in the order of their partition ids
+func (lds *storedProcDataStore) beginWrites(writeSet [][]byte) error {
+	if lds.writePartitions == nil {
+		lds.writePartitions = make(map[int]bool)
+	}
+
+	for idx := range writeSet {
+		if lds.cip.IsLocal(writeSet[idx]) {
+			lds.writePartitions[lds.cip.FindPartitionForKey(writeSet[idx])] = true
+		}
+	}
+
+	partitionIDs := make([]int, 0, len(lds.writePartitions))
+	for partitionID := range lds.writePartitions {
+		partitionIDs = append(partitionIDs, partitionID)
+	}
+	sort.Ints(partitionIDs)
+
+	for _, partitionID := range partitionIDs {
+		_, err := lds.getTxnForPartition(partitionID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makes the writes of the txn show up as their own version in versioned partitions
+func (lds *storedProcDataStore) stampVersion(txn *pb.Transaction) {
+	lds.version = &util.Version{
+		BatchIndex:  txn.BatchIndex,
+		TxnPosition: txn.BatchPosition,
+	}
+}
+
+// makes the txn leave a marker in all partitions it commits into
+// and in all local partitions of its write set
+// partitions that contain the marker already aren't written to again
+// that's how txns that are replayed after a crash don't apply their effects twice
+func (lds *storedProcDataStore) trackAppliedTxn(txn *pb.Transaction, cp *checkpointer) {
+	lds.marker = &appliedTxnMarker{
+		batchIndex: txn.BatchIndex,
+		key:        appliedTxnKey(txn.Id),
+		outcome: &pb.TransactionOutcome{
+			BatchIndex: txn.BatchIndex,
+		},
+	}
+	lds.checkpointer = cp
+	lds.applied = make(map[int]bool)
+	lds.writeSet = txn.ReadWriteSet
+}
+
+// the marker keeps the outcome of the txn
+func (lds *storedProcDataStore) setOutcome(outcome *pb.TransactionOutcome) {
+	if lds.marker != nil {
+		lds.marker.outcome = outcome
+	}
+}
+
+// looks for the marker of a txn with the same id that ran in an earlier batch
+// only markers at most window batches older than this txn count
+// that decision is the same on all replicas because markers are kept at least that long
+func (lds *storedProcDataStore) findEarlierOutcome(window uint64) (*pb.TransactionOutcome, error) {
+	for idx := range lds.writeSet {
+		key := lds.writeSet[idx]
+		if !lds.cip.IsLocal(key) {
+			continue
+		}
+
+		txn, err := lds.getTxnForKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		bites := txn.Get(lds.marker.key)
+		if bites == nil {
+			continue
+		}
+
+		// markers that can't be read don't tell which batch they belong to
+		outcome, err := unmarshalAppliedTxnMarker(bites)
+		if err != nil {
+			continue
+		}
+
+		if outcome.BatchIndex < lds.marker.batchIndex && lds.marker.batchIndex-outcome.BatchIndex <= window {
+			return outcome, nil
+		}
+	}
+	return nil, nil
 }
 
 func (lds *storedProcDataStore) Get(key string) string {
@@ -66,18 +192,169 @@ func (lds *storedProcDataStore) Set(key string, value string) {
 		log.Panicf("you tried to access key [%s] but wasn't in the keys declared to be accessed", key)
 	}
 
-	txn, err := lds.getTxnForKey([]byte(key))
+	partitionID := lds.cip.FindPartitionForKey([]byte(key))
+	if lds.writePartitions != nil && !lds.writePartitions[partitionID] {
+		// taking another writer now could deadlock with other workers
+		// the scheduler didn't lock the key for writing either
+		lds.abort(fmt.Sprintf("txn writes key [%s] that isn't in its read-write set", key))
+		return
+	}
+
+	txn, err := lds.getTxnForPartition(partitionID)
 	if err != nil {
 		log.Panicf("can't get txn for key [%s]: %s", key, err.Error())
 	}
 
+	if lds.applied[partitionID] {
+		return
+	}
+
 	txn.Set([]byte(key), []byte(value))
 }
 
+// Scan returns the keys from start (inclusive) to end (exclusive) and their values in key order.
+// An empty end scans to the last key.
+// A page holds at most limit keys and never more than MaxScanPageSize.
+// If there are more keys, next is the key the following page starts with. Otherwise next is empty.
+// Scans only see keys of local partitions.
+// Only txns with Scans set can scan. All other txns are aborted when they try to.
+func (lds *storedProcDataStore) Scan(start string, end string, limit int) ([]string, []string, string) {
+	var endKey []byte
+	if end != "" {
+		endKey = []byte(end)
+	}
+	return lds.scan([]byte(start), endKey, limit)
+}
+
+// ScanPrefix works like Scan for all keys that start with prefix.
+// An empty from starts with the first key, otherwise from is the next key of the previous page.
+func (lds *storedProcDataStore) ScanPrefix(prefix string, from string, limit int) ([]string, []string, string) {
+	start := []byte(prefix)
+	if from > prefix {
+		start = []byte(from)
+	}
+	return lds.scan(start, util.PrefixEnd([]byte(prefix)), limit)
+}
+
+// partitions don't share keys
+// that's why merging them only needs to pick the smallest key every time
+func (lds *storedProcDataStore) scan(start []byte, end []byte, limit int) ([]string, []string, string) {
+	if !lds.scans {
+		// the scheduler didn't keep other txns away from the range
+		// the results would differ between replicas
+		lds.abort("txn scans keys but doesn't have Scans set")
+		return []string{}, []string{}, ""
+	} else if limit <= 0 || limit > MaxScanPageSize {
+		limit = MaxScanPageSize
+	}
+
+	iterators := make([]util.DataStoreIterator, 0)
+	defer func() {
+		for idx := range iterators {
+			iterators[idx].Close()
+		}
+	}()
+
+	// iterators that have a current key
+	live := make([]util.DataStoreIterator, 0)
+	for _, partitionID := range lds.cip.MyPartitions() {
+		txn, err := lds.getScanTxnForPartition(partitionID)
+		if err != nil {
+			log.Panicf("can't get txn for partition [%d]: %s", partitionID, err.Error())
+		}
+
+		it := txn.Iterate(start, end)
+		iterators = append(iterators, it)
+		if it.Next() {
+			live = append(live, it)
+		}
+	}
+
+	keys := make([]string, 0)
+	values := make([]string, 0)
+	for len(live) > 0 {
+		minIdx := 0
+		for idx := range live {
+			if bytes.Compare(live[idx].Key(), live[minIdx].Key()) < 0 {
+				minIdx = idx
+			}
+		}
+
+		it := live[minIdx]
+		// calvin's own keys aren't visible to procedures
+		if !bytes.HasPrefix(it.Key(), internalKeyPrefix) {
+			if len(keys) == limit {
+				return keys, values, string(it.Key())
+			}
+			keys = append(keys, string(it.Key()))
+			values = append(values, string(it.Value()))
+		}
+
+		if !it.Next() {
+			live = append(live[:minIdx], live[minIdx+1:]...)
+		}
+	}
+	return keys, values, ""
+}
+
 func (lds *storedProcDataStore) getTxnForKey(key []byte) (util.DataStoreTxn, error) {
-	partitionID := lds.cip.FindPartitionForKey(key)
+	return lds.getTxnForPartition(lds.cip.FindPartitionForKey(key))
+}
+
+// partitions this txn writes to are scanned with the txn that writes
+// that way the scan sees the writes of the txn
+// all other partitions are scanned read-only and don't get a marker
+func (lds *storedProcDataStore) getScanTxnForPartition(partitionID int) (util.DataStoreTxn, error) {
+	txn, ok := lds.txns[partitionID]
+	if ok {
+		return txn, nil
+	}
+
+	txn, ok = lds.scanTxns[partitionID]
+	if ok {
+		return txn, nil
+	}
+
+	txnProvider, err := lds.partitionedStore.GetPartition(partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err = txnProvider.StartTxn(false)
+	if err != nil {
+		return nil, err
+	}
+	lds.scanTxns[partitionID] = txn
+	return txn, nil
+}
+
+// bolt can't grow its file while a read txn is open
+// read txns are closed before anything is committed for that reason
+func (lds *storedProcDataStore) closeScanTxns() error {
+	defer func() { lds.scanTxns = make(map[int]util.DataStoreTxn) }()
+	var firstErr error
+	for _, txn := range lds.scanTxns {
+		err := txn.Rollback()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (lds *storedProcDataStore) getTxnForPartition(partitionID int) (util.DataStoreTxn, error) {
 	txn, ok := lds.txns[partitionID]
 	if !ok {
+		// a partition that was scanned before is written to now
+		scanTxn, ok := lds.scanTxns[partitionID]
+		if ok {
+			delete(lds.scanTxns, partitionID)
+			err := scanTxn.Rollback()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		var txnProvider util.DataStoreTxnProvider
 		var err error
 
@@ -86,34 +363,120 @@ func (lds *storedProcDataStore) getTxnForKey(key []byte) (util.DataStoreTxn, err
 			return nil, err
 		}
 
-		txn, err = txnProvider.StartTxn(true)
+		versionedTxnProvider, ok := txnProvider.(util.VersionedDataStoreTxnProvider)
+		if ok && lds.version != nil {
+			txn, err = versionedTxnProvider.StartVersionedTxn(*lds.version)
+		} else {
+			txn, err = txnProvider.StartTxn(true)
+		}
 		if err != nil {
 			return nil, err
 		}
 
+		if lds.marker != nil && isAppliedInPartition(txn, lds.marker) {
+			lds.applied[partitionID] = true
+		}
 		lds.txns[partitionID] = txn
 	}
 	return txn, nil
 }
 
+// partitions that contain the effects of this txn already are rolled back
+// all other partitions get the marker of this txn
+// if this node crashes in between committing partitions, only some of them contain the effects
+// a partition contains the effects of a txn if the marker of the txn is present
+// or if the checkpoint of the partition is past the batch of the txn
+// (in that case the marker might have been deleted already)
+// partitions restored from a snapshot can be ahead of the rest of the node
+// markers of earlier txns with the same id don't count
+func isAppliedInPartition(txn util.DataStoreTxn, marker *appliedTxnMarker) bool {
+	bites := txn.Get(marker.key)
+	if bites != nil {
+		outcome, err := unmarshalAppliedTxnMarker(bites)
+		// markers that can't be read are assumed to be left by this txn
+		if err != nil || outcome.BatchIndex == marker.batchIndex {
+			return true
+		}
+	}
+
+	bites = txn.Get(checkpointKey)
+	return bites != nil && util.BytesToUint64(bites) >= marker.batchIndex
+}
+
 func (lds *storedProcDataStore) commit() error {
+	err := lds.closeScanTxns()
+	if err != nil {
+		lds.rollback()
+		return err
+	}
+
+	var markerValue []byte
+	if lds.marker != nil {
+		markerValue, err = marshalAppliedTxnMarker(lds.marker.outcome)
+		if err != nil {
+			lds.rollback()
+			return err
+		}
+
+		// a txn that was rolled back takes its writers again to leave the marker
+		err = lds.beginWrites(lds.writeSet)
+		if err != nil {
+			lds.rollback()
+			return err
+		}
+	}
+
 	defer func() { lds.txns = nil }()
-	for _, txn := range lds.txns {
-		err := txn.Commit()
+	for partitionID, txn := range lds.txns {
+		if lds.applied[partitionID] {
+			err = txn.Rollback()
+		} else if lds.marker != nil {
+			err = txn.Set(lds.marker.key, markerValue)
+			if err != nil {
+				txn.Rollback()
+				return err
+			}
+			err = txn.Commit()
+		} else {
+			err = txn.Commit()
+		}
+
 		if err != nil {
 			return err
 		}
 	}
+
+	// only register markers after all partition txns are closed
+	// the checkpointer writes into the same partitions while holding its lock
+	if lds.marker != nil && lds.checkpointer != nil {
+		for partitionID := range lds.txns {
+			lds.checkpointer.addMarker(partitionID, lds.marker.batchIndex, lds.marker.key)
+		}
+	}
 	return nil
 }
 
+// rolls back all partition txns even if some of them fail
+// and returns the first error it encountered
+// the data store can be committed afterwards which only writes the marker
 func (lds *storedProcDataStore) rollback() error {
-	defer func() { lds.txns = nil }()
+	defer func() { lds.txns = make(map[int]util.DataStoreTxn) }()
+	firstErr := lds.closeScanTxns()
 	for _, txn := range lds.txns {
 		err := txn.Rollback()
-		if err != nil {
-			return err
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
-	return nil
+	return firstErr
+}
+
+// marks the txn as aborted by the stored procedure
+// once a procedure aborted, nothing it does afterwards can revert that
+func (lds *storedProcDataStore) abort(reason string) {
+	if lds.aborted {
+		return
+	}
+	lds.aborted = true
+	lds.abortReason = reason
 }
@@ -53,7 +53,27 @@ type EngineOpts struct {
 	ConnCache        util.ConnectionCache
 	Cip              util.ClusterInfoProvider
 	NumWorkers       int
-	Logger           *log.Entry
+	NodeID           uint64
+	// the registry the scheduler installs stored procedures in
+	// if nil, the engine only knows about the built-in procedures
+	StoredProcs *util.StoredProcedureRegistry
+	// the scheduler publishes the index of the last batch that is done on this channel
+	// if set, the engine checkpoints all local partitions at these indexes
+	// and makes sure txns that are replayed after a crash don't apply their effects twice
+	CheckpointChan <-chan uint64
+	// txns that never made it into the log arrive here with their outcome set
+	// their outcome is reported to the node they were submitted to
+	DroppedTxnChan <-chan *pb.Transaction
+	// a txn that is submitted again with the same id at most this many batches after the first one
+	// isn't executed again and is answered with the outcome of the first one
+	// this only works with checkpointing and all nodes need to use the same window
+	// zero turns this off
+	DedupWindow uint64
+	// versioned partitions keep all versions of at least this many batches before the checkpoint
+	// this only works with checkpointing
+	// zero keeps all versions
+	VersionHorizon uint64
+	Logger         *log.Entry
 }
 
 func NewEngine(opts EngineOpts) *Engine {
@@ -61,11 +81,23 @@ func NewEngine(opts EngineOpts) *Engine {
 
 	rrs := newRemoteReadServer(readyToExecChan, opts.Logger)
 	pb.RegisterRemoteReadServer(opts.Srvr, rrs)
+	outcomes := newOutcomeRegistry(opts.Logger)
+	pb.RegisterTransactionOutcomesServer(opts.Srvr, outcomes)
+	outcomeChan := make(chan *pb.Transaction, opts.NumWorkers*2+1)
 	txnsToExecute := &sync.Map{}
-	storedProcs := &sync.Map{}
+	storedProcs := opts.StoredProcs
+	if storedProcs == nil {
+		storedProcs = util.NewStoredProcedureRegistry()
+	}
 	initStoredProcedures(storedProcs)
 	counter := uint64(0)
 
+	var cp *checkpointer
+	if opts.CheckpointChan != nil {
+		cp = newCheckpointer(opts.CheckpointChan, opts.PartitionedStore, opts.Cip, opts.DedupWindow, opts.VersionHorizon, opts.Logger)
+		go cp.runCheckpointer()
+	}
+
 	for i := 0; i < opts.NumWorkers; i++ {
 		w := worker{
 			scheduledTxnChan: opts.ScheduledTxnChan,
@@ -83,16 +115,28 @@ func NewEngine(opts EngineOpts) *Engine {
 			// lua state
 			// and because all workers have their own lua state,
 			// all workers need their own procedure cache
-			compiledStoredProcs: make(map[string]*glua.LFunction),
+			compiledStoredProcs: make(map[string]*compiledStoredProc),
+			outcomeChan:         outcomeChan,
+			checkpointer:        cp,
+			dedupWindow:         opts.DedupWindow,
+			nodeID:              opts.NodeID,
 			logger:              opts.Logger,
 			counter:             &counter,
 		}
 		go w.runWorker()
 	}
 
+	reporter := newOutcomeReporter(outcomeChan, outcomes, opts.ConnCache, opts.NodeID, opts.Logger)
+	go reporter.runReporter()
+	if opts.DroppedTxnChan != nil {
+		go runDroppedTxnReporter(opts.DroppedTxnChan, outcomeChan)
+	}
+
 	e := &Engine{
-		storedProcs: storedProcs,
-		counter:     &counter,
+		storedProcs:  storedProcs,
+		outcomes:     outcomes,
+		checkpointer: cp,
+		counter:      &counter,
 	}
 
 	// DEBUG
@@ -110,8 +154,36 @@ func NewEngine(opts EngineOpts) *Engine {
 }
 
 type Engine struct {
-	storedProcs *sync.Map
-	counter     *uint64
+	storedProcs  *util.StoredProcedureRegistry
+	outcomes     *outcomeRegistry
+	checkpointer *checkpointer
+	counter      *uint64
+}
+
+// WaitForCheckpoint returns a channel that is closed once all batches up to
+// the provided index are done executing on this node.
+// Without checkpointing the channel is closed right away.
+func (e *Engine) WaitForCheckpoint(index uint64) <-chan struct{} {
+	if e.checkpointer == nil {
+		c := make(chan struct{})
+		close(c)
+		return c
+	}
+	return e.checkpointer.waitFor(index)
+}
+
+// NewTxnFuture registers a future for the txn with the provided id.
+// The future resolves once the outcome of the txn was reported back to this node.
+// That only happens for txns that have this node set as their origin node.
+func (e *Engine) NewTxnFuture(txnID *pb.Id128) (*TxnFuture, error) {
+	return e.outcomes.register(txnID)
+}
+
+// TxnFutureFor returns the future of the txn with the provided id if there is one waiting for its outcome.
+// Otherwise it registers a new future just like NewTxnFuture.
+// Clients that submit a txn again after giving up on it share the future with the first submission.
+func (e *Engine) TxnFutureFor(txnID *pb.Id128) (*TxnFuture, error) {
+	return e.outcomes.registerOrGet(txnID)
 }
 
 type worker struct {
@@ -121,11 +193,15 @@ type worker struct {
 	connCache           util.ConnectionCache
 	cip                 util.ClusterInfoProvider
 	txnsToExecute       *sync.Map
-	storedProcs         *sync.Map
-	compiledStoredProcs map[string]*glua.LFunction
+	storedProcs         *util.StoredProcedureRegistry
+	compiledStoredProcs map[string]*compiledStoredProc
 	luaState            *glua.LState
 	partitionIDToTxn    map[int]util.DataStoreTxn
 	partitionedStore    util.PartitionedDataStore
+	outcomeChan         chan<- *pb.Transaction
+	checkpointer        *checkpointer
+	dedupWindow         uint64
+	nodeID              uint64
 	logger              *log.Entry
 	counter             *uint64
 }
@@ -168,21 +244,26 @@ func (w *worker) renewLuaState() {
 	defer util.TrackTime(w.logger, "renewLuaState", time.Now())
 	w.luaState.Close()
 	w.luaState = newLuaState()
-	w.compiledStoredProcs = make(map[string]*glua.LFunction)
+	w.compiledStoredProcs = make(map[string]*compiledStoredProc)
 }
 
 // low iso reads only need to do local reads as it is assumed this node owns the key
 func (w *worker) processLowIsolationRead(txn *pb.Transaction) {
-	localKeys, localValues := w.doLocalReads(txn)
+	localKeys, localValues, _ := w.doLocalReads(txn)
+	found := make([]bool, len(localValues))
+	for idx := range localValues {
+		found[idx] = localValues[idx] != nil
+	}
 	txn.LowIsolationReadResponse = &pb.LowIsolationReadResponse{
 		Keys:   localKeys,
 		Values: localValues,
+		Found:  found,
 	}
 	w.doneTxnChan <- txn
 }
 
 func (w *worker) processScheduledTxn(txn *pb.Transaction) {
-	localKeys, localValues := w.doLocalReads(txn)
+	localKeys, localValues, appliedOutcome := w.doLocalReads(txn)
 
 	txnID, err := ulid.ParseIdFromProto(txn.Id)
 	if err != nil {
@@ -190,24 +271,49 @@ func (w *worker) processScheduledTxn(txn *pb.Transaction) {
 	}
 	txnIDStr := txnID.String()
 
-	if w.cip.AmIWriter(txn.WriterNodes) {
+	if appliedOutcome != nil && (w.cip.AmIWriter(txn.WriterNodes) || w.isReplicatingWriter(txn)) {
+		// the other nodes executed this txn before this node went down
+		// they don't send their remote reads again
+		// and the reads of this node would contain the effects of the txn already
+		w.logger.Infof("txn [%s] was applied before this node restarted", txnIDStr)
+		txn.Outcome = appliedOutcome
+		w.doneTxnChan <- txn
+		if !w.cip.IsLearner(w.nodeID) {
+			w.reportOutcome(txn)
+		}
+		return
+	} else if w.cip.AmIWriter(txn.WriterNodes) {
 		// stash this anyways to dedup on receiving a ready txn
 		w.logger.Debugf("setting [%s] into txnsToExecute", txnIDStr)
-		w.txnsToExecute.Store(txnIDStr, txn)
+		w.txnsToExecute.Store(execEnvKey(txnID, txn.BatchIndex, txn.BatchPosition), txn)
+	} else if w.isReplicatingWriter(txn) {
+		w.logger.Debugf("setting [%s] into txnsToExecute on learner", txnIDStr)
+		w.txnsToExecute.Store(execEnvKey(txnID, txn.BatchIndex, txn.BatchPosition), txn)
 	} else {
 		// if I'm not a writer, I'm done now
 		// and can tell the lock manager to release the locks
 		w.logger.Debugf("releasing locks for RO txn [%s]", txnIDStr)
+		reportOutcome := w.isReportingReadOnlyTxn(txn)
+		if reportOutcome {
+			txn.Outcome = w.newOutcome(txn, pb.COMMITTED, "")
+		}
 		w.doneTxnChan <- txn
+		if reportOutcome {
+			w.reportOutcome(txn)
+		}
 	}
 
 	// broadcast remote reads to all write peers
-	if len(localKeys) > 0 {
+	// learners get all reads from the owners of the keys
+	// their own reads would be counted twice
+	if len(localKeys) > 0 && !w.cip.IsLearner(w.nodeID) {
 		w.broadcastLocalReadsToWriterNodes(txn, localKeys, localValues, txnIDStr)
 	}
 }
 
-func (w *worker) doLocalReads(txn *pb.Transaction) ([][]byte, [][]byte) {
+// if all local partitions the txn writes to contain its effects already,
+// the outcome of the txn is returned as well
+func (w *worker) doLocalReads(txn *pb.Transaction) ([][]byte, [][]byte, *pb.TransactionOutcome) {
 	localKeys := make([][]byte, 0)
 	localValues := make([][]byte, 0)
 	w.partitionIDToTxn = make(map[int]util.DataStoreTxn)
@@ -241,13 +347,62 @@ func (w *worker) doLocalReads(txn *pb.Transaction) ([][]byte, [][]byte) {
 		}
 	}
 
+	appliedOutcome := w.findAppliedOutcome(txn)
 	err := w.rollback()
 	if err != nil {
 		w.logger.Panicf("Can't roll back txn: %s", err.Error())
 	}
 
 	w.partitionIDToTxn = nil
-	return localKeys, localValues
+	return localKeys, localValues, appliedOutcome
+}
+
+// txns after the checkpoint are executed again when the node restarts
+// a txn that committed before can be told apart by its markers
+// the local partitions it writes to were read already and are still open
+func (w *worker) findAppliedOutcome(txn *pb.Transaction) *pb.TransactionOutcome {
+	if w.checkpointer == nil || txn.BatchIndex == 0 {
+		return nil
+	}
+
+	marker := &appliedTxnMarker{
+		batchIndex: txn.BatchIndex,
+		key:        appliedTxnKey(txn.Id),
+	}
+	applied := false
+	var earlier *pb.TransactionOutcome
+	for idx := range txn.ReadWriteSet {
+		key := txn.ReadWriteSet[idx]
+		if !w.cip.IsLocal(key) {
+			continue
+		}
+
+		dsTxn, err := w.getTxnForKey(key, false)
+		if err != nil {
+			w.logger.Panicf("can't get txn for key [%s]: %s", string(key), err.Error())
+		}
+
+		if !isAppliedInPartition(dsTxn, marker) {
+			return nil
+		}
+		applied = true
+
+		bites := dsTxn.Get(marker.key)
+		if earlier == nil && bites != nil {
+			outcome, err := unmarshalAppliedTxnMarker(bites)
+			if err == nil && outcome.BatchIndex == txn.BatchIndex {
+				earlier = outcome
+			}
+		}
+	}
+
+	if !applied {
+		return nil
+	} else if earlier == nil {
+		// the partitions moved past the txn and its markers are gone already
+		return w.newOutcome(txn, pb.COMMITTED, "")
+	}
+	return w.newDuplicateOutcome(txn, earlier)
 }
 
 func (w *worker) getTxnForKey(key []byte, writable bool) (util.DataStoreTxn, error) {
@@ -287,28 +442,65 @@ func (w *worker) rollback() error {
 	return nil
 }
 
+// learners execute the txns of the nodes they replicate
+// and need to see the same reads as these nodes
+func (w *worker) isReplicatingWriter(txn *pb.Transaction) bool {
+	if !w.cip.IsLearner(w.nodeID) {
+		return false
+	}
+
+	for idx := range txn.WriterNodes {
+		learners := w.cip.FindLearnersForNode(txn.WriterNodes[idx])
+		for _, learnerID := range learners {
+			if learnerID == w.nodeID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// all writers and the learners replicating them receive remote reads
+func (w *worker) findRemoteReadReceivers(txn *pb.Transaction) []uint64 {
+	receivers := make([]uint64, 0, len(txn.WriterNodes))
+	seen := make(map[uint64]bool)
+	for idx := range txn.WriterNodes {
+		nodeIDs := append([]uint64{txn.WriterNodes[idx]}, w.cip.FindLearnersForNode(txn.WriterNodes[idx])...)
+		for _, nodeID := range nodeIDs {
+			if !seen[nodeID] {
+				seen[nodeID] = true
+				receivers = append(receivers, nodeID)
+			}
+		}
+	}
+	return receivers
+}
+
 func (w *worker) broadcastLocalReadsToWriterNodes(txn *pb.Transaction, keys [][]byte, values [][]byte, txnID string) {
 	defer util.TrackTime(w.logger, fmt.Sprintf("broadcastLocalReadsToWriterNodes [%s]", txnID), time.Now())
-	for idx := range txn.WriterNodes {
-		client, err := w.connCache.GetRemoteReadClient(txn.WriterNodes[idx])
+	receivers := w.findRemoteReadReceivers(txn)
+	for idx := range receivers {
+		client, err := w.connCache.GetRemoteReadClient(receivers[idx])
 		if err != nil {
 			w.logger.Panicf("%s\n", err.Error())
 		}
 
 		if log.GetLevel() == log.DebugLevel {
-			w.logger.Debugf("broadcasting remote reads for [%s] to %d", txnID, txn.WriterNodes[idx])
+			w.logger.Debugf("broadcasting remote reads for [%s] to %d", txnID, receivers[idx])
 		}
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		resp, err := client.RemoteRead(ctx, &pb.RemoteReadRequest{
 			TxnId:         txn.Id,
+			BatchIndex:    txn.BatchIndex,
+			BatchPosition: txn.BatchPosition,
 			TotalNumLocks: uint32(len(txn.ReadWriteSet) + len(txn.ReadSet)),
 			Keys:          keys,
 			Values:        values,
 		})
 		if err != nil || resp.Error != "" {
-			w.logger.Errorf("Node [%d] wasn't reachable: %s\n", txn.WriterNodes[idx], err.Error())
+			w.logger.Errorf("Node [%d] wasn't reachable: %s\n", receivers[idx], err.Error())
 		}
 	}
 }
@@ -316,52 +508,168 @@ func (w *worker) broadcastLocalReadsToWriterNodes(txn *pb.Transaction, keys [][]
 func (w *worker) runReadyTxn(execEnv *txnExecEnvironment) {
 	txnID := execEnv.txnId.String()
 	defer util.TrackTime(w.logger, fmt.Sprintf("runReadyTxn [%s]", txnID), time.Now())
-	t, ok := w.txnsToExecute.Load(txnID)
+	key := execEnvKey(execEnv.txnId, execEnv.batchIndex, execEnv.batchPosition)
+	t, ok := w.txnsToExecute.Load(key)
 	if !ok {
-		w.logger.Panicf("Can't find txn [%s]", txnID)
+		w.logger.Panicf("Can't find txn [%s]", key)
 	}
-	w.txnsToExecute.Delete(txnID)
+	w.txnsToExecute.Delete(key)
 	txn := t.(*pb.Transaction)
 
-	err := w.runTxn(txn, execEnv, txnID)
-	if err != nil {
-		w.logger.Panicf("%s", err.Error())
-	}
+	txn.Outcome = w.runTxn(txn, execEnv, txnID)
 	w.doneTxnChan <- txn
+	// the nodes owning the keys report the outcome
+	if !w.cip.IsLearner(w.nodeID) {
+		w.reportOutcome(txn)
+	}
 }
 
-func (w *worker) runTxn(txn *pb.Transaction, execEnv *txnExecEnvironment, txnID string) error {
+func (w *worker) runTxn(txn *pb.Transaction, execEnv *txnExecEnvironment, txnID string) *pb.TransactionOutcome {
 	defer util.TrackTime(w.logger, fmt.Sprintf("runTxn [%s]", txnID), time.Now())
 	lds := newStoredProcDataStore(w.partitionedStore, execEnv.keys, execEnv.values, w.cip)
+	lds.scans = txn.Scans
+	if w.checkpointer != nil && txn.BatchIndex > 0 {
+		lds.trackAppliedTxn(txn, w.checkpointer)
+	}
+	if txn.BatchIndex > 0 {
+		lds.stampVersion(txn)
+	}
 
-	err := w.runLua(txn, execEnv, lds)
+	err := lds.beginWrites(txn.ReadWriteSet)
 	if err != nil {
+		w.logger.Panicf("can't start writing txn [%s]: %s", txnID, err.Error())
+	}
+
+	if w.dedupWindow > 0 && lds.marker != nil {
+		earlier, err := lds.findEarlierOutcome(w.dedupWindow)
+		if err != nil {
+			w.logger.Panicf("can't look for earlier runs of txn [%s]: %s", txnID, err.Error())
+		}
+
+		if earlier != nil {
+			err = lds.rollback()
+			if err != nil {
+				w.logger.Panicf("can't roll back txn [%s]: %s", txnID, err.Error())
+			}
+			w.logger.Infof("txn [%s] was submitted again and ran in batch [%d] already", txnID, earlier.BatchIndex)
+			return w.newDuplicateOutcome(txn, earlier)
+		}
+	}
+
+	result, err := w.runLua(txn, execEnv, lds)
+	if lds.aborted {
+		// the procedure decided to abort the txn
+		// that is just as deterministic as running it successfully
+		// (even if the procedure swallowed the error raised by abort)
+		err = lds.rollback()
+		if err != nil {
+			w.logger.Panicf("can't roll back aborted txn [%s]: %s", txnID, err.Error())
+		}
+		w.logger.Debugf("txn [%s] aborted: %s", txnID, lds.abortReason)
+		outcome := w.newOutcome(txn, pb.ABORTED, lds.abortReason)
+		w.commitMarkers(lds, outcome, txnID)
+		return outcome
+	} else if err != nil {
+		// errors in stored procedures are deterministic
+		// all replicas fail the same way and it's safe to move on
 		err2 := lds.rollback()
 		if err2 != nil {
-			return fmt.Errorf("%s %s", err.Error(), err2.Error())
+			w.logger.Panicf("can't roll back txn [%s]: %s %s", txnID, err.Error(), err2.Error())
 		}
-		return fmt.Errorf("error running lua: %s", err.Error())
+		w.logger.Errorf("txn [%s] failed: %s", txnID, err.Error())
+		outcome := w.newOutcome(txn, pb.FAILED, fmt.Sprintf("error running lua: %s", err.Error()))
+		w.commitMarkers(lds, outcome, txnID)
+		return outcome
 	}
 
-	return lds.commit()
+	outcome := w.newOutcome(txn, pb.COMMITTED, "")
+	outcome.Result = result
+	lds.setOutcome(outcome)
+	// failing to commit however is not deterministic
+	// this node can't continue
+	err = lds.commit()
+	if err != nil {
+		w.logger.Panicf("can't commit txn [%s]: %s", txnID, err.Error())
+	}
+	return outcome
 }
 
-func (w *worker) runLua(txn *pb.Transaction, execEnv *txnExecEnvironment, lds *storedProcDataStore) error {
-	fction, ok := w.compiledStoredProcs[txn.StoredProcedure]
-	if !ok {
-		v, ok := w.storedProcs.Load(txn.StoredProcedure)
-		if !ok {
-			return fmt.Errorf("Can't find proc [%s]", txn.StoredProcedure)
-		}
-		script := v.(string)
+// txns that didn't commit leave their marker too
+// a txn that is submitted again gets to see that outcome instead of running again
+// and a txn that is replayed after a restart doesn't wait for remote reads again
+func (w *worker) commitMarkers(lds *storedProcDataStore, outcome *pb.TransactionOutcome, txnID string) {
+	if lds.marker == nil {
+		return
+	}
 
-		fn, err := w.luaState.LoadString(script)
-		if err != nil {
-			w.logger.Panicf("%s\n", err.Error())
+	lds.setOutcome(outcome)
+	err := lds.commit()
+	if err != nil {
+		w.logger.Panicf("can't commit markers of txn [%s]: %s", txnID, err.Error())
+	}
+}
+
+func (w *worker) newOutcome(txn *pb.Transaction, status pb.TransactionStatus, errStr string) *pb.TransactionOutcome {
+	return &pb.TransactionOutcome{
+		TxnId:       txn.Id,
+		Status:      status,
+		Error:       errStr,
+		BatchIndex:  txn.BatchIndex,
+		BatchTerm:   txn.BatchTerm,
+		NodeId:      w.nodeID,
+		WriterNodes: txn.WriterNodes,
+	}
+}
+
+// the outcome of a txn that was submitted again is the outcome of its first run
+// it's reported by this node on behalf of the writers of the retry
+func (w *worker) newDuplicateOutcome(txn *pb.Transaction, earlier *pb.TransactionOutcome) *pb.TransactionOutcome {
+	outcome := w.newOutcome(txn, earlier.Status, earlier.Error)
+	outcome.BatchIndex = earlier.BatchIndex
+	outcome.BatchTerm = earlier.BatchTerm
+	outcome.Result = earlier.Result
+	return outcome
+}
+
+// outcomes of txns without writers are reported by the readers
+// if there are no readers either, the origin reports to itself
+func (w *worker) isReportingReadOnlyTxn(txn *pb.Transaction) bool {
+	if txn.OriginNode == 0 || len(txn.WriterNodes) > 0 {
+		return false
+	} else if len(txn.ReaderNodes) == 0 {
+		return txn.OriginNode == w.nodeID
+	}
+
+	for idx := range txn.ReaderNodes {
+		if txn.ReaderNodes[idx] == w.nodeID {
+			return true
 		}
+	}
+	return false
+}
+
+// only txns that have an origin node set are reported
+// everything else is fire and forget
+// workers don't wait for the reporter
+// if it can't keep up, the origin finds the outcome when the txn is submitted again
+func (w *worker) reportOutcome(txn *pb.Transaction) {
+	if txn.OriginNode == 0 || w.outcomeChan == nil {
+		return
+	}
+
+	select {
+	case w.outcomeChan <- txn:
+	default:
+		w.logger.Warningf("dropping outcome of a txn for node [%d]: the outcome reporter is behind", txn.OriginNode)
+	}
+}
 
-		w.compiledStoredProcs[txn.StoredProcedure] = fn
-		fction = fn
+// runs the stored procedure of a txn and returns
+// the serialized value the procedure returned (if any)
+func (w *worker) runLua(txn *pb.Transaction, execEnv *txnExecEnvironment, lds *storedProcDataStore) ([]byte, error) {
+	fction, err := w.getCompiledStoredProc(txn.StoredProcedure, txn.StoredProcedureVersion, txn.BatchIndex)
+	if err != nil {
+		return nil, err
 	}
 
 	keys := w.convertByteArrayToStringArray(execEnv.keys)
@@ -376,9 +684,65 @@ func (w *worker) runLua(txn *pb.Transaction, execEnv *txnExecEnvironment, lds *s
 	w.luaState.SetGlobal("KEYV", gluar.New(w.luaState, keys))
 	w.luaState.SetGlobal("ARGC", gluar.New(w.luaState, len(args)))
 	w.luaState.SetGlobal("ARGV", gluar.New(w.luaState, args))
-
+	w.luaState.SetGlobal("abort", w.luaState.NewFunction(func(L *glua.LState) int {
+		reason := L.OptString(1, "")
+		lds.abort(reason)
+		L.RaiseError("txn aborted: %s", reason)
+		return 0
+	}))
+
+	top := w.luaState.GetTop()
 	w.luaState.Push(fction)
-	return w.luaState.PCall(0, glua.MultRet, nil)
+	err = w.luaState.PCall(0, glua.MultRet, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the first return value is handed back to the client
+	// everything else is dropped on the floor
+	numResults := w.luaState.GetTop() - top
+	if numResults <= 0 {
+		return nil, nil
+	}
+	ret := w.luaState.Get(top + 1)
+	w.luaState.Pop(numResults)
+	return marshalLuaResult(ret)
+}
+
+// the cache only holds one version per procedure
+// running a different version replaces the cached one
+func (w *worker) getCompiledStoredProc(name string, version uint64, batchIndex uint64) (*glua.LFunction, error) {
+	// txns that went through the log had their version pinned by the scheduler
+	// no version means the procedure didn't exist when the txn was scheduled
+	if version == 0 && batchIndex > 0 {
+		return nil, fmt.Errorf("Can't find proc [%s]", name)
+	}
+
+	proc, ok := w.storedProcs.Get(name, version, batchIndex)
+	if !ok {
+		return nil, fmt.Errorf("Can't find proc [%s] version [%d]", name, version)
+	}
+
+	compiled, ok := w.compiledStoredProcs[name]
+	if ok && compiled.version == proc.Version {
+		return compiled.fn, nil
+	}
+
+	fn, err := w.luaState.LoadString(proc.Source)
+	if err != nil {
+		return nil, fmt.Errorf("Can't compile proc [%s] version [%d]: %s", name, proc.Version, err.Error())
+	}
+
+	w.compiledStoredProcs[name] = &compiledStoredProc{
+		version: proc.Version,
+		fn:      fn,
+	}
+	return fn, nil
+}
+
+type compiledStoredProc struct {
+	version uint64
+	fn      *glua.LFunction
 }
 
 func (w *worker) convertBitesToArgs(args [][]byte) []*ssa {
@@ -24,6 +24,7 @@ import (
 	"github.com/mhelmich/calvin/mocks"
 	"github.com/mhelmich/calvin/pb"
 	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -63,6 +64,8 @@ func TestEngineBasic(t *testing.T) {
 		func(b []byte) bool { return "narf" == string(b) || "moep" == string(b) },
 	)
 	mockCIP.On("AmIWriter", mock.AnythingOfType("[]uint64")).Return(true)
+	mockCIP.On("IsLearner", mock.AnythingOfType("uint64")).Return(false)
+	mockCIP.On("FindLearnersForNode", mock.AnythingOfType("uint64")).Return([]uint64{})
 	mockCIP.On("FindPartitionForKey", mock.AnythingOfType("[]uint8")).Return(1)
 
 	mockTxn := new(mocks.DataStoreTxn)
@@ -131,6 +134,8 @@ func TestWorkerBasic(t *testing.T) {
 		func(b []byte) bool { return "narf" == string(b) || "moep" == string(b) },
 	)
 	mockCIP.On("AmIWriter", mock.AnythingOfType("[]uint64")).Return(true)
+	mockCIP.On("IsLearner", mock.AnythingOfType("uint64")).Return(false)
+	mockCIP.On("FindLearnersForNode", mock.AnythingOfType("uint64")).Return([]uint64{})
 	mockCIP.On("FindPartitionForKey", mock.AnythingOfType("[]uint8")).Return(1)
 
 	mockTxn := new(mocks.DataStoreTxn)
@@ -144,7 +149,7 @@ func TestWorkerBasic(t *testing.T) {
 
 	txnsToExecute := &sync.Map{}
 	logger := log.WithFields(log.Fields{})
-	procs := &sync.Map{}
+	procs := util.NewStoredProcedureRegistry()
 	initStoredProcedures(procs)
 
 	counter := uint64(0)
@@ -157,7 +162,7 @@ func TestWorkerBasic(t *testing.T) {
 		cip:                 mockCIP,
 		txnsToExecute:       txnsToExecute,
 		storedProcs:         procs,
-		compiledStoredProcs: make(map[string]*glua.LFunction),
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
 		luaState:            glua.NewState(),
 		counter:             &counter,
 		logger:              logger,
@@ -171,7 +176,7 @@ func TestWorkerBasic(t *testing.T) {
 		Id:              id.ToProto(),
 		StoredProcedure: simpleSetterProcName,
 	}
-	txnsToExecute.Store(id.String(), txn)
+	txnsToExecute.Store(execEnvKey(id, 0, 0), txn)
 
 	readyToExecChan <- &txnExecEnvironment{
 		txnId: id,
@@ -216,6 +221,8 @@ func TestWorkerSimpleSetter(t *testing.T) {
 		func(b []byte) bool { return "narf" == string(b) || "moep" == string(b) },
 	)
 	mockCIP.On("AmIWriter", mock.AnythingOfType("[]uint64")).Return(true)
+	mockCIP.On("IsLearner", mock.AnythingOfType("uint64")).Return(false)
+	mockCIP.On("FindLearnersForNode", mock.AnythingOfType("uint64")).Return([]uint64{})
 	mockCIP.On("FindPartitionForKey", mock.AnythingOfType("[]uint8")).Return(1)
 
 	mockTxn := new(mocks.DataStoreTxn)
@@ -229,7 +236,7 @@ func TestWorkerSimpleSetter(t *testing.T) {
 	txnsToExecute := &sync.Map{}
 	logger := log.WithFields(log.Fields{})
 
-	procs := &sync.Map{}
+	procs := util.NewStoredProcedureRegistry()
 	initStoredProcedures(procs)
 
 	counter := uint64(0)
@@ -242,7 +249,7 @@ func TestWorkerSimpleSetter(t *testing.T) {
 		partitionedStore:    mockStore,
 		txnsToExecute:       txnsToExecute,
 		storedProcs:         procs,
-		compiledStoredProcs: make(map[string]*glua.LFunction),
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
 		luaState:            glua.NewState(),
 		counter:             &counter,
 		logger:              logger,
@@ -264,7 +271,7 @@ func TestWorkerSimpleSetter(t *testing.T) {
 		StoredProcedure:     simpleSetterProcName,
 		StoredProcedureArgs: [][]byte{argBites},
 	}
-	txnsToExecute.Store(id.String(), txn)
+	txnsToExecute.Store(execEnvKey(id, 0, 0), txn)
 
 	readyToExecChan <- &txnExecEnvironment{
 		txnId:  id,
@@ -278,3 +285,246 @@ func TestWorkerSimpleSetter(t *testing.T) {
 	assert.Equal(t, id.String(), doneID.String())
 	close(scheduledTxnChan)
 }
+
+func TestWorkerLearner(t *testing.T) {
+	scheduledTxnChan := make(chan *pb.Transaction)
+	readyToExecChan := make(chan *txnExecEnvironment, 1)
+	doneTxnChan := make(chan *pb.Transaction)
+	outcomeChan := make(chan *pb.Transaction, 1)
+
+	// learners don't send remote reads anywhere
+	mockCC := new(mocks.ConnectionCache)
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("IsLocal", mock.AnythingOfType("[]uint8")).Return(true)
+	mockCIP.On("AmIWriter", mock.AnythingOfType("[]uint64")).Return(false)
+	mockCIP.On("IsLearner", uint64(3)).Return(true)
+	mockCIP.On("FindLearnersForNode", uint64(1)).Return([]uint64{3})
+	mockCIP.On("FindPartitionForKey", mock.AnythingOfType("[]uint8")).Return(1)
+
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Get", mock.AnythingOfType("[]uint8")).Return([]byte("narf_value"))
+	mockTxn.On("Rollback").Return(nil)
+	mockTxn.On("Set", []byte("narf"), []byte("moep_value")).Return(nil)
+	mockTxn.On("Commit").Return(nil)
+	mockTxnProvider := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider.On("StartTxn", mock.AnythingOfType("bool")).Return(mockTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", mock.AnythingOfType("int")).Return(mockTxnProvider, nil)
+
+	txnsToExecute := &sync.Map{}
+	logger := log.WithFields(log.Fields{})
+
+	procs := util.NewStoredProcedureRegistry()
+	initStoredProcedures(procs)
+
+	counter := uint64(0)
+	w := worker{
+		scheduledTxnChan:    scheduledTxnChan,
+		readyToExecChan:     readyToExecChan,
+		doneTxnChan:         doneTxnChan,
+		connCache:           mockCC,
+		cip:                 mockCIP,
+		partitionedStore:    mockStore,
+		txnsToExecute:       txnsToExecute,
+		storedProcs:         procs,
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
+		luaState:            glua.NewState(),
+		outcomeChan:         outcomeChan,
+		nodeID:              uint64(3),
+		counter:             &counter,
+		logger:              logger,
+	}
+	go w.runWorker()
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+
+	arg := &pb.SimpleSetterArg{
+		Key:   []byte("narf"),
+		Value: []byte("moep_value"),
+	}
+	argBites, err := arg.Marshal()
+	assert.Nil(t, err)
+
+	scheduledTxnChan <- &pb.Transaction{
+		Id:                  id.ToProto(),
+		ReadWriteSet:        [][]byte{[]byte("narf")},
+		StoredProcedure:     simpleSetterProcName,
+		StoredProcedureArgs: [][]byte{argBites},
+		WriterNodes:         []uint64{1},
+		ReaderNodes:         []uint64{1},
+		OriginNode:          uint64(1),
+	}
+
+	// the reads of the owner of the key
+	readyToExecChan <- &txnExecEnvironment{
+		txnId:  id,
+		keys:   [][]byte{[]byte("narf")},
+		values: [][]byte{[]byte("narf_value")},
+	}
+
+	doneTxn := <-doneTxnChan
+	doneID, err := ulid.ParseIdFromProto(doneTxn.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, id.String(), doneID.String())
+	assert.Equal(t, pb.COMMITTED, doneTxn.Outcome.Status)
+	mockTxn.AssertCalled(t, "Set", []byte("narf"), []byte("moep_value"))
+	// the owner reports the outcome
+	assert.Equal(t, 0, len(outcomeChan))
+	mockCC.AssertNotCalled(t, "GetRemoteReadClient", mock.AnythingOfType("uint64"))
+	close(scheduledTxnChan)
+}
+
+func TestWorkerProcedureResult(t *testing.T) {
+	scheduledTxnChan := make(chan *pb.Transaction)
+	readyToExecChan := make(chan *txnExecEnvironment, 1)
+	doneTxnChan := make(chan *pb.Transaction)
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("AmIWriter", mock.AnythingOfType("[]uint64")).Return(true)
+	mockCIP.On("IsLearner", mock.AnythingOfType("uint64")).Return(false)
+	mockCIP.On("FindLearnersForNode", mock.AnythingOfType("uint64")).Return([]uint64{})
+	mockStore := new(mocks.PartitionedDataStore)
+
+	txnsToExecute := &sync.Map{}
+	procs := util.NewStoredProcedureRegistry()
+	initStoredProcedures(procs)
+	procs.Install("__new_order__", `
+	  local lines = {}
+	  lines[1] = "line_1"
+	  lines[2] = "line_2"
+	  return { order_id = 42, lines = lines }, "ignored"
+	`)
+
+	counter := uint64(0)
+	w := worker{
+		scheduledTxnChan:    scheduledTxnChan,
+		readyToExecChan:     readyToExecChan,
+		doneTxnChan:         doneTxnChan,
+		partitionedStore:    mockStore,
+		connCache:           new(mocks.ConnectionCache),
+		cip:                 mockCIP,
+		txnsToExecute:       txnsToExecute,
+		storedProcs:         procs,
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
+		luaState:            glua.NewState(),
+		counter:             &counter,
+		nodeID:              uint64(1),
+		logger:              log.WithFields(log.Fields{}),
+	}
+	go w.runWorker()
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	txnsToExecute.Store(execEnvKey(id, 0, 0), &pb.Transaction{
+		Id:              id.ToProto(),
+		StoredProcedure: "__new_order__",
+	})
+	readyToExecChan <- &txnExecEnvironment{
+		txnId: id,
+	}
+
+	doneTxn := <-doneTxnChan
+	assert.Equal(t, pb.COMMITTED, doneTxn.Outcome.Status)
+	assert.Equal(t, `{"lines":["line_1","line_2"],"order_id":42}`, string(doneTxn.Outcome.Result))
+
+	result := struct {
+		OrderID int      `json:"order_id"`
+		Lines   []string `json:"lines"`
+	}{}
+	err = doneTxn.Outcome.UnmarshalResult(&result)
+	assert.Nil(t, err)
+	assert.Equal(t, 42, result.OrderID)
+	assert.Equal(t, []string{"line_1", "line_2"}, result.Lines)
+	// the stack is clean after the procedure ran
+	assert.Equal(t, 0, w.luaState.GetTop())
+	close(scheduledTxnChan)
+}
+
+func TestWorkerAbort(t *testing.T) {
+	scheduledTxnChan := make(chan *pb.Transaction)
+	readyToExecChan := make(chan *txnExecEnvironment, 1)
+	doneTxnChan := make(chan *pb.Transaction)
+
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("IsLocal", mock.AnythingOfType("[]uint8")).Return(true)
+	mockCIP.On("AmIWriter", mock.AnythingOfType("[]uint64")).Return(true)
+	mockCIP.On("IsLearner", mock.AnythingOfType("uint64")).Return(false)
+	mockCIP.On("FindLearnersForNode", mock.AnythingOfType("uint64")).Return([]uint64{})
+	mockCIP.On("FindPartitionForKey", mock.AnythingOfType("[]uint8")).Return(
+		func(b []byte) int {
+			if "narf" == string(b) {
+				return 1
+			}
+			return 2
+		},
+	)
+
+	mockTxn1 := new(mocks.DataStoreTxn)
+	mockTxn1.On("Set", mock.AnythingOfType("[]uint8"), mock.AnythingOfType("[]uint8")).Return(nil)
+	mockTxn1.On("Rollback").Return(nil)
+	mockTxnProvider1 := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider1.On("StartTxn", true).Return(mockTxn1, nil)
+	mockTxn2 := new(mocks.DataStoreTxn)
+	mockTxn2.On("Set", mock.AnythingOfType("[]uint8"), mock.AnythingOfType("[]uint8")).Return(nil)
+	mockTxn2.On("Rollback").Return(nil)
+	mockTxnProvider2 := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider2.On("StartTxn", true).Return(mockTxn2, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider1, nil)
+	mockStore.On("GetPartition", 2).Return(mockTxnProvider2, nil)
+
+	txnsToExecute := &sync.Map{}
+	procs := util.NewStoredProcedureRegistry()
+	initStoredProcedures(procs)
+	// the procedure swallows the abort error
+	// the txn is aborted nonetheless
+	procs.Install("__insufficient_stock__", `
+	  store:Set("narf", "narf_value")
+	  store:Set("moep", "moep_value")
+	  pcall(abort, "insufficient stock")
+	  return "too late"
+	`)
+
+	counter := uint64(0)
+	w := worker{
+		scheduledTxnChan:    scheduledTxnChan,
+		readyToExecChan:     readyToExecChan,
+		doneTxnChan:         doneTxnChan,
+		partitionedStore:    mockStore,
+		connCache:           new(mocks.ConnectionCache),
+		cip:                 mockCIP,
+		txnsToExecute:       txnsToExecute,
+		storedProcs:         procs,
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
+		luaState:            glua.NewState(),
+		counter:             &counter,
+		nodeID:              uint64(1),
+		logger:              log.WithFields(log.Fields{}),
+	}
+	go w.runWorker()
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	txnsToExecute.Store(execEnvKey(id, 0, 0), &pb.Transaction{
+		Id:              id.ToProto(),
+		StoredProcedure: "__insufficient_stock__",
+		ReadWriteSet:    [][]byte{[]byte("moep"), []byte("narf")},
+	})
+	readyToExecChan <- &txnExecEnvironment{
+		txnId:  id,
+		keys:   [][]byte{[]byte("moep"), []byte("narf")},
+		values: [][]byte{[]byte("moep_value"), []byte("narf_value")},
+	}
+
+	doneTxn := <-doneTxnChan
+	assert.Equal(t, pb.ABORTED, doneTxn.Outcome.Status)
+	assert.Equal(t, "insufficient stock", doneTxn.Outcome.Error)
+	assert.Nil(t, doneTxn.Outcome.Result)
+	mockTxn1.AssertCalled(t, "Rollback")
+	mockTxn1.AssertNotCalled(t, "Commit")
+	mockTxn2.AssertCalled(t, "Rollback")
+	mockTxn2.AssertNotCalled(t, "Commit")
+	close(scheduledTxnChan)
+}
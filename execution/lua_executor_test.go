@@ -17,11 +17,12 @@
 package execution
 
 import (
-	"sync"
+	"fmt"
 	"testing"
 
 	"github.com/mhelmich/calvin/mocks"
 	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	glua "github.com/yuin/gopher-lua"
@@ -199,13 +200,13 @@ func TestLuaExecutorScriptInvocation(t *testing.T) {
 	mockStore.On("GetPartition", mock.AnythingOfType("int")).Return(mockTxnProvider, nil)
 	lds := newStoredProcDataStore(mockStore, execEnv.keys, execEnv.values, mockCIP)
 
-	procs := &sync.Map{}
-	procs.Store(simpleSetterProcName, simpleSetterProc)
+	procs := util.NewStoredProcedureRegistry()
+	procs.Install(simpleSetterProcName, simpleSetterProc)
 
 	w := &worker{
 		storedProcs:         procs,
 		luaState:            glua.NewState(),
-		compiledStoredProcs: make(map[string]*glua.LFunction),
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
 	}
 	w.runLua(txn, execEnv, lds)
 
@@ -355,13 +356,13 @@ func BenchmarkLuaExecutorScriptInvocation(b *testing.B) {
 	mockStore.On("GetPartition", mock.AnythingOfType("int")).Return(mockTxnProvider, nil)
 	lds := newStoredProcDataStore(mockStore, execEnv.keys, execEnv.values, mockCIP)
 
-	procs := &sync.Map{}
-	procs.Store(simpleSetterProcName, simpleSetterProc)
+	procs := util.NewStoredProcedureRegistry()
+	procs.Install(simpleSetterProcName, simpleSetterProc)
 
 	w := &worker{
 		storedProcs:         procs,
 		luaState:            glua.NewState(),
-		compiledStoredProcs: make(map[string]*glua.LFunction),
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
 	}
 
 	// f1, err := os.Create("./narf.pprof")
@@ -382,3 +383,37 @@ func BenchmarkLuaExecutorScriptInvocation(b *testing.B) {
 	v = lds.Get("moep")
 	assert.Equal(b, "moep_arg", v)
 }
+
+func TestLuaExecutorProcedureVersions(t *testing.T) {
+	procs := util.NewStoredProcedureRegistry()
+	assert.Equal(t, uint64(1), procs.Install("narf", "return 1"))
+	assert.Equal(t, uint64(2), procs.Install("narf", "return 2"))
+
+	w := &worker{
+		storedProcs:         procs,
+		luaState:            glua.NewState(),
+		compiledStoredProcs: make(map[string]*compiledStoredProc),
+	}
+	execEnv := &txnExecEnvironment{}
+
+	for _, version := range []uint64{1, 2, 1, 0} {
+		lds := newStoredProcDataStore(new(mocks.PartitionedDataStore), nil, nil, new(mocks.ClusterInfoProvider))
+		txn := &pb.Transaction{
+			StoredProcedure:        "narf",
+			StoredProcedureVersion: version,
+		}
+		result, err := w.runLua(txn, execEnv, lds)
+		assert.Nil(t, err)
+		expected := version
+		if expected == 0 {
+			expected = 2
+		}
+		assert.Equal(t, fmt.Sprintf("%d", expected), string(result))
+	}
+
+	_, err := w.runLua(&pb.Transaction{
+		StoredProcedure:        "narf",
+		StoredProcedureVersion: uint64(3),
+	}, execEnv, newStoredProcDataStore(new(mocks.PartitionedDataStore), nil, nil, new(mocks.ClusterInfoProvider)))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+
+	glua "github.com/yuin/gopher-lua"
+)
+
+const (
+	// guards against tables that reference themselves
+	maxLuaResultDepth = 32
+)
+
+// serializes the value a stored procedure returned into json
+// every writer node runs the same procedure and has to come up with the same bytes
+// json sorts map keys which makes the serialization deterministic
+func marshalLuaResult(lv glua.LValue) ([]byte, error) {
+	if lv == glua.LNil {
+		return nil, nil
+	}
+
+	v, err := luaValueToGo(lv, 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func luaValueToGo(lv glua.LValue, depth int) (interface{}, error) {
+	if depth > maxLuaResultDepth {
+		return nil, fmt.Errorf("stored procedure result is nested deeper than %d levels", maxLuaResultDepth)
+	}
+
+	switch v := lv.(type) {
+	case *glua.LNilType:
+		return nil, nil
+	case glua.LBool:
+		return bool(v), nil
+	case glua.LNumber:
+		return float64(v), nil
+	case glua.LString:
+		return string(v), nil
+	case *glua.LUserData:
+		// values that were wrapped by gluar (e.g. the result of store:Get)
+		return v.Value, nil
+	case *glua.LTable:
+		return luaTableToGo(v, depth+1)
+	default:
+		return nil, fmt.Errorf("can't return value of type [%s] from stored procedure", lv.Type().String())
+	}
+}
+
+// tables that only have consecutive integer keys starting at 1 become arrays
+// everything else becomes an object with string keys
+func luaTableToGo(tbl *glua.LTable, depth int) (interface{}, error) {
+	maxN := tbl.MaxN()
+	numKeys := 0
+	tbl.ForEach(func(glua.LValue, glua.LValue) { numKeys++ })
+
+	if maxN > 0 && maxN == numKeys {
+		arr := make([]interface{}, maxN)
+		for i := 1; i <= maxN; i++ {
+			v, err := luaValueToGo(tbl.RawGetInt(i), depth)
+			if err != nil {
+				return nil, err
+			}
+			arr[i-1] = v
+		}
+		return arr, nil
+	}
+
+	var err error
+	m := make(map[string]interface{}, numKeys)
+	tbl.ForEach(func(k glua.LValue, v glua.LValue) {
+		if err != nil {
+			return
+		}
+
+		var gv interface{}
+		gv, err = luaValueToGo(v, depth)
+		if err != nil {
+			return
+		}
+		m[k.String()] = gv
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
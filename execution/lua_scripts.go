@@ -16,10 +16,37 @@
 
 package execution
 
-import "sync"
+import (
+	"fmt"
+	"strings"
 
-func initStoredProcedures(m *sync.Map) {
-	m.Store(simpleSetterProcName, simpleSetterProc)
+	"github.com/mhelmich/calvin/util"
+)
+
+// built-in procedures are installed on every node at startup
+// they never go through the log and always have version 1
+func initStoredProcedures(r *util.StoredProcedureRegistry) {
+	if r.CurrentVersion(simpleSetterProcName) == 0 {
+		r.InstallBuiltIn(simpleSetterProcName, simpleSetterProc)
+	}
+}
+
+// ValidateStoredProcedure checks whether a procedure can be registered.
+// Names starting with "__" are reserved for built-in procedures.
+func ValidateStoredProcedure(name string, source string) error {
+	if name == "" {
+		return fmt.Errorf("stored procedure needs a name")
+	} else if strings.HasPrefix(name, "__") {
+		return fmt.Errorf("stored procedure names starting with '__' are reserved")
+	}
+
+	l := newLuaState()
+	defer l.Close()
+	_, err := l.LoadString(source)
+	if err != nil {
+		return fmt.Errorf("can't compile stored procedure [%s]: %s", name, err.Error())
+	}
+	return nil
 }
 
 const simpleSetterProcName = "__simple_setter__"
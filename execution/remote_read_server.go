@@ -37,10 +37,19 @@ func newRemoteReadServer(readyToExecChan chan<- *txnExecEnvironment, logger *log
 }
 
 type txnExecEnvironment struct {
-	txnId  *ulid.ID
-	keys   [][]byte
-	values [][]byte
-	mutex  *sync.Mutex
+	txnId         *ulid.ID
+	batchIndex    uint64
+	batchPosition uint64
+	keys          [][]byte
+	values        [][]byte
+	mutex         *sync.Mutex
+}
+
+// a txn that is submitted again with the same id can end up in the same batch or a later one
+// both of them can collect remote reads at the same time
+// their position in the log tells them apart
+func execEnvKey(txnID *ulid.ID, batchIndex uint64, batchPosition uint64) string {
+	return fmt.Sprintf("%s/%d/%d", txnID.String(), batchIndex, batchPosition)
 }
 
 func (e *txnExecEnvironment) String() string {
@@ -66,9 +75,12 @@ func (rrs *remoteReadServer) RemoteRead(ctx context.Context, req *pb.RemoteReadR
 	}
 
 	defer util.TrackTime(rrs.logger, fmt.Sprintf("RemoteRead [%s]", txnIDStr), time.Now())
-	v, _ := rrs.txnIdToTxnExecEnv.LoadOrStore(txnIDStr, &txnExecEnvironment{
-		mutex: &sync.Mutex{},
-		txnId: id,
+	key := execEnvKey(id, req.BatchIndex, req.BatchPosition)
+	v, _ := rrs.txnIdToTxnExecEnv.LoadOrStore(key, &txnExecEnvironment{
+		mutex:         &sync.Mutex{},
+		txnId:         id,
+		batchIndex:    req.BatchIndex,
+		batchPosition: req.BatchPosition,
 	})
 	execEnv := v.(*txnExecEnvironment)
 
@@ -79,12 +91,12 @@ func (rrs *remoteReadServer) RemoteRead(ctx context.Context, req *pb.RemoteReadR
 	if int(req.TotalNumLocks) == len(execEnv.keys) {
 		// this txn can run
 		rrs.logger.Debugf("txn [%s] can run [%d] [%d] [%d]", txnIDStr, int(req.TotalNumLocks), len(execEnv.keys), len(req.Keys))
-		rrs.txnIdToTxnExecEnv.Delete(txnIDStr)
+		rrs.txnIdToTxnExecEnv.Delete(key)
 		rrs.readyToExecChan <- execEnv
 	} else {
 		// stashing remote reads for a later delivery of remote reads
 		rrs.logger.Debugf("stashing remote reads for txn [%s]", txnIDStr)
-		rrs.txnIdToTxnExecEnv.Store(txnIDStr, execEnv)
+		rrs.txnIdToTxnExecEnv.Store(key, execEnv)
 	}
 
 	execEnv.mutex.Unlock()
@@ -60,3 +60,43 @@ func TestRemoteReadServer(t *testing.T) {
 	assert.Equal(t, 0, execEnv.txnId.CompareTo(id))
 	fmt.Printf("%s\n", execEnv.String())
 }
+
+func TestRemoteReadServerSameTxnInSameBatch(t *testing.T) {
+	readyExecEnvChan := make(chan *txnExecEnvironment, 2)
+	logger := log.WithFields(log.Fields{})
+	rrs := newRemoteReadServer(readyExecEnvChan, logger)
+
+	// a txn that was submitted twice with the same id
+	// both runs collect their reads on their own
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	for position := uint64(0); position < 2; position++ {
+		resp, err := rrs.RemoteRead(context.TODO(), &pb.RemoteReadRequest{
+			TxnId:         id.ToProto(),
+			BatchIndex:    uint64(7),
+			BatchPosition: position,
+			TotalNumLocks: uint32(2),
+			Keys:          [][]byte{[]byte("narf")},
+			Values:        [][]byte{[]byte("narf")},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "", resp.Error)
+	}
+	assert.Equal(t, 0, len(readyExecEnvChan))
+
+	resp, err := rrs.RemoteRead(context.TODO(), &pb.RemoteReadRequest{
+		TxnId:         id.ToProto(),
+		BatchIndex:    uint64(7),
+		BatchPosition: uint64(1),
+		TotalNumLocks: uint32(2),
+		Keys:          [][]byte{[]byte("moep")},
+		Values:        [][]byte{[]byte("moep")},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Error)
+
+	execEnv := <-readyExecEnvChan
+	assert.Equal(t, uint64(1), execEnv.batchPosition)
+	assert.Equal(t, 2, len(execEnv.keys))
+	assert.Equal(t, 0, len(readyExecEnvChan))
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// OpenSnapshotView opens read txns on all local partitions.
+// Nothing may be written into the partitions while the view is opened,
+// that's what makes the view consistent across partitions.
+func OpenSnapshotView(partitionedStore util.PartitionedDataStore, cip util.ClusterInfoProvider, index uint64, term uint64, logger *log.Entry) (*SnapshotView, error) {
+	return openSnapshotView(partitionedStore, cip, index, term, logger, func(txnProvider util.DataStoreTxnProvider) (util.DataStoreTxn, error) {
+		return txnProvider.StartTxn(false)
+	})
+}
+
+// OpenVersionedSnapshotView opens read txns on all local partitions that see the versions
+// right after the batch with the provided index ran.
+// All local partitions need to keep older versions of their values.
+// Unlike OpenSnapshotView, writes of later batches can go on while the view is opened.
+func OpenVersionedSnapshotView(partitionedStore util.PartitionedDataStore, cip util.ClusterInfoProvider, index uint64, term uint64, logger *log.Entry) (*SnapshotView, error) {
+	version := util.Version{
+		BatchIndex:  index,
+		TxnPosition: math.MaxUint64,
+	}
+	return openSnapshotView(partitionedStore, cip, index, term, logger, func(txnProvider util.DataStoreTxnProvider) (util.DataStoreTxn, error) {
+		versionedTxnProvider, ok := txnProvider.(util.VersionedDataStoreTxnProvider)
+		if !ok {
+			return nil, fmt.Errorf("partition doesn't keep older versions")
+		}
+		return versionedTxnProvider.StartTxnAt(version)
+	})
+}
+
+func openSnapshotView(partitionedStore util.PartitionedDataStore, cip util.ClusterInfoProvider, index uint64, term uint64, logger *log.Entry, startTxn func(util.DataStoreTxnProvider) (util.DataStoreTxn, error)) (*SnapshotView, error) {
+	v := &SnapshotView{
+		index:  index,
+		term:   term,
+		cip:    cip,
+		mutex:  &sync.Mutex{},
+		txns:   make(map[int]util.DataStoreTxn),
+		logger: logger,
+	}
+
+	for _, partitionID := range cip.MyPartitions() {
+		txnProvider, err := partitionedStore.GetPartition(partitionID)
+		if err != nil {
+			v.close()
+			return nil, err
+		}
+
+		txn, err := startTxn(txnProvider)
+		if err != nil {
+			v.close()
+			return nil, err
+		}
+		v.txns[partitionID] = txn
+	}
+	return v, nil
+}
+
+// A SnapshotView is a read-only copy of the local partitions as of a batch boundary.
+// Reads from a view don't take locks and don't wait for txns.
+// The view stays open as long as readers hold on to it.
+// Once it's retired, the last reader to release it closes it.
+type SnapshotView struct {
+	index   uint64
+	term    uint64
+	cip     util.ClusterInfoProvider
+	mutex   *sync.Mutex
+	txns    map[int]util.DataStoreTxn
+	readers int
+	retired bool
+	logger  *log.Entry
+}
+
+// Index returns the index of the last batch whose effects are visible in the view.
+func (v *SnapshotView) Index() uint64 {
+	return v.index
+}
+
+// Term returns the term of the last batch whose effects are visible in the view.
+func (v *SnapshotView) Term() uint64 {
+	return v.term
+}
+
+// Acquire registers a reader with the view.
+// It returns false if the view was retired already.
+func (v *SnapshotView) Acquire() bool {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.retired {
+		return false
+	}
+	v.readers++
+	return true
+}
+
+// Release is called by readers once they are done with the view.
+func (v *SnapshotView) Release() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.readers--
+	if v.retired && v.readers <= 0 {
+		v.close()
+	}
+}
+
+// Retire makes sure no new readers acquire the view.
+// Long running read txns keep the data store from reclaiming space.
+func (v *SnapshotView) Retire() {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.retired {
+		return
+	}
+	v.retired = true
+	if v.readers <= 0 {
+		v.close()
+	}
+}
+
+// Get returns a copy of the value of a local key as of the batch boundary of the view.
+func (v *SnapshotView) Get(key []byte) ([]byte, error) {
+	partitionID := v.cip.FindPartitionForKey(key)
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	txn, ok := v.txns[partitionID]
+	if !ok {
+		return nil, fmt.Errorf("key [%s] isn't local", string(key))
+	}
+
+	value := txn.Get(key)
+	if value == nil {
+		return nil, nil
+	}
+	// values are only valid as long as the txn is open
+	bites := make([]byte, len(value))
+	copy(bites, value)
+	return bites, nil
+}
+
+// needs to be called with the lock held
+func (v *SnapshotView) close() {
+	for partitionID, txn := range v.txns {
+		err := txn.Rollback()
+		if err != nil {
+			v.logger.Errorf("can't close snapshot view of partition [%d]: %s", partitionID, err.Error())
+		}
+	}
+	v.txns = nil
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSnapshotViewLifecycle(t *testing.T) {
+	value := []byte("narf_value")
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("MyPartitions").Return([]int{1})
+	mockCIP.On("FindPartitionForKey", []byte("narf")).Return(1)
+	mockCIP.On("FindPartitionForKey", []byte("moep")).Return(2)
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Get", []byte("narf")).Return(value)
+	mockTxn.On("Rollback").Return(nil)
+	mockTxnProvider := new(mocks.DataStoreTxnProvider)
+	mockTxnProvider.On("StartTxn", false).Return(mockTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider, nil)
+
+	v, err := OpenSnapshotView(mockStore, mockCIP, uint64(5), uint64(2), log.WithFields(log.Fields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), v.Index())
+	assert.Equal(t, uint64(2), v.Term())
+	assert.True(t, v.Acquire())
+
+	// readers get a copy of the value
+	bites, err := v.Get([]byte("narf"))
+	assert.Nil(t, err)
+	assert.Equal(t, value, bites)
+	bites[0] = 'm'
+	assert.Equal(t, "narf_value", string(value))
+	_, err = v.Get([]byte("moep"))
+	assert.NotNil(t, err)
+
+	// the view stays open until the last reader is done
+	v.Retire()
+	assert.False(t, v.Acquire())
+	mockTxn.AssertNotCalled(t, "Rollback")
+	v.Release()
+	mockTxn.AssertNumberOfCalls(t, "Rollback", 1)
+	mockTxnProvider.AssertCalled(t, "StartTxn", mock.AnythingOfType("bool"))
+}
+
+func TestVersionedSnapshotView(t *testing.T) {
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("MyPartitions").Return([]int{1})
+	mockTxn := new(mocks.DataStoreTxn)
+	mockTxn.On("Rollback").Return(nil)
+	mockTxnProvider := new(mocks.VersionedDataStoreTxnProvider)
+	mockTxnProvider.On("StartTxnAt", util.Version{BatchIndex: 5, TxnPosition: math.MaxUint64}).Return(mockTxn, nil)
+	mockStore := new(mocks.PartitionedDataStore)
+	mockStore.On("GetPartition", 1).Return(mockTxnProvider, nil)
+
+	// the view sees all txns of the batch
+	v, err := OpenVersionedSnapshotView(mockStore, mockCIP, uint64(5), uint64(2), log.WithFields(log.Fields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), v.Index())
+	v.Retire()
+	mockTxn.AssertNumberOfCalls(t, "Rollback", 1)
+	mockTxnProvider.AssertNotCalled(t, "StartTxn", mock.Anything)
+
+	// partitions without older versions can't be read that way
+	mockUnversionedStore := new(mocks.PartitionedDataStore)
+	mockUnversionedStore.On("GetPartition", 1).Return(new(mocks.DataStoreTxnProvider), nil)
+	_, err = OpenVersionedSnapshotView(mockUnversionedStore, mockCIP, uint64(5), uint64(2), log.WithFields(log.Fields{}))
+	assert.NotNil(t, err)
+}
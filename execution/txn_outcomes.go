@@ -0,0 +1,377 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxOutcomesPerReport = 128
+	// outcomes per origin node that wait to be reported at most
+	outcomeQueueSize = 8 * maxOutcomesPerReport
+	// futures that didn't resolve by then are removed unless somebody waits for them
+	futureTTL = time.Minute
+)
+
+// A TxnFuture resolves once all nodes that own the write set of a transaction
+// executed the transaction and reported its outcome back to the node the transaction
+// was submitted to.
+type TxnFuture struct {
+	txnID    string
+	doneChan chan struct{}
+	mutex    *sync.Mutex
+	outcome  *pb.TransactionOutcome
+	reported map[uint64]bool
+	created  time.Time
+	// number of callers blocked in Wait
+	waiters int
+}
+
+func newTxnFuture(txnID string) *TxnFuture {
+	return &TxnFuture{
+		txnID:    txnID,
+		doneChan: make(chan struct{}),
+		mutex:    &sync.Mutex{},
+		reported: make(map[uint64]bool),
+		created:  time.Now(),
+	}
+}
+
+// Done returns a channel that is closed once the outcome of the transaction is known.
+func (f *TxnFuture) Done() <-chan struct{} {
+	return f.doneChan
+}
+
+// Outcome returns the outcome of the transaction or nil if the transaction didn't finish yet.
+func (f *TxnFuture) Outcome() *pb.TransactionOutcome {
+	select {
+	case <-f.doneChan:
+		return f.outcome
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until either the outcome of the transaction is known or the context is done.
+// A future that isn't resolved and nobody waits for is forgotten after a while.
+// Submitting the transaction again with the same id gets a new future
+// that resolves with the outcome of the first run.
+func (f *TxnFuture) Wait(ctx context.Context) (*pb.TransactionOutcome, error) {
+	f.mutex.Lock()
+	f.waiters++
+	f.mutex.Unlock()
+	defer func() {
+		f.mutex.Lock()
+		f.waiters--
+		f.mutex.Unlock()
+	}()
+
+	select {
+	case <-f.doneChan:
+		return f.outcome, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// merges the outcome reported by a single node into the overall outcome of the txn
+// returns true if the future resolved with this outcome
+func (f *TxnFuture) addOutcome(outcome *pb.TransactionOutcome) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	select {
+	case <-f.doneChan:
+		return false
+	default:
+	}
+
+	if f.reported[outcome.NodeId] {
+		return false
+	}
+	f.reported[outcome.NodeId] = true
+
+	if f.outcome == nil {
+		f.outcome = &pb.TransactionOutcome{
+			TxnId:       outcome.TxnId,
+			Status:      outcome.Status,
+			Error:       outcome.Error,
+			BatchIndex:  outcome.BatchIndex,
+			BatchTerm:   outcome.BatchTerm,
+			NodeId:      outcome.NodeId,
+			WriterNodes: outcome.WriterNodes,
+			Result:      outcome.Result,
+		}
+	} else if f.outcome.Status == pb.COMMITTED && outcome.Status != pb.COMMITTED {
+		// if one node didn't commit, the txn as a whole didn't commit
+		f.outcome.Status = outcome.Status
+		f.outcome.Error = outcome.Error
+		f.outcome.NodeId = outcome.NodeId
+	}
+
+	// all writers run the same procedure and return the same result
+	// readers (and failed txns) don't return anything though
+	if len(f.outcome.Result) == 0 && len(outcome.Result) > 0 {
+		f.outcome.Result = outcome.Result
+	}
+
+	for idx := range f.outcome.WriterNodes {
+		if !f.reported[f.outcome.WriterNodes[idx]] {
+			return false
+		}
+	}
+
+	close(f.doneChan)
+	return true
+}
+
+func (f *TxnFuture) isExpired(now time.Time) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.waiters == 0 && now.Sub(f.created) > futureTTL
+}
+
+func newOutcomeRegistry(logger *log.Entry) *outcomeRegistry {
+	return &outcomeRegistry{
+		mutex:     &sync.Mutex{},
+		futures:   make(map[string]*TxnFuture),
+		lastSweep: time.Now(),
+		logger:    logger,
+	}
+}
+
+// the outcome registry keeps track of all futures that are handed out by this node
+// and is the receiving end of outcome reports
+// outcomes might never show up, for instance if the node that ran the txn went down
+// futures like that are swept out every so often
+type outcomeRegistry struct {
+	mutex     *sync.Mutex
+	futures   map[string]*TxnFuture
+	lastSweep time.Time
+	logger    *log.Entry
+}
+
+func (or *outcomeRegistry) register(txnID *pb.Id128) (*TxnFuture, error) {
+	id, err := ulid.ParseIdFromProto(txnID)
+	if err != nil {
+		return nil, err
+	}
+
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+	or.sweep(time.Now())
+	if _, ok := or.futures[id.String()]; ok {
+		return nil, fmt.Errorf("txn [%s] has a future already", id.String())
+	}
+
+	f := newTxnFuture(id.String())
+	or.futures[f.txnID] = f
+	return f, nil
+}
+
+// futures are removed once they resolved
+// a txn that is submitted again after that gets a new future
+func (or *outcomeRegistry) registerOrGet(txnID *pb.Id128) (*TxnFuture, error) {
+	id, err := ulid.ParseIdFromProto(txnID)
+	if err != nil {
+		return nil, err
+	}
+
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+	or.sweep(time.Now())
+	f, ok := or.futures[id.String()]
+	if !ok {
+		f = newTxnFuture(id.String())
+		or.futures[f.txnID] = f
+	}
+	return f, nil
+}
+
+func (or *outcomeRegistry) resolve(outcome *pb.TransactionOutcome) {
+	id, err := ulid.ParseIdFromProto(outcome.TxnId)
+	if err != nil {
+		or.logger.Errorf("can't parse txn id of outcome: %s", err.Error())
+		return
+	}
+	txnID := id.String()
+
+	or.mutex.Lock()
+	f, ok := or.futures[txnID]
+	or.mutex.Unlock()
+	if !ok {
+		or.logger.Debugf("no future registered for txn [%s]", txnID)
+		return
+	}
+
+	if f.addOutcome(outcome) {
+		or.remove(f)
+	}
+}
+
+// only removes the future if it wasn't replaced in the meantime
+func (or *outcomeRegistry) remove(f *TxnFuture) {
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+	if or.futures[f.txnID] == f {
+		delete(or.futures, f.txnID)
+	}
+}
+
+// walks all futures at most twice per ttl
+// needs to be called with the lock held
+func (or *outcomeRegistry) sweep(now time.Time) {
+	if now.Sub(or.lastSweep) < futureTTL/2 {
+		return
+	}
+
+	or.lastSweep = now
+	for txnID, f := range or.futures {
+		if f.isExpired(now) {
+			or.logger.Warningf("no outcome for txn [%s] showed up within %s", txnID, futureTTL.String())
+			delete(or.futures, txnID)
+		}
+	}
+}
+
+func (or *outcomeRegistry) ReportTransactionOutcomes(ctx context.Context, req *pb.ReportTransactionOutcomesRequest) (*pb.ReportTransactionOutcomesResponse, error) {
+	for idx := range req.Outcomes {
+		or.resolve(req.Outcomes[idx])
+	}
+	return &pb.ReportTransactionOutcomesResponse{}, nil
+}
+
+func newOutcomeReporter(outcomeChan <-chan *pb.Transaction, registry *outcomeRegistry, connCache util.ConnectionCache, nodeID uint64, logger *log.Entry) *outcomeReporter {
+	return &outcomeReporter{
+		outcomeChan: outcomeChan,
+		registry:    registry,
+		connCache:   connCache,
+		nodeID:      nodeID,
+		originChans: make(map[uint64]chan *pb.TransactionOutcome),
+		logger:      logger,
+	}
+}
+
+// the outcome reporter sends outcomes of txns that ran on this node
+// back to the nodes these txns were submitted to
+// every origin node gets its own queue and goroutine
+// that way a slow or unreachable node doesn't hold up reports to other nodes
+type outcomeReporter struct {
+	outcomeChan <-chan *pb.Transaction
+	registry    *outcomeRegistry
+	connCache   util.ConnectionCache
+	nodeID      uint64
+	originChans map[uint64]chan *pb.TransactionOutcome
+	logger      *log.Entry
+}
+
+func (r *outcomeReporter) runReporter() {
+	for {
+		txn, ok := <-r.outcomeChan
+		if !ok {
+			r.logger.Warningf("Stopping outcome reporter")
+			for _, c := range r.originChans {
+				close(c)
+			}
+			return
+		}
+
+		if txn.OriginNode == r.nodeID {
+			r.registry.resolve(txn.Outcome)
+			continue
+		}
+
+		c, ok := r.originChans[txn.OriginNode]
+		if !ok {
+			c = make(chan *pb.TransactionOutcome, outcomeQueueSize)
+			r.originChans[txn.OriginNode] = c
+			go r.runOriginReporter(txn.OriginNode, c)
+		}
+
+		// the origin finds the outcome when the txn is submitted again
+		select {
+		case c <- txn.Outcome:
+		default:
+			r.logger.Warningf("dropping outcome of a txn for node [%d]: too many outcomes are waiting to be reported", txn.OriginNode)
+		}
+	}
+}
+
+func (r *outcomeReporter) runOriginReporter(origin uint64, c <-chan *pb.TransactionOutcome) {
+	for {
+		outcome, ok := <-c
+		if !ok {
+			return
+		}
+
+		// pick up everything else that is waiting right now
+		// and report it in as few calls as possible
+		outcomes := []*pb.TransactionOutcome{outcome}
+	drain:
+		for len(outcomes) < maxOutcomesPerReport {
+			select {
+			case outcome, ok = <-c:
+				if !ok {
+					break drain
+				}
+				outcomes = append(outcomes, outcome)
+			default:
+				break drain
+			}
+		}
+
+		r.report(origin, outcomes)
+	}
+}
+
+// dropped txns didn't run anywhere and are reported by the node that tried to sequence them
+// their outcome doesn't list any writers and resolves the future right away
+func runDroppedTxnReporter(droppedTxnChan <-chan *pb.Transaction, outcomeChan chan<- *pb.Transaction) {
+	for txn := range droppedTxnChan {
+		if txn.OriginNode != 0 {
+			outcomeChan <- txn
+		}
+	}
+}
+
+func (r *outcomeReporter) report(origin uint64, outcomes []*pb.TransactionOutcome) {
+	client, err := r.connCache.GetTransactionOutcomesClient(origin)
+	if err != nil {
+		r.logger.Errorf("can't report outcomes to node [%d]: %s", origin, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.ReportTransactionOutcomes(ctx, &pb.ReportTransactionOutcomesRequest{
+		Outcomes: outcomes,
+	})
+	if err != nil {
+		r.logger.Errorf("can't report outcomes to node [%d]: %s", origin, err.Error())
+	} else if resp.Error != "" {
+		r.logger.Errorf("can't report outcomes to node [%d]: %s", origin, resp.Error)
+	}
+}
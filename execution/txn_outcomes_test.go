@@ -0,0 +1,220 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package execution
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTxnFutureMultipleWriters(t *testing.T) {
+	registry := newOutcomeRegistry(log.WithFields(log.Fields{}))
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+
+	f, err := registry.register(id.ToProto())
+	assert.Nil(t, err)
+	_, err = registry.register(id.ToProto())
+	assert.NotNil(t, err)
+
+	resp, err := registry.ReportTransactionOutcomes(context.TODO(), &pb.ReportTransactionOutcomesRequest{
+		Outcomes: []*pb.TransactionOutcome{
+			&pb.TransactionOutcome{
+				TxnId:       id.ToProto(),
+				Status:      pb.COMMITTED,
+				BatchIndex:  uint64(7),
+				BatchTerm:   uint64(2),
+				NodeId:      uint64(1),
+				WriterNodes: []uint64{1, 2},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", resp.Error)
+	assert.Nil(t, f.Outcome())
+
+	// reporting twice from the same node doesn't resolve the future
+	registry.resolve(&pb.TransactionOutcome{
+		TxnId:       id.ToProto(),
+		Status:      pb.COMMITTED,
+		NodeId:      uint64(1),
+		WriterNodes: []uint64{1, 2},
+	})
+	assert.Nil(t, f.Outcome())
+
+	registry.resolve(&pb.TransactionOutcome{
+		TxnId:       id.ToProto(),
+		Status:      pb.FAILED,
+		Error:       "narf",
+		NodeId:      uint64(2),
+		WriterNodes: []uint64{1, 2},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	outcome, err := f.Wait(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.FAILED, outcome.Status)
+	assert.Equal(t, "narf", outcome.Error)
+	assert.Equal(t, uint64(7), outcome.BatchIndex)
+	assert.Equal(t, uint64(2), outcome.BatchTerm)
+	assert.True(t, id.ToProto().Equal(outcome.TxnId))
+	assert.Equal(t, 0, lenFutures(registry))
+}
+
+func TestTxnFutureWaitTimesOut(t *testing.T) {
+	registry := newOutcomeRegistry(log.WithFields(log.Fields{}))
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+
+	f, err := registry.register(id.ToProto())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	outcome, err := f.Wait(ctx)
+	assert.NotNil(t, err)
+	assert.Nil(t, outcome)
+
+	// futures that are still waited for stay around
+	f.mutex.Lock()
+	f.waiters++
+	f.mutex.Unlock()
+	registry.mutex.Lock()
+	registry.sweep(time.Now().Add(2 * futureTTL))
+	registry.mutex.Unlock()
+	assert.Equal(t, 1, lenFutures(registry))
+
+	// nobody waits for the future anymore and it expired
+	f.mutex.Lock()
+	f.waiters--
+	f.mutex.Unlock()
+	registry.mutex.Lock()
+	registry.sweep(time.Now().Add(4 * futureTTL))
+	registry.mutex.Unlock()
+	assert.Equal(t, 0, lenFutures(registry))
+}
+
+func lenFutures(registry *outcomeRegistry) int {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return len(registry.futures)
+}
+
+func TestTxnFutureResult(t *testing.T) {
+	registry := newOutcomeRegistry(log.WithFields(log.Fields{}))
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+
+	f, err := registry.register(id.ToProto())
+	assert.Nil(t, err)
+
+	registry.resolve(&pb.TransactionOutcome{
+		TxnId:       id.ToProto(),
+		Status:      pb.COMMITTED,
+		NodeId:      uint64(1),
+		WriterNodes: []uint64{1, 2},
+	})
+	registry.resolve(&pb.TransactionOutcome{
+		TxnId:       id.ToProto(),
+		Status:      pb.COMMITTED,
+		NodeId:      uint64(2),
+		WriterNodes: []uint64{1, 2},
+		Result:      []byte(`{"order_id":42}`),
+	})
+
+	outcome := f.Outcome()
+	assert.NotNil(t, outcome)
+	assert.Equal(t, pb.COMMITTED, outcome.Status)
+	m := make(map[string]int)
+	err = outcome.UnmarshalResult(&m)
+	assert.Nil(t, err)
+	assert.Equal(t, 42, m["order_id"])
+}
+
+func TestTxnFutureDropped(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	registry := newOutcomeRegistry(logger)
+	outcomeChan := make(chan *pb.Transaction)
+	droppedTxnChan := make(chan *pb.Transaction)
+	reporter := newOutcomeReporter(outcomeChan, registry, nil, uint64(1), logger)
+	go reporter.runReporter()
+	go runDroppedTxnReporter(droppedTxnChan, outcomeChan)
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	f, err := registry.register(id.ToProto())
+	assert.Nil(t, err)
+
+	// nobody else is going to report a dropped txn
+	droppedTxnChan <- &pb.Transaction{
+		Id:          id.ToProto(),
+		OriginNode:  uint64(1),
+		WriterNodes: []uint64{1, 2},
+		Outcome: &pb.TransactionOutcome{
+			TxnId:  id.ToProto(),
+			Status: pb.DROPPED,
+			Error:  "narf",
+			NodeId: uint64(1),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	outcome, err := f.Wait(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, pb.DROPPED, outcome.Status)
+	assert.Equal(t, "narf", outcome.Error)
+	close(droppedTxnChan)
+	close(outcomeChan)
+}
+
+func TestOutcomeReporterPerOrigin(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	unblock := make(chan time.Time)
+	reported := make(chan struct{})
+	mockCC := new(mocks.ConnectionCache)
+	// node 2 hangs
+	mockCC.On("GetTransactionOutcomesClient", uint64(2)).WaitUntil(unblock).Return(nil, fmt.Errorf("narf"))
+	mockCC.On("GetTransactionOutcomesClient", uint64(3)).Run(func(args mock.Arguments) {
+		close(reported)
+	}).Return(nil, fmt.Errorf("moep"))
+
+	outcomeChan := make(chan *pb.Transaction)
+	reporter := newOutcomeReporter(outcomeChan, newOutcomeRegistry(logger), mockCC, uint64(1), logger)
+	go reporter.runReporter()
+	outcomeChan <- &pb.Transaction{OriginNode: uint64(2), Outcome: &pb.TransactionOutcome{}}
+	outcomeChan <- &pb.Transaction{OriginNode: uint64(3), Outcome: &pb.TransactionOutcome{}}
+
+	// reports to other nodes don't wait for node 2
+	select {
+	case <-reported:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "outcome wasn't reported to node 3")
+	}
+	close(unblock)
+	close(outcomeChan)
+}
@@ -22,6 +22,7 @@ mockery -dir util -name ClusterInfoProvider -output "$(dirname "$0")/mocks"
 mockery -dir util -name ConnectionCache -output "$(dirname "$0")/mocks"
 mockery -dir util -name PartitionedDataStore -output "$(dirname "$0")/mocks"
 mockery -dir util -name DataStoreTxnProvider -output "$(dirname "$0")/mocks"
+mockery -dir util -name VersionedDataStoreTxnProvider -output "$(dirname "$0")/mocks"
 mockery -dir util -name DataStoreTxn -output "$(dirname "$0")/mocks"
 mockery -dir pb -name RemoteReadClient -output "$(dirname "$0")/mocks"
 mockery -dir sequencer -name SnapshotHandler -output "$(dirname "$0")/mocks"
@@ -17,8 +17,12 @@
 package calvin
 
 import (
+	"fmt"
 	"os"
+	"runtime"
+	"time"
 
+	"github.com/mhelmich/calvin/execution"
 	"github.com/mhelmich/calvin/pb"
 	"github.com/mhelmich/calvin/sequencer"
 	"github.com/mhelmich/calvin/ulid"
@@ -61,15 +65,25 @@ func DefaultOptions(partitionedDataStore util.PartitionedDataStore, clusterInfoP
 }
 
 type Options struct {
-	hostname             string
-	port                 int
-	storePath            string
-	raftID               uint64
-	peers                []uint64
-	clusterInfoProvider  util.ClusterInfoProvider
-	snapshotHandler      sequencer.SnapshotHandler
-	partitionedDataStore util.PartitionedDataStore
-	numWorkers           int
+	hostname               string
+	port                   int
+	storePath              string
+	raftID                 uint64
+	peers                  []uint64
+	clusterInfoProvider    util.ClusterInfoProvider
+	snapshotHandler        sequencer.SnapshotHandler
+	partialSnapshotHandler sequencer.PartialSnapshotHandler
+	partitionedDataStore   util.PartitionedDataStore
+	numWorkers             int
+	channelSize            int
+	batchFrequency         time.Duration
+	maxBatchTxns           int
+	maxBatchBytes          int
+	raftTickInterval       time.Duration
+	numSequencerGroups     int
+	dedupWindow            *uint64
+	versionHorizon         *uint64
+	snapshotViewMaxAge     time.Duration
 }
 
 func (o Options) WithSnapshotHandler(snapshotHandler sequencer.SnapshotHandler) Options {
@@ -77,6 +91,13 @@ func (o Options) WithSnapshotHandler(snapshotHandler sequencer.SnapshotHandler)
 	return o
 }
 
+// WithPartialSnapshotHandler makes calvin snapshot and restore each partition on its own.
+// It takes precedence over a snapshot handler.
+func (o Options) WithPartialSnapshotHandler(partialSnapshotHandler sequencer.PartialSnapshotHandler) Options {
+	o.partialSnapshotHandler = partialSnapshotHandler
+	return o
+}
+
 func (o Options) WithDataStore(dataStore util.PartitionedDataStore) Options {
 	o.partitionedDataStore = dataStore
 	return o
@@ -97,6 +118,77 @@ func (o Options) WithNumWorkers(numWorkers int) Options {
 	return o
 }
 
+// WithChannelSize sets the capacity of the channels connecting sequencer, scheduler and engine.
+func (o Options) WithChannelSize(channelSize int) Options {
+	o.channelSize = channelSize
+	return o
+}
+
+// WithBatchFrequency sets how long the first transaction in a batch waits at most before the batch is cut.
+// Batches that are full are cut right away.
+func (o Options) WithBatchFrequency(batchFrequency time.Duration) Options {
+	o.batchFrequency = batchFrequency
+	return o
+}
+
+// WithMaxBatchTxns sets how many transactions a batch holds at most.
+func (o Options) WithMaxBatchTxns(maxBatchTxns int) Options {
+	o.maxBatchTxns = maxBatchTxns
+	return o
+}
+
+// WithMaxBatchBytes sets how many bytes of transactions and stored procedure changes a batch holds at most.
+// A transaction that doesn't fit into the current batch anymore goes into the next one.
+// A transaction that is bigger than that on its own ends up in a batch of its own.
+func (o Options) WithMaxBatchBytes(maxBatchBytes int) Options {
+	o.maxBatchBytes = maxBatchBytes
+	return o
+}
+
+// WithNumSequencerGroups sets how many raft groups sequence transactions.
+// Every group elects its own leader which spreads the work of sequencing across nodes.
+// All nodes of a cluster need to use the same number of groups
+// and it can't be changed once a node stored a log.
+func (o Options) WithNumSequencerGroups(numSequencerGroups int) Options {
+	o.numSequencerGroups = numSequencerGroups
+	return o
+}
+
+// WithDedupWindow sets for how many batches a transaction that is submitted again
+// with the same id is answered with the outcome of its first run instead of running again.
+// All nodes of a cluster need to use the same window.
+// A window of zero turns this off and runs every submitted transaction.
+func (o Options) WithDedupWindow(dedupWindow uint64) Options {
+	o.dedupWindow = &dedupWindow
+	return o
+}
+
+// WithVersionHorizon sets for how many batches before the last checkpoint
+// versioned data stores keep older versions of their values around.
+// Historical reads can go back that far at least.
+// A horizon of zero keeps all versions.
+func (o Options) WithVersionHorizon(versionHorizon uint64) Options {
+	o.versionHorizon = &versionHorizon
+	return o
+}
+
+// WithSnapshotViewMaxAge sets for how long snapshot reads share a view of the data store.
+// Sharing views keeps reads from opening a view each time, which can hold back later batches.
+// A view keeps a read transaction open on every local partition though.
+// Writes that need to grow a bolt database block until all read transactions on it are done.
+// The longer views are shared, the longer commits can stall under sustained reads.
+func (o Options) WithSnapshotViewMaxAge(snapshotViewMaxAge time.Duration) Options {
+	o.snapshotViewMaxAge = snapshotViewMaxAge
+	return o
+}
+
+// WithRaftTickInterval sets how often the raft state machine ticks.
+// Election and heartbeat timeouts are multiples of this interval.
+func (o Options) WithRaftTickInterval(raftTickInterval time.Duration) Options {
+	o.raftTickInterval = raftTickInterval
+	return o
+}
+
 func (o Options) WithPeers(peers []uint64) Options {
 	o.peers = peers
 	return o
@@ -107,6 +199,70 @@ func (o Options) WithStorePath(storePath string) Options {
 	return o
 }
 
+// fills in defaults for everything that wasn't set explicitly
+func (o Options) withDefaults() Options {
+	if o.numWorkers == 0 {
+		o.numWorkers = runtime.NumCPU()
+	}
+	if o.channelSize == 0 {
+		o.channelSize = o.numWorkers*2 + 1
+	}
+	if o.batchFrequency == 0 {
+		o.batchFrequency = sequencer.DefaultBatchFrequency
+	}
+	if o.maxBatchTxns == 0 {
+		o.maxBatchTxns = sequencer.DefaultMaxBatchTxns
+	}
+	if o.maxBatchBytes == 0 {
+		o.maxBatchBytes = sequencer.DefaultMaxBatchBytes
+	}
+	if o.raftTickInterval == 0 {
+		o.raftTickInterval = sequencer.DefaultRaftTickInterval
+	}
+	if o.numSequencerGroups == 0 {
+		o.numSequencerGroups = 1
+	}
+	if o.dedupWindow == nil {
+		dedupWindow := execution.DefaultDedupWindow
+		o.dedupWindow = &dedupWindow
+	}
+	if o.snapshotViewMaxAge == 0 {
+		o.snapshotViewMaxAge = DefaultSnapshotViewMaxAge
+	}
+	if o.versionHorizon == nil {
+		versionHorizon := execution.DefaultVersionHorizon
+		o.versionHorizon = &versionHorizon
+	}
+	return o
+}
+
+func (o Options) validate() error {
+	if o.numWorkers < 1 {
+		return fmt.Errorf("number of workers needs to be at least 1 but was %d", o.numWorkers)
+	} else if o.channelSize < 1 {
+		return fmt.Errorf("channel size needs to be at least 1 but was %d", o.channelSize)
+	} else if o.batchFrequency < time.Millisecond {
+		return fmt.Errorf("batch frequency needs to be at least 1ms but was %s", o.batchFrequency.String())
+	} else if o.maxBatchTxns < 1 {
+		return fmt.Errorf("max batch txns needs to be at least 1 but was %d", o.maxBatchTxns)
+	} else if o.maxBatchBytes < 1 {
+		return fmt.Errorf("max batch bytes needs to be at least 1 but was %d", o.maxBatchBytes)
+	} else if o.raftTickInterval < time.Millisecond {
+		return fmt.Errorf("raft tick interval needs to be at least 1ms but was %s", o.raftTickInterval.String())
+	} else if o.snapshotViewMaxAge < time.Millisecond {
+		return fmt.Errorf("snapshot view max age needs to be at least 1ms but was %s", o.snapshotViewMaxAge.String())
+	} else if o.numSequencerGroups < 1 {
+		return fmt.Errorf("number of sequencer groups needs to be at least 1 but was %d", o.numSequencerGroups)
+	} else if o.numSequencerGroups > 1 && o.partialSnapshotHandler != nil {
+		return fmt.Errorf("partial snapshot handlers can't be used with %d sequencer groups", o.numSequencerGroups)
+	} else if o.clusterInfoProvider == nil {
+		return fmt.Errorf("cluster info provider needs to be set")
+	} else if o.partitionedDataStore == nil {
+		return fmt.Errorf("partitioned data store needs to be set")
+	}
+	return nil
+}
+
 type config struct {
 	RaftID    uint64
 	Hostname  string
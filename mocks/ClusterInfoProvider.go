@@ -23,6 +23,22 @@ func (_m *ClusterInfoProvider) AmIWriter(writerNodes []uint64) bool {
 	return r0
 }
 
+// FindLearnersForNode provides a mock function with given fields: nodeID
+func (_m *ClusterInfoProvider) FindLearnersForNode(nodeID uint64) []uint64 {
+	ret := _m.Called(nodeID)
+
+	var r0 []uint64
+	if rf, ok := ret.Get(0).(func(uint64) []uint64); ok {
+		r0 = rf(nodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint64)
+		}
+	}
+
+	return r0
+}
+
 // FindOwnerForKey provides a mock function with given fields: key
 func (_m *ClusterInfoProvider) FindOwnerForKey(key []byte) uint64 {
 	ret := _m.Called(key)
@@ -79,6 +95,20 @@ func (_m *ClusterInfoProvider) GetAddressFor(nodeID uint64) string {
 	return r0
 }
 
+// IsLearner provides a mock function with given fields: nodeID
+func (_m *ClusterInfoProvider) IsLearner(nodeID uint64) bool {
+	ret := _m.Called(nodeID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(uint64) bool); ok {
+		r0 = rf(nodeID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // IsLocal provides a mock function with given fields: key
 func (_m *ClusterInfoProvider) IsLocal(key []byte) bool {
 	ret := _m.Called(key)
@@ -108,3 +138,43 @@ func (_m *ClusterInfoProvider) MyPartitions() []int {
 
 	return r0
 }
+
+// PartitionsForNode provides a mock function with given fields: nodeID
+func (_m *ClusterInfoProvider) PartitionsForNode(nodeID uint64) []int {
+	ret := _m.Called(nodeID)
+
+	var r0 []int
+	if rf, ok := ret.Get(0).(func(uint64) []int); ok {
+		r0 = rf(nodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	return r0
+}
+
+// RemoveNode provides a mock function with given fields: nodeID
+func (_m *ClusterInfoProvider) RemoveNode(nodeID uint64) {
+	_m.Called(nodeID)
+}
+
+// SetLearners provides a mock function with given fields: learners
+func (_m *ClusterInfoProvider) SetLearners(learners []uint64) {
+	_m.Called(learners)
+}
+
+// SetAddressFor provides a mock function with given fields: nodeID, address
+func (_m *ClusterInfoProvider) SetAddressFor(nodeID uint64, address string) error {
+	ret := _m.Called(nodeID, address)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, string) error); ok {
+		r0 = rf(nodeID, address)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
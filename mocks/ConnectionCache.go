@@ -15,6 +15,11 @@ func (_m *ConnectionCache) Close() {
 	_m.Called()
 }
 
+// CloseConnection provides a mock function with given fields: nodeID
+func (_m *ConnectionCache) CloseConnection(nodeID uint64) {
+	_m.Called(nodeID)
+}
+
 // GetLowIsolationReadClient provides a mock function with given fields: nodeID
 func (_m *ConnectionCache) GetLowIsolationReadClient(nodeID uint64) (pb.LowIsolationReadClient, error) {
 	ret := _m.Called(nodeID)
@@ -83,3 +88,26 @@ func (_m *ConnectionCache) GetRemoteReadClient(nodeID uint64) (pb.RemoteReadClie
 
 	return r0, r1
 }
+
+// GetTransactionOutcomesClient provides a mock function with given fields: nodeID
+func (_m *ConnectionCache) GetTransactionOutcomesClient(nodeID uint64) (pb.TransactionOutcomesClient, error) {
+	ret := _m.Called(nodeID)
+
+	var r0 pb.TransactionOutcomesClient
+	if rf, ok := ret.Get(0).(func(uint64) pb.TransactionOutcomesClient); ok {
+		r0 = rf(nodeID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(pb.TransactionOutcomesClient)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uint64) error); ok {
+		r1 = rf(nodeID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
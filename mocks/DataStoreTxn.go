@@ -3,6 +3,7 @@
 package mocks
 
 import mock "github.com/stretchr/testify/mock"
+import util "github.com/mhelmich/calvin/util"
 
 // DataStoreTxn is an autogenerated mock type for the DataStoreTxn type
 type DataStoreTxn struct {
@@ -23,6 +24,20 @@ func (_m *DataStoreTxn) Commit() error {
 	return r0
 }
 
+// Delete provides a mock function with given fields: key
+func (_m *DataStoreTxn) Delete(key []byte) error {
+	ret := _m.Called(key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: key
 func (_m *DataStoreTxn) Get(key []byte) []byte {
 	ret := _m.Called(key)
@@ -39,6 +54,22 @@ func (_m *DataStoreTxn) Get(key []byte) []byte {
 	return r0
 }
 
+// Iterate provides a mock function with given fields: start, end
+func (_m *DataStoreTxn) Iterate(start []byte, end []byte) util.DataStoreIterator {
+	ret := _m.Called(start, end)
+
+	var r0 util.DataStoreIterator
+	if rf, ok := ret.Get(0).(func([]byte, []byte) util.DataStoreIterator); ok {
+		r0 = rf(start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(util.DataStoreIterator)
+		}
+	}
+
+	return r0
+}
+
 // Rollback provides a mock function with given fields:
 func (_m *DataStoreTxn) Rollback() error {
 	ret := _m.Called()
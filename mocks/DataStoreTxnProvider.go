@@ -21,6 +21,20 @@ func (_m *DataStoreTxnProvider) Delete() {
 	_m.Called()
 }
 
+// Restore provides a mock function with given fields: r
+func (_m *DataStoreTxnProvider) Restore(r io.Reader) error {
+	ret := _m.Called(r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(io.Reader) error); ok {
+		r0 = rf(r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Snapshot provides a mock function with given fields: w
 func (_m *DataStoreTxnProvider) Snapshot(w io.Writer) error {
 	ret := _m.Called(w)
@@ -51,6 +51,79 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_afc31d04251e05fb, []int{0}
 }
 
+type TransactionStatus int32
+
+const (
+	PENDING   TransactionStatus = 0
+	COMMITTED TransactionStatus = 1
+	FAILED    TransactionStatus = 2
+	// the stored procedure called abort(reason)
+	ABORTED TransactionStatus = 3
+	// the txn was never sequenced and didn't run anywhere
+	DROPPED TransactionStatus = 4
+	// it's unknown whether the txn was sequenced
+	// for instance because the leader that accepted it lost its leadership
+	// submitting the txn again with the same id either runs it or finds the outcome of its first run
+	UNKNOWN TransactionStatus = 5
+)
+
+var TransactionStatus_name = map[int32]string{
+	0: "PENDING",
+	1: "COMMITTED",
+	2: "FAILED",
+	3: "ABORTED",
+	4: "DROPPED",
+	5: "UNKNOWN",
+}
+
+var TransactionStatus_value = map[string]int32{
+	"PENDING":   0,
+	"COMMITTED": 1,
+	"FAILED":    2,
+	"ABORTED":   3,
+	"DROPPED":   4,
+	"UNKNOWN":   5,
+}
+
+func (x TransactionStatus) String() string {
+	return proto.EnumName(TransactionStatus_name, int32(x))
+}
+
+func (TransactionStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{1}
+}
+
+type StoredProcedureOp int32
+
+const (
+	// installs a new version and makes it the current version
+	INSTALL_PROCEDURE StoredProcedureOp = 0
+	// makes an existing version the current version
+	SET_CURRENT_PROCEDURE StoredProcedureOp = 1
+	// removes a version once no queued transaction references it anymore
+	REMOVE_PROCEDURE StoredProcedureOp = 2
+)
+
+var StoredProcedureOp_name = map[int32]string{
+	0: "INSTALL_PROCEDURE",
+	1: "SET_CURRENT_PROCEDURE",
+	2: "REMOVE_PROCEDURE",
+}
+
+var StoredProcedureOp_value = map[string]int32{
+	"INSTALL_PROCEDURE":     0,
+	"SET_CURRENT_PROCEDURE": 1,
+	"REMOVE_PROCEDURE":      2,
+}
+
+func (x StoredProcedureOp) String() string {
+	return proto.EnumName(StoredProcedureOp_name, int32(x))
+}
+
+func (StoredProcedureOp) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{2}
+}
+
 type SimpleSetterArg struct {
 	Key                  []byte   `protobuf:"bytes,1,opt,name=Key,proto3" json:"Key,omitempty"`
 	Value                []byte   `protobuf:"bytes,2,opt,name=Value,proto3" json:"Value,omitempty"`
@@ -191,9 +264,30 @@ type Transaction struct {
 	// a nifty way to get this info from the execution back to the scheduler
 	IsLowIsolationRead       bool                      `protobuf:"varint,9,opt,name=IsLowIsolationRead,proto3" json:"IsLowIsolationRead,omitempty"`
 	LowIsolationReadResponse *LowIsolationReadResponse `protobuf:"bytes,10,opt,name=LowIsolationReadResponse,proto3" json:"LowIsolationReadResponse,omitempty"`
-	XXX_NoUnkeyedLiteral     struct{}                  `json:"-"`
-	XXX_unrecognized         []byte                    `json:"-"`
-	XXX_sizecache            int32                     `json:"-"`
+	// the node this transaction was submitted to
+	// if set, executing nodes report the outcome of the transaction back to it
+	OriginNode uint64 `protobuf:"varint,11,opt,name=OriginNode,proto3" json:"OriginNode,omitempty"`
+	// index and term of the raft entry this transaction was sequenced in
+	// they are filled in when the batch is published by the raft backend
+	BatchIndex uint64 `protobuf:"varint,12,opt,name=BatchIndex,proto3" json:"BatchIndex,omitempty"`
+	BatchTerm  uint64 `protobuf:"varint,13,opt,name=BatchTerm,proto3" json:"BatchTerm,omitempty"`
+	// populated by the execution routines after the transaction ran
+	Outcome *TransactionOutcome `protobuf:"bytes,14,opt,name=Outcome,proto3" json:"Outcome,omitempty"`
+	// the version of the stored procedure this transaction runs with
+	// if it isn't set by the client, the scheduler fills in the current version
+	// when the transaction is being locked
+	StoredProcedureVersion uint64 `protobuf:"varint,15,opt,name=StoredProcedureVersion,proto3" json:"StoredProcedureVersion,omitempty"`
+	// position of this transaction in its batch
+	// together with the batch index it identifies the versions the transaction writes
+	BatchPosition uint64 `protobuf:"varint,16,opt,name=BatchPosition,proto3" json:"BatchPosition,omitempty"`
+	// set if the stored procedure scans key ranges
+	// the transaction runs once all transactions before it are done on this node
+	// and transactions after it wait until it's done
+	// scans of transactions without it are rejected
+	Scans                bool     `protobuf:"varint,17,opt,name=Scans,proto3" json:"Scans,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Transaction) Reset()         { *m = Transaction{} }
@@ -229,6 +323,56 @@ func (m *Transaction) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Transaction proto.InternalMessageInfo
 
+type TransactionOutcome struct {
+	TxnId      *Id128            `protobuf:"bytes,1,opt,name=TxnId,proto3" json:"TxnId,omitempty"`
+	Status     TransactionStatus `protobuf:"varint,2,opt,name=Status,proto3,enum=pb.TransactionStatus" json:"Status,omitempty"`
+	Error      string            `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
+	BatchIndex uint64            `protobuf:"varint,4,opt,name=BatchIndex,proto3" json:"BatchIndex,omitempty"`
+	BatchTerm  uint64            `protobuf:"varint,5,opt,name=BatchTerm,proto3" json:"BatchTerm,omitempty"`
+	// the node that executed the transaction and reported this outcome
+	NodeId uint64 `protobuf:"varint,6,opt,name=NodeId,proto3" json:"NodeId,omitempty"`
+	// all nodes that are going to report an outcome for this transaction
+	WriterNodes []uint64 `protobuf:"varint,7,rep,packed,name=WriterNodes,proto3" json:"WriterNodes,omitempty"`
+	// JSON serialized value the stored procedure returned (if any)
+	Result               []byte   `protobuf:"bytes,8,opt,name=Result,proto3" json:"Result,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionOutcome) Reset()         { *m = TransactionOutcome{} }
+func (m *TransactionOutcome) String() string { return proto.CompactTextString(m) }
+func (*TransactionOutcome) ProtoMessage()    {}
+func (*TransactionOutcome) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{4}
+}
+func (m *TransactionOutcome) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransactionOutcome) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransactionOutcome.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransactionOutcome) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionOutcome.Merge(m, src)
+}
+func (m *TransactionOutcome) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransactionOutcome) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionOutcome.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionOutcome proto.InternalMessageInfo
+
 type LowIsoRead struct {
 	Type                     MessageType               `protobuf:"varint,1,opt,name=Type,proto3,enum=pb.MessageType" json:"Type,omitempty"`
 	LowIsolationReadResponse *LowIsolationReadResponse `protobuf:"bytes,2,opt,name=LowIsolationReadResponse,proto3" json:"LowIsolationReadResponse,omitempty"`
@@ -241,7 +385,7 @@ func (m *LowIsoRead) Reset()         { *m = LowIsoRead{} }
 func (m *LowIsoRead) String() string { return proto.CompactTextString(m) }
 func (*LowIsoRead) ProtoMessage()    {}
 func (*LowIsoRead) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{4}
+	return fileDescriptor_afc31d04251e05fb, []int{5}
 }
 func (m *LowIsoRead) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -271,17 +415,35 @@ func (m *LowIsoRead) XXX_DiscardUnknown() {
 var xxx_messageInfo_LowIsoRead proto.InternalMessageInfo
 
 type TransactionBatch struct {
-	Transactions         []*Transaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	Transactions []*Transaction `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	// stored procedure changes sequenced in this batch
+	// they are applied before any transaction of the batch is scheduled
+	StoredProcedures []*StoredProcedure `protobuf:"bytes,2,rep,name=StoredProcedures,proto3" json:"StoredProcedures,omitempty"`
+	// index and term of the raft entry this batch was sequenced in
+	// they are filled in when the batch is published by the raft backend
+	Index uint64 `protobuf:"varint,3,opt,name=Index,proto3" json:"Index,omitempty"`
+	Term  uint64 `protobuf:"varint,4,opt,name=Term,proto3" json:"Term,omitempty"`
+	// if true, the stored procedures in this batch replace all procedures that were installed before
+	// this is how the procedures contained in a snapshot are installed
+	ReplaceStoredProcedures bool `protobuf:"varint,5,opt,name=ReplaceStoredProcedures,proto3" json:"ReplaceStoredProcedures,omitempty"`
+	// every raft group of the sequencer numbers its batches with increasing epochs
+	// proposers ask for an epoch, the raft backend hands out the next free one at or after it
+	// the scheduler merges the batches of all groups by epoch and group
+	Epoch          uint64 `protobuf:"varint,6,opt,name=Epoch,proto3" json:"Epoch,omitempty"`
+	SequencerGroup uint32 `protobuf:"varint,7,opt,name=SequencerGroup,proto3" json:"SequencerGroup,omitempty"`
+	// set by the node that proposed the batch
+	// that's how the node tells that its proposal made it into the log
+	ProposalId           uint64   `protobuf:"varint,8,opt,name=ProposalId,proto3" json:"ProposalId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *TransactionBatch) Reset()         { *m = TransactionBatch{} }
 func (m *TransactionBatch) String() string { return proto.CompactTextString(m) }
 func (*TransactionBatch) ProtoMessage()    {}
 func (*TransactionBatch) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{5}
+	return fileDescriptor_afc31d04251e05fb, []int{6}
 }
 func (m *TransactionBatch) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -310,8 +472,138 @@ func (m *TransactionBatch) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_TransactionBatch proto.InternalMessageInfo
 
+type StoredProcedure struct {
+	Name   string `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Source string `protobuf:"bytes,2,opt,name=Source,proto3" json:"Source,omitempty"`
+	// assigned by the scheduler when the procedure is installed
+	Version uint64            `protobuf:"varint,3,opt,name=Version,proto3" json:"Version,omitempty"`
+	Op      StoredProcedureOp `protobuf:"varint,4,opt,name=Op,proto3,enum=pb.StoredProcedureOp" json:"Op,omitempty"`
+	// only used for listing procedures
+	Current              bool     `protobuf:"varint,5,opt,name=Current,proto3" json:"Current,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StoredProcedure) Reset()         { *m = StoredProcedure{} }
+func (m *StoredProcedure) String() string { return proto.CompactTextString(m) }
+func (*StoredProcedure) ProtoMessage()    {}
+func (*StoredProcedure) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{7}
+}
+func (m *StoredProcedure) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *StoredProcedure) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StoredProcedure.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *StoredProcedure) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoredProcedure.Merge(m, src)
+}
+func (m *StoredProcedure) XXX_Size() int {
+	return m.Size()
+}
+func (m *StoredProcedure) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoredProcedure.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoredProcedure proto.InternalMessageInfo
+
+type ReportTransactionOutcomesRequest struct {
+	Outcomes             []*TransactionOutcome `protobuf:"bytes,1,rep,name=Outcomes,proto3" json:"Outcomes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ReportTransactionOutcomesRequest) Reset()         { *m = ReportTransactionOutcomesRequest{} }
+func (m *ReportTransactionOutcomesRequest) String() string { return proto.CompactTextString(m) }
+func (*ReportTransactionOutcomesRequest) ProtoMessage()    {}
+func (*ReportTransactionOutcomesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{8}
+}
+func (m *ReportTransactionOutcomesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReportTransactionOutcomesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReportTransactionOutcomesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ReportTransactionOutcomesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportTransactionOutcomesRequest.Merge(m, src)
+}
+func (m *ReportTransactionOutcomesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReportTransactionOutcomesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportTransactionOutcomesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportTransactionOutcomesRequest proto.InternalMessageInfo
+
+type ReportTransactionOutcomesResponse struct {
+	Error                string   `protobuf:"bytes,1,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReportTransactionOutcomesResponse) Reset()         { *m = ReportTransactionOutcomesResponse{} }
+func (m *ReportTransactionOutcomesResponse) String() string { return proto.CompactTextString(m) }
+func (*ReportTransactionOutcomesResponse) ProtoMessage()    {}
+func (*ReportTransactionOutcomesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{9}
+}
+func (m *ReportTransactionOutcomesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ReportTransactionOutcomesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ReportTransactionOutcomesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ReportTransactionOutcomesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReportTransactionOutcomesResponse.Merge(m, src)
+}
+func (m *ReportTransactionOutcomesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ReportTransactionOutcomesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReportTransactionOutcomesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReportTransactionOutcomesResponse proto.InternalMessageInfo
+
 type LowIsolationReadRequest struct {
-	Keys                 [][]byte `protobuf:"bytes,1,rep,name=Keys,proto3" json:"Keys,omitempty"`
+	Keys [][]byte `protobuf:"bytes,1,rep,name=Keys,proto3" json:"Keys,omitempty"`
+	// linearizable reads wait until the node executed everything
+	// that was committed before the read
+	// the response carries index and term of the last batch the read reflects
+	Linearizable         bool     `protobuf:"varint,2,opt,name=Linearizable,proto3" json:"Linearizable,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -321,7 +613,7 @@ func (m *LowIsolationReadRequest) Reset()         { *m = LowIsolationReadRequest
 func (m *LowIsolationReadRequest) String() string { return proto.CompactTextString(m) }
 func (*LowIsolationReadRequest) ProtoMessage()    {}
 func (*LowIsolationReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{6}
+	return fileDescriptor_afc31d04251e05fb, []int{10}
 }
 func (m *LowIsolationReadRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -351,10 +643,13 @@ func (m *LowIsolationReadRequest) XXX_DiscardUnknown() {
 var xxx_messageInfo_LowIsolationReadRequest proto.InternalMessageInfo
 
 type LowIsolationReadResponse struct {
-	Keys                 [][]byte `protobuf:"bytes,1,rep,name=Keys,proto3" json:"Keys,omitempty"`
-	Values               [][]byte `protobuf:"bytes,2,rep,name=Values,proto3" json:"Values,omitempty"`
-	Term                 uint64   `protobuf:"varint,3,opt,name=Term,proto3" json:"Term,omitempty"`
-	Index                uint64   `protobuf:"varint,4,opt,name=Index,proto3" json:"Index,omitempty"`
+	Keys   [][]byte `protobuf:"bytes,1,rep,name=Keys,proto3" json:"Keys,omitempty"`
+	Values [][]byte `protobuf:"bytes,2,rep,name=Values,proto3" json:"Values,omitempty"`
+	Term   uint64   `protobuf:"varint,3,opt,name=Term,proto3" json:"Term,omitempty"`
+	Index  uint64   `protobuf:"varint,4,opt,name=Index,proto3" json:"Index,omitempty"`
+	// empty values and missing keys look the same on the wire
+	// found tells them apart
+	Found                []bool   `protobuf:"varint,5,rep,packed,name=Found,proto3" json:"Found,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -364,7 +659,7 @@ func (m *LowIsolationReadResponse) Reset()         { *m = LowIsolationReadRespon
 func (m *LowIsolationReadResponse) String() string { return proto.CompactTextString(m) }
 func (*LowIsolationReadResponse) ProtoMessage()    {}
 func (*LowIsolationReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{7}
+	return fileDescriptor_afc31d04251e05fb, []int{11}
 }
 func (m *LowIsolationReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -394,10 +689,14 @@ func (m *LowIsolationReadResponse) XXX_DiscardUnknown() {
 var xxx_messageInfo_LowIsolationReadResponse proto.InternalMessageInfo
 
 type RemoteReadRequest struct {
-	TxnId                *Id128   `protobuf:"bytes,1,opt,name=TxnId,proto3" json:"TxnId,omitempty"`
-	Keys                 [][]byte `protobuf:"bytes,2,rep,name=Keys,proto3" json:"Keys,omitempty"`
-	Values               [][]byte `protobuf:"bytes,3,rep,name=Values,proto3" json:"Values,omitempty"`
-	TotalNumLocks        uint32   `protobuf:"varint,4,opt,name=TotalNumLocks,proto3" json:"TotalNumLocks,omitempty"`
+	TxnId         *Id128   `protobuf:"bytes,1,opt,name=TxnId,proto3" json:"TxnId,omitempty"`
+	Keys          [][]byte `protobuf:"bytes,2,rep,name=Keys,proto3" json:"Keys,omitempty"`
+	Values        [][]byte `protobuf:"bytes,3,rep,name=Values,proto3" json:"Values,omitempty"`
+	TotalNumLocks uint32   `protobuf:"varint,4,opt,name=TotalNumLocks,proto3" json:"TotalNumLocks,omitempty"`
+	// txns that are submitted again keep their id
+	// the position in the log tells them apart
+	BatchIndex           uint64   `protobuf:"varint,5,opt,name=BatchIndex,proto3" json:"BatchIndex,omitempty"`
+	BatchPosition        uint64   `protobuf:"varint,6,opt,name=BatchPosition,proto3" json:"BatchPosition,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -407,7 +706,7 @@ func (m *RemoteReadRequest) Reset()         { *m = RemoteReadRequest{} }
 func (m *RemoteReadRequest) String() string { return proto.CompactTextString(m) }
 func (*RemoteReadRequest) ProtoMessage()    {}
 func (*RemoteReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{8}
+	return fileDescriptor_afc31d04251e05fb, []int{12}
 }
 func (m *RemoteReadRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -447,7 +746,7 @@ func (m *RemoteReadResponse) Reset()         { *m = RemoteReadResponse{} }
 func (m *RemoteReadResponse) String() string { return proto.CompactTextString(m) }
 func (*RemoteReadResponse) ProtoMessage()    {}
 func (*RemoteReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{9}
+	return fileDescriptor_afc31d04251e05fb, []int{13}
 }
 func (m *RemoteReadResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -488,7 +787,7 @@ func (m *RaftPeer) Reset()         { *m = RaftPeer{} }
 func (m *RaftPeer) String() string { return proto.CompactTextString(m) }
 func (*RaftPeer) ProtoMessage()    {}
 func (*RaftPeer) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{10}
+	return fileDescriptor_afc31d04251e05fb, []int{14}
 }
 func (m *RaftPeer) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -517,19 +816,111 @@ func (m *RaftPeer) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_RaftPeer proto.InternalMessageInfo
 
+// followers forward their proposals to the leader
+type ProposeRequest struct {
+	From uint64 `protobuf:"varint,1,opt,name=From,proto3" json:"From,omitempty"`
+	Data []byte `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
+	// if set, Data is a marshaled conf change
+	IsConfChange bool `protobuf:"varint,3,opt,name=IsConfChange,proto3" json:"IsConfChange,omitempty"`
+	// the raft group the proposal is for
+	GroupId              uint32   `protobuf:"varint,4,opt,name=GroupId,proto3" json:"GroupId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProposeRequest) Reset()         { *m = ProposeRequest{} }
+func (m *ProposeRequest) String() string { return proto.CompactTextString(m) }
+func (*ProposeRequest) ProtoMessage()    {}
+func (*ProposeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{15}
+}
+func (m *ProposeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ProposeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ProposeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ProposeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProposeRequest.Merge(m, src)
+}
+func (m *ProposeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ProposeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProposeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProposeRequest proto.InternalMessageInfo
+
+type ProposeResponse struct {
+	// the leader as far as the receiver of the proposal knows
+	LeaderId uint64 `protobuf:"varint,1,opt,name=LeaderId,proto3" json:"LeaderId,omitempty"`
+	Error    string `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	// set if the proposal definitely didn't make it into the log
+	Dropped              bool     `protobuf:"varint,3,opt,name=Dropped,proto3" json:"Dropped,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProposeResponse) Reset()         { *m = ProposeResponse{} }
+func (m *ProposeResponse) String() string { return proto.CompactTextString(m) }
+func (*ProposeResponse) ProtoMessage()    {}
+func (*ProposeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{16}
+}
+func (m *ProposeResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ProposeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ProposeResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ProposeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProposeResponse.Merge(m, src)
+}
+func (m *ProposeResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ProposeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProposeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProposeResponse proto.InternalMessageInfo
+
 type StepRequest struct {
-	RaftNodeId           uint64          `protobuf:"varint,1,opt,name=RaftNodeId,proto3" json:"RaftNodeId,omitempty"`
-	Message              *raftpb.Message `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+	RaftNodeId uint64          `protobuf:"varint,1,opt,name=RaftNodeId,proto3" json:"RaftNodeId,omitempty"`
+	Message    *raftpb.Message `protobuf:"bytes,2,opt,name=Message,proto3" json:"Message,omitempty"`
+	// the raft group the message is for
+	GroupId              uint32   `protobuf:"varint,3,opt,name=GroupId,proto3" json:"GroupId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *StepRequest) Reset()         { *m = StepRequest{} }
 func (m *StepRequest) String() string { return proto.CompactTextString(m) }
 func (*StepRequest) ProtoMessage()    {}
 func (*StepRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{11}
+	return fileDescriptor_afc31d04251e05fb, []int{17}
 }
 func (m *StepRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -569,7 +960,7 @@ func (m *StepResponse) Reset()         { *m = StepResponse{} }
 func (m *StepResponse) String() string { return proto.CompactTextString(m) }
 func (*StepResponse) ProtoMessage()    {}
 func (*StepResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{12}
+	return fileDescriptor_afc31d04251e05fb, []int{18}
 }
 func (m *StepResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -598,28 +989,38 @@ func (m *StepResponse) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_StepResponse proto.InternalMessageInfo
 
-// This is used by the implmentor of the data store!
-// Be mindful of that.
-type PartitionedSnapshot struct {
-	PartitionIDs         []uint64 `protobuf:"varint,1,rep,packed,name=PartitionIDs,proto3" json:"PartitionIDs,omitempty"`
-	Snapshots            [][]byte `protobuf:"bytes,2,rep,name=Snapshots,proto3" json:"Snapshots,omitempty"`
+// The first chunk of a snapshot transfer carries the raft message without the snapshot data.
+// The receiver answers with the offset to continue at.
+// All following chunks carry consecutive pieces of the snapshot data.
+type SnapshotChunk struct {
+	Message   *raftpb.Message `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+	TotalSize uint64          `protobuf:"varint,2,opt,name=TotalSize,proto3" json:"TotalSize,omitempty"`
+	// crc32 of the entire snapshot data
+	Checksum uint32 `protobuf:"varint,3,opt,name=Checksum,proto3" json:"Checksum,omitempty"`
+	Offset   uint64 `protobuf:"varint,4,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	Data     []byte `protobuf:"bytes,5,opt,name=Data,proto3" json:"Data,omitempty"`
+	// crc32 of the data in this chunk
+	ChunkChecksum uint32 `protobuf:"varint,6,opt,name=ChunkChecksum,proto3" json:"ChunkChecksum,omitempty"`
+	// the raft group the snapshot is for
+	// only set on the first chunk
+	GroupId              uint32   `protobuf:"varint,7,opt,name=GroupId,proto3" json:"GroupId,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *PartitionedSnapshot) Reset()         { *m = PartitionedSnapshot{} }
-func (m *PartitionedSnapshot) String() string { return proto.CompactTextString(m) }
-func (*PartitionedSnapshot) ProtoMessage()    {}
-func (*PartitionedSnapshot) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{13}
+func (m *SnapshotChunk) Reset()         { *m = SnapshotChunk{} }
+func (m *SnapshotChunk) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunk) ProtoMessage()    {}
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{19}
 }
-func (m *PartitionedSnapshot) XXX_Unmarshal(b []byte) error {
+func (m *SnapshotChunk) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *PartitionedSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *SnapshotChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_PartitionedSnapshot.Marshal(b, m, deterministic)
+		return xxx_messageInfo_SnapshotChunk.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -629,36 +1030,40 @@ func (m *PartitionedSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte,
 		return b[:n], nil
 	}
 }
-func (m *PartitionedSnapshot) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_PartitionedSnapshot.Merge(m, src)
+func (m *SnapshotChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotChunk.Merge(m, src)
 }
-func (m *PartitionedSnapshot) XXX_Size() int {
+func (m *SnapshotChunk) XXX_Size() int {
 	return m.Size()
 }
-func (m *PartitionedSnapshot) XXX_DiscardUnknown() {
-	xxx_messageInfo_PartitionedSnapshot.DiscardUnknown(m)
+func (m *SnapshotChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotChunk.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_PartitionedSnapshot proto.InternalMessageInfo
+var xxx_messageInfo_SnapshotChunk proto.InternalMessageInfo
 
-type SubmitTransactionRequest struct {
+type SnapshotChunkResponse struct {
+	Offset uint64 `protobuf:"varint,1,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	// the snapshot was verified and installed by the receiver
+	Installed            bool     `protobuf:"varint,2,opt,name=Installed,proto3" json:"Installed,omitempty"`
+	Error                string   `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *SubmitTransactionRequest) Reset()         { *m = SubmitTransactionRequest{} }
-func (m *SubmitTransactionRequest) String() string { return proto.CompactTextString(m) }
-func (*SubmitTransactionRequest) ProtoMessage()    {}
-func (*SubmitTransactionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{14}
+func (m *SnapshotChunkResponse) Reset()         { *m = SnapshotChunkResponse{} }
+func (m *SnapshotChunkResponse) String() string { return proto.CompactTextString(m) }
+func (*SnapshotChunkResponse) ProtoMessage()    {}
+func (*SnapshotChunkResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{20}
 }
-func (m *SubmitTransactionRequest) XXX_Unmarshal(b []byte) error {
+func (m *SnapshotChunkResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *SubmitTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *SnapshotChunkResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_SubmitTransactionRequest.Marshal(b, m, deterministic)
+		return xxx_messageInfo_SnapshotChunkResponse.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -668,36 +1073,51 @@ func (m *SubmitTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]
 		return b[:n], nil
 	}
 }
-func (m *SubmitTransactionRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SubmitTransactionRequest.Merge(m, src)
+func (m *SnapshotChunkResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotChunkResponse.Merge(m, src)
 }
-func (m *SubmitTransactionRequest) XXX_Size() int {
+func (m *SnapshotChunkResponse) XXX_Size() int {
 	return m.Size()
 }
-func (m *SubmitTransactionRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_SubmitTransactionRequest.DiscardUnknown(m)
-}
-
-var xxx_messageInfo_SubmitTransactionRequest proto.InternalMessageInfo
-
-type SubmitTransactionResponse struct {
+func (m *SnapshotChunkResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotChunkResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotChunkResponse proto.InternalMessageInfo
+
+// the data of every raft snapshot
+// Data is what the snapshot handler provided
+// StoredProcedures are all stored procedure changes up to the index of the snapshot
+// Peers are the addresses of all members of the raft group at the index of the snapshot
+type RaftSnapshot struct {
+	Data             []byte             `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	StoredProcedures []*StoredProcedure `protobuf:"bytes,2,rep,name=StoredProcedures,proto3" json:"StoredProcedures,omitempty"`
+	Peers            []*RaftPeer        `protobuf:"bytes,3,rep,name=Peers,proto3" json:"Peers,omitempty"`
+	// epoch of the last batch at the index of the snapshot
+	Epoch uint64 `protobuf:"varint,4,opt,name=Epoch,proto3" json:"Epoch,omitempty"`
+	// set on snapshots that were streamed from another node
+	// their data went straight into the data store and isn't part of the snapshot
+	WithoutData bool `protobuf:"varint,5,opt,name=WithoutData,proto3" json:"WithoutData,omitempty"`
+	// proposal ids of the batches applied shortly before the index of the snapshot
+	// they are used to drop batches that were proposed more than once
+	AppliedProposals     []byte   `protobuf:"bytes,6,opt,name=AppliedProposals,proto3" json:"AppliedProposals,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *SubmitTransactionResponse) Reset()         { *m = SubmitTransactionResponse{} }
-func (m *SubmitTransactionResponse) String() string { return proto.CompactTextString(m) }
-func (*SubmitTransactionResponse) ProtoMessage()    {}
-func (*SubmitTransactionResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_afc31d04251e05fb, []int{15}
+func (m *RaftSnapshot) Reset()         { *m = RaftSnapshot{} }
+func (m *RaftSnapshot) String() string { return proto.CompactTextString(m) }
+func (*RaftSnapshot) ProtoMessage()    {}
+func (*RaftSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{21}
 }
-func (m *SubmitTransactionResponse) XXX_Unmarshal(b []byte) error {
+func (m *RaftSnapshot) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
 }
-func (m *SubmitTransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+func (m *RaftSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
 	if deterministic {
-		return xxx_messageInfo_SubmitTransactionResponse.Marshal(b, m, deterministic)
+		return xxx_messageInfo_RaftSnapshot.Marshal(b, m, deterministic)
 	} else {
 		b = b[:cap(b)]
 		n, err := m.MarshalTo(b)
@@ -707,258 +1127,907 @@ func (m *SubmitTransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([
 		return b[:n], nil
 	}
 }
-func (m *SubmitTransactionResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SubmitTransactionResponse.Merge(m, src)
+func (m *RaftSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RaftSnapshot.Merge(m, src)
 }
-func (m *SubmitTransactionResponse) XXX_Size() int {
+func (m *RaftSnapshot) XXX_Size() int {
 	return m.Size()
 }
-func (m *SubmitTransactionResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_SubmitTransactionResponse.DiscardUnknown(m)
+func (m *RaftSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_RaftSnapshot.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SubmitTransactionResponse proto.InternalMessageInfo
+var xxx_messageInfo_RaftSnapshot proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterEnum("pb.MessageType", MessageType_name, MessageType_value)
-	proto.RegisterType((*SimpleSetterArg)(nil), "pb.SimpleSetterArg")
-	proto.RegisterType((*Id128)(nil), "pb.Id128")
-	proto.RegisterType((*BaseMessage)(nil), "pb.BaseMessage")
-	proto.RegisterType((*Transaction)(nil), "pb.Transaction")
-	proto.RegisterType((*LowIsoRead)(nil), "pb.LowIsoRead")
-	proto.RegisterType((*TransactionBatch)(nil), "pb.TransactionBatch")
-	proto.RegisterType((*LowIsolationReadRequest)(nil), "pb.LowIsolationReadRequest")
-	proto.RegisterType((*LowIsolationReadResponse)(nil), "pb.LowIsolationReadResponse")
-	proto.RegisterType((*RemoteReadRequest)(nil), "pb.RemoteReadRequest")
-	proto.RegisterType((*RemoteReadResponse)(nil), "pb.RemoteReadResponse")
-	proto.RegisterType((*RaftPeer)(nil), "pb.RaftPeer")
-	proto.RegisterType((*StepRequest)(nil), "pb.StepRequest")
-	proto.RegisterType((*StepResponse)(nil), "pb.StepResponse")
-	proto.RegisterType((*PartitionedSnapshot)(nil), "pb.PartitionedSnapshot")
-	proto.RegisterType((*SubmitTransactionRequest)(nil), "pb.SubmitTransactionRequest")
-	proto.RegisterType((*SubmitTransactionResponse)(nil), "pb.SubmitTransactionResponse")
+// This is used by the implmentor of the data store!
+// Be mindful of that.
+type PartitionedSnapshot struct {
+	PartitionIDs         []uint64 `protobuf:"varint,1,rep,packed,name=PartitionIDs,proto3" json:"PartitionIDs,omitempty"`
+	Snapshots            [][]byte `protobuf:"bytes,2,rep,name=Snapshots,proto3" json:"Snapshots,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("pb/calvin.proto", fileDescriptor_afc31d04251e05fb) }
-
-var fileDescriptor_afc31d04251e05fb = []byte{
-	// 884 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x51, 0x6f, 0x1b, 0x45,
-	0x10, 0xf6, 0xd9, 0xe7, 0x24, 0x1e, 0x3b, 0xd8, 0xdd, 0x86, 0xb2, 0x75, 0x8b, 0x63, 0x1d, 0x15,
-	0x3a, 0x2a, 0xe1, 0x04, 0x57, 0x48, 0x80, 0xd4, 0x07, 0xa7, 0x2d, 0xe8, 0x14, 0x93, 0x44, 0x7b,
-	0x86, 0xf4, 0x01, 0xa9, 0x3a, 0xfb, 0xa6, 0xae, 0x85, 0xed, 0x3d, 0x76, 0xd7, 0xb4, 0xe1, 0x07,
-	0xf0, 0xc2, 0x1f, 0xe0, 0x11, 0x7e, 0x09, 0xaf, 0x7d, 0xec, 0x4f, 0xa0, 0xe1, 0xa5, 0x3f, 0x03,
-	0xed, 0xae, 0x2f, 0x39, 0x27, 0x76, 0x88, 0xc4, 0x8b, 0xbd, 0xf3, 0xcd, 0xb7, 0xb3, 0xdf, 0xec,
-	0xcc, 0xec, 0x41, 0x35, 0xe9, 0xef, 0x0c, 0xa2, 0xf1, 0xcf, 0xa3, 0x69, 0x2b, 0x11, 0x5c, 0x71,
-	0x92, 0x4f, 0xfa, 0xf5, 0xad, 0x21, 0x1f, 0x72, 0x63, 0xee, 0xe8, 0x95, 0xf5, 0xd4, 0x3f, 0x1e,
-	0xf2, 0x16, 0xaa, 0x41, 0xdc, 0x1a, 0xf1, 0x1d, 0xfd, 0xbf, 0x23, 0xa2, 0xe7, 0xca, 0xfc, 0x24,
-	0x7d, 0xf3, 0x67, 0x79, 0xde, 0x97, 0x50, 0x0d, 0x47, 0x93, 0x64, 0x8c, 0x21, 0x2a, 0x85, 0xa2,
-	0x23, 0x86, 0xa4, 0x06, 0x85, 0x7d, 0x3c, 0xa1, 0x4e, 0xd3, 0xf1, 0x2b, 0x4c, 0x2f, 0xc9, 0x16,
-	0x14, 0xbf, 0x8f, 0xc6, 0x33, 0xa4, 0x79, 0x83, 0x59, 0xc3, 0x7b, 0x08, 0xc5, 0x20, 0xfe, 0xac,
-	0xfd, 0x85, 0x76, 0x7f, 0x97, 0x24, 0x28, 0xcc, 0x16, 0x97, 0x59, 0x43, 0xa3, 0x5d, 0xfe, 0x12,
-	0x85, 0xd9, 0xe4, 0x32, 0x6b, 0x7c, 0xb5, 0xf1, 0xee, 0x8f, 0x6d, 0xe7, 0xdd, 0x9f, 0xdb, 0x8e,
-	0xd7, 0x86, 0xf2, 0x5e, 0x24, 0xf1, 0x5b, 0x94, 0x32, 0x1a, 0x22, 0xf9, 0x08, 0xdc, 0xde, 0x49,
-	0x82, 0x26, 0xc6, 0x7b, 0xed, 0x6a, 0x2b, 0xe9, 0xb7, 0xe6, 0x2e, 0x0d, 0x33, 0xe3, 0xf4, 0xfe,
-	0x2a, 0x40, 0xb9, 0x27, 0xa2, 0xa9, 0x8c, 0x06, 0x6a, 0xc4, 0xa7, 0xd7, 0xda, 0x44, 0x6e, 0x43,
-	0x3e, 0x88, 0x8d, 0x8a, 0x72, 0xbb, 0xa4, 0x29, 0x46, 0x35, 0xcb, 0x07, 0x31, 0xa1, 0xb0, 0xce,
-	0x30, 0x8a, 0x43, 0x54, 0xb4, 0xd0, 0x2c, 0xf8, 0x15, 0x96, 0x9a, 0xc4, 0x83, 0x8a, 0x5e, 0x1e,
-	0x8b, 0x91, 0xd2, 0x57, 0x43, 0x5d, 0xe3, 0x5e, 0xc0, 0x48, 0x13, 0xca, 0xda, 0x46, 0x71, 0xc0,
-	0x63, 0x94, 0xb4, 0xd8, 0x2c, 0xf8, 0x2e, 0xcb, 0x42, 0x9a, 0x61, 0xd8, 0x73, 0xc6, 0x9a, 0x65,
-	0x64, 0x20, 0xe2, 0x43, 0x35, 0x54, 0x5c, 0x60, 0x7c, 0x24, 0xf8, 0x00, 0xe3, 0x99, 0x40, 0xba,
-	0xde, 0x74, 0xfc, 0x12, 0xbb, 0x08, 0x93, 0x5d, 0xb8, 0x79, 0x01, 0xea, 0x88, 0xa1, 0xa4, 0x1b,
-	0x46, 0xd8, 0x32, 0x17, 0x69, 0x01, 0x09, 0x64, 0x97, 0xbf, 0x0c, 0x24, 0x1f, 0x47, 0xfa, 0xbe,
-	0xb4, 0x34, 0x5a, 0x6a, 0x3a, 0xfe, 0x06, 0x5b, 0xe2, 0x21, 0x4f, 0x81, 0x5e, 0xc4, 0x18, 0xca,
-	0x84, 0x4f, 0x25, 0x52, 0x30, 0xd7, 0x77, 0x57, 0x5f, 0xdf, 0x2a, 0x0e, 0x5b, 0xb9, 0x3b, 0x53,
-	0xf5, 0xdf, 0x1c, 0x00, 0x4b, 0x33, 0x47, 0x5e, 0xab, 0x80, 0x57, 0xe9, 0xca, 0xff, 0x1f, 0x5d,
-	0xde, 0x37, 0x50, 0xcb, 0xb4, 0xd3, 0x5e, 0xa4, 0x06, 0x2f, 0xc8, 0x03, 0xa8, 0xa8, 0x73, 0x4c,
-	0x52, 0xa7, 0x59, 0xf0, 0xcb, 0x56, 0x5a, 0x86, 0xcb, 0x16, 0x48, 0xde, 0xa7, 0xf0, 0xc1, 0xe5,
-	0x43, 0x7e, 0x9a, 0xa1, 0x54, 0x84, 0x80, 0xbb, 0x8f, 0x27, 0x36, 0x4e, 0x85, 0x99, 0xb5, 0xf7,
-	0xcb, 0xea, 0x8c, 0x96, 0xf1, 0xc9, 0x2d, 0x58, 0x33, 0x33, 0x27, 0x69, 0xde, 0xa0, 0x73, 0x4b,
-	0x73, 0x7b, 0x28, 0x26, 0xb4, 0x60, 0x46, 0xcc, 0xac, 0xf5, 0xdc, 0x05, 0xd3, 0x18, 0x5f, 0x51,
-	0xd7, 0xce, 0x9d, 0x31, 0x32, 0x15, 0xf8, 0xd5, 0x81, 0x1b, 0x0c, 0x27, 0x5c, 0x61, 0x56, 0xe5,
-	0x36, 0x14, 0x7b, 0xaf, 0xa6, 0x41, 0x6c, 0x2a, 0xb1, 0x30, 0x27, 0x16, 0x3f, 0x93, 0x95, 0x5f,
-	0x2a, 0xab, 0xb0, 0x20, 0xeb, 0x1e, 0x6c, 0xf6, 0xb8, 0x8a, 0xc6, 0x07, 0xb3, 0x49, 0x97, 0x0f,
-	0x7e, 0x94, 0x46, 0xca, 0x26, 0x5b, 0x04, 0xbd, 0xfb, 0x40, 0xb2, 0x3a, 0xe6, 0xe9, 0x6f, 0x41,
-	0xf1, 0x89, 0x10, 0xdc, 0x3e, 0x26, 0x25, 0x66, 0x0d, 0xaf, 0x0b, 0x1b, 0x2c, 0x7a, 0xae, 0x8e,
-	0x10, 0x05, 0x69, 0x00, 0xe8, 0xb5, 0x9e, 0x9f, 0xb9, 0x5e, 0x97, 0x65, 0x10, 0x3d, 0x74, 0x9a,
-	0xd7, 0x89, 0x63, 0x81, 0x52, 0x9a, 0x0e, 0x29, 0xb1, 0x2c, 0xe4, 0x3d, 0x85, 0x72, 0xa8, 0x30,
-	0x49, 0x73, 0xff, 0xaf, 0x80, 0x9f, 0xc0, 0xfa, 0xbc, 0x29, 0xe7, 0xed, 0x56, 0x6d, 0xd9, 0x87,
-	0x34, 0xed, 0x55, 0x96, 0xfa, 0xbd, 0x7b, 0x50, 0xb1, 0x91, 0xaf, 0xcc, 0xe6, 0x18, 0x6e, 0x1e,
-	0x45, 0x42, 0x8d, 0x74, 0xed, 0x31, 0x0e, 0xa7, 0x51, 0x22, 0x5f, 0x70, 0xf3, 0xe6, 0x9c, 0xc1,
-	0xc1, 0x63, 0xdb, 0x01, 0x2e, 0x5b, 0xc0, 0xc8, 0x5d, 0x28, 0xa5, 0xfc, 0xb4, 0x16, 0xe7, 0x80,
-	0x57, 0x07, 0x1a, 0xce, 0xfa, 0x93, 0x91, 0xca, 0x76, 0xaa, 0xcd, 0xd2, 0xbb, 0x03, 0xb7, 0x97,
-	0xf8, 0xac, 0xce, 0xfb, 0xbb, 0x50, 0xce, 0xcc, 0x1d, 0xa9, 0x42, 0xb9, 0xc7, 0x3a, 0x07, 0x61,
-	0xe7, 0x51, 0x2f, 0x38, 0x3c, 0xa8, 0xe5, 0x48, 0x0d, 0x2a, 0xdd, 0xc3, 0xe3, 0x67, 0x41, 0x78,
-	0xf8, 0x8c, 0x3d, 0xe9, 0x3c, 0xae, 0x39, 0xed, 0x01, 0xd4, 0x2e, 0x3d, 0x20, 0x87, 0x4b, 0xb0,
-	0x3b, 0xcb, 0x47, 0xd3, 0x68, 0xaa, 0x5f, 0x39, 0xb7, 0x5e, 0xae, 0xbd, 0x0f, 0x70, 0xde, 0x22,
-	0xe4, 0xe1, 0x82, 0xf5, 0xbe, 0xde, 0x7b, 0xa9, 0x91, 0xeb, 0xb7, 0x2e, 0xc2, 0x67, 0xc1, 0xbe,
-	0x86, 0x4d, 0x5d, 0x54, 0x93, 0x7e, 0xc2, 0x85, 0x22, 0x9f, 0x03, 0xe8, 0x62, 0x85, 0x4a, 0x60,
-	0x34, 0x21, 0x66, 0xc2, 0x33, 0x6d, 0x51, 0xaf, 0x9d, 0x03, 0x69, 0x0c, 0xdf, 0xd9, 0x75, 0xda,
-	0x3f, 0xc0, 0xda, 0x23, 0xf3, 0x11, 0x26, 0x0c, 0x6e, 0x5c, 0xba, 0x52, 0x62, 0x72, 0x5a, 0x55,
-	0x85, 0xfa, 0x87, 0x2b, 0xbc, 0xe9, 0x09, 0x7b, 0xf4, 0xf5, 0xdb, 0x46, 0xee, 0xcd, 0xdb, 0x46,
-	0xee, 0xf5, 0x69, 0xc3, 0x79, 0x73, 0xda, 0x70, 0xfe, 0x3e, 0x6d, 0x38, 0xbf, 0xff, 0xd3, 0xc8,
-	0xf5, 0xd7, 0xcc, 0x17, 0xfb, 0xc1, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xf2, 0x10, 0xb4, 0x49,
-	0x06, 0x08, 0x00, 0x00,
+func (m *PartitionedSnapshot) Reset()         { *m = PartitionedSnapshot{} }
+func (m *PartitionedSnapshot) String() string { return proto.CompactTextString(m) }
+func (*PartitionedSnapshot) ProtoMessage()    {}
+func (*PartitionedSnapshot) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{22}
 }
-
-func (this *Id128) Compare(that interface{}) int {
-	if that == nil {
-		if this == nil {
-			return 0
+func (m *PartitionedSnapshot) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PartitionedSnapshot) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PartitionedSnapshot.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
+}
+func (m *PartitionedSnapshot) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PartitionedSnapshot.Merge(m, src)
+}
+func (m *PartitionedSnapshot) XXX_Size() int {
+	return m.Size()
+}
+func (m *PartitionedSnapshot) XXX_DiscardUnknown() {
+	xxx_messageInfo_PartitionedSnapshot.DiscardUnknown(m)
+}
 
-	that1, ok := that.(*Id128)
-	if !ok {
-		that2, ok := that.(Id128)
-		if ok {
-			that1 = &that2
-		} else {
-			return 1
-		}
-	}
-	if that1 == nil {
-		if this == nil {
-			return 0
+var xxx_messageInfo_PartitionedSnapshot proto.InternalMessageInfo
+
+type SubmitTransactionRequest struct {
+	Txn *Transaction `protobuf:"bytes,1,opt,name=Txn,proto3" json:"Txn,omitempty"`
+	// if true, the response is only sent after the transaction was executed
+	// and carries the outcome of the transaction
+	WaitForOutcome       bool     `protobuf:"varint,2,opt,name=WaitForOutcome,proto3" json:"WaitForOutcome,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SubmitTransactionRequest) Reset()         { *m = SubmitTransactionRequest{} }
+func (m *SubmitTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*SubmitTransactionRequest) ProtoMessage()    {}
+func (*SubmitTransactionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{23}
+}
+func (m *SubmitTransactionRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubmitTransactionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubmitTransactionRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
-	} else if this == nil {
-		return -1
+		return b[:n], nil
 	}
-	if this.Upper != that1.Upper {
-		if this.Upper < that1.Upper {
-			return -1
+}
+func (m *SubmitTransactionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitTransactionRequest.Merge(m, src)
+}
+func (m *SubmitTransactionRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubmitTransactionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitTransactionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitTransactionRequest proto.InternalMessageInfo
+
+type SubmitTransactionResponse struct {
+	// the id the transaction was sequenced under
+	// if the client didn't provide an id, calvin assigns one
+	TxnId *Id128 `protobuf:"bytes,1,opt,name=TxnId,proto3" json:"TxnId,omitempty"`
+	// true if the transaction was handed to the sequencer
+	Accepted             bool                `protobuf:"varint,2,opt,name=Accepted,proto3" json:"Accepted,omitempty"`
+	Error                string              `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
+	Outcome              *TransactionOutcome `protobuf:"bytes,4,opt,name=Outcome,proto3" json:"Outcome,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *SubmitTransactionResponse) Reset()         { *m = SubmitTransactionResponse{} }
+func (m *SubmitTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*SubmitTransactionResponse) ProtoMessage()    {}
+func (*SubmitTransactionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{24}
+}
+func (m *SubmitTransactionResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubmitTransactionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubmitTransactionResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
-	if this.Lower != that1.Lower {
-		if this.Lower < that1.Lower {
-			return -1
+}
+func (m *SubmitTransactionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitTransactionResponse.Merge(m, src)
+}
+func (m *SubmitTransactionResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubmitTransactionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitTransactionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitTransactionResponse proto.InternalMessageInfo
+
+type RegisterStoredProcedureRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Source               string   `protobuf:"bytes,2,opt,name=Source,proto3" json:"Source,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegisterStoredProcedureRequest) Reset()         { *m = RegisterStoredProcedureRequest{} }
+func (m *RegisterStoredProcedureRequest) String() string { return proto.CompactTextString(m) }
+func (*RegisterStoredProcedureRequest) ProtoMessage()    {}
+func (*RegisterStoredProcedureRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{25}
+}
+func (m *RegisterStoredProcedureRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RegisterStoredProcedureRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RegisterStoredProcedureRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
-	}
-	if c := bytes.Compare(this.XXX_unrecognized, that1.XXX_unrecognized); c != 0 {
-		return c
+		return b[:n], nil
 	}
-	return 0
 }
-func (this *Transaction) Compare(that interface{}) int {
-	if that == nil {
-		if this == nil {
-			return 0
+func (m *RegisterStoredProcedureRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterStoredProcedureRequest.Merge(m, src)
+}
+func (m *RegisterStoredProcedureRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *RegisterStoredProcedureRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterStoredProcedureRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegisterStoredProcedureRequest proto.InternalMessageInfo
+
+type RegisterStoredProcedureResponse struct {
+	// true if the procedure was handed to the sequencer
+	Accepted             bool     `protobuf:"varint,1,opt,name=Accepted,proto3" json:"Accepted,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegisterStoredProcedureResponse) Reset()         { *m = RegisterStoredProcedureResponse{} }
+func (m *RegisterStoredProcedureResponse) String() string { return proto.CompactTextString(m) }
+func (*RegisterStoredProcedureResponse) ProtoMessage()    {}
+func (*RegisterStoredProcedureResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{26}
+}
+func (m *RegisterStoredProcedureResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RegisterStoredProcedureResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RegisterStoredProcedureResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
+}
+func (m *RegisterStoredProcedureResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterStoredProcedureResponse.Merge(m, src)
+}
+func (m *RegisterStoredProcedureResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *RegisterStoredProcedureResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterStoredProcedureResponse.DiscardUnknown(m)
+}
 
-	that1, ok := that.(*Transaction)
-	if !ok {
-		that2, ok := that.(Transaction)
-		if ok {
-			that1 = &that2
-		} else {
-			return 1
+var xxx_messageInfo_RegisterStoredProcedureResponse proto.InternalMessageInfo
+
+type SetCurrentStoredProcedureRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Version              uint64   `protobuf:"varint,2,opt,name=Version,proto3" json:"Version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetCurrentStoredProcedureRequest) Reset()         { *m = SetCurrentStoredProcedureRequest{} }
+func (m *SetCurrentStoredProcedureRequest) String() string { return proto.CompactTextString(m) }
+func (*SetCurrentStoredProcedureRequest) ProtoMessage()    {}
+func (*SetCurrentStoredProcedureRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{27}
+}
+func (m *SetCurrentStoredProcedureRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SetCurrentStoredProcedureRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SetCurrentStoredProcedureRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if that1 == nil {
-		if this == nil {
-			return 0
+}
+func (m *SetCurrentStoredProcedureRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetCurrentStoredProcedureRequest.Merge(m, src)
+}
+func (m *SetCurrentStoredProcedureRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SetCurrentStoredProcedureRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetCurrentStoredProcedureRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetCurrentStoredProcedureRequest proto.InternalMessageInfo
+
+type SetCurrentStoredProcedureResponse struct {
+	// true if the change was handed to the sequencer
+	Accepted             bool     `protobuf:"varint,1,opt,name=Accepted,proto3" json:"Accepted,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetCurrentStoredProcedureResponse) Reset()         { *m = SetCurrentStoredProcedureResponse{} }
+func (m *SetCurrentStoredProcedureResponse) String() string { return proto.CompactTextString(m) }
+func (*SetCurrentStoredProcedureResponse) ProtoMessage()    {}
+func (*SetCurrentStoredProcedureResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{28}
+}
+func (m *SetCurrentStoredProcedureResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SetCurrentStoredProcedureResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SetCurrentStoredProcedureResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
-	} else if this == nil {
-		return -1
+		return b[:n], nil
 	}
-	if this.Type != that1.Type {
-		if this.Type < that1.Type {
-			return -1
+}
+func (m *SetCurrentStoredProcedureResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetCurrentStoredProcedureResponse.Merge(m, src)
+}
+func (m *SetCurrentStoredProcedureResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *SetCurrentStoredProcedureResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetCurrentStoredProcedureResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetCurrentStoredProcedureResponse proto.InternalMessageInfo
+
+type RemoveStoredProcedureRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Version              uint64   `protobuf:"varint,2,opt,name=Version,proto3" json:"Version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveStoredProcedureRequest) Reset()         { *m = RemoveStoredProcedureRequest{} }
+func (m *RemoveStoredProcedureRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveStoredProcedureRequest) ProtoMessage()    {}
+func (*RemoveStoredProcedureRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{29}
+}
+func (m *RemoveStoredProcedureRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RemoveStoredProcedureRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RemoveStoredProcedureRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
-	if c := this.Id.Compare(that1.Id); c != 0 {
-		return c
+}
+func (m *RemoveStoredProcedureRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveStoredProcedureRequest.Merge(m, src)
+}
+func (m *RemoveStoredProcedureRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *RemoveStoredProcedureRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveStoredProcedureRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveStoredProcedureRequest proto.InternalMessageInfo
+
+type RemoveStoredProcedureResponse struct {
+	// true if the change was handed to the sequencer
+	Accepted             bool     `protobuf:"varint,1,opt,name=Accepted,proto3" json:"Accepted,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveStoredProcedureResponse) Reset()         { *m = RemoveStoredProcedureResponse{} }
+func (m *RemoveStoredProcedureResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveStoredProcedureResponse) ProtoMessage()    {}
+func (*RemoveStoredProcedureResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{30}
+}
+func (m *RemoveStoredProcedureResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RemoveStoredProcedureResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RemoveStoredProcedureResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if len(this.ReadSet) != len(that1.ReadSet) {
-		if len(this.ReadSet) < len(that1.ReadSet) {
-			return -1
+}
+func (m *RemoveStoredProcedureResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveStoredProcedureResponse.Merge(m, src)
+}
+func (m *RemoveStoredProcedureResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *RemoveStoredProcedureResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveStoredProcedureResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveStoredProcedureResponse proto.InternalMessageInfo
+
+type ListStoredProceduresRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListStoredProceduresRequest) Reset()         { *m = ListStoredProceduresRequest{} }
+func (m *ListStoredProceduresRequest) String() string { return proto.CompactTextString(m) }
+func (*ListStoredProceduresRequest) ProtoMessage()    {}
+func (*ListStoredProceduresRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{31}
+}
+func (m *ListStoredProceduresRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListStoredProceduresRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListStoredProceduresRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
-	for i := range this.ReadSet {
-		if c := bytes.Compare(this.ReadSet[i], that1.ReadSet[i]); c != 0 {
-			return c
+}
+func (m *ListStoredProceduresRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStoredProceduresRequest.Merge(m, src)
+}
+func (m *ListStoredProceduresRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListStoredProceduresRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStoredProceduresRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStoredProceduresRequest proto.InternalMessageInfo
+
+type ListStoredProceduresResponse struct {
+	Procedures           []*StoredProcedure `protobuf:"bytes,1,rep,name=Procedures,proto3" json:"Procedures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ListStoredProceduresResponse) Reset()         { *m = ListStoredProceduresResponse{} }
+func (m *ListStoredProceduresResponse) String() string { return proto.CompactTextString(m) }
+func (*ListStoredProceduresResponse) ProtoMessage()    {}
+func (*ListStoredProceduresResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{32}
+}
+func (m *ListStoredProceduresResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListStoredProceduresResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListStoredProceduresResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if len(this.ReadWriteSet) != len(that1.ReadWriteSet) {
-		if len(this.ReadWriteSet) < len(that1.ReadWriteSet) {
-			return -1
+}
+func (m *ListStoredProceduresResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListStoredProceduresResponse.Merge(m, src)
+}
+func (m *ListStoredProceduresResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListStoredProceduresResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListStoredProceduresResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListStoredProceduresResponse proto.InternalMessageInfo
+
+type AddNodeRequest struct {
+	NodeId uint64 `protobuf:"varint,1,opt,name=NodeId,proto3" json:"NodeId,omitempty"`
+	// host:port
+	Address              string   `protobuf:"bytes,2,opt,name=Address,proto3" json:"Address,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddNodeRequest) Reset()         { *m = AddNodeRequest{} }
+func (m *AddNodeRequest) String() string { return proto.CompactTextString(m) }
+func (*AddNodeRequest) ProtoMessage()    {}
+func (*AddNodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{33}
+}
+func (m *AddNodeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AddNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AddNodeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
-	for i := range this.ReadWriteSet {
-		if c := bytes.Compare(this.ReadWriteSet[i], that1.ReadWriteSet[i]); c != 0 {
-			return c
+}
+func (m *AddNodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddNodeRequest.Merge(m, src)
+}
+func (m *AddNodeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AddNodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddNodeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddNodeRequest proto.InternalMessageInfo
+
+type AddNodeResponse struct {
+	// true once the change was committed
+	Committed            bool     `protobuf:"varint,1,opt,name=Committed,proto3" json:"Committed,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AddNodeResponse) Reset()         { *m = AddNodeResponse{} }
+func (m *AddNodeResponse) String() string { return proto.CompactTextString(m) }
+func (*AddNodeResponse) ProtoMessage()    {}
+func (*AddNodeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{34}
+}
+func (m *AddNodeResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AddNodeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AddNodeResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if len(this.ReaderNodes) != len(that1.ReaderNodes) {
-		if len(this.ReaderNodes) < len(that1.ReaderNodes) {
-			return -1
+}
+func (m *AddNodeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddNodeResponse.Merge(m, src)
+}
+func (m *AddNodeResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *AddNodeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddNodeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddNodeResponse proto.InternalMessageInfo
+
+type RemoveNodeRequest struct {
+	NodeId               uint64   `protobuf:"varint,1,opt,name=NodeId,proto3" json:"NodeId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveNodeRequest) Reset()         { *m = RemoveNodeRequest{} }
+func (m *RemoveNodeRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveNodeRequest) ProtoMessage()    {}
+func (*RemoveNodeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{35}
+}
+func (m *RemoveNodeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RemoveNodeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RemoveNodeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
-	for i := range this.ReaderNodes {
-		if this.ReaderNodes[i] != that1.ReaderNodes[i] {
-			if this.ReaderNodes[i] < that1.ReaderNodes[i] {
-				return -1
-			}
-			return 1
+}
+func (m *RemoveNodeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveNodeRequest.Merge(m, src)
+}
+func (m *RemoveNodeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *RemoveNodeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveNodeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveNodeRequest proto.InternalMessageInfo
+
+type RemoveNodeResponse struct {
+	// true once the change was committed
+	Committed            bool     `protobuf:"varint,1,opt,name=Committed,proto3" json:"Committed,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveNodeResponse) Reset()         { *m = RemoveNodeResponse{} }
+func (m *RemoveNodeResponse) String() string { return proto.CompactTextString(m) }
+func (*RemoveNodeResponse) ProtoMessage()    {}
+func (*RemoveNodeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{36}
+}
+func (m *RemoveNodeResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RemoveNodeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RemoveNodeResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if len(this.WriterNodes) != len(that1.WriterNodes) {
-		if len(this.WriterNodes) < len(that1.WriterNodes) {
-			return -1
+}
+func (m *RemoveNodeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveNodeResponse.Merge(m, src)
+}
+func (m *RemoveNodeResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *RemoveNodeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveNodeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveNodeResponse proto.InternalMessageInfo
+
+type PromoteLearnerRequest struct {
+	NodeId               uint64   `protobuf:"varint,1,opt,name=NodeId,proto3" json:"NodeId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromoteLearnerRequest) Reset()         { *m = PromoteLearnerRequest{} }
+func (m *PromoteLearnerRequest) String() string { return proto.CompactTextString(m) }
+func (*PromoteLearnerRequest) ProtoMessage()    {}
+func (*PromoteLearnerRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{37}
+}
+func (m *PromoteLearnerRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PromoteLearnerRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PromoteLearnerRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
-		return 1
+		return b[:n], nil
 	}
-	for i := range this.WriterNodes {
-		if this.WriterNodes[i] != that1.WriterNodes[i] {
-			if this.WriterNodes[i] < that1.WriterNodes[i] {
-				return -1
-			}
-			return 1
+}
+func (m *PromoteLearnerRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromoteLearnerRequest.Merge(m, src)
+}
+func (m *PromoteLearnerRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *PromoteLearnerRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromoteLearnerRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromoteLearnerRequest proto.InternalMessageInfo
+
+type PromoteLearnerResponse struct {
+	// true once the change was committed
+	Committed            bool     `protobuf:"varint,1,opt,name=Committed,proto3" json:"Committed,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=Error,proto3" json:"Error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromoteLearnerResponse) Reset()         { *m = PromoteLearnerResponse{} }
+func (m *PromoteLearnerResponse) String() string { return proto.CompactTextString(m) }
+func (*PromoteLearnerResponse) ProtoMessage()    {}
+func (*PromoteLearnerResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_afc31d04251e05fb, []int{38}
+}
+func (m *PromoteLearnerResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PromoteLearnerResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PromoteLearnerResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	if this.StoredProcedure != that1.StoredProcedure {
-		if this.StoredProcedure < that1.StoredProcedure {
-			return -1
-		}
-		return 1
-	}
-	if len(this.StoredProcedureArgs) != len(that1.StoredProcedureArgs) {
-		if len(this.StoredProcedureArgs) < len(that1.StoredProcedureArgs) {
-			return -1
-		}
-		return 1
-	}
-	for i := range this.StoredProcedureArgs {
-		if c := bytes.Compare(this.StoredProcedureArgs[i], that1.StoredProcedureArgs[i]); c != 0 {
-			return c
-		}
-	}
-	if this.IsLowIsolationRead != that1.IsLowIsolationRead {
-		if !this.IsLowIsolationRead {
-			return -1
-		}
-		return 1
-	}
-	if c := this.LowIsolationReadResponse.Compare(that1.LowIsolationReadResponse); c != 0 {
-		return c
-	}
-	if c := bytes.Compare(this.XXX_unrecognized, that1.XXX_unrecognized); c != 0 {
-		return c
-	}
-	return 0
 }
-func (this *LowIsolationReadResponse) Compare(that interface{}) int {
+func (m *PromoteLearnerResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromoteLearnerResponse.Merge(m, src)
+}
+func (m *PromoteLearnerResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *PromoteLearnerResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromoteLearnerResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromoteLearnerResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterEnum("pb.MessageType", MessageType_name, MessageType_value)
+	proto.RegisterEnum("pb.TransactionStatus", TransactionStatus_name, TransactionStatus_value)
+	proto.RegisterEnum("pb.StoredProcedureOp", StoredProcedureOp_name, StoredProcedureOp_value)
+	proto.RegisterType((*SimpleSetterArg)(nil), "pb.SimpleSetterArg")
+	proto.RegisterType((*Id128)(nil), "pb.Id128")
+	proto.RegisterType((*BaseMessage)(nil), "pb.BaseMessage")
+	proto.RegisterType((*Transaction)(nil), "pb.Transaction")
+	proto.RegisterType((*TransactionOutcome)(nil), "pb.TransactionOutcome")
+	proto.RegisterType((*LowIsoRead)(nil), "pb.LowIsoRead")
+	proto.RegisterType((*TransactionBatch)(nil), "pb.TransactionBatch")
+	proto.RegisterType((*StoredProcedure)(nil), "pb.StoredProcedure")
+	proto.RegisterType((*ReportTransactionOutcomesRequest)(nil), "pb.ReportTransactionOutcomesRequest")
+	proto.RegisterType((*ReportTransactionOutcomesResponse)(nil), "pb.ReportTransactionOutcomesResponse")
+	proto.RegisterType((*LowIsolationReadRequest)(nil), "pb.LowIsolationReadRequest")
+	proto.RegisterType((*LowIsolationReadResponse)(nil), "pb.LowIsolationReadResponse")
+	proto.RegisterType((*RemoteReadRequest)(nil), "pb.RemoteReadRequest")
+	proto.RegisterType((*RemoteReadResponse)(nil), "pb.RemoteReadResponse")
+	proto.RegisterType((*RaftPeer)(nil), "pb.RaftPeer")
+	proto.RegisterType((*ProposeRequest)(nil), "pb.ProposeRequest")
+	proto.RegisterType((*ProposeResponse)(nil), "pb.ProposeResponse")
+	proto.RegisterType((*StepRequest)(nil), "pb.StepRequest")
+	proto.RegisterType((*StepResponse)(nil), "pb.StepResponse")
+	proto.RegisterType((*SnapshotChunk)(nil), "pb.SnapshotChunk")
+	proto.RegisterType((*SnapshotChunkResponse)(nil), "pb.SnapshotChunkResponse")
+	proto.RegisterType((*RaftSnapshot)(nil), "pb.RaftSnapshot")
+	proto.RegisterType((*PartitionedSnapshot)(nil), "pb.PartitionedSnapshot")
+	proto.RegisterType((*SubmitTransactionRequest)(nil), "pb.SubmitTransactionRequest")
+	proto.RegisterType((*SubmitTransactionResponse)(nil), "pb.SubmitTransactionResponse")
+	proto.RegisterType((*RegisterStoredProcedureRequest)(nil), "pb.RegisterStoredProcedureRequest")
+	proto.RegisterType((*RegisterStoredProcedureResponse)(nil), "pb.RegisterStoredProcedureResponse")
+	proto.RegisterType((*SetCurrentStoredProcedureRequest)(nil), "pb.SetCurrentStoredProcedureRequest")
+	proto.RegisterType((*SetCurrentStoredProcedureResponse)(nil), "pb.SetCurrentStoredProcedureResponse")
+	proto.RegisterType((*RemoveStoredProcedureRequest)(nil), "pb.RemoveStoredProcedureRequest")
+	proto.RegisterType((*RemoveStoredProcedureResponse)(nil), "pb.RemoveStoredProcedureResponse")
+	proto.RegisterType((*ListStoredProceduresRequest)(nil), "pb.ListStoredProceduresRequest")
+	proto.RegisterType((*ListStoredProceduresResponse)(nil), "pb.ListStoredProceduresResponse")
+	proto.RegisterType((*AddNodeRequest)(nil), "pb.AddNodeRequest")
+	proto.RegisterType((*AddNodeResponse)(nil), "pb.AddNodeResponse")
+	proto.RegisterType((*RemoveNodeRequest)(nil), "pb.RemoveNodeRequest")
+	proto.RegisterType((*RemoveNodeResponse)(nil), "pb.RemoveNodeResponse")
+	proto.RegisterType((*PromoteLearnerRequest)(nil), "pb.PromoteLearnerRequest")
+	proto.RegisterType((*PromoteLearnerResponse)(nil), "pb.PromoteLearnerResponse")
+}
+
+func init() { proto.RegisterFile("pb/calvin.proto", fileDescriptor_afc31d04251e05fb) }
+
+var fileDescriptor_afc31d04251e05fb = []byte{
+	// 2059 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0x4f, 0x73, 0xdb, 0xc6,
+	0x15, 0x17, 0xf8, 0x4f, 0xe4, 0x23, 0x25, 0x52, 0xab, 0x3f, 0x86, 0x18, 0x59, 0xa2, 0x11, 0x39,
+	0xa3, 0xba, 0x53, 0xc9, 0xa5, 0xd3, 0x4e, 0xd2, 0x99, 0x4c, 0x87, 0x22, 0xe9, 0x14, 0x63, 0x9a,
+	0x64, 0x16, 0x94, 0xd5, 0x99, 0xcc, 0xd4, 0x03, 0x11, 0x2b, 0x89, 0x31, 0x09, 0xa0, 0x0b, 0xd0,
+	0xb1, 0x73, 0xeb, 0xb9, 0x97, 0x1e, 0x7a, 0xc8, 0x31, 0xbd, 0xf4, 0xd2, 0x0f, 0xd1, 0x99, 0xf6,
+	0x92, 0xe9, 0x29, 0x1f, 0x21, 0x71, 0x2f, 0xf9, 0x18, 0x9d, 0xdd, 0x05, 0x40, 0x00, 0x24, 0x28,
+	0xd5, 0xce, 0x45, 0xc2, 0xfb, 0xbd, 0xb7, 0x6f, 0xdf, 0xbe, 0xf7, 0xf6, 0xbd, 0xb7, 0x84, 0xb2,
+	0x7d, 0x71, 0x32, 0xd4, 0xc7, 0x2f, 0x47, 0xe6, 0xb1, 0x4d, 0x2d, 0xd7, 0x42, 0x29, 0xfb, 0xa2,
+	0xba, 0x75, 0x65, 0x5d, 0x59, 0x9c, 0x3c, 0x61, 0x5f, 0x82, 0x53, 0xfd, 0xe0, 0xca, 0x3a, 0x26,
+	0xee, 0xd0, 0x38, 0x1e, 0x59, 0x27, 0xec, 0xff, 0x09, 0xd5, 0x2f, 0x5d, 0xfe, 0xc7, 0xbe, 0xe0,
+	0xff, 0x84, 0x9c, 0xf2, 0x31, 0x94, 0xb5, 0xd1, 0xc4, 0x1e, 0x13, 0x8d, 0xb8, 0x2e, 0xa1, 0x0d,
+	0x7a, 0x85, 0x2a, 0x90, 0x7e, 0x42, 0x5e, 0xcb, 0x52, 0x4d, 0x3a, 0x2a, 0x61, 0xf6, 0x89, 0xb6,
+	0x20, 0xfb, 0x4c, 0x1f, 0x4f, 0x89, 0x9c, 0xe2, 0x98, 0x20, 0x94, 0x4f, 0x20, 0xab, 0x1a, 0xbf,
+	0xac, 0x7f, 0xc4, 0xd8, 0x67, 0xb6, 0x4d, 0x28, 0x5f, 0x92, 0xc1, 0x82, 0x60, 0x68, 0xc7, 0xfa,
+	0x92, 0x50, 0xbe, 0x28, 0x83, 0x05, 0xf1, 0x9b, 0xfc, 0x8f, 0xdf, 0x1c, 0x48, 0x3f, 0xfe, 0xed,
+	0x40, 0x52, 0xea, 0x50, 0x3c, 0xd5, 0x1d, 0xf2, 0x94, 0x38, 0x8e, 0x7e, 0x45, 0xd0, 0xfb, 0x90,
+	0x19, 0xbc, 0xb6, 0x09, 0xd7, 0xb1, 0x5e, 0x2f, 0x1f, 0xdb, 0x17, 0xc7, 0x1e, 0x8b, 0xc1, 0x98,
+	0x33, 0x95, 0x7f, 0x67, 0xa1, 0x38, 0xa0, 0xba, 0xe9, 0xe8, 0x43, 0x77, 0x64, 0x99, 0xb7, 0x5a,
+	0x84, 0x76, 0x21, 0xa5, 0x1a, 0xdc, 0x8a, 0x62, 0xbd, 0xc0, 0x44, 0xb8, 0xd5, 0x38, 0xa5, 0x1a,
+	0x48, 0x86, 0x55, 0x4c, 0x74, 0x43, 0x23, 0xae, 0x9c, 0xae, 0xa5, 0x8f, 0x4a, 0xd8, 0x27, 0x91,
+	0x02, 0x25, 0xf6, 0x79, 0x4e, 0x47, 0x2e, 0x73, 0x8d, 0x9c, 0xe1, 0xec, 0x08, 0x86, 0x6a, 0x50,
+	0x64, 0x34, 0xa1, 0x5d, 0xcb, 0x20, 0x8e, 0x9c, 0xad, 0xa5, 0x8f, 0x32, 0x38, 0x0c, 0x31, 0x09,
+	0x2e, 0xed, 0x49, 0xe4, 0x84, 0x44, 0x08, 0x42, 0x47, 0x50, 0xd6, 0x5c, 0x8b, 0x12, 0xa3, 0x4f,
+	0xad, 0x21, 0x31, 0xa6, 0x94, 0xc8, 0xab, 0x35, 0xe9, 0xa8, 0x80, 0xe3, 0x30, 0x7a, 0x08, 0x9b,
+	0x31, 0xa8, 0x41, 0xaf, 0x1c, 0x39, 0xcf, 0x0d, 0x5b, 0xc4, 0x42, 0xc7, 0x80, 0x54, 0xa7, 0x63,
+	0x7d, 0xa9, 0x3a, 0xd6, 0x58, 0x67, 0xfe, 0x62, 0xa6, 0xc9, 0x85, 0x9a, 0x74, 0x94, 0xc7, 0x0b,
+	0x38, 0xe8, 0xf7, 0x20, 0xc7, 0x31, 0x4c, 0x1c, 0xdb, 0x32, 0x1d, 0x22, 0x03, 0x77, 0xdf, 0x1e,
+	0x73, 0x5f, 0x92, 0x0c, 0x4e, 0x5c, 0x8d, 0xf6, 0x01, 0x7a, 0x74, 0x74, 0x35, 0x32, 0xd9, 0xa1,
+	0xe5, 0x22, 0x4f, 0x88, 0x10, 0xc2, 0xf8, 0xa7, 0xba, 0x3b, 0xbc, 0x56, 0x4d, 0x83, 0xbc, 0x92,
+	0x4b, 0x82, 0x3f, 0x43, 0xd0, 0x1e, 0x14, 0x38, 0x35, 0x20, 0x74, 0x22, 0xaf, 0x71, 0xf6, 0x0c,
+	0x40, 0x0f, 0x61, 0xb5, 0x37, 0x75, 0x87, 0xd6, 0x84, 0xc8, 0xeb, 0xdc, 0xcc, 0x1d, 0x66, 0x66,
+	0x28, 0x4f, 0x3c, 0x2e, 0xf6, 0xc5, 0xd0, 0xaf, 0x61, 0x27, 0xe6, 0xb0, 0x67, 0x84, 0x3a, 0x23,
+	0xcb, 0x94, 0xcb, 0x5c, 0x79, 0x02, 0x17, 0x1d, 0xc2, 0x1a, 0xdf, 0xb6, 0x6f, 0x39, 0x23, 0xa6,
+	0x58, 0xae, 0x70, 0xf1, 0x28, 0xc8, 0x32, 0x5f, 0x1b, 0xea, 0xa6, 0x23, 0x6f, 0x70, 0x57, 0x0b,
+	0x22, 0x94, 0xf9, 0x7f, 0x4d, 0x01, 0x9a, 0xb7, 0x0e, 0x1d, 0x40, 0x76, 0xf0, 0xca, 0x54, 0x0d,
+	0x59, 0x8a, 0xa7, 0xaa, 0xc0, 0xd1, 0x2f, 0x20, 0xa7, 0xb9, 0xba, 0x3b, 0x75, 0x78, 0x32, 0xaf,
+	0xd7, 0xb7, 0x63, 0xc7, 0x14, 0x4c, 0xec, 0x09, 0x31, 0x33, 0xda, 0x94, 0x5a, 0x54, 0x4e, 0xf3,
+	0x84, 0x12, 0x44, 0xcc, 0xd5, 0x99, 0xe5, 0xae, 0xce, 0xc6, 0x5d, 0xbd, 0x03, 0x39, 0x16, 0x30,
+	0xd5, 0x90, 0x73, 0x9c, 0xe5, 0x51, 0xf1, 0x44, 0x5f, 0x9d, 0x4f, 0xf4, 0x1d, 0xc8, 0x61, 0xe2,
+	0x4c, 0xc7, 0xae, 0x9c, 0xe7, 0x45, 0xc4, 0xa3, 0x42, 0x6e, 0xf9, 0xb3, 0x04, 0x20, 0x32, 0x88,
+	0x67, 0xe3, 0xad, 0xee, 0xf6, 0xb2, 0x94, 0x4d, 0xbd, 0x4b, 0xca, 0x2a, 0xff, 0x49, 0x41, 0x25,
+	0xe4, 0x5b, 0xee, 0x02, 0xf4, 0x08, 0x4a, 0xee, 0x0c, 0x73, 0x64, 0xa9, 0x96, 0x3e, 0x2a, 0x0a,
+	0xdb, 0x42, 0xb2, 0x38, 0x22, 0x84, 0x7e, 0x0b, 0x95, 0x58, 0x3a, 0xb1, 0x00, 0xb2, 0x85, 0x9b,
+	0x6c, 0x61, 0x8c, 0x87, 0xe7, 0x84, 0x59, 0x20, 0x45, 0xb4, 0xd2, 0xa2, 0x92, 0x72, 0x02, 0x21,
+	0xc8, 0xf0, 0x18, 0x89, 0x10, 0xf2, 0x6f, 0xf4, 0x11, 0xdc, 0xc1, 0xc4, 0x1e, 0xeb, 0x43, 0x32,
+	0xb7, 0x63, 0x96, 0xe7, 0x62, 0x12, 0x9b, 0x27, 0x8b, 0x6d, 0x0d, 0xaf, 0xbd, 0xb8, 0x0a, 0x02,
+	0x7d, 0x00, 0xeb, 0x1a, 0xf9, 0xe3, 0x94, 0x98, 0x43, 0x42, 0x3f, 0xa5, 0xd6, 0xd4, 0xe6, 0xc5,
+	0x69, 0x0d, 0xc7, 0x50, 0x96, 0x54, 0x7d, 0x6a, 0xd9, 0x96, 0xa3, 0x8f, 0x55, 0x83, 0x07, 0x38,
+	0x83, 0x43, 0x88, 0xf2, 0xb5, 0x34, 0x57, 0xe6, 0x98, 0xfd, 0x5d, 0x7d, 0x22, 0xe2, 0x5b, 0xc0,
+	0xfc, 0x9b, 0x25, 0x89, 0x66, 0x4d, 0xe9, 0x50, 0x04, 0xaf, 0x80, 0x3d, 0x8a, 0xd5, 0x69, 0xff,
+	0x82, 0x0a, 0x1f, 0xf8, 0x24, 0xba, 0x0f, 0xa9, 0x9e, 0x2d, 0x67, 0x66, 0xf7, 0x21, 0xb6, 0x4d,
+	0xcf, 0xc6, 0xa9, 0x9e, 0xcd, 0x14, 0x34, 0xa7, 0x94, 0x12, 0xd3, 0xf5, 0x1c, 0xe1, 0x93, 0xca,
+	0x33, 0xa8, 0x61, 0x62, 0x5b, 0xd4, 0x9d, 0xbf, 0x91, 0x0e, 0x66, 0xa7, 0x74, 0x5c, 0x54, 0x87,
+	0xbc, 0x0f, 0x79, 0x21, 0x4f, 0xaa, 0x30, 0x81, 0x9c, 0xf2, 0x31, 0xdc, 0x5b, 0xa2, 0xd7, 0xab,
+	0x8b, 0xc1, 0x15, 0x95, 0x42, 0x57, 0x54, 0xf9, 0x0c, 0xee, 0xcc, 0xa7, 0xa5, 0xb0, 0x04, 0x41,
+	0xe6, 0x09, 0x79, 0x2d, 0xac, 0x28, 0x61, 0xfe, 0xcd, 0x5a, 0x55, 0x67, 0x64, 0x12, 0x9d, 0x8e,
+	0xbe, 0xd2, 0x2f, 0xc6, 0xc2, 0x75, 0x79, 0x1c, 0xc1, 0x94, 0xbf, 0x48, 0xc9, 0x17, 0x65, 0xa1,
+	0xd2, 0x1d, 0xc8, 0xf1, 0x2e, 0x2f, 0x52, 0xb5, 0x84, 0x3d, 0x2a, 0xc8, 0xba, 0x74, 0x28, 0xeb,
+	0x82, 0xfc, 0xcc, 0x84, 0xf3, 0x73, 0x0b, 0xb2, 0x8f, 0xad, 0xa9, 0x69, 0xf0, 0xbe, 0x98, 0xc7,
+	0x82, 0x08, 0x5d, 0xf7, 0xbf, 0x4b, 0xb0, 0x81, 0xc9, 0xc4, 0x72, 0x49, 0xf8, 0x80, 0x37, 0x16,
+	0x41, 0xdf, 0xd8, 0xd4, 0x42, 0x63, 0xd3, 0x11, 0x63, 0x0f, 0x61, 0x6d, 0x60, 0xb9, 0xfa, 0xb8,
+	0x3b, 0x9d, 0x74, 0xac, 0xe1, 0x0b, 0x87, 0x1b, 0xb8, 0x86, 0xa3, 0x60, 0xac, 0x22, 0x66, 0xe3,
+	0x15, 0x51, 0x79, 0x00, 0x28, 0x6c, 0xe7, 0xd2, 0xd0, 0x75, 0x20, 0x8f, 0xf5, 0x4b, 0xb7, 0x4f,
+	0x08, 0xaf, 0xb4, 0xec, 0xdb, 0xab, 0x97, 0x62, 0x36, 0x0a, 0x21, 0xac, 0x66, 0x32, 0xb9, 0x86,
+	0x61, 0x50, 0xe2, 0x38, 0x5e, 0xc6, 0x87, 0x21, 0xe5, 0x25, 0xac, 0x8b, 0x4b, 0x44, 0x42, 0xf1,
+	0x7f, 0x4c, 0xad, 0x89, 0xa7, 0x8d, 0x7f, 0x33, 0xac, 0xa5, 0xbb, 0xba, 0x37, 0x9c, 0xf1, 0x6f,
+	0x96, 0x13, 0xaa, 0xd3, 0xb4, 0xcc, 0xcb, 0xe6, 0xb5, 0x6e, 0x5e, 0x11, 0x1e, 0xae, 0x3c, 0x8e,
+	0x60, 0xec, 0x4e, 0xf0, 0xdb, 0xab, 0x1a, 0x9e, 0x5f, 0x7c, 0x52, 0x69, 0x42, 0x39, 0xd8, 0xd7,
+	0x3b, 0x6e, 0x15, 0xf2, 0x1d, 0x3e, 0xd8, 0x04, 0x47, 0x09, 0xe8, 0x99, 0x2b, 0x52, 0x61, 0x57,
+	0x50, 0x28, 0x6a, 0x2e, 0xb1, 0x7d, 0xcb, 0x6f, 0xf2, 0xc6, 0xcf, 0x60, 0xd5, 0x2b, 0xef, 0x5e,
+	0xe1, 0x2e, 0x1f, 0x8b, 0x69, 0xd5, 0xaf, 0xfa, 0xd8, 0xe7, 0x87, 0x0d, 0x4f, 0x47, 0x0d, 0x3f,
+	0x84, 0x92, 0xd8, 0x73, 0x69, 0x90, 0xbe, 0x97, 0x60, 0x4d, 0x33, 0x75, 0xdb, 0xb9, 0xb6, 0xdc,
+	0xe6, 0xf5, 0xd4, 0x7c, 0x11, 0xde, 0x5c, 0xba, 0x61, 0xf3, 0x3d, 0x28, 0xf0, 0xf4, 0xd1, 0x46,
+	0x5f, 0x11, 0x6f, 0xb4, 0x9d, 0x01, 0xcc, 0x4d, 0xcd, 0x6b, 0x32, 0x7c, 0xe1, 0x4c, 0x27, 0x9e,
+	0x6d, 0x01, 0xcd, 0xb2, 0xb4, 0x77, 0x79, 0xe9, 0xf0, 0x61, 0x92, 0xf7, 0x4e, 0x41, 0x05, 0xf1,
+	0xcb, 0x86, 0xe2, 0x77, 0x08, 0x6b, 0xdc, 0xb2, 0x40, 0x59, 0x4e, 0x64, 0x6e, 0x04, 0x0c, 0x3b,
+	0x62, 0x35, 0xea, 0x88, 0x21, 0x6c, 0x47, 0x4e, 0x18, 0x78, 0x64, 0x66, 0x84, 0x14, 0x31, 0x62,
+	0x0f, 0x0a, 0xaa, 0xe9, 0xb8, 0xfa, 0x78, 0x4c, 0x0c, 0xaf, 0x82, 0xcc, 0x80, 0xc5, 0xa3, 0x84,
+	0xf2, 0x4f, 0x09, 0x4a, 0x2c, 0x82, 0xfe, 0x4e, 0xc1, 0x49, 0xa4, 0xd0, 0x49, 0xde, 0xb9, 0xfb,
+	0x29, 0x90, 0x65, 0x77, 0x42, 0xdc, 0xed, 0x62, 0xbd, 0xc4, 0x56, 0xf9, 0x77, 0x0c, 0x0b, 0xd6,
+	0xac, 0x7b, 0x65, 0xc2, 0xdd, 0x8b, 0x0d, 0x25, 0x23, 0xf7, 0xda, 0x9a, 0xba, 0x81, 0x7f, 0xf3,
+	0x38, 0x0c, 0x29, 0xe7, 0xb0, 0xd9, 0xd7, 0xa9, 0xcb, 0xc7, 0x36, 0x62, 0x04, 0xe7, 0x50, 0xa0,
+	0x14, 0xc0, 0x6a, 0x4b, 0x14, 0xc6, 0x0c, 0x8e, 0x60, 0xcc, 0x61, 0xbe, 0xbc, 0x5f, 0x8c, 0x66,
+	0x80, 0x42, 0x40, 0xd6, 0xa6, 0x17, 0x93, 0x51, 0xb8, 0xfa, 0xfb, 0x37, 0xe1, 0x1e, 0xa4, 0x07,
+	0xaf, 0xcc, 0x20, 0xd1, 0x62, 0xb3, 0x03, 0xe3, 0xb1, 0xbe, 0x7b, 0xae, 0x8f, 0xdc, 0xc7, 0x16,
+	0xf5, 0x07, 0x5b, 0x11, 0x92, 0x18, 0xaa, 0x7c, 0x23, 0xc1, 0xee, 0x82, 0x7d, 0xbc, 0x58, 0xdf,
+	0x58, 0x4b, 0xab, 0x90, 0x6f, 0x0c, 0x87, 0xc4, 0x76, 0x83, 0x98, 0x07, 0x74, 0xc2, 0xf4, 0x18,
+	0x1a, 0xb5, 0x33, 0xb7, 0x1a, 0xb5, 0x95, 0x0e, 0xec, 0x63, 0x72, 0x35, 0x72, 0x5c, 0x42, 0xe3,
+	0xb1, 0x9e, 0xd5, 0xb4, 0xdb, 0x0e, 0x02, 0x8a, 0x06, 0x07, 0x89, 0xda, 0x66, 0x95, 0x2a, 0x38,
+	0x94, 0x94, 0x74, 0xa8, 0x48, 0xa5, 0xea, 0x43, 0x4d, 0x23, 0xae, 0x37, 0x10, 0xfc, 0x1f, 0x46,
+	0x86, 0xa6, 0x92, 0x54, 0x64, 0x2a, 0x51, 0xce, 0xe0, 0xde, 0x12, 0x8d, 0x6f, 0x6d, 0x68, 0x07,
+	0xf6, 0x58, 0x27, 0x7a, 0x49, 0x7e, 0x12, 0x23, 0x3f, 0x83, 0xbb, 0x09, 0xda, 0xde, 0xda, 0xc0,
+	0xbb, 0xf0, 0x5e, 0x67, 0xe4, 0xc4, 0x4f, 0xec, 0xcf, 0x51, 0x8a, 0x06, 0x7b, 0x8b, 0xd9, 0xde,
+	0x86, 0x8f, 0x00, 0x66, 0xa8, 0x2c, 0x25, 0x57, 0x89, 0x90, 0x98, 0x72, 0x0a, 0xeb, 0x0d, 0xc3,
+	0x60, 0x5d, 0xc4, 0x77, 0xc3, 0xec, 0x91, 0x22, 0x45, 0x1e, 0x29, 0x32, 0xac, 0x46, 0x9b, 0xad,
+	0x4f, 0x2a, 0x6d, 0x28, 0x07, 0x3a, 0x3c, 0x5b, 0xf6, 0xa0, 0xd0, 0xb4, 0x26, 0x93, 0x91, 0x3b,
+	0x3b, 0xfd, 0x0c, 0x48, 0x38, 0xfe, 0xcf, 0xc5, 0x44, 0xf3, 0x92, 0xdc, 0xc2, 0x1a, 0xe5, 0x77,
+	0x80, 0xc2, 0xc2, 0xef, 0xb0, 0xed, 0x09, 0x6c, 0xf7, 0xa9, 0x35, 0xb1, 0x5c, 0xd2, 0x21, 0x3a,
+	0x35, 0x09, 0xbd, 0x69, 0xeb, 0x0e, 0xec, 0xc4, 0x17, 0xbc, 0xfd, 0xf6, 0x0f, 0x1e, 0x42, 0x31,
+	0xf4, 0x30, 0x43, 0x65, 0x28, 0x0e, 0x70, 0xa3, 0xab, 0x35, 0x9a, 0x03, 0xb5, 0xd7, 0xad, 0xac,
+	0xa0, 0x0a, 0x94, 0x3a, 0xbd, 0xf3, 0xe7, 0xaa, 0xd6, 0x7b, 0x8e, 0xdb, 0x8d, 0x56, 0x45, 0x7a,
+	0x70, 0x01, 0x1b, 0x73, 0xcf, 0x56, 0x54, 0x84, 0xd5, 0x7e, 0xbb, 0xdb, 0x52, 0xbb, 0x9f, 0x56,
+	0x56, 0xd0, 0x1a, 0x14, 0x9a, 0xbd, 0xa7, 0x4f, 0xd5, 0xc1, 0xa0, 0xdd, 0xaa, 0x48, 0x08, 0x20,
+	0xf7, 0xb8, 0xa1, 0x76, 0xda, 0xad, 0x4a, 0x8a, 0xc9, 0x35, 0x4e, 0x7b, 0x98, 0x31, 0xd2, 0x8c,
+	0x68, 0xe1, 0x5e, 0xbf, 0xdf, 0x6e, 0x55, 0x32, 0x8c, 0x38, 0xeb, 0x3e, 0xe9, 0xf6, 0xce, 0xbb,
+	0x95, 0xec, 0x83, 0xcf, 0x61, 0x63, 0xee, 0x29, 0x80, 0xb6, 0x61, 0x43, 0xed, 0x6a, 0x83, 0x46,
+	0xa7, 0xf3, 0xbc, 0x8f, 0x7b, 0xcd, 0x76, 0xeb, 0x0c, 0xb7, 0x2b, 0x2b, 0x68, 0x17, 0xb6, 0xb5,
+	0xf6, 0xe0, 0x79, 0xf3, 0x0c, 0xe3, 0x76, 0x77, 0x10, 0x62, 0x49, 0x68, 0x0b, 0x2a, 0xb8, 0xfd,
+	0xb4, 0xf7, 0xac, 0x1d, 0x42, 0x53, 0xf5, 0x3f, 0x49, 0xb0, 0xb9, 0x60, 0xae, 0x47, 0x5f, 0xc0,
+	0x6e, 0xe2, 0xd0, 0x8f, 0x0e, 0x79, 0xe7, 0xba, 0xe1, 0xad, 0x51, 0xbd, 0x7f, 0x83, 0x94, 0xf7,
+	0x3c, 0x5d, 0xa9, 0x0f, 0xa1, 0x32, 0xf7, 0x0b, 0x4e, 0x6f, 0x01, 0xf6, 0xde, 0xe2, 0x07, 0xb0,
+	0xd8, 0x6d, 0xe9, 0xeb, 0x58, 0x59, 0xa9, 0x3f, 0x01, 0x98, 0xcd, 0xbe, 0xe8, 0x93, 0x08, 0xb5,
+	0x2d, 0x2c, 0x8d, 0x4d, 0xf0, 0xd5, 0x9d, 0x38, 0x1c, 0x28, 0xfb, 0x97, 0x04, 0x6b, 0xac, 0x73,
+	0xf3, 0x73, 0xb1, 0x03, 0xa2, 0x5f, 0x01, 0xb0, 0x79, 0x4d, 0x73, 0x29, 0xd1, 0x27, 0xa8, 0x2c,
+	0xae, 0x7a, 0x30, 0x33, 0x56, 0x2b, 0x33, 0xc0, 0x57, 0x72, 0x24, 0x3d, 0x94, 0x50, 0x0b, 0xd6,
+	0xfd, 0x56, 0xeb, 0x2d, 0xdd, 0xe0, 0x92, 0xe1, 0x89, 0xa7, 0xba, 0x3b, 0x07, 0xc5, 0xb4, 0x7c,
+	0x08, 0xab, 0xde, 0x94, 0x8b, 0x10, 0x93, 0x8d, 0x8e, 0xda, 0xd5, 0xcd, 0x08, 0x16, 0x1c, 0xe2,
+	0x1f, 0x59, 0xc8, 0x35, 0xf9, 0x6f, 0xb0, 0x08, 0xc3, 0xc6, 0x5c, 0xf3, 0x45, 0xdc, 0xa3, 0x49,
+	0xbd, 0xbf, 0x7a, 0x37, 0x81, 0xeb, 0xab, 0x47, 0x06, 0xdc, 0x49, 0x68, 0x70, 0x48, 0x11, 0x8e,
+	0x5d, 0xd6, 0x4b, 0xab, 0xef, 0x2f, 0x95, 0x09, 0x76, 0xf9, 0x02, 0x76, 0x13, 0xfb, 0x93, 0xc8,
+	0xd3, 0x9b, 0x1a, 0x62, 0xf5, 0xfe, 0x0d, 0x52, 0xc1, 0x5e, 0x7f, 0x80, 0xed, 0x85, 0x6d, 0x06,
+	0xd5, 0xfc, 0x44, 0x49, 0xea, 0x67, 0xd5, 0x7b, 0x4b, 0x24, 0x02, 0xfd, 0x9f, 0xc3, 0xd6, 0xa2,
+	0xa6, 0x82, 0x0e, 0x78, 0x6a, 0x27, 0x77, 0xa3, 0x6a, 0x2d, 0x59, 0x20, 0x50, 0xfe, 0x21, 0x6f,
+	0x19, 0xfc, 0x37, 0x4a, 0x9e, 0x23, 0xd1, 0x4e, 0x53, 0xdd, 0x8c, 0x60, 0xc1, 0x2a, 0xef, 0x9e,
+	0x88, 0xd2, 0x3e, 0xbb, 0x27, 0x91, 0xbe, 0x50, 0xdd, 0x89, 0xc3, 0xc1, 0x72, 0x95, 0x3f, 0xfb,
+	0x42, 0xe5, 0x19, 0xed, 0x7a, 0xb9, 0x38, 0x5f, 0xe3, 0xab, 0xd5, 0x45, 0x2c, 0x5f, 0xd5, 0xa9,
+	0xfc, 0xed, 0x0f, 0xfb, 0x2b, 0xdf, 0xfd, 0xb0, 0xbf, 0xf2, 0xed, 0x9b, 0x7d, 0xe9, 0xbb, 0x37,
+	0xfb, 0xd2, 0xf7, 0x6f, 0xf6, 0xa5, 0xaf, 0xff, 0xbb, 0xbf, 0x72, 0x91, 0xe3, 0xbf, 0xff, 0x3f,
+	0xfa, 0xdf, 0x00, 0xe5, 0xff, 0x67, 0x78, 0x54, 0x18, 0x00, 0x00,
+}
+
+func (this *Id128) Compare(that interface{}) int {
 	if that == nil {
 		if this == nil {
 			return 0
@@ -966,9 +2035,9 @@ func (this *LowIsolationReadResponse) Compare(that interface{}) int {
 		return 1
 	}
 
-	that1, ok := that.(*LowIsolationReadResponse)
+	that1, ok := that.(*Id128)
 	if !ok {
-		that2, ok := that.(LowIsolationReadResponse)
+		that2, ok := that.(Id128)
 		if ok {
 			that1 = &that2
 		} else {
@@ -983,36 +2052,14 @@ func (this *LowIsolationReadResponse) Compare(that interface{}) int {
 	} else if this == nil {
 		return -1
 	}
-	if len(this.Keys) != len(that1.Keys) {
-		if len(this.Keys) < len(that1.Keys) {
-			return -1
-		}
-		return 1
-	}
-	for i := range this.Keys {
-		if c := bytes.Compare(this.Keys[i], that1.Keys[i]); c != 0 {
-			return c
-		}
-	}
-	if len(this.Values) != len(that1.Values) {
-		if len(this.Values) < len(that1.Values) {
-			return -1
-		}
-		return 1
-	}
-	for i := range this.Values {
-		if c := bytes.Compare(this.Values[i], that1.Values[i]); c != 0 {
-			return c
-		}
-	}
-	if this.Term != that1.Term {
-		if this.Term < that1.Term {
+	if this.Upper != that1.Upper {
+		if this.Upper < that1.Upper {
 			return -1
 		}
 		return 1
 	}
-	if this.Index != that1.Index {
-		if this.Index < that1.Index {
+	if this.Lower != that1.Lower {
+		if this.Lower < that1.Lower {
 			return -1
 		}
 		return 1
@@ -1022,39 +2069,12 @@ func (this *LowIsolationReadResponse) Compare(that interface{}) int {
 	}
 	return 0
 }
-func (this *Id128) Equal(that interface{}) bool {
+func (this *Transaction) Compare(that interface{}) int {
 	if that == nil {
-		return this == nil
-	}
-
-	that1, ok := that.(*Id128)
-	if !ok {
-		that2, ok := that.(Id128)
-		if ok {
-			that1 = &that2
-		} else {
-			return false
+		if this == nil {
+			return 0
 		}
-	}
-	if that1 == nil {
-		return this == nil
-	} else if this == nil {
-		return false
-	}
-	if this.Upper != that1.Upper {
-		return false
-	}
-	if this.Lower != that1.Lower {
-		return false
-	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return false
-	}
-	return true
-}
-func (this *Transaction) Equal(that interface{}) bool {
-	if that == nil {
-		return this == nil
+		return 1
 	}
 
 	that1, ok := that.(*Transaction)
@@ -1063,113 +2083,327 @@ func (this *Transaction) Equal(that interface{}) bool {
 		if ok {
 			that1 = &that2
 		} else {
-			return false
+			return 1
 		}
 	}
 	if that1 == nil {
-		return this == nil
+		if this == nil {
+			return 0
+		}
+		return 1
 	} else if this == nil {
-		return false
+		return -1
 	}
 	if this.Type != that1.Type {
-		return false
+		if this.Type < that1.Type {
+			return -1
+		}
+		return 1
 	}
-	if !this.Id.Equal(that1.Id) {
-		return false
+	if c := this.Id.Compare(that1.Id); c != 0 {
+		return c
 	}
 	if len(this.ReadSet) != len(that1.ReadSet) {
-		return false
+		if len(this.ReadSet) < len(that1.ReadSet) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.ReadSet {
-		if !bytes.Equal(this.ReadSet[i], that1.ReadSet[i]) {
-			return false
+		if c := bytes.Compare(this.ReadSet[i], that1.ReadSet[i]); c != 0 {
+			return c
 		}
 	}
 	if len(this.ReadWriteSet) != len(that1.ReadWriteSet) {
-		return false
+		if len(this.ReadWriteSet) < len(that1.ReadWriteSet) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.ReadWriteSet {
-		if !bytes.Equal(this.ReadWriteSet[i], that1.ReadWriteSet[i]) {
-			return false
+		if c := bytes.Compare(this.ReadWriteSet[i], that1.ReadWriteSet[i]); c != 0 {
+			return c
 		}
 	}
 	if len(this.ReaderNodes) != len(that1.ReaderNodes) {
-		return false
+		if len(this.ReaderNodes) < len(that1.ReaderNodes) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.ReaderNodes {
 		if this.ReaderNodes[i] != that1.ReaderNodes[i] {
-			return false
+			if this.ReaderNodes[i] < that1.ReaderNodes[i] {
+				return -1
+			}
+			return 1
 		}
 	}
 	if len(this.WriterNodes) != len(that1.WriterNodes) {
-		return false
+		if len(this.WriterNodes) < len(that1.WriterNodes) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.WriterNodes {
 		if this.WriterNodes[i] != that1.WriterNodes[i] {
-			return false
+			if this.WriterNodes[i] < that1.WriterNodes[i] {
+				return -1
+			}
+			return 1
 		}
 	}
 	if this.StoredProcedure != that1.StoredProcedure {
-		return false
+		if this.StoredProcedure < that1.StoredProcedure {
+			return -1
+		}
+		return 1
 	}
 	if len(this.StoredProcedureArgs) != len(that1.StoredProcedureArgs) {
-		return false
+		if len(this.StoredProcedureArgs) < len(that1.StoredProcedureArgs) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.StoredProcedureArgs {
-		if !bytes.Equal(this.StoredProcedureArgs[i], that1.StoredProcedureArgs[i]) {
-			return false
+		if c := bytes.Compare(this.StoredProcedureArgs[i], that1.StoredProcedureArgs[i]); c != 0 {
+			return c
 		}
 	}
 	if this.IsLowIsolationRead != that1.IsLowIsolationRead {
-		return false
+		if !this.IsLowIsolationRead {
+			return -1
+		}
+		return 1
 	}
-	if !this.LowIsolationReadResponse.Equal(that1.LowIsolationReadResponse) {
-		return false
+	if c := this.LowIsolationReadResponse.Compare(that1.LowIsolationReadResponse); c != 0 {
+		return c
 	}
-	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
-		return false
+	if this.OriginNode != that1.OriginNode {
+		if this.OriginNode < that1.OriginNode {
+			return -1
+		}
+		return 1
 	}
-	return true
+	if this.BatchIndex != that1.BatchIndex {
+		if this.BatchIndex < that1.BatchIndex {
+			return -1
+		}
+		return 1
+	}
+	if this.BatchTerm != that1.BatchTerm {
+		if this.BatchTerm < that1.BatchTerm {
+			return -1
+		}
+		return 1
+	}
+	if c := this.Outcome.Compare(that1.Outcome); c != 0 {
+		return c
+	}
+	if this.StoredProcedureVersion != that1.StoredProcedureVersion {
+		if this.StoredProcedureVersion < that1.StoredProcedureVersion {
+			return -1
+		}
+		return 1
+	}
+	if this.BatchPosition != that1.BatchPosition {
+		if this.BatchPosition < that1.BatchPosition {
+			return -1
+		}
+		return 1
+	}
+	if this.Scans != that1.Scans {
+		if !this.Scans {
+			return -1
+		}
+		return 1
+	}
+	if c := bytes.Compare(this.XXX_unrecognized, that1.XXX_unrecognized); c != 0 {
+		return c
+	}
+	return 0
 }
-func (this *LowIsolationReadResponse) Equal(that interface{}) bool {
+func (this *TransactionOutcome) Compare(that interface{}) int {
 	if that == nil {
-		return this == nil
+		if this == nil {
+			return 0
+		}
+		return 1
 	}
 
-	that1, ok := that.(*LowIsolationReadResponse)
+	that1, ok := that.(*TransactionOutcome)
 	if !ok {
-		that2, ok := that.(LowIsolationReadResponse)
+		that2, ok := that.(TransactionOutcome)
 		if ok {
 			that1 = &that2
 		} else {
-			return false
+			return 1
 		}
 	}
 	if that1 == nil {
-		return this == nil
-	} else if this == nil {
-		return false
-	}
+		if this == nil {
+			return 0
+		}
+		return 1
+	} else if this == nil {
+		return -1
+	}
+	if c := this.TxnId.Compare(that1.TxnId); c != 0 {
+		return c
+	}
+	if this.Status != that1.Status {
+		if this.Status < that1.Status {
+			return -1
+		}
+		return 1
+	}
+	if this.Error != that1.Error {
+		if this.Error < that1.Error {
+			return -1
+		}
+		return 1
+	}
+	if this.BatchIndex != that1.BatchIndex {
+		if this.BatchIndex < that1.BatchIndex {
+			return -1
+		}
+		return 1
+	}
+	if this.BatchTerm != that1.BatchTerm {
+		if this.BatchTerm < that1.BatchTerm {
+			return -1
+		}
+		return 1
+	}
+	if this.NodeId != that1.NodeId {
+		if this.NodeId < that1.NodeId {
+			return -1
+		}
+		return 1
+	}
+	if len(this.WriterNodes) != len(that1.WriterNodes) {
+		if len(this.WriterNodes) < len(that1.WriterNodes) {
+			return -1
+		}
+		return 1
+	}
+	for i := range this.WriterNodes {
+		if this.WriterNodes[i] != that1.WriterNodes[i] {
+			if this.WriterNodes[i] < that1.WriterNodes[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	if c := bytes.Compare(this.Result, that1.Result); c != 0 {
+		return c
+	}
+	if c := bytes.Compare(this.XXX_unrecognized, that1.XXX_unrecognized); c != 0 {
+		return c
+	}
+	return 0
+}
+func (this *LowIsolationReadResponse) Compare(that interface{}) int {
+	if that == nil {
+		if this == nil {
+			return 0
+		}
+		return 1
+	}
+
+	that1, ok := that.(*LowIsolationReadResponse)
+	if !ok {
+		that2, ok := that.(LowIsolationReadResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return 1
+		}
+	}
+	if that1 == nil {
+		if this == nil {
+			return 0
+		}
+		return 1
+	} else if this == nil {
+		return -1
+	}
 	if len(this.Keys) != len(that1.Keys) {
-		return false
+		if len(this.Keys) < len(that1.Keys) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.Keys {
-		if !bytes.Equal(this.Keys[i], that1.Keys[i]) {
-			return false
+		if c := bytes.Compare(this.Keys[i], that1.Keys[i]); c != 0 {
+			return c
 		}
 	}
 	if len(this.Values) != len(that1.Values) {
-		return false
+		if len(this.Values) < len(that1.Values) {
+			return -1
+		}
+		return 1
 	}
 	for i := range this.Values {
-		if !bytes.Equal(this.Values[i], that1.Values[i]) {
-			return false
+		if c := bytes.Compare(this.Values[i], that1.Values[i]); c != 0 {
+			return c
 		}
 	}
 	if this.Term != that1.Term {
-		return false
+		if this.Term < that1.Term {
+			return -1
+		}
+		return 1
 	}
 	if this.Index != that1.Index {
+		if this.Index < that1.Index {
+			return -1
+		}
+		return 1
+	}
+	if len(this.Found) != len(that1.Found) {
+		if len(this.Found) < len(that1.Found) {
+			return -1
+		}
+		return 1
+	}
+	for i := range this.Found {
+		if this.Found[i] != that1.Found[i] {
+			if !this.Found[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	if c := bytes.Compare(this.XXX_unrecognized, that1.XXX_unrecognized); c != 0 {
+		return c
+	}
+	return 0
+}
+func (this *Id128) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*Id128)
+	if !ok {
+		that2, ok := that.(Id128)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Upper != that1.Upper {
+		return false
+	}
+	if this.Lower != that1.Lower {
 		return false
 	}
 	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
@@ -1177,195 +2411,510 @@ func (this *LowIsolationReadResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
-
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
-
-// LowIsolationReadClient is the client API for LowIsolationRead service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type LowIsolationReadClient interface {
-	LowIsolationRead(ctx context.Context, in *LowIsolationReadRequest, opts ...grpc.CallOption) (*LowIsolationReadResponse, error)
-}
-
-type lowIsolationReadClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewLowIsolationReadClient(cc *grpc.ClientConn) LowIsolationReadClient {
-	return &lowIsolationReadClient{cc}
-}
-
-func (c *lowIsolationReadClient) LowIsolationRead(ctx context.Context, in *LowIsolationReadRequest, opts ...grpc.CallOption) (*LowIsolationReadResponse, error) {
-	out := new(LowIsolationReadResponse)
-	err := c.cc.Invoke(ctx, "/pb.LowIsolationRead/LowIsolationRead", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (this *Transaction) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	return out, nil
-}
-
-// LowIsolationReadServer is the server API for LowIsolationRead service.
-type LowIsolationReadServer interface {
-	LowIsolationRead(context.Context, *LowIsolationReadRequest) (*LowIsolationReadResponse, error)
-}
-
-func RegisterLowIsolationReadServer(s *grpc.Server, srv LowIsolationReadServer) {
-	s.RegisterService(&_LowIsolationRead_serviceDesc, srv)
-}
 
-func _LowIsolationRead_LowIsolationRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(LowIsolationReadRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+	that1, ok := that.(*Transaction)
+	if !ok {
+		that2, ok := that.(Transaction)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	if interceptor == nil {
-		return srv.(LowIsolationReadServer).LowIsolationRead(ctx, in)
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.LowIsolationRead/LowIsolationRead",
+	if this.Type != that1.Type {
+		return false
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(LowIsolationReadServer).LowIsolationRead(ctx, req.(*LowIsolationReadRequest))
+	if !this.Id.Equal(that1.Id) {
+		return false
 	}
-	return interceptor(ctx, in, info, handler)
-}
-
-var _LowIsolationRead_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.LowIsolationRead",
-	HandlerType: (*LowIsolationReadServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "LowIsolationRead",
-			Handler:    _LowIsolationRead_LowIsolationRead_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "pb/calvin.proto",
-}
-
-// RemoteReadClient is the client API for RemoteRead service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type RemoteReadClient interface {
-	RemoteRead(ctx context.Context, in *RemoteReadRequest, opts ...grpc.CallOption) (*RemoteReadResponse, error)
-}
-
-type remoteReadClient struct {
-	cc *grpc.ClientConn
-}
-
-func NewRemoteReadClient(cc *grpc.ClientConn) RemoteReadClient {
-	return &remoteReadClient{cc}
-}
-
-func (c *remoteReadClient) RemoteRead(ctx context.Context, in *RemoteReadRequest, opts ...grpc.CallOption) (*RemoteReadResponse, error) {
-	out := new(RemoteReadResponse)
-	err := c.cc.Invoke(ctx, "/pb.RemoteRead/RemoteRead", in, out, opts...)
-	if err != nil {
-		return nil, err
+	if len(this.ReadSet) != len(that1.ReadSet) {
+		return false
 	}
-	return out, nil
-}
-
-// RemoteReadServer is the server API for RemoteRead service.
-type RemoteReadServer interface {
-	RemoteRead(context.Context, *RemoteReadRequest) (*RemoteReadResponse, error)
-}
-
-func RegisterRemoteReadServer(s *grpc.Server, srv RemoteReadServer) {
-	s.RegisterService(&_RemoteRead_serviceDesc, srv)
+	for i := range this.ReadSet {
+		if !bytes.Equal(this.ReadSet[i], that1.ReadSet[i]) {
+			return false
+		}
+	}
+	if len(this.ReadWriteSet) != len(that1.ReadWriteSet) {
+		return false
+	}
+	for i := range this.ReadWriteSet {
+		if !bytes.Equal(this.ReadWriteSet[i], that1.ReadWriteSet[i]) {
+			return false
+		}
+	}
+	if len(this.ReaderNodes) != len(that1.ReaderNodes) {
+		return false
+	}
+	for i := range this.ReaderNodes {
+		if this.ReaderNodes[i] != that1.ReaderNodes[i] {
+			return false
+		}
+	}
+	if len(this.WriterNodes) != len(that1.WriterNodes) {
+		return false
+	}
+	for i := range this.WriterNodes {
+		if this.WriterNodes[i] != that1.WriterNodes[i] {
+			return false
+		}
+	}
+	if this.StoredProcedure != that1.StoredProcedure {
+		return false
+	}
+	if len(this.StoredProcedureArgs) != len(that1.StoredProcedureArgs) {
+		return false
+	}
+	for i := range this.StoredProcedureArgs {
+		if !bytes.Equal(this.StoredProcedureArgs[i], that1.StoredProcedureArgs[i]) {
+			return false
+		}
+	}
+	if this.IsLowIsolationRead != that1.IsLowIsolationRead {
+		return false
+	}
+	if !this.LowIsolationReadResponse.Equal(that1.LowIsolationReadResponse) {
+		return false
+	}
+	if this.OriginNode != that1.OriginNode {
+		return false
+	}
+	if this.BatchIndex != that1.BatchIndex {
+		return false
+	}
+	if this.BatchTerm != that1.BatchTerm {
+		return false
+	}
+	if !this.Outcome.Equal(that1.Outcome) {
+		return false
+	}
+	if this.StoredProcedureVersion != that1.StoredProcedureVersion {
+		return false
+	}
+	if this.BatchPosition != that1.BatchPosition {
+		return false
+	}
+	if this.Scans != that1.Scans {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
 }
+func (this *TransactionOutcome) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
 
-func _RemoteRead_RemoteRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RemoteReadRequest)
+	that1, ok := that.(*TransactionOutcome)
+	if !ok {
+		that2, ok := that.(TransactionOutcome)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.TxnId.Equal(that1.TxnId) {
+		return false
+	}
+	if this.Status != that1.Status {
+		return false
+	}
+	if this.Error != that1.Error {
+		return false
+	}
+	if this.BatchIndex != that1.BatchIndex {
+		return false
+	}
+	if this.BatchTerm != that1.BatchTerm {
+		return false
+	}
+	if this.NodeId != that1.NodeId {
+		return false
+	}
+	if len(this.WriterNodes) != len(that1.WriterNodes) {
+		return false
+	}
+	for i := range this.WriterNodes {
+		if this.WriterNodes[i] != that1.WriterNodes[i] {
+			return false
+		}
+	}
+	if !bytes.Equal(this.Result, that1.Result) {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *LowIsolationReadResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*LowIsolationReadResponse)
+	if !ok {
+		that2, ok := that.(LowIsolationReadResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Keys) != len(that1.Keys) {
+		return false
+	}
+	for i := range this.Keys {
+		if !bytes.Equal(this.Keys[i], that1.Keys[i]) {
+			return false
+		}
+	}
+	if len(this.Values) != len(that1.Values) {
+		return false
+	}
+	for i := range this.Values {
+		if !bytes.Equal(this.Values[i], that1.Values[i]) {
+			return false
+		}
+	}
+	if this.Term != that1.Term {
+		return false
+	}
+	if this.Index != that1.Index {
+		return false
+	}
+	if len(this.Found) != len(that1.Found) {
+		return false
+	}
+	for i := range this.Found {
+		if this.Found[i] != that1.Found[i] {
+			return false
+		}
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TransactionOutcomesClient is the client API for TransactionOutcomes service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TransactionOutcomesClient interface {
+	ReportTransactionOutcomes(ctx context.Context, in *ReportTransactionOutcomesRequest, opts ...grpc.CallOption) (*ReportTransactionOutcomesResponse, error)
+}
+
+type transactionOutcomesClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTransactionOutcomesClient(cc *grpc.ClientConn) TransactionOutcomesClient {
+	return &transactionOutcomesClient{cc}
+}
+
+func (c *transactionOutcomesClient) ReportTransactionOutcomes(ctx context.Context, in *ReportTransactionOutcomesRequest, opts ...grpc.CallOption) (*ReportTransactionOutcomesResponse, error) {
+	out := new(ReportTransactionOutcomesResponse)
+	err := c.cc.Invoke(ctx, "/pb.TransactionOutcomes/ReportTransactionOutcomes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransactionOutcomesServer is the server API for TransactionOutcomes service.
+type TransactionOutcomesServer interface {
+	ReportTransactionOutcomes(context.Context, *ReportTransactionOutcomesRequest) (*ReportTransactionOutcomesResponse, error)
+}
+
+func RegisterTransactionOutcomesServer(s *grpc.Server, srv TransactionOutcomesServer) {
+	s.RegisterService(&_TransactionOutcomes_serviceDesc, srv)
+}
+
+func _TransactionOutcomes_ReportTransactionOutcomes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportTransactionOutcomesRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(RemoteReadServer).RemoteRead(ctx, in)
+		return srv.(TransactionOutcomesServer).ReportTransactionOutcomes(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/pb.RemoteRead/RemoteRead",
+		FullMethod: "/pb.TransactionOutcomes/ReportTransactionOutcomes",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(RemoteReadServer).RemoteRead(ctx, req.(*RemoteReadRequest))
+		return srv.(TransactionOutcomesServer).ReportTransactionOutcomes(ctx, req.(*ReportTransactionOutcomesRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _RemoteRead_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.RemoteRead",
-	HandlerType: (*RemoteReadServer)(nil),
+var _TransactionOutcomes_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.TransactionOutcomes",
+	HandlerType: (*TransactionOutcomesServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "RemoteRead",
-			Handler:    _RemoteRead_RemoteRead_Handler,
+			MethodName: "ReportTransactionOutcomes",
+			Handler:    _TransactionOutcomes_ReportTransactionOutcomes_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pb/calvin.proto",
 }
 
-// RaftTransportClient is the client API for RaftTransport service.
+// LowIsolationReadClient is the client API for LowIsolationRead service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type RaftTransportClient interface {
-	StepStream(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_StepStreamClient, error)
+type LowIsolationReadClient interface {
+	LowIsolationRead(ctx context.Context, in *LowIsolationReadRequest, opts ...grpc.CallOption) (*LowIsolationReadResponse, error)
 }
 
-type raftTransportClient struct {
+type lowIsolationReadClient struct {
 	cc *grpc.ClientConn
 }
 
-func NewRaftTransportClient(cc *grpc.ClientConn) RaftTransportClient {
-	return &raftTransportClient{cc}
+func NewLowIsolationReadClient(cc *grpc.ClientConn) LowIsolationReadClient {
+	return &lowIsolationReadClient{cc}
 }
 
-func (c *raftTransportClient) StepStream(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_StepStreamClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_RaftTransport_serviceDesc.Streams[0], "/pb.RaftTransport/StepStream", opts...)
+func (c *lowIsolationReadClient) LowIsolationRead(ctx context.Context, in *LowIsolationReadRequest, opts ...grpc.CallOption) (*LowIsolationReadResponse, error) {
+	out := new(LowIsolationReadResponse)
+	err := c.cc.Invoke(ctx, "/pb.LowIsolationRead/LowIsolationRead", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &raftTransportStepStreamClient{stream}
-	return x, nil
-}
-
-type RaftTransport_StepStreamClient interface {
-	Send(*StepRequest) error
-	Recv() (*StepResponse, error)
-	grpc.ClientStream
+	return out, nil
 }
 
-type raftTransportStepStreamClient struct {
-	grpc.ClientStream
+// LowIsolationReadServer is the server API for LowIsolationRead service.
+type LowIsolationReadServer interface {
+	LowIsolationRead(context.Context, *LowIsolationReadRequest) (*LowIsolationReadResponse, error)
 }
 
-func (x *raftTransportStepStreamClient) Send(m *StepRequest) error {
-	return x.ClientStream.SendMsg(m)
+func RegisterLowIsolationReadServer(s *grpc.Server, srv LowIsolationReadServer) {
+	s.RegisterService(&_LowIsolationRead_serviceDesc, srv)
 }
 
-func (x *raftTransportStepStreamClient) Recv() (*StepResponse, error) {
-	m := new(StepResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func _LowIsolationRead_LowIsolationRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LowIsolationReadRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return m, nil
-}
-
-// RaftTransportServer is the server API for RaftTransport service.
-type RaftTransportServer interface {
-	StepStream(RaftTransport_StepStreamServer) error
+	if interceptor == nil {
+		return srv.(LowIsolationReadServer).LowIsolationRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.LowIsolationRead/LowIsolationRead",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LowIsolationReadServer).LowIsolationRead(ctx, req.(*LowIsolationReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterRaftTransportServer(s *grpc.Server, srv RaftTransportServer) {
+var _LowIsolationRead_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.LowIsolationRead",
+	HandlerType: (*LowIsolationReadServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "LowIsolationRead",
+			Handler:    _LowIsolationRead_LowIsolationRead_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/calvin.proto",
+}
+
+// RemoteReadClient is the client API for RemoteRead service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RemoteReadClient interface {
+	RemoteRead(ctx context.Context, in *RemoteReadRequest, opts ...grpc.CallOption) (*RemoteReadResponse, error)
+}
+
+type remoteReadClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRemoteReadClient(cc *grpc.ClientConn) RemoteReadClient {
+	return &remoteReadClient{cc}
+}
+
+func (c *remoteReadClient) RemoteRead(ctx context.Context, in *RemoteReadRequest, opts ...grpc.CallOption) (*RemoteReadResponse, error) {
+	out := new(RemoteReadResponse)
+	err := c.cc.Invoke(ctx, "/pb.RemoteRead/RemoteRead", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RemoteReadServer is the server API for RemoteRead service.
+type RemoteReadServer interface {
+	RemoteRead(context.Context, *RemoteReadRequest) (*RemoteReadResponse, error)
+}
+
+func RegisterRemoteReadServer(s *grpc.Server, srv RemoteReadServer) {
+	s.RegisterService(&_RemoteRead_serviceDesc, srv)
+}
+
+func _RemoteRead_RemoteRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoteReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RemoteReadServer).RemoteRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RemoteRead/RemoteRead",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RemoteReadServer).RemoteRead(ctx, req.(*RemoteReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _RemoteRead_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.RemoteRead",
+	HandlerType: (*RemoteReadServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RemoteRead",
+			Handler:    _RemoteRead_RemoteRead_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/calvin.proto",
+}
+
+// RaftTransportClient is the client API for RaftTransport service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RaftTransportClient interface {
+	StepStream(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_StepStreamClient, error)
+	SnapshotStream(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_SnapshotStreamClient, error)
+	Propose(ctx context.Context, in *ProposeRequest, opts ...grpc.CallOption) (*ProposeResponse, error)
+}
+
+type raftTransportClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRaftTransportClient(cc *grpc.ClientConn) RaftTransportClient {
+	return &raftTransportClient{cc}
+}
+
+func (c *raftTransportClient) StepStream(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_StepStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RaftTransport_serviceDesc.Streams[0], "/pb.RaftTransport/StepStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &raftTransportStepStreamClient{stream}
+	return x, nil
+}
+
+type RaftTransport_StepStreamClient interface {
+	Send(*StepRequest) error
+	Recv() (*StepResponse, error)
+	grpc.ClientStream
+}
+
+type raftTransportStepStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *raftTransportStepStreamClient) Send(m *StepRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *raftTransportStepStreamClient) Recv() (*StepResponse, error) {
+	m := new(StepResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *raftTransportClient) SnapshotStream(ctx context.Context, opts ...grpc.CallOption) (RaftTransport_SnapshotStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RaftTransport_serviceDesc.Streams[1], "/pb.RaftTransport/SnapshotStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &raftTransportSnapshotStreamClient{stream}
+	return x, nil
+}
+
+type RaftTransport_SnapshotStreamClient interface {
+	Send(*SnapshotChunk) error
+	Recv() (*SnapshotChunkResponse, error)
+	grpc.ClientStream
+}
+
+type raftTransportSnapshotStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *raftTransportSnapshotStreamClient) Send(m *SnapshotChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *raftTransportSnapshotStreamClient) Recv() (*SnapshotChunkResponse, error) {
+	m := new(SnapshotChunkResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *raftTransportClient) Propose(ctx context.Context, in *ProposeRequest, opts ...grpc.CallOption) (*ProposeResponse, error) {
+	out := new(ProposeResponse)
+	err := c.cc.Invoke(ctx, "/pb.RaftTransport/Propose", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RaftTransportServer is the server API for RaftTransport service.
+type RaftTransportServer interface {
+	StepStream(RaftTransport_StepStreamServer) error
+	SnapshotStream(RaftTransport_SnapshotStreamServer) error
+	Propose(context.Context, *ProposeRequest) (*ProposeResponse, error)
+}
+
+func RegisterRaftTransportServer(s *grpc.Server, srv RaftTransportServer) {
 	s.RegisterService(&_RaftTransport_serviceDesc, srv)
 }
 
@@ -1395,10 +2944,59 @@ func (x *raftTransportStepStreamServer) Recv() (*StepRequest, error) {
 	return m, nil
 }
 
+func _RaftTransport_SnapshotStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RaftTransportServer).SnapshotStream(&raftTransportSnapshotStreamServer{stream})
+}
+
+type RaftTransport_SnapshotStreamServer interface {
+	Send(*SnapshotChunkResponse) error
+	Recv() (*SnapshotChunk, error)
+	grpc.ServerStream
+}
+
+type raftTransportSnapshotStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *raftTransportSnapshotStreamServer) Send(m *SnapshotChunkResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *raftTransportSnapshotStreamServer) Recv() (*SnapshotChunk, error) {
+	m := new(SnapshotChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _RaftTransport_Propose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RaftTransportServer).Propose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RaftTransport/Propose",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RaftTransportServer).Propose(ctx, req.(*ProposeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _RaftTransport_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.RaftTransport",
 	HandlerType: (*RaftTransportServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Propose",
+			Handler:    _RaftTransport_Propose_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "StepStream",
@@ -1406,6 +3004,12 @@ var _RaftTransport_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "SnapshotStream",
+			Handler:       _RaftTransport_SnapshotStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "pb/calvin.proto",
 }
@@ -1415,6 +3019,13 @@ var _RaftTransport_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type CalvinClient interface {
 	SubmitTransaction(ctx context.Context, in *SubmitTransactionRequest, opts ...grpc.CallOption) (*SubmitTransactionResponse, error)
+	RegisterStoredProcedure(ctx context.Context, in *RegisterStoredProcedureRequest, opts ...grpc.CallOption) (*RegisterStoredProcedureResponse, error)
+	SetCurrentStoredProcedure(ctx context.Context, in *SetCurrentStoredProcedureRequest, opts ...grpc.CallOption) (*SetCurrentStoredProcedureResponse, error)
+	RemoveStoredProcedure(ctx context.Context, in *RemoveStoredProcedureRequest, opts ...grpc.CallOption) (*RemoveStoredProcedureResponse, error)
+	ListStoredProcedures(ctx context.Context, in *ListStoredProceduresRequest, opts ...grpc.CallOption) (*ListStoredProceduresResponse, error)
+	AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*AddNodeResponse, error)
+	RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*RemoveNodeResponse, error)
+	PromoteLearner(ctx context.Context, in *PromoteLearnerRequest, opts ...grpc.CallOption) (*PromoteLearnerResponse, error)
 }
 
 type calvinClient struct {
@@ -1434,9 +3045,79 @@ func (c *calvinClient) SubmitTransaction(ctx context.Context, in *SubmitTransact
 	return out, nil
 }
 
+func (c *calvinClient) RegisterStoredProcedure(ctx context.Context, in *RegisterStoredProcedureRequest, opts ...grpc.CallOption) (*RegisterStoredProcedureResponse, error) {
+	out := new(RegisterStoredProcedureResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/RegisterStoredProcedure", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calvinClient) SetCurrentStoredProcedure(ctx context.Context, in *SetCurrentStoredProcedureRequest, opts ...grpc.CallOption) (*SetCurrentStoredProcedureResponse, error) {
+	out := new(SetCurrentStoredProcedureResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/SetCurrentStoredProcedure", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calvinClient) RemoveStoredProcedure(ctx context.Context, in *RemoveStoredProcedureRequest, opts ...grpc.CallOption) (*RemoveStoredProcedureResponse, error) {
+	out := new(RemoveStoredProcedureResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/RemoveStoredProcedure", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calvinClient) ListStoredProcedures(ctx context.Context, in *ListStoredProceduresRequest, opts ...grpc.CallOption) (*ListStoredProceduresResponse, error) {
+	out := new(ListStoredProceduresResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/ListStoredProcedures", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calvinClient) AddNode(ctx context.Context, in *AddNodeRequest, opts ...grpc.CallOption) (*AddNodeResponse, error) {
+	out := new(AddNodeResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/AddNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calvinClient) RemoveNode(ctx context.Context, in *RemoveNodeRequest, opts ...grpc.CallOption) (*RemoveNodeResponse, error) {
+	out := new(RemoveNodeResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/RemoveNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calvinClient) PromoteLearner(ctx context.Context, in *PromoteLearnerRequest, opts ...grpc.CallOption) (*PromoteLearnerResponse, error) {
+	out := new(PromoteLearnerResponse)
+	err := c.cc.Invoke(ctx, "/pb.Calvin/PromoteLearner", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CalvinServer is the server API for Calvin service.
 type CalvinServer interface {
 	SubmitTransaction(context.Context, *SubmitTransactionRequest) (*SubmitTransactionResponse, error)
+	RegisterStoredProcedure(context.Context, *RegisterStoredProcedureRequest) (*RegisterStoredProcedureResponse, error)
+	SetCurrentStoredProcedure(context.Context, *SetCurrentStoredProcedureRequest) (*SetCurrentStoredProcedureResponse, error)
+	RemoveStoredProcedure(context.Context, *RemoveStoredProcedureRequest) (*RemoveStoredProcedureResponse, error)
+	ListStoredProcedures(context.Context, *ListStoredProceduresRequest) (*ListStoredProceduresResponse, error)
+	AddNode(context.Context, *AddNodeRequest) (*AddNodeResponse, error)
+	RemoveNode(context.Context, *RemoveNodeRequest) (*RemoveNodeResponse, error)
+	PromoteLearner(context.Context, *PromoteLearnerRequest) (*PromoteLearnerResponse, error)
 }
 
 func RegisterCalvinServer(s *grpc.Server, srv CalvinServer) {
@@ -1461,27 +3142,181 @@ func _Calvin_SubmitTransaction_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Calvin_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "pb.Calvin",
-	HandlerType: (*CalvinServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "SubmitTransaction",
-			Handler:    _Calvin_SubmitTransaction_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "pb/calvin.proto",
-}
-
-func (m *SimpleSetterArg) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalTo(dAtA)
-	if err != nil {
+func _Calvin_RegisterStoredProcedure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterStoredProcedureRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return dAtA[:n], nil
+	if interceptor == nil {
+		return srv.(CalvinServer).RegisterStoredProcedure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/RegisterStoredProcedure",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).RegisterStoredProcedure(ctx, req.(*RegisterStoredProcedureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calvin_SetCurrentStoredProcedure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCurrentStoredProcedureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalvinServer).SetCurrentStoredProcedure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/SetCurrentStoredProcedure",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).SetCurrentStoredProcedure(ctx, req.(*SetCurrentStoredProcedureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calvin_RemoveStoredProcedure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveStoredProcedureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalvinServer).RemoveStoredProcedure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/RemoveStoredProcedure",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).RemoveStoredProcedure(ctx, req.(*RemoveStoredProcedureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calvin_ListStoredProcedures_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStoredProceduresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalvinServer).ListStoredProcedures(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/ListStoredProcedures",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).ListStoredProcedures(ctx, req.(*ListStoredProceduresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calvin_AddNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalvinServer).AddNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/AddNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).AddNode(ctx, req.(*AddNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calvin_RemoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalvinServer).RemoveNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/RemoveNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).RemoveNode(ctx, req.(*RemoveNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Calvin_PromoteLearner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteLearnerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalvinServer).PromoteLearner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Calvin/PromoteLearner",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalvinServer).PromoteLearner(ctx, req.(*PromoteLearnerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Calvin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.Calvin",
+	HandlerType: (*CalvinServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitTransaction",
+			Handler:    _Calvin_SubmitTransaction_Handler,
+		},
+		{
+			MethodName: "RegisterStoredProcedure",
+			Handler:    _Calvin_RegisterStoredProcedure_Handler,
+		},
+		{
+			MethodName: "SetCurrentStoredProcedure",
+			Handler:    _Calvin_SetCurrentStoredProcedure_Handler,
+		},
+		{
+			MethodName: "RemoveStoredProcedure",
+			Handler:    _Calvin_RemoveStoredProcedure_Handler,
+		},
+		{
+			MethodName: "ListStoredProcedures",
+			Handler:    _Calvin_ListStoredProcedures_Handler,
+		},
+		{
+			MethodName: "AddNode",
+			Handler:    _Calvin_AddNode_Handler,
+		},
+		{
+			MethodName: "RemoveNode",
+			Handler:    _Calvin_RemoveNode_Handler,
+		},
+		{
+			MethodName: "PromoteLearner",
+			Handler:    _Calvin_PromoteLearner_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/calvin.proto",
+}
+
+func (m *SimpleSetterArg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
 }
 
 func (m *SimpleSetterArg) MarshalTo(dAtA []byte) (int, error) {
@@ -1678,6 +3513,135 @@ func (m *Transaction) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n6
 	}
+	if m.OriginNode != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.OriginNode))
+	}
+	if m.BatchIndex != 0 {
+		dAtA[i] = 0x60
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchIndex))
+	}
+	if m.BatchTerm != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchTerm))
+	}
+	if m.Outcome != nil {
+		dAtA[i] = 0x72
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Outcome.Size()))
+		n7, err := m.Outcome.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n7
+	}
+	if m.StoredProcedureVersion != 0 {
+		dAtA[i] = 0x78
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.StoredProcedureVersion))
+	}
+	if m.BatchPosition != 0 {
+		dAtA[i] = 0x80
+		i++
+		dAtA[i] = 0x1
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchPosition))
+	}
+	if m.Scans {
+		dAtA[i] = 0x88
+		i++
+		dAtA[i] = 0x1
+		i++
+		if m.Scans {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *TransactionOutcome) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransactionOutcome) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.TxnId != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.TxnId.Size()))
+		n8, err := m.TxnId.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n8
+	}
+	if m.Status != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Status))
+	}
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.BatchIndex != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchIndex))
+	}
+	if m.BatchTerm != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchTerm))
+	}
+	if m.NodeId != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.NodeId))
+	}
+	if len(m.WriterNodes) > 0 {
+		dAtA10 := make([]byte, len(m.WriterNodes)*10)
+		var j9 int
+		for _, num := range m.WriterNodes {
+			for num >= 1<<7 {
+				dAtA10[j9] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j9++
+			}
+			dAtA10[j9] = uint8(num)
+			j9++
+		}
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(j9))
+		i += copy(dAtA[i:], dAtA10[:j9])
+	}
+	if len(m.Result) > 0 {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Result)))
+		i += copy(dAtA[i:], m.Result)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1708,11 +3672,11 @@ func (m *LowIsoRead) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0x12
 		i++
 		i = encodeVarintCalvin(dAtA, i, uint64(m.LowIsolationReadResponse.Size()))
-		n7, err := m.LowIsolationReadResponse.MarshalTo(dAtA[i:])
+		n11, err := m.LowIsolationReadResponse.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n7
+		i += n11
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -1747,13 +3711,60 @@ func (m *TransactionBatch) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.StoredProcedures) > 0 {
+		for _, msg := range m.StoredProcedures {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Index != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Index))
+	}
+	if m.Term != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Term))
+	}
+	if m.ReplaceStoredProcedures {
+		dAtA[i] = 0x28
+		i++
+		if m.ReplaceStoredProcedures {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Epoch != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Epoch))
+	}
+	if m.SequencerGroup != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.SequencerGroup))
+	}
+	if m.ProposalId != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.ProposalId))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func (m *LowIsolationReadRequest) Marshal() (dAtA []byte, err error) {
+func (m *StoredProcedure) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1763,26 +3774,149 @@ func (m *LowIsolationReadRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *LowIsolationReadRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *StoredProcedure) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.Keys) > 0 {
-		for _, b := range m.Keys {
-			dAtA[i] = 0xa
-			i++
-			i = encodeVarintCalvin(dAtA, i, uint64(len(b)))
-			i += copy(dAtA[i:], b)
-		}
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
 	}
-	if m.XXX_unrecognized != nil {
-		i += copy(dAtA[i:], m.XXX_unrecognized)
+	if len(m.Source) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Source)))
+		i += copy(dAtA[i:], m.Source)
 	}
-	return i, nil
-}
-
-func (m *LowIsolationReadResponse) Marshal() (dAtA []byte, err error) {
+	if m.Version != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Version))
+	}
+	if m.Op != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Op))
+	}
+	if m.Current {
+		dAtA[i] = 0x28
+		i++
+		if m.Current {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ReportTransactionOutcomesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReportTransactionOutcomesRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Outcomes) > 0 {
+		for _, msg := range m.Outcomes {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *ReportTransactionOutcomesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ReportTransactionOutcomesResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Error) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *LowIsolationReadRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LowIsolationReadRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, b := range m.Keys {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(len(b)))
+			i += copy(dAtA[i:], b)
+		}
+	}
+	if m.Linearizable {
+		dAtA[i] = 0x10
+		i++
+		if m.Linearizable {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *LowIsolationReadResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1823,6 +3957,19 @@ func (m *LowIsolationReadResponse) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintCalvin(dAtA, i, uint64(m.Index))
 	}
+	if len(m.Found) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Found)))
+		for _, b := range m.Found {
+			if b {
+				dAtA[i] = 1
+			} else {
+				dAtA[i] = 0
+			}
+			i++
+		}
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1848,11 +3995,11 @@ func (m *RemoteReadRequest) MarshalTo(dAtA []byte) (int, error) {
 		dAtA[i] = 0xa
 		i++
 		i = encodeVarintCalvin(dAtA, i, uint64(m.TxnId.Size()))
-		n8, err := m.TxnId.MarshalTo(dAtA[i:])
+		n12, err := m.TxnId.MarshalTo(dAtA[i:])
 		if err != nil {
 			return 0, err
 		}
-		i += n8
+		i += n12
 	}
 	if len(m.Keys) > 0 {
 		for _, b := range m.Keys {
@@ -1875,6 +4022,16 @@ func (m *RemoteReadRequest) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintCalvin(dAtA, i, uint64(m.TotalNumLocks))
 	}
+	if m.BatchIndex != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchIndex))
+	}
+	if m.BatchPosition != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.BatchPosition))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -1940,7 +4097,7 @@ func (m *RaftPeer) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *StepRequest) Marshal() (dAtA []byte, err error) {
+func (m *ProposeRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1950,25 +4107,36 @@ func (m *StepRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *StepRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *ProposeRequest) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if m.RaftNodeId != 0 {
+	if m.From != 0 {
 		dAtA[i] = 0x8
 		i++
-		i = encodeVarintCalvin(dAtA, i, uint64(m.RaftNodeId))
+		i = encodeVarintCalvin(dAtA, i, uint64(m.From))
 	}
-	if m.Message != nil {
+	if len(m.Data) > 0 {
 		dAtA[i] = 0x12
 		i++
-		i = encodeVarintCalvin(dAtA, i, uint64(m.Message.Size()))
-		n9, err := m.Message.MarshalTo(dAtA[i:])
-		if err != nil {
-			return 0, err
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Data)))
+		i += copy(dAtA[i:], m.Data)
+	}
+	if m.IsConfChange {
+		dAtA[i] = 0x18
+		i++
+		if m.IsConfChange {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
-		i += n9
+		i++
+	}
+	if m.GroupId != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.GroupId))
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -1976,7 +4144,7 @@ func (m *StepRequest) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *StepResponse) Marshal() (dAtA []byte, err error) {
+func (m *ProposeResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -1986,24 +4154,39 @@ func (m *StepResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *StepResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *ProposeResponse) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
+	if m.LeaderId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.LeaderId))
+	}
 	if len(m.Error) > 0 {
-		dAtA[i] = 0xa
+		dAtA[i] = 0x12
 		i++
 		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
 		i += copy(dAtA[i:], m.Error)
 	}
+	if m.Dropped {
+		dAtA[i] = 0x18
+		i++
+		if m.Dropped {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func (m *PartitionedSnapshot) Marshal() (dAtA []byte, err error) {
+func (m *StepRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -2013,35 +4196,30 @@ func (m *PartitionedSnapshot) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PartitionedSnapshot) MarshalTo(dAtA []byte) (int, error) {
+func (m *StepRequest) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
-	if len(m.PartitionIDs) > 0 {
-		dAtA11 := make([]byte, len(m.PartitionIDs)*10)
-		var j10 int
-		for _, num := range m.PartitionIDs {
-			for num >= 1<<7 {
-				dAtA11[j10] = uint8(uint64(num)&0x7f | 0x80)
-				num >>= 7
-				j10++
-			}
-			dAtA11[j10] = uint8(num)
-			j10++
-		}
-		dAtA[i] = 0xa
+	if m.RaftNodeId != 0 {
+		dAtA[i] = 0x8
 		i++
-		i = encodeVarintCalvin(dAtA, i, uint64(j10))
-		i += copy(dAtA[i:], dAtA11[:j10])
+		i = encodeVarintCalvin(dAtA, i, uint64(m.RaftNodeId))
 	}
-	if len(m.Snapshots) > 0 {
-		for _, b := range m.Snapshots {
-			dAtA[i] = 0x12
-			i++
-			i = encodeVarintCalvin(dAtA, i, uint64(len(b)))
-			i += copy(dAtA[i:], b)
+	if m.Message != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Message.Size()))
+		n13, err := m.Message.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n13
+	}
+	if m.GroupId != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.GroupId))
 	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
@@ -2049,7 +4227,7 @@ func (m *PartitionedSnapshot) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
-func (m *SubmitTransactionRequest) Marshal() (dAtA []byte, err error) {
+func (m *StepResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -2059,18 +4237,24 @@ func (m *SubmitTransactionRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SubmitTransactionRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *StepResponse) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
+	if len(m.Error) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func (m *SubmitTransactionResponse) Marshal() (dAtA []byte, err error) {
+func (m *SnapshotChunk) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalTo(dAtA)
@@ -2080,390 +4264,5053 @@ func (m *SubmitTransactionResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SubmitTransactionResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *SnapshotChunk) MarshalTo(dAtA []byte) (int, error) {
 	var i int
 	_ = i
 	var l int
 	_ = l
+	if m.Message != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Message.Size()))
+		n14, err := m.Message.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n14
+	}
+	if m.TotalSize != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.TotalSize))
+	}
+	if m.Checksum != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Checksum))
+	}
+	if m.Offset != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Offset))
+	}
+	if len(m.Data) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Data)))
+		i += copy(dAtA[i:], m.Data)
+	}
+	if m.ChunkChecksum != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.ChunkChecksum))
+	}
+	if m.GroupId != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.GroupId))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
 	return i, nil
 }
 
-func encodeVarintCalvin(dAtA []byte, offset int, v uint64) int {
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *SnapshotChunkResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return offset + 1
+	return dAtA[:n], nil
 }
-func (m *SimpleSetterArg) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *SnapshotChunkResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.Key)
-	if l > 0 {
-		n += 1 + l + sovCalvin(uint64(l))
-	}
-	l = len(m.Value)
-	if l > 0 {
-		n += 1 + l + sovCalvin(uint64(l))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if m.Offset != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Offset))
 	}
-	return n
-}
-
-func (m *Id128) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Upper != 0 {
-		n += 1 + sovCalvin(uint64(m.Upper))
+	if m.Installed {
+		dAtA[i] = 0x10
+		i++
+		if m.Installed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
 	}
-	if m.Lower != 0 {
-		n += 1 + sovCalvin(uint64(m.Lower))
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *BaseMessage) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Type != 0 {
-		n += 1 + sovCalvin(uint64(m.Type))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+func (m *RaftSnapshot) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *Transaction) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *RaftSnapshot) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.Type != 0 {
-		n += 1 + sovCalvin(uint64(m.Type))
-	}
-	if m.Id != nil {
-		l = m.Id.Size()
-		n += 1 + l + sovCalvin(uint64(l))
-	}
-	if len(m.ReadSet) > 0 {
-		for _, b := range m.ReadSet {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
-		}
-	}
-	if len(m.ReadWriteSet) > 0 {
-		for _, b := range m.ReadWriteSet {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
-		}
+	if len(m.Data) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Data)))
+		i += copy(dAtA[i:], m.Data)
 	}
-	if len(m.ReaderNodes) > 0 {
-		l = 0
-		for _, e := range m.ReaderNodes {
-			l += sovCalvin(uint64(e))
+	if len(m.StoredProcedures) > 0 {
+		for _, msg := range m.StoredProcedures {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
-		n += 1 + sovCalvin(uint64(l)) + l
 	}
-	if len(m.WriterNodes) > 0 {
-		l = 0
-		for _, e := range m.WriterNodes {
-			l += sovCalvin(uint64(e))
+	if len(m.Peers) > 0 {
+		for _, msg := range m.Peers {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
 		}
-		n += 1 + sovCalvin(uint64(l)) + l
 	}
-	l = len(m.StoredProcedure)
-	if l > 0 {
-		n += 1 + l + sovCalvin(uint64(l))
+	if m.Epoch != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Epoch))
 	}
-	if len(m.StoredProcedureArgs) > 0 {
-		for _, b := range m.StoredProcedureArgs {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
+	if m.WithoutData {
+		dAtA[i] = 0x28
+		i++
+		if m.WithoutData {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
+		i++
 	}
-	if m.IsLowIsolationRead {
-		n += 2
-	}
-	if m.LowIsolationReadResponse != nil {
-		l = m.LowIsolationReadResponse.Size()
-		n += 1 + l + sovCalvin(uint64(l))
+	if len(m.AppliedProposals) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.AppliedProposals)))
+		i += copy(dAtA[i:], m.AppliedProposals)
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *LowIsoRead) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *PartitionedSnapshot) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *PartitionedSnapshot) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.Type != 0 {
-		n += 1 + sovCalvin(uint64(m.Type))
+	if len(m.PartitionIDs) > 0 {
+		dAtA16 := make([]byte, len(m.PartitionIDs)*10)
+		var j15 int
+		for _, num := range m.PartitionIDs {
+			for num >= 1<<7 {
+				dAtA16[j15] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j15++
+			}
+			dAtA16[j15] = uint8(num)
+			j15++
+		}
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(j15))
+		i += copy(dAtA[i:], dAtA16[:j15])
 	}
-	if m.LowIsolationReadResponse != nil {
-		l = m.LowIsolationReadResponse.Size()
-		n += 1 + l + sovCalvin(uint64(l))
+	if len(m.Snapshots) > 0 {
+		for _, b := range m.Snapshots {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(len(b)))
+			i += copy(dAtA[i:], b)
+		}
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *TransactionBatch) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *SubmitTransactionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *SubmitTransactionRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if len(m.Transactions) > 0 {
-		for _, e := range m.Transactions {
-			l = e.Size()
-			n += 1 + l + sovCalvin(uint64(l))
+	if m.Txn != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Txn.Size()))
+		n17, err := m.Txn.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n17
+	}
+	if m.WaitForOutcome {
+		dAtA[i] = 0x10
+		i++
+		if m.WaitForOutcome {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *LowIsolationReadRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *SubmitTransactionResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *SubmitTransactionResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if len(m.Keys) > 0 {
-		for _, b := range m.Keys {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
+	if m.TxnId != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.TxnId.Size()))
+		n18, err := m.TxnId.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n18
+	}
+	if m.Accepted {
+		dAtA[i] = 0x10
+		i++
+		if m.Accepted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.Outcome != nil {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Outcome.Size()))
+		n19, err := m.Outcome.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
 		}
+		i += n19
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *LowIsolationReadResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *RegisterStoredProcedureRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *RegisterStoredProcedureRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if len(m.Keys) > 0 {
-		for _, b := range m.Keys {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
-		}
-	}
-	if len(m.Values) > 0 {
-		for _, b := range m.Values {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
-		}
-	}
-	if m.Term != 0 {
-		n += 1 + sovCalvin(uint64(m.Term))
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
 	}
-	if m.Index != 0 {
-		n += 1 + sovCalvin(uint64(m.Index))
+	if len(m.Source) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Source)))
+		i += copy(dAtA[i:], m.Source)
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *RemoteReadRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *RegisterStoredProcedureResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *RegisterStoredProcedureResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.TxnId != nil {
-		l = m.TxnId.Size()
-		n += 1 + l + sovCalvin(uint64(l))
-	}
-	if len(m.Keys) > 0 {
-		for _, b := range m.Keys {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
-		}
-	}
-	if len(m.Values) > 0 {
-		for _, b := range m.Values {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
+	if m.Accepted {
+		dAtA[i] = 0x8
+		i++
+		if m.Accepted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
 		}
+		i++
 	}
-	if m.TotalNumLocks != 0 {
-		n += 1 + sovCalvin(uint64(m.TotalNumLocks))
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *RemoteReadResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *SetCurrentStoredProcedureRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *SetCurrentStoredProcedureRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovCalvin(uint64(l))
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
+	}
+	if m.Version != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Version))
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *RaftPeer) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *SetCurrentStoredProcedureResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *SetCurrentStoredProcedureResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.RaftNodeId != 0 {
-		n += 1 + sovCalvin(uint64(m.RaftNodeId))
+	if m.Accepted {
+		dAtA[i] = 0x8
+		i++
+		if m.Accepted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
 	}
-	l = len(m.PeerAddress)
-	if l > 0 {
-		n += 1 + l + sovCalvin(uint64(l))
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *StepRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *RemoveStoredProcedureRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *RemoveStoredProcedureRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if m.RaftNodeId != 0 {
-		n += 1 + sovCalvin(uint64(m.RaftNodeId))
+	if len(m.Name) > 0 {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Name)))
+		i += copy(dAtA[i:], m.Name)
 	}
-	if m.Message != nil {
-		l = m.Message.Size()
-		n += 1 + l + sovCalvin(uint64(l))
+	if m.Version != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.Version))
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *StepResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *RemoveStoredProcedureResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *RemoveStoredProcedureResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	l = len(m.Error)
-	if l > 0 {
-		n += 1 + l + sovCalvin(uint64(l))
+	if m.Accepted {
+		dAtA[i] = 0x8
+		i++
+		if m.Accepted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
 	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *PartitionedSnapshot) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *ListStoredProceduresRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *ListStoredProceduresRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
-	if len(m.PartitionIDs) > 0 {
-		l = 0
-		for _, e := range m.PartitionIDs {
-			l += sovCalvin(uint64(e))
-		}
-		n += 1 + sovCalvin(uint64(l)) + l
-	}
-	if len(m.Snapshots) > 0 {
-		for _, b := range m.Snapshots {
-			l = len(b)
-			n += 1 + l + sovCalvin(uint64(l))
-		}
-	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *SubmitTransactionRequest) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *ListStoredProceduresResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *ListStoredProceduresResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
+	if len(m.Procedures) > 0 {
+		for _, msg := range m.Procedures {
+			dAtA[i] = 0xa
+			i++
+			i = encodeVarintCalvin(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
+	return i, nil
 }
 
-func (m *SubmitTransactionResponse) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *AddNodeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *AddNodeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
 	var l int
 	_ = l
+	if m.NodeId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.NodeId))
+	}
+	if len(m.Address) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Address)))
+		i += copy(dAtA[i:], m.Address)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *AddNodeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AddNodeResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Committed {
+		dAtA[i] = 0x8
+		i++
+		if m.Committed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *RemoveNodeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RemoveNodeRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.NodeId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.NodeId))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *RemoveNodeResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RemoveNodeResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Committed {
+		dAtA[i] = 0x8
+		i++
+		if m.Committed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *PromoteLearnerRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PromoteLearnerRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.NodeId != 0 {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(m.NodeId))
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func (m *PromoteLearnerResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PromoteLearnerResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Committed {
+		dAtA[i] = 0x8
+		i++
+		if m.Committed {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if len(m.Error) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintCalvin(dAtA, i, uint64(len(m.Error)))
+		i += copy(dAtA[i:], m.Error)
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
+func encodeVarintCalvin(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *SimpleSetterArg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *Id128) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Upper != 0 {
+		n += 1 + sovCalvin(uint64(m.Upper))
+	}
+	if m.Lower != 0 {
+		n += 1 + sovCalvin(uint64(m.Lower))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *BaseMessage) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovCalvin(uint64(m.Type))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
-	return n
-}
+	return n
+}
+
+func (m *Transaction) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovCalvin(uint64(m.Type))
+	}
+	if m.Id != nil {
+		l = m.Id.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if len(m.ReadSet) > 0 {
+		for _, b := range m.ReadSet {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if len(m.ReadWriteSet) > 0 {
+		for _, b := range m.ReadWriteSet {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if len(m.ReaderNodes) > 0 {
+		l = 0
+		for _, e := range m.ReaderNodes {
+			l += sovCalvin(uint64(e))
+		}
+		n += 1 + sovCalvin(uint64(l)) + l
+	}
+	if len(m.WriterNodes) > 0 {
+		l = 0
+		for _, e := range m.WriterNodes {
+			l += sovCalvin(uint64(e))
+		}
+		n += 1 + sovCalvin(uint64(l)) + l
+	}
+	l = len(m.StoredProcedure)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if len(m.StoredProcedureArgs) > 0 {
+		for _, b := range m.StoredProcedureArgs {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.IsLowIsolationRead {
+		n += 2
+	}
+	if m.LowIsolationReadResponse != nil {
+		l = m.LowIsolationReadResponse.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.OriginNode != 0 {
+		n += 1 + sovCalvin(uint64(m.OriginNode))
+	}
+	if m.BatchIndex != 0 {
+		n += 1 + sovCalvin(uint64(m.BatchIndex))
+	}
+	if m.BatchTerm != 0 {
+		n += 1 + sovCalvin(uint64(m.BatchTerm))
+	}
+	if m.Outcome != nil {
+		l = m.Outcome.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.StoredProcedureVersion != 0 {
+		n += 1 + sovCalvin(uint64(m.StoredProcedureVersion))
+	}
+	if m.BatchPosition != 0 {
+		n += 2 + sovCalvin(uint64(m.BatchPosition))
+	}
+	if m.Scans {
+		n += 3
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TransactionOutcome) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.TxnId != nil {
+		l = m.TxnId.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovCalvin(uint64(m.Status))
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.BatchIndex != 0 {
+		n += 1 + sovCalvin(uint64(m.BatchIndex))
+	}
+	if m.BatchTerm != 0 {
+		n += 1 + sovCalvin(uint64(m.BatchTerm))
+	}
+	if m.NodeId != 0 {
+		n += 1 + sovCalvin(uint64(m.NodeId))
+	}
+	if len(m.WriterNodes) > 0 {
+		l = 0
+		for _, e := range m.WriterNodes {
+			l += sovCalvin(uint64(e))
+		}
+		n += 1 + sovCalvin(uint64(l)) + l
+	}
+	l = len(m.Result)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LowIsoRead) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovCalvin(uint64(m.Type))
+	}
+	if m.LowIsolationReadResponse != nil {
+		l = m.LowIsolationReadResponse.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *TransactionBatch) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Transactions) > 0 {
+		for _, e := range m.Transactions {
+			l = e.Size()
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if len(m.StoredProcedures) > 0 {
+		for _, e := range m.StoredProcedures {
+			l = e.Size()
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.Index != 0 {
+		n += 1 + sovCalvin(uint64(m.Index))
+	}
+	if m.Term != 0 {
+		n += 1 + sovCalvin(uint64(m.Term))
+	}
+	if m.ReplaceStoredProcedures {
+		n += 2
+	}
+	if m.Epoch != 0 {
+		n += 1 + sovCalvin(uint64(m.Epoch))
+	}
+	if m.SequencerGroup != 0 {
+		n += 1 + sovCalvin(uint64(m.SequencerGroup))
+	}
+	if m.ProposalId != 0 {
+		n += 1 + sovCalvin(uint64(m.ProposalId))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StoredProcedure) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	l = len(m.Source)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Version != 0 {
+		n += 1 + sovCalvin(uint64(m.Version))
+	}
+	if m.Op != 0 {
+		n += 1 + sovCalvin(uint64(m.Op))
+	}
+	if m.Current {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ReportTransactionOutcomesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Outcomes) > 0 {
+		for _, e := range m.Outcomes {
+			l = e.Size()
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ReportTransactionOutcomesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LowIsolationReadRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, b := range m.Keys {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.Linearizable {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *LowIsolationReadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Keys) > 0 {
+		for _, b := range m.Keys {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if len(m.Values) > 0 {
+		for _, b := range m.Values {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.Term != 0 {
+		n += 1 + sovCalvin(uint64(m.Term))
+	}
+	if m.Index != 0 {
+		n += 1 + sovCalvin(uint64(m.Index))
+	}
+	if len(m.Found) > 0 {
+		n += 1 + sovCalvin(uint64(len(m.Found))) + len(m.Found)*1
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RemoteReadRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.TxnId != nil {
+		l = m.TxnId.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if len(m.Keys) > 0 {
+		for _, b := range m.Keys {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if len(m.Values) > 0 {
+		for _, b := range m.Values {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.TotalNumLocks != 0 {
+		n += 1 + sovCalvin(uint64(m.TotalNumLocks))
+	}
+	if m.BatchIndex != 0 {
+		n += 1 + sovCalvin(uint64(m.BatchIndex))
+	}
+	if m.BatchPosition != 0 {
+		n += 1 + sovCalvin(uint64(m.BatchPosition))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RemoteReadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RaftPeer) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.RaftNodeId != 0 {
+		n += 1 + sovCalvin(uint64(m.RaftNodeId))
+	}
+	l = len(m.PeerAddress)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProposeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.From != 0 {
+		n += 1 + sovCalvin(uint64(m.From))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.IsConfChange {
+		n += 2
+	}
+	if m.GroupId != 0 {
+		n += 1 + sovCalvin(uint64(m.GroupId))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ProposeResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.LeaderId != 0 {
+		n += 1 + sovCalvin(uint64(m.LeaderId))
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Dropped {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StepRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.RaftNodeId != 0 {
+		n += 1 + sovCalvin(uint64(m.RaftNodeId))
+	}
+	if m.Message != nil {
+		l = m.Message.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.GroupId != 0 {
+		n += 1 + sovCalvin(uint64(m.GroupId))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *StepResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Message != nil {
+		l = m.Message.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.TotalSize != 0 {
+		n += 1 + sovCalvin(uint64(m.TotalSize))
+	}
+	if m.Checksum != 0 {
+		n += 1 + sovCalvin(uint64(m.Checksum))
+	}
+	if m.Offset != 0 {
+		n += 1 + sovCalvin(uint64(m.Offset))
+	}
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.ChunkChecksum != 0 {
+		n += 1 + sovCalvin(uint64(m.ChunkChecksum))
+	}
+	if m.GroupId != 0 {
+		n += 1 + sovCalvin(uint64(m.GroupId))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SnapshotChunkResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Offset != 0 {
+		n += 1 + sovCalvin(uint64(m.Offset))
+	}
+	if m.Installed {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RaftSnapshot) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if len(m.StoredProcedures) > 0 {
+		for _, e := range m.StoredProcedures {
+			l = e.Size()
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if len(m.Peers) > 0 {
+		for _, e := range m.Peers {
+			l = e.Size()
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.Epoch != 0 {
+		n += 1 + sovCalvin(uint64(m.Epoch))
+	}
+	if m.WithoutData {
+		n += 2
+	}
+	l = len(m.AppliedProposals)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PartitionedSnapshot) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.PartitionIDs) > 0 {
+		l = 0
+		for _, e := range m.PartitionIDs {
+			l += sovCalvin(uint64(e))
+		}
+		n += 1 + sovCalvin(uint64(l)) + l
+	}
+	if len(m.Snapshots) > 0 {
+		for _, b := range m.Snapshots {
+			l = len(b)
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SubmitTransactionRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Txn != nil {
+		l = m.Txn.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.WaitForOutcome {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SubmitTransactionResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.TxnId != nil {
+		l = m.TxnId.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Accepted {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Outcome != nil {
+		l = m.Outcome.Size()
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RegisterStoredProcedureRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	l = len(m.Source)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RegisterStoredProcedureResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Accepted {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SetCurrentStoredProcedureRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Version != 0 {
+		n += 1 + sovCalvin(uint64(m.Version))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *SetCurrentStoredProcedureResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Accepted {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RemoveStoredProcedureRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.Version != 0 {
+		n += 1 + sovCalvin(uint64(m.Version))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RemoveStoredProcedureResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Accepted {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListStoredProceduresRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListStoredProceduresResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Procedures) > 0 {
+		for _, e := range m.Procedures {
+			l = e.Size()
+			n += 1 + l + sovCalvin(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AddNodeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.NodeId != 0 {
+		n += 1 + sovCalvin(uint64(m.NodeId))
+	}
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *AddNodeResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Committed {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RemoveNodeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.NodeId != 0 {
+		n += 1 + sovCalvin(uint64(m.NodeId))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RemoveNodeResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Committed {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PromoteLearnerRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.NodeId != 0 {
+		n += 1 + sovCalvin(uint64(m.NodeId))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *PromoteLearnerResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Committed {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovCalvin(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovCalvin(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozCalvin(x uint64) (n int) {
+	return sovCalvin(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *SimpleSetterArg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SimpleSetterArg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SimpleSetterArg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value[:0], dAtA[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Id128) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Id128: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Id128: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Upper", wireType)
+			}
+			m.Upper = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Upper |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Lower", wireType)
+			}
+			m.Lower = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Lower |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BaseMessage) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BaseMessage: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BaseMessage: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= MessageType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Transaction) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Transaction: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Transaction: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= MessageType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Id == nil {
+				m.Id = &Id128{}
+			}
+			if err := m.Id.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadSet", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ReadSet = append(m.ReadSet, make([]byte, postIndex-iNdEx))
+			copy(m.ReadSet[len(m.ReadSet)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadWriteSet", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ReadWriteSet = append(m.ReadWriteSet, make([]byte, postIndex-iNdEx))
+			copy(m.ReadWriteSet[len(m.ReadWriteSet)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.ReaderNodes = append(m.ReaderNodes, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.ReaderNodes) == 0 {
+					m.ReaderNodes = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCalvin
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.ReaderNodes = append(m.ReaderNodes, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReaderNodes", wireType)
+			}
+		case 6:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.WriterNodes = append(m.WriterNodes, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.WriterNodes) == 0 {
+					m.WriterNodes = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCalvin
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.WriterNodes = append(m.WriterNodes, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field WriterNodes", wireType)
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedure", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StoredProcedure = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedureArgs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StoredProcedureArgs = append(m.StoredProcedureArgs, make([]byte, postIndex-iNdEx))
+			copy(m.StoredProcedureArgs[len(m.StoredProcedureArgs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsLowIsolationRead", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsLowIsolationRead = bool(v != 0)
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LowIsolationReadResponse", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LowIsolationReadResponse == nil {
+				m.LowIsolationReadResponse = &LowIsolationReadResponse{}
+			}
+			if err := m.LowIsolationReadResponse.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OriginNode", wireType)
+			}
+			m.OriginNode = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OriginNode |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchIndex", wireType)
+			}
+			m.BatchIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchTerm", wireType)
+			}
+			m.BatchTerm = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchTerm |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Outcome", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Outcome == nil {
+				m.Outcome = &TransactionOutcome{}
+			}
+			if err := m.Outcome.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedureVersion", wireType)
+			}
+			m.StoredProcedureVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StoredProcedureVersion |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchPosition", wireType)
+			}
+			m.BatchPosition = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchPosition |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scans", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Scans = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransactionOutcome) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransactionOutcome: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransactionOutcome: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TxnId", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.TxnId == nil {
+				m.TxnId = &Id128{}
+			}
+			if err := m.TxnId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= TransactionStatus(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchIndex", wireType)
+			}
+			m.BatchIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchTerm", wireType)
+			}
+			m.BatchTerm = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchTerm |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeId", wireType)
+			}
+			m.NodeId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NodeId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.WriterNodes = append(m.WriterNodes, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.WriterNodes) == 0 {
+					m.WriterNodes = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCalvin
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.WriterNodes = append(m.WriterNodes, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field WriterNodes", wireType)
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Result", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Result = append(m.Result[:0], dAtA[iNdEx:postIndex]...)
+			if m.Result == nil {
+				m.Result = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LowIsoRead) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LowIsoRead: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LowIsoRead: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= MessageType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LowIsolationReadResponse", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LowIsolationReadResponse == nil {
+				m.LowIsolationReadResponse = &LowIsolationReadResponse{}
+			}
+			if err := m.LowIsolationReadResponse.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransactionBatch) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransactionBatch: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransactionBatch: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Transactions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Transactions = append(m.Transactions, &Transaction{})
+			if err := m.Transactions[len(m.Transactions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedures", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StoredProcedures = append(m.StoredProcedures, &StoredProcedure{})
+			if err := m.StoredProcedures[len(m.StoredProcedures)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			m.Term = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Term |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplaceStoredProcedures", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReplaceStoredProcedures = bool(v != 0)
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Epoch", wireType)
+			}
+			m.Epoch = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Epoch |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SequencerGroup", wireType)
+			}
+			m.SequencerGroup = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SequencerGroup |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProposalId", wireType)
+			}
+			m.ProposalId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProposalId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StoredProcedure) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StoredProcedure: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StoredProcedure: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Source = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			m.Op = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Op |= StoredProcedureOp(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Current", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Current = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReportTransactionOutcomesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReportTransactionOutcomesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReportTransactionOutcomesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Outcomes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Outcomes = append(m.Outcomes, &TransactionOutcome{})
+			if err := m.Outcomes[len(m.Outcomes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ReportTransactionOutcomesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ReportTransactionOutcomesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ReportTransactionOutcomesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LowIsolationReadRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LowIsolationReadRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LowIsolationReadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
+			copy(m.Keys[len(m.Keys)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Linearizable", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Linearizable = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LowIsolationReadResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LowIsolationReadResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LowIsolationReadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
+			copy(m.Keys[len(m.Keys)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, make([]byte, postIndex-iNdEx))
+			copy(m.Values[len(m.Values)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
+			}
+			m.Term = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Term |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+			}
+			m.Index = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Index |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType == 0 {
+				var v int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Found = append(m.Found, bool(v != 0))
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				elementCount = packedLen
+				if elementCount != 0 && len(m.Found) == 0 {
+					m.Found = make([]bool, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCalvin
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Found = append(m.Found, bool(v != 0))
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Found", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RemoteReadRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RemoteReadRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RemoteReadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TxnId", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.TxnId == nil {
+				m.TxnId = &Id128{}
+			}
+			if err := m.TxnId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
+			copy(m.Keys[len(m.Keys)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, make([]byte, postIndex-iNdEx))
+			copy(m.Values[len(m.Values)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalNumLocks", wireType)
+			}
+			m.TotalNumLocks = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalNumLocks |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchIndex", wireType)
+			}
+			m.BatchIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchIndex |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchPosition", wireType)
+			}
+			m.BatchPosition = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BatchPosition |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RemoteReadResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RemoteReadResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RemoteReadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RaftPeer) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RaftPeer: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RaftPeer: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RaftNodeId", wireType)
+			}
+			m.RaftNodeId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RaftNodeId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerAddress", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PeerAddress = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProposeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProposeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProposeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field From", wireType)
+			}
+			m.From = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.From |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IsConfChange", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.IsConfChange = bool(v != 0)
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupId |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ProposeResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ProposeResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ProposeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderId", wireType)
+			}
+			m.LeaderId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LeaderId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dropped", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Dropped = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StepRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StepRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StepRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RaftNodeId", wireType)
+			}
+			m.RaftNodeId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RaftNodeId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Message == nil {
+				m.Message = &raftpb.Message{}
+			}
+			if err := m.Message.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupId |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StepResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StepResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StepResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SnapshotChunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Message == nil {
+				m.Message = &raftpb.Message{}
+			}
+			if err := m.Message.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalSize", wireType)
+			}
+			m.TotalSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TotalSize |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksum", wireType)
+			}
+			m.Checksum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Checksum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
+			}
+			m.Offset = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Offset |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkChecksum", wireType)
+			}
+			m.ChunkChecksum = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkChecksum |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.GroupId |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
 
-func sovCalvin(x uint64) (n int) {
-	for {
-		n++
-		x >>= 7
-		if x == 0 {
-			break
-		}
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
-}
-func sozCalvin(x uint64) (n int) {
-	return sovCalvin(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *SimpleSetterArg) Unmarshal(dAtA []byte) error {
+func (m *SnapshotChunkResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2486,17 +9333,17 @@ func (m *SimpleSetterArg) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SimpleSetterArg: wiretype end group for non-group")
+			return fmt.Errorf("proto: SnapshotChunkResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SimpleSetterArg: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SnapshotChunkResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Offset", wireType)
 			}
-			var byteLen int
+			m.Offset = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -2506,31 +9353,36 @@ func (m *SimpleSetterArg) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				m.Offset |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Installed", wireType)
 			}
-			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
-			if m.Key == nil {
-				m.Key = []byte{}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			iNdEx = postIndex
-		case 2:
+			m.Installed = bool(v != 0)
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -2540,25 +9392,23 @@ func (m *SimpleSetterArg) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Value = append(m.Value[:0], dAtA[iNdEx:postIndex]...)
-			if m.Value == nil {
-				m.Value = []byte{}
-			}
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -2585,7 +9435,7 @@ func (m *SimpleSetterArg) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Id128) Unmarshal(dAtA []byte) error {
+func (m *RaftSnapshot) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2608,17 +9458,17 @@ func (m *Id128) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Id128: wiretype end group for non-group")
+			return fmt.Errorf("proto: RaftSnapshot: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Id128: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RaftSnapshot: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Upper", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
 			}
-			m.Upper = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -2628,16 +9478,99 @@ func (m *Id128) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Upper |= uint64(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
 		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedures", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StoredProcedures = append(m.StoredProcedures, &StoredProcedure{})
+			if err := m.StoredProcedures[len(m.StoredProcedures)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Peers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Peers = append(m.Peers, &RaftPeer{})
+			if err := m.Peers[len(m.Peers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Lower", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Epoch", wireType)
 			}
-			m.Lower = 0
+			m.Epoch = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -2647,11 +9580,65 @@ func (m *Id128) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Lower |= uint64(b&0x7F) << shift
+				m.Epoch |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WithoutData", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.WithoutData = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppliedProposals", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AppliedProposals = append(m.AppliedProposals[:0], dAtA[iNdEx:postIndex]...)
+			if m.AppliedProposals == nil {
+				m.AppliedProposals = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -2677,7 +9664,7 @@ func (m *Id128) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *BaseMessage) Unmarshal(dAtA []byte) error {
+func (m *PartitionedSnapshot) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2696,21 +9683,97 @@ func (m *BaseMessage) Unmarshal(dAtA []byte) error {
 			if b < 0x80 {
 				break
 			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: BaseMessage: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: BaseMessage: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PartitionedSnapshot: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PartitionedSnapshot: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.PartitionIDs = append(m.PartitionIDs, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowCalvin
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthCalvin
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.PartitionIDs) == 0 {
+					m.PartitionIDs = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowCalvin
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.PartitionIDs = append(m.PartitionIDs, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field PartitionIDs", wireType)
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Snapshots", wireType)
 			}
-			m.Type = 0
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -2720,11 +9783,24 @@ func (m *BaseMessage) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= MessageType(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Snapshots = append(m.Snapshots, make([]byte, postIndex-iNdEx))
+			copy(m.Snapshots[len(m.Snapshots)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -2750,7 +9826,7 @@ func (m *BaseMessage) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Transaction) Unmarshal(dAtA []byte) error {
+func (m *SubmitTransactionRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -2773,34 +9849,15 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Transaction: wiretype end group for non-group")
+			return fmt.Errorf("proto: SubmitTransactionRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Transaction: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SubmitTransactionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
-			}
-			m.Type = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowCalvin
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Type |= MessageType(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Txn", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -2827,18 +9884,18 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Id == nil {
-				m.Id = &Id128{}
+			if m.Txn == nil {
+				m.Txn = &Transaction{}
 			}
-			if err := m.Id.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.Txn.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReadSet", wireType)
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field WaitForOutcome", wireType)
 			}
-			var byteLen int
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -2848,211 +9905,125 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			m.WaitForOutcome = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ReadSet = append(m.ReadSet, make([]byte, postIndex-iNdEx))
-			copy(m.ReadSet[len(m.ReadSet)-1], dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 4:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReadWriteSet", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowCalvin
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthCalvin
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SubmitTransactionResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
 			}
-			if postIndex > l {
+			if iNdEx >= l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ReadWriteSet = append(m.ReadWriteSet, make([]byte, postIndex-iNdEx))
-			copy(m.ReadWriteSet[len(m.ReadWriteSet)-1], dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 5:
-			if wireType == 0 {
-				var v uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowCalvin
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					v |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				m.ReaderNodes = append(m.ReaderNodes, v)
-			} else if wireType == 2 {
-				var packedLen int
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowCalvin
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					packedLen |= int(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				if packedLen < 0 {
-					return ErrInvalidLengthCalvin
-				}
-				postIndex := iNdEx + packedLen
-				if postIndex < 0 {
-					return ErrInvalidLengthCalvin
-				}
-				if postIndex > l {
-					return io.ErrUnexpectedEOF
-				}
-				var elementCount int
-				var count int
-				for _, integer := range dAtA[iNdEx:postIndex] {
-					if integer < 128 {
-						count++
-					}
-				}
-				elementCount = count
-				if elementCount != 0 && len(m.ReaderNodes) == 0 {
-					m.ReaderNodes = make([]uint64, 0, elementCount)
-				}
-				for iNdEx < postIndex {
-					var v uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowCalvin
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						v |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					m.ReaderNodes = append(m.ReaderNodes, v)
-				}
-			} else {
-				return fmt.Errorf("proto: wrong wireType = %d for field ReaderNodes", wireType)
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
 			}
-		case 6:
-			if wireType == 0 {
-				var v uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowCalvin
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					v |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SubmitTransactionResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SubmitTransactionResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TxnId", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
 				}
-				m.WriterNodes = append(m.WriterNodes, v)
-			} else if wireType == 2 {
-				var packedLen int
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowCalvin
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					packedLen |= int(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
 				}
-				if packedLen < 0 {
-					return ErrInvalidLengthCalvin
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
-				postIndex := iNdEx + packedLen
-				if postIndex < 0 {
-					return ErrInvalidLengthCalvin
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.TxnId == nil {
+				m.TxnId = &Id128{}
+			}
+			if err := m.TxnId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Accepted", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
 				}
-				if postIndex > l {
+				if iNdEx >= l {
 					return io.ErrUnexpectedEOF
 				}
-				var elementCount int
-				var count int
-				for _, integer := range dAtA[iNdEx:postIndex] {
-					if integer < 128 {
-						count++
-					}
-				}
-				elementCount = count
-				if elementCount != 0 && len(m.WriterNodes) == 0 {
-					m.WriterNodes = make([]uint64, 0, elementCount)
-				}
-				for iNdEx < postIndex {
-					var v uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowCalvin
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						v |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					m.WriterNodes = append(m.WriterNodes, v)
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
-			} else {
-				return fmt.Errorf("proto: wrong wireType = %d for field WriterNodes", wireType)
 			}
-		case 7:
+			m.Accepted = bool(v != 0)
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedure", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3080,13 +10051,13 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.StoredProcedure = string(dAtA[iNdEx:postIndex])
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 8:
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field StoredProcedureArgs", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Outcome", wireType)
 			}
-			var byteLen int
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3096,29 +10067,87 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.StoredProcedureArgs = append(m.StoredProcedureArgs, make([]byte, postIndex-iNdEx))
-			copy(m.StoredProcedureArgs[len(m.StoredProcedureArgs)-1], dAtA[iNdEx:postIndex])
+			if m.Outcome == nil {
+				m.Outcome = &TransactionOutcome{}
+			}
+			if err := m.Outcome.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
-		case 9:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field IsLowIsolationRead", wireType)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			var v int
+			if skippy < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RegisterStoredProcedureRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RegisterStoredProcedureRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RegisterStoredProcedureRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3128,17 +10157,29 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				v |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			m.IsLowIsolationRead = bool(v != 0)
-		case 10:
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LowIsolationReadResponse", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3148,27 +10189,23 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.LowIsolationReadResponse == nil {
-				m.LowIsolationReadResponse = &LowIsolationReadResponse{}
-			}
-			if err := m.LowIsolationReadResponse.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Source = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3195,7 +10232,7 @@ func (m *Transaction) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LowIsoRead) Unmarshal(dAtA []byte) error {
+func (m *RegisterStoredProcedureResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3218,17 +10255,17 @@ func (m *LowIsoRead) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LowIsoRead: wiretype end group for non-group")
+			return fmt.Errorf("proto: RegisterStoredProcedureResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LowIsoRead: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RegisterStoredProcedureResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Accepted", wireType)
 			}
-			m.Type = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3238,16 +10275,17 @@ func (m *LowIsoRead) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Type |= MessageType(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			m.Accepted = bool(v != 0)
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field LowIsolationReadResponse", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3257,27 +10295,23 @@ func (m *LowIsoRead) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.LowIsolationReadResponse == nil {
-				m.LowIsolationReadResponse = &LowIsolationReadResponse{}
-			}
-			if err := m.LowIsolationReadResponse.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3304,7 +10338,7 @@ func (m *LowIsoRead) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TransactionBatch) Unmarshal(dAtA []byte) error {
+func (m *SetCurrentStoredProcedureRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3327,17 +10361,17 @@ func (m *TransactionBatch) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TransactionBatch: wiretype end group for non-group")
+			return fmt.Errorf("proto: SetCurrentStoredProcedureRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TransactionBatch: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SetCurrentStoredProcedureRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Transactions", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3347,26 +10381,43 @@ func (m *TransactionBatch) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Transactions = append(m.Transactions, &Transaction{})
-			if err := m.Transactions[len(m.Transactions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -3392,7 +10443,7 @@ func (m *TransactionBatch) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LowIsolationReadRequest) Unmarshal(dAtA []byte) error {
+func (m *SetCurrentStoredProcedureResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3415,17 +10466,37 @@ func (m *LowIsolationReadRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LowIsolationReadRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SetCurrentStoredProcedureResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LowIsolationReadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SetCurrentStoredProcedureResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Accepted", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Accepted = bool(v != 0)
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3435,23 +10506,23 @@ func (m *LowIsolationReadRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
-			copy(m.Keys[len(m.Keys)-1], dAtA[iNdEx:postIndex])
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3478,7 +10549,7 @@ func (m *LowIsolationReadRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *LowIsolationReadResponse) Unmarshal(dAtA []byte) error {
+func (m *RemoveStoredProcedureRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3501,17 +10572,17 @@ func (m *LowIsolationReadResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: LowIsolationReadResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: RemoveStoredProcedureRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: LowIsolationReadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RemoveStoredProcedureRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3521,80 +10592,29 @@ func (m *LowIsolationReadResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
-			copy(m.Keys[len(m.Keys)-1], dAtA[iNdEx:postIndex])
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowCalvin
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Values = append(m.Values, make([]byte, postIndex-iNdEx))
-			copy(m.Values[len(m.Values)-1], dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 3:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Term", wireType)
-			}
-			m.Term = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowCalvin
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.Term |= uint64(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-		case 4:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Index", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
 			}
-			m.Index = 0
+			m.Version = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3604,7 +10624,7 @@ func (m *LowIsolationReadResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Index |= uint64(b&0x7F) << shift
+				m.Version |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -3634,7 +10654,7 @@ func (m *LowIsolationReadResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RemoteReadRequest) Unmarshal(dAtA []byte) error {
+func (m *RemoveStoredProcedureResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3657,17 +10677,17 @@ func (m *RemoteReadRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RemoteReadRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: RemoveStoredProcedureResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RemoteReadRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RemoveStoredProcedureResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TxnId", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Accepted", wireType)
 			}
-			var msglen int
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3677,33 +10697,17 @@ func (m *RemoteReadRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			if m.TxnId == nil {
-				m.TxnId = &Id128{}
-			}
-			if err := m.TxnId.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
-			iNdEx = postIndex
+			m.Accepted = bool(v != 0)
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3713,75 +10717,78 @@ func (m *RemoteReadRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Keys = append(m.Keys, make([]byte, postIndex-iNdEx))
-			copy(m.Keys[len(m.Keys)-1], dAtA[iNdEx:postIndex])
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowCalvin
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCalvin(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			if byteLen < 0 {
+			if skippy < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
+			if (iNdEx + skippy) < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Values = append(m.Values, make([]byte, postIndex-iNdEx))
-			copy(m.Values[len(m.Values)-1], dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
-		case 4:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TotalNumLocks", wireType)
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListStoredProceduresRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCalvin
 			}
-			m.TotalNumLocks = 0
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowCalvin
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				m.TotalNumLocks |= uint32(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
 			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListStoredProceduresRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListStoredProceduresRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -3807,7 +10814,7 @@ func (m *RemoteReadRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RemoteReadResponse) Unmarshal(dAtA []byte) error {
+func (m *ListStoredProceduresResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3830,17 +10837,17 @@ func (m *RemoteReadResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RemoteReadResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: ListStoredProceduresResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RemoteReadResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ListStoredProceduresResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Procedures", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3850,23 +10857,25 @@ func (m *RemoteReadResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Error = string(dAtA[iNdEx:postIndex])
+			m.Procedures = append(m.Procedures, &StoredProcedure{})
+			if err := m.Procedures[len(m.Procedures)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3893,7 +10902,7 @@ func (m *RemoteReadResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *RaftPeer) Unmarshal(dAtA []byte) error {
+func (m *AddNodeRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -3916,17 +10925,17 @@ func (m *RaftPeer) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: RaftPeer: wiretype end group for non-group")
+			return fmt.Errorf("proto: AddNodeRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: RaftPeer: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AddNodeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RaftNodeId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeId", wireType)
 			}
-			m.RaftNodeId = 0
+			m.NodeId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -3936,14 +10945,14 @@ func (m *RaftPeer) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.RaftNodeId |= uint64(b&0x7F) << shift
+				m.NodeId |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PeerAddress", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -3971,7 +10980,7 @@ func (m *RaftPeer) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.PeerAddress = string(dAtA[iNdEx:postIndex])
+			m.Address = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -3998,7 +11007,7 @@ func (m *RaftPeer) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *StepRequest) Unmarshal(dAtA []byte) error {
+func (m *AddNodeResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4021,17 +11030,17 @@ func (m *StepRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: StepRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: AddNodeResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StepRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AddNodeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field RaftNodeId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Committed", wireType)
 			}
-			m.RaftNodeId = 0
+			var v int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -4041,16 +11050,17 @@ func (m *StepRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.RaftNodeId |= uint64(b&0x7F) << shift
+				v |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			m.Committed = bool(v != 0)
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -4060,27 +11070,23 @@ func (m *StepRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Message == nil {
-				m.Message = &raftpb.Message{}
-			}
-			if err := m.Message.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -4107,7 +11113,7 @@ func (m *StepRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *StepResponse) Unmarshal(dAtA []byte) error {
+func (m *RemoveNodeRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4130,17 +11136,17 @@ func (m *StepResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: StepResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: RemoveNodeRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: StepResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RemoveNodeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeId", wireType)
 			}
-			var stringLen uint64
+			m.NodeId = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -4150,24 +11156,11 @@ func (m *StepResponse) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.NodeId |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthCalvin
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Error = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -4193,7 +11186,7 @@ func (m *StepResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PartitionedSnapshot) Unmarshal(dAtA []byte) error {
+func (m *RemoveNodeResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4216,93 +11209,37 @@ func (m *PartitionedSnapshot) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PartitionedSnapshot: wiretype end group for non-group")
+			return fmt.Errorf("proto: RemoveNodeResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PartitionedSnapshot: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: RemoveNodeResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType == 0 {
-				var v uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowCalvin
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					v |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				m.PartitionIDs = append(m.PartitionIDs, v)
-			} else if wireType == 2 {
-				var packedLen int
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowCalvin
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					packedLen |= int(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				if packedLen < 0 {
-					return ErrInvalidLengthCalvin
-				}
-				postIndex := iNdEx + packedLen
-				if postIndex < 0 {
-					return ErrInvalidLengthCalvin
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Committed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
 				}
-				if postIndex > l {
+				if iNdEx >= l {
 					return io.ErrUnexpectedEOF
 				}
-				var elementCount int
-				var count int
-				for _, integer := range dAtA[iNdEx:postIndex] {
-					if integer < 128 {
-						count++
-					}
-				}
-				elementCount = count
-				if elementCount != 0 && len(m.PartitionIDs) == 0 {
-					m.PartitionIDs = make([]uint64, 0, elementCount)
-				}
-				for iNdEx < postIndex {
-					var v uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowCalvin
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						v |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					m.PartitionIDs = append(m.PartitionIDs, v)
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
 				}
-			} else {
-				return fmt.Errorf("proto: wrong wireType = %d for field PartitionIDs", wireType)
 			}
+			m.Committed = bool(v != 0)
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Snapshots", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
 			}
-			var byteLen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowCalvin
@@ -4312,23 +11249,23 @@ func (m *PartitionedSnapshot) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				byteLen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if byteLen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthCalvin
 			}
-			postIndex := iNdEx + byteLen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthCalvin
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Snapshots = append(m.Snapshots, make([]byte, postIndex-iNdEx))
-			copy(m.Snapshots[len(m.Snapshots)-1], dAtA[iNdEx:postIndex])
+			m.Error = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -4355,7 +11292,7 @@ func (m *PartitionedSnapshot) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SubmitTransactionRequest) Unmarshal(dAtA []byte) error {
+func (m *PromoteLearnerRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4378,12 +11315,31 @@ func (m *SubmitTransactionRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SubmitTransactionRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: PromoteLearnerRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SubmitTransactionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PromoteLearnerRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NodeId", wireType)
+			}
+			m.NodeId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NodeId |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -4409,7 +11365,7 @@ func (m *SubmitTransactionRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SubmitTransactionResponse) Unmarshal(dAtA []byte) error {
+func (m *PromoteLearnerResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4432,12 +11388,64 @@ func (m *SubmitTransactionResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SubmitTransactionResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: PromoteLearnerResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SubmitTransactionResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PromoteLearnerResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Committed", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Committed = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCalvin
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCalvin
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCalvin(dAtA[iNdEx:])
@@ -16,7 +16,10 @@
 
 package pb
 
-import fmt "fmt"
+import (
+	"encoding/json"
+	fmt "fmt"
+)
 
 func (m *Transaction) AddSimpleSetterArg(key []byte, value []byte) error {
 	if m.StoredProcedure == "" {
@@ -40,3 +43,11 @@ func (m *Transaction) AddSimpleSetterArg(key []byte, value []byte) error {
 	m.StoredProcedureArgs = append(m.StoredProcedureArgs, bites)
 	return nil
 }
+
+// UnmarshalResult decodes the value the stored procedure returned into v.
+func (m *TransactionOutcome) UnmarshalResult(v interface{}) error {
+	if len(m.Result) == 0 {
+		return fmt.Errorf("txn outcome doesn't carry a result")
+	}
+	return json.Unmarshal(m.Result, v)
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/mhelmich/calvin/pb"
+)
+
+func newBatchBoundary() *batchBoundary {
+	return &batchBoundary{
+		mutex: &sync.Mutex{},
+	}
+}
+
+// a batch boundary is a point at which all batches up to an index are done
+// and no txn of a later batch did anything yet
+// txns of later batches that become ready are held back while a boundary is active
+// txns are handed out in log order per key and held txns don't block anything before the boundary
+type batchBoundary struct {
+	mutex *sync.Mutex
+	// position of the last batch that was scheduled
+	lastIndex uint64
+	lastTerm  uint64
+	active    bool
+	index     uint64
+	held      []*pb.Transaction
+}
+
+func (bb *batchBoundary) scheduled(batch *pb.TransactionBatch) {
+	bb.mutex.Lock()
+	defer bb.mutex.Unlock()
+	if batch.Index > bb.lastIndex {
+		bb.lastIndex = batch.Index
+		bb.lastTerm = batch.Term
+	}
+}
+
+// returns true if the txn needs to wait for the boundary to be over
+func (bb *batchBoundary) hold(txn *pb.Transaction) bool {
+	bb.mutex.Lock()
+	defer bb.mutex.Unlock()
+	if !bb.active || txn.BatchIndex <= bb.index {
+		return false
+	}
+
+	bb.held = append(bb.held, txn)
+	return true
+}
+
+// the boundary is after the last batch that was scheduled
+// txns of all batches after that haven't been handed out yet
+func (bb *batchBoundary) start() (uint64, uint64) {
+	bb.mutex.Lock()
+	defer bb.mutex.Unlock()
+	bb.active = true
+	bb.index = bb.lastIndex
+	return bb.lastIndex, bb.lastTerm
+}
+
+// returns the txns that were held back in the order they became ready
+func (bb *batchBoundary) stop() []*pb.Transaction {
+	bb.mutex.Lock()
+	defer bb.mutex.Unlock()
+	held := bb.held
+	bb.active = false
+	bb.held = nil
+	return held
+}
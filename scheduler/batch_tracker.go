@@ -0,0 +1,170 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/mhelmich/calvin/pb"
+)
+
+func newBatchTracker(checkpointChan chan uint64) *batchTracker {
+	return &batchTracker{
+		mutex:          &sync.Mutex{},
+		outstanding:    make(map[uint64]int),
+		terms:          make(map[uint64]uint64),
+		checkpointChan: checkpointChan,
+	}
+}
+
+// the batch tracker figures out up to which batch all txns are done executing on this node
+// txns of different batches finish out of order
+// the tracker only advances once all batches before a batch are done as well
+type batchTracker struct {
+	mutex *sync.Mutex
+	// indexes of all batches that aren't done yet in log order
+	batches []uint64
+	// number of txns per batch that aren't done yet
+	outstanding map[uint64]int
+	// terms of all batches that aren't done yet
+	terms          map[uint64]uint64
+	lastIndex      uint64
+	checkpointChan chan uint64
+	// index and term of the last batch that is done along with all batches before it
+	doneIndex uint64
+	doneTerm  uint64
+	waiters   []batchWaiter
+}
+
+type batchWaiter struct {
+	index uint64
+	c     chan struct{}
+}
+
+// returns a channel that is closed once all batches up to the provided index are done
+func (bt *batchTracker) waitFor(index uint64) <-chan struct{} {
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	c := make(chan struct{})
+	if index <= bt.doneIndex {
+		close(c)
+		return c
+	}
+
+	bt.waiters = append(bt.waiters, batchWaiter{
+		index: index,
+		c:     c,
+	})
+	return c
+}
+
+// waiters that gave up are removed right away
+// otherwise they would stay around until their batch is done
+func (bt *batchTracker) cancelWait(c <-chan struct{}) {
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	for idx := range bt.waiters {
+		if bt.waiters[idx].c == c {
+			bt.waiters = append(bt.waiters[:idx], bt.waiters[idx+1:]...)
+			return
+		}
+	}
+}
+
+// returns index and term of the last batch that is done along with all batches before it
+func (bt *batchTracker) lastDone() (uint64, uint64) {
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	return bt.doneIndex, bt.doneTerm
+}
+
+func (bt *batchTracker) scheduled(batch *pb.TransactionBatch) {
+	// batches that don't come out of the log (low isolation reads) don't count
+	if batch.Index == 0 {
+		return
+	}
+
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	if batch.Index <= bt.lastIndex {
+		return
+	}
+
+	bt.lastIndex = batch.Index
+	bt.batches = append(bt.batches, batch.Index)
+	bt.outstanding[batch.Index] = len(batch.Transactions)
+	bt.terms[batch.Index] = batch.Term
+	bt.advance()
+}
+
+func (bt *batchTracker) done(txn *pb.Transaction) {
+	if txn.BatchIndex == 0 {
+		return
+	}
+
+	bt.mutex.Lock()
+	defer bt.mutex.Unlock()
+	n, ok := bt.outstanding[txn.BatchIndex]
+	if !ok {
+		return
+	}
+
+	bt.outstanding[txn.BatchIndex] = n - 1
+	bt.advance()
+}
+
+// needs to be called with the lock held
+func (bt *batchTracker) advance() {
+	var checkpoint uint64
+	var term uint64
+	for len(bt.batches) > 0 && bt.outstanding[bt.batches[0]] <= 0 {
+		checkpoint = bt.batches[0]
+		term = bt.terms[checkpoint]
+		delete(bt.outstanding, checkpoint)
+		delete(bt.terms, checkpoint)
+		bt.batches = bt.batches[1:]
+	}
+
+	if checkpoint == 0 {
+		return
+	}
+
+	bt.doneIndex = checkpoint
+	bt.doneTerm = term
+	waiters := bt.waiters[:0]
+	for idx := range bt.waiters {
+		if bt.waiters[idx].index <= checkpoint {
+			close(bt.waiters[idx].c)
+		} else {
+			waiters = append(waiters, bt.waiters[idx])
+		}
+	}
+	bt.waiters = waiters
+
+	if bt.checkpointChan == nil {
+		return
+	}
+
+	// only the latest checkpoint matters
+	// drop the one that wasn't picked up yet
+	// this is safe because all sends happen under the lock
+	select {
+	case <-bt.checkpointChan:
+	default:
+	}
+	bt.checkpointChan <- checkpoint
+}
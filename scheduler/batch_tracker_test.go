@@ -0,0 +1,74 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchTrackerOutOfOrder(t *testing.T) {
+	checkpointChan := make(chan uint64, 1)
+	bt := newBatchTracker(checkpointChan)
+
+	txn1 := &pb.Transaction{BatchIndex: uint64(3)}
+	txn2 := &pb.Transaction{BatchIndex: uint64(3)}
+	txn3 := &pb.Transaction{BatchIndex: uint64(5)}
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(3), Term: uint64(1), Transactions: []*pb.Transaction{txn1, txn2}})
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(5), Term: uint64(2), Transactions: []*pb.Transaction{txn3}})
+
+	// the later batch finishing doesn't move the checkpoint
+	bt.done(txn3)
+	bt.done(txn1)
+	assert.Equal(t, 0, len(checkpointChan))
+
+	bt.done(txn2)
+	assert.Equal(t, uint64(5), <-checkpointChan)
+	index, term := bt.lastDone()
+	assert.Equal(t, uint64(5), index)
+	assert.Equal(t, uint64(2), term)
+
+	// batches without txns are done right away
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(6)})
+	assert.Equal(t, uint64(6), <-checkpointChan)
+
+	// batches that weren't sequenced and batches that were seen already don't count
+	bt.scheduled(&pb.TransactionBatch{Transactions: []*pb.Transaction{&pb.Transaction{}}})
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(4)})
+	bt.done(&pb.Transaction{})
+	assert.Equal(t, 0, len(checkpointChan))
+
+	// only the latest checkpoint is kept
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(7)})
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(8)})
+	assert.Equal(t, 1, len(checkpointChan))
+	assert.Equal(t, uint64(8), <-checkpointChan)
+}
+
+func TestBatchTrackerCancelWait(t *testing.T) {
+	bt := newBatchTracker(nil)
+	c1 := bt.waitFor(uint64(3))
+	c2 := bt.waitFor(uint64(3))
+	bt.cancelWait(c1)
+	assert.Equal(t, 1, len(bt.waiters))
+
+	bt.scheduled(&pb.TransactionBatch{Index: uint64(3)})
+	<-c2
+	assert.Equal(t, 0, len(bt.waiters))
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"github.com/mhelmich/calvin/pb"
+)
+
+func newEpochMerger(numGroups int) *epochMerger {
+	return &epochMerger{
+		numGroups:  numGroups,
+		lastEpochs: make([]uint64, numGroups),
+		queues:     make([][]*pb.TransactionBatch, numGroups),
+	}
+}
+
+// every sequencer group publishes batches with increasing epochs
+// the groups don't wait for each other and their batches arrive in any order
+// the merger hands them out ordered by epoch and by group within an epoch
+// that order is the same on all nodes
+// a batch is handed out once no group can come up with a batch that goes before it anymore
+type epochMerger struct {
+	numGroups int
+	// the epoch of the last batch that arrived from each group
+	lastEpochs []uint64
+	// batches per group that are waiting for the other groups
+	queues [][]*pb.TransactionBatch
+}
+
+// returns the batches that are ready to be scheduled in merged order
+// batches without an epoch don't come out of the log and are handed out right away
+func (m *epochMerger) add(batch *pb.TransactionBatch) []*pb.TransactionBatch {
+	if m.numGroups <= 1 || batch.Epoch == 0 {
+		return []*pb.TransactionBatch{batch}
+	}
+
+	group := int(batch.SequencerGroup)
+	if batch.Epoch <= m.lastEpochs[group] {
+		// the group restarted or installed a snapshot at an epoch that was handed out already
+		// only the stored procedures in there are of interest
+		if len(batch.StoredProcedures) > 0 || batch.ReplaceStoredProcedures {
+			return []*pb.TransactionBatch{batch}
+		}
+		return nil
+	}
+
+	m.lastEpochs[group] = batch.Epoch
+	m.queues[group] = append(m.queues[group], batch)
+	return m.takeReady()
+}
+
+func (m *epochMerger) takeReady() []*pb.TransactionBatch {
+	ready := make([]*pb.TransactionBatch, 0)
+	for {
+		next := -1
+		for group := range m.queues {
+			if len(m.queues[group]) > 0 && (next < 0 || m.queues[group][0].Epoch < m.queues[next][0].Epoch) {
+				next = group
+			}
+		}
+
+		if next < 0 || !m.isReady(next) {
+			return ready
+		}
+
+		ready = append(ready, m.queues[next][0])
+		m.queues[next] = m.queues[next][1:]
+	}
+}
+
+// groups without waiting batches can still come up with a batch at the next epoch after their last one
+func (m *epochMerger) isReady(group int) bool {
+	epoch := m.queues[group][0].Epoch
+	for other := range m.queues {
+		if len(m.queues[other]) > 0 {
+			continue
+		}
+
+		nextEpoch := m.lastEpochs[other] + 1
+		if nextEpoch < epoch || (nextEpoch == epoch && other < group) {
+			return false
+		}
+	}
+	return true
+}
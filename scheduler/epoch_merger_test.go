@@ -0,0 +1,89 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEpochMergerBasic(t *testing.T) {
+	m := newEpochMerger(3)
+
+	// the other groups could still come up with something at epoch 1
+	assert.Equal(t, 0, len(m.add(&pb.TransactionBatch{Epoch: 1, SequencerGroup: 2})))
+	assert.Equal(t, 0, len(m.add(&pb.TransactionBatch{Epoch: 2, SequencerGroup: 1})))
+	assert.Equal(t, 0, len(m.add(&pb.TransactionBatch{Epoch: 4, SequencerGroup: 2})))
+
+	// batches that don't come out of the log aren't held back
+	batches := m.add(&pb.TransactionBatch{})
+	assert.Equal(t, 1, len(batches))
+
+	// group 0 skipped epoch 1
+	// group 1 can't go back to epoch 1 anymore
+	batches = m.add(&pb.TransactionBatch{Epoch: 3, SequencerGroup: 0})
+	assertMergedOrder(t, [][]uint64{{1, 2}, {2, 1}, {3, 0}}, batches)
+
+	// group 1 needs to get past epoch 4 before epoch 4 of group 2 can go
+	batches = m.add(&pb.TransactionBatch{Epoch: 5, SequencerGroup: 0})
+	assert.Equal(t, 0, len(batches))
+	batches = m.add(&pb.TransactionBatch{Epoch: 4, SequencerGroup: 1})
+	// nothing of group 1 can go before epoch 5 of group 0 anymore
+	assertMergedOrder(t, [][]uint64{{4, 1}, {4, 2}, {5, 0}}, batches)
+	batches = m.add(&pb.TransactionBatch{Epoch: 5, SequencerGroup: 1})
+	assertMergedOrder(t, [][]uint64{{5, 1}}, batches)
+	// group 0 could still come up with epoch 6
+	batches = m.add(&pb.TransactionBatch{Epoch: 6, SequencerGroup: 2})
+	assert.Equal(t, 0, len(batches))
+}
+
+func TestEpochMergerOldEpochs(t *testing.T) {
+	m := newEpochMerger(2)
+	batches := m.add(&pb.TransactionBatch{Epoch: 3, SequencerGroup: 0})
+	assert.Equal(t, 0, len(batches))
+	batches = m.add(&pb.TransactionBatch{Epoch: 3, SequencerGroup: 1})
+	assertMergedOrder(t, [][]uint64{{3, 0}, {3, 1}}, batches)
+
+	// a group that restarted at an epoch that was handed out already
+	batches = m.add(&pb.TransactionBatch{Epoch: 3, SequencerGroup: 1})
+	assert.Equal(t, 0, len(batches))
+	// the procedures of a snapshot are never dropped
+	batches = m.add(&pb.TransactionBatch{Epoch: 2, SequencerGroup: 0, ReplaceStoredProcedures: true})
+	assert.Equal(t, 1, len(batches))
+}
+
+func TestEpochMergerSingleGroup(t *testing.T) {
+	m := newEpochMerger(1)
+	for epoch := uint64(5); epoch > 0; epoch-- {
+		batches := m.add(&pb.TransactionBatch{Epoch: epoch})
+		assert.Equal(t, 1, len(batches))
+		assert.Equal(t, epoch, batches[0].Epoch)
+	}
+}
+
+// expected holds epoch and group of each batch
+func assertMergedOrder(t *testing.T, expected [][]uint64, batches []*pb.TransactionBatch) {
+	if !assert.Equal(t, len(expected), len(batches)) {
+		return
+	}
+	for idx := range batches {
+		assert.Equal(t, expected[idx][0], batches[idx].Epoch)
+		assert.Equal(t, uint32(expected[idx][1]), batches[idx].SequencerGroup)
+	}
+}
@@ -167,7 +167,7 @@ func (lm *lockManager) innerLockSingleKey(txn *pb.Transaction, mode lockMode, ke
 	// iterating over the correct key
 	for ; j < len(lockRequests); j++ {
 
-		if txn.Id.Equal(lockRequests[j].txn.Id) {
+		if isSameTxn(txn, lockRequests[j].txn) {
 			// it seems I requested the lock already
 			return 0
 		}
@@ -219,8 +219,8 @@ func (lm *lockManager) release(txn *pb.Transaction) []*pb.Transaction {
 	lm.lockMgrMutex.Lock()
 	delete(lm.txnsToWaiters, txn)
 	// find lock that was held and release it
-	grantedRequests = append(grantedRequests, lm.innerRelease(txn.Id, txn.ReadWriteSet)...)
-	grantedRequests = append(grantedRequests, lm.innerRelease(txn.Id, txn.ReadSet)...)
+	grantedRequests = append(grantedRequests, lm.innerRelease(txn, txn.ReadWriteSet)...)
+	grantedRequests = append(grantedRequests, lm.innerRelease(txn, txn.ReadSet)...)
 
 	// id, _ := ulid.ParseIdFromProto(txn.Id)
 	// log.Debugf("released locks for [%s] [%d]", id.String(), len(grantedRequests))
@@ -244,7 +244,7 @@ func (lm *lockManager) release(txn *pb.Transaction) []*pb.Transaction {
 	return newOwners
 }
 
-func (lm *lockManager) innerRelease(txnIDProto *pb.Id128, set [][]byte) []lockRequest {
+func (lm *lockManager) innerRelease(txn *pb.Transaction, set [][]byte) []lockRequest {
 	newOwner := make([]lockRequest, 0)
 	for i := 0; i < len(set); i++ {
 		key := set[i]
@@ -259,7 +259,7 @@ func (lm *lockManager) innerRelease(txnIDProto *pb.Id128, set [][]byte) []lockRe
 				if lockRequests[j].mode == write {
 					precededByWrite = true
 				}
-				if txnIDProto.Equal(lockRequests[j].txn.Id) {
+				if isSameTxn(txn, lockRequests[j].txn) {
 					break
 				}
 			}
@@ -302,6 +302,12 @@ func (lm *lockManager) innerRelease(txnIDProto *pb.Id128, set [][]byte) []lockRe
 	return newOwner
 }
 
+// a txn that is submitted again keeps its id but has its own position in the log
+// both of them wait for their locks in log order
+func isSameTxn(a *pb.Transaction, b *pb.Transaction) bool {
+	return a.Id.Equal(b.Id) && a.BatchIndex == b.BatchIndex && a.BatchPosition == b.BatchPosition
+}
+
 // order preserving remove idx ... is probably inefficient though :/
 func (lm *lockManager) removeIdx(lockRequests []lockRequest, idx int) []lockRequest {
 	if idx == 0 {
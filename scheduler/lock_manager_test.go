@@ -143,6 +143,37 @@ func TestLockManagerSameTxnTwice(t *testing.T) {
 	assert.Equal(t, 1, len(requests))
 }
 
+func TestLockManagerRetriedTxn(t *testing.T) {
+	lm := newLockManager()
+	key := []byte("narf")
+	keyHash := lm.hash(key)
+
+	txnID, err := ulid.NewId()
+	assert.Nil(t, err)
+	first := &pb.Transaction{
+		Id:           txnID.ToProto(),
+		ReadWriteSet: [][]byte{key},
+		BatchIndex:   uint64(1),
+	}
+	retry := &pb.Transaction{
+		Id:           txnID.ToProto(),
+		ReadWriteSet: [][]byte{key},
+		BatchIndex:   uint64(2),
+	}
+
+	// a retry queues up behind the txn with the same id
+	assert.Equal(t, 0, lm.lock(first))
+	assert.Equal(t, 1, lm.lock(retry))
+	assert.Equal(t, 2, len(lm.lockMap[keyHash]))
+
+	newOwners := lm.release(first)
+	assert.Equal(t, 1, len(newOwners))
+	assert.True(t, retry == newOwners[0])
+	lm.release(retry)
+	_, ok := lm.lockMap[keyHash]
+	assert.False(t, ok)
+}
+
 func TestLockManagerTxnsReleaseMiddle(t *testing.T) {
 	lm := newLockManager()
 	key1 := []byte("key1")
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/mhelmich/calvin/pb"
+)
+
+type txnKey [2]uint64
+
+func newRetriedTxns() *retriedTxns {
+	return &retriedTxns{
+		mutex:  &sync.Mutex{},
+		queues: make(map[txnKey][]*retriedTxn),
+	}
+}
+
+// clients that submit a txn again keep its id
+// a retry takes its locks in log order just like every other txn
+// that way all replicas grant locks in the same order
+// it only doesn't run before the txn with the same id before it is done on this node
+// otherwise the retry might not find the marker of the first run and apply the txn twice
+type retriedTxns struct {
+	mutex *sync.Mutex
+	// txns with the same id in log order
+	// only the first one can run
+	queues map[txnKey][]*retriedTxn
+}
+
+type retriedTxn struct {
+	txn *pb.Transaction
+	// the retry got all its locks and waits for the txn before it
+	ready bool
+}
+
+func (rt *retriedTxns) scheduled(txn *pb.Transaction) {
+	if txn.BatchIndex == 0 {
+		return
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	id := txnKey{txn.Id.Upper, txn.Id.Lower}
+	rt.queues[id] = append(rt.queues[id], &retriedTxn{txn: txn})
+}
+
+// returns true if the txn needs to wait for another txn with the same id
+// the txn is handed out by done once it can run
+func (rt *retriedTxns) hold(txn *pb.Transaction) bool {
+	if txn.BatchIndex == 0 {
+		return false
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	queue := rt.queues[txnKey{txn.Id.Upper, txn.Id.Lower}]
+	if len(queue) == 0 || isSameTxn(queue[0].txn, txn) {
+		return false
+	}
+
+	for idx := range queue {
+		if isSameTxn(queue[idx].txn, txn) {
+			queue[idx].ready = true
+		}
+	}
+	return true
+}
+
+// returns the next txn with the same id if it was held and can run now
+func (rt *retriedTxns) done(txn *pb.Transaction) *pb.Transaction {
+	if txn.BatchIndex == 0 {
+		return nil
+	}
+
+	rt.mutex.Lock()
+	defer rt.mutex.Unlock()
+	id := txnKey{txn.Id.Upper, txn.Id.Lower}
+	queue := rt.queues[id]
+	remaining := make([]*retriedTxn, 0, len(queue))
+	for idx := range queue {
+		if !isSameTxn(queue[idx].txn, txn) {
+			remaining = append(remaining, queue[idx])
+		}
+	}
+
+	if len(remaining) == 0 {
+		delete(rt.queues, id)
+		return nil
+	}
+
+	rt.queues[id] = remaining
+	if !remaining[0].ready {
+		return nil
+	}
+	return remaining[0].txn
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetriedTxns(t *testing.T) {
+	rt := newRetriedTxns()
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	newTxn := func(batchIndex uint64) *pb.Transaction {
+		return &pb.Transaction{
+			Id:         id.ToProto(),
+			BatchIndex: batchIndex,
+		}
+	}
+
+	first := newTxn(1)
+	retry1 := newTxn(2)
+	retry2 := newTxn(3)
+	rt.scheduled(first)
+	rt.scheduled(retry1)
+	rt.scheduled(retry2)
+
+	// retries that got their locks wait for the txn before them
+	assert.True(t, rt.hold(retry2))
+	assert.False(t, rt.hold(first))
+	// retry1 isn't ready yet
+	assert.Nil(t, rt.done(first))
+	assert.False(t, rt.hold(retry1))
+	assert.True(t, retry2 == rt.done(retry1))
+	assert.False(t, rt.hold(retry2))
+	assert.Nil(t, rt.done(retry2))
+	assert.Equal(t, 0, len(rt.queues))
+
+	// txns that don't come out of the log never wait
+	rt.scheduled(newTxn(4))
+	assert.False(t, rt.hold(newTxn(4)))
+	assert.False(t, rt.hold(newTxn(0)))
+	assert.Nil(t, rt.done(newTxn(0)))
+	assert.Nil(t, rt.done(newTxn(4)))
+	assert.Equal(t, 0, len(rt.queues))
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/mhelmich/calvin/pb"
+)
+
+func newScanBarrier() *scanBarrier {
+	return &scanBarrier{
+		mutex:    &sync.Mutex{},
+		finished: make(map[uint64]bool),
+		seqs:     make(map[*pb.Transaction]uint64),
+		waiting:  make(map[uint64]*pb.Transaction),
+	}
+}
+
+// scans see all keys in a range and not only the keys their txn locked
+// a txn that scans runs once all txns scheduled before it are done
+// and txns scheduled after it are held back until it's done
+// that way a scan sees the same data on all replicas
+// txns are numbered in the order they are scheduled to tell before from after
+type scanBarrier struct {
+	mutex   *sync.Mutex
+	nextSeq uint64
+	// numbers of all txns that aren't done yet in the order they were scheduled
+	outstanding []uint64
+	finished    map[uint64]bool
+	seqs        map[*pb.Transaction]uint64
+	// numbers of all scanning txns that aren't done yet in order
+	scans []uint64
+	// scanning txns that have their locks but wait for earlier txns to be done
+	waiting map[uint64]*pb.Transaction
+	// txns after a scan in the order they became ready
+	held []*pb.Transaction
+}
+
+func (sb *scanBarrier) scheduled(txn *pb.Transaction) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	sb.nextSeq++
+	sb.seqs[txn] = sb.nextSeq
+	sb.outstanding = append(sb.outstanding, sb.nextSeq)
+	if txn.Scans {
+		sb.scans = append(sb.scans, sb.nextSeq)
+	}
+}
+
+// returns true if the txn can't run yet
+// the txn is handed out by done later
+func (sb *scanBarrier) hold(txn *pb.Transaction) bool {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	seq, ok := sb.seqs[txn]
+	if !ok {
+		return false
+	}
+
+	if txn.Scans {
+		if sb.outstanding[0] != seq {
+			sb.waiting[seq] = txn
+			return true
+		}
+		return false
+	}
+
+	if len(sb.scans) > 0 && sb.scans[0] < seq {
+		sb.held = append(sb.held, txn)
+		return true
+	}
+	return false
+}
+
+// returns the txns that can run now that the provided txn is done
+func (sb *scanBarrier) done(txn *pb.Transaction) []*pb.Transaction {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	seq, ok := sb.seqs[txn]
+	if !ok {
+		return nil
+	}
+
+	delete(sb.seqs, txn)
+	sb.finished[seq] = true
+	for len(sb.outstanding) > 0 && sb.finished[sb.outstanding[0]] {
+		delete(sb.finished, sb.outstanding[0])
+		sb.outstanding = sb.outstanding[1:]
+	}
+
+	ready := make([]*pb.Transaction, 0)
+	if txn.Scans {
+		sb.scans = sb.scans[1:]
+		held := sb.held[:0]
+		for idx := range sb.held {
+			if len(sb.scans) > 0 && sb.scans[0] < sb.seqs[sb.held[idx]] {
+				held = append(held, sb.held[idx])
+			} else {
+				ready = append(ready, sb.held[idx])
+			}
+		}
+		sb.held = held
+	}
+
+	if len(sb.scans) > 0 && len(sb.outstanding) > 0 && sb.outstanding[0] == sb.scans[0] {
+		scan, ok := sb.waiting[sb.scans[0]]
+		if ok {
+			delete(sb.waiting, sb.scans[0])
+			ready = append(ready, scan)
+		}
+	}
+	return ready
+}
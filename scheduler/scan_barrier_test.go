@@ -0,0 +1,68 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanBarrier(t *testing.T) {
+	sb := newScanBarrier()
+	before := &pb.Transaction{}
+	scan1 := &pb.Transaction{Scans: true}
+	between := &pb.Transaction{}
+	scan2 := &pb.Transaction{Scans: true}
+	after := &pb.Transaction{}
+	for _, txn := range []*pb.Transaction{before, scan1, between, scan2, after} {
+		sb.scheduled(txn)
+	}
+
+	// nothing waits for txns after a scan
+	assert.False(t, sb.hold(before))
+	// the scan waits for the txn before it
+	// and the txns after it wait for the scan
+	assert.True(t, sb.hold(scan1))
+	assert.True(t, sb.hold(after))
+	assert.True(t, sb.hold(between))
+	assert.True(t, sb.hold(scan2))
+
+	ready := sb.done(before)
+	assert.Equal(t, []*pb.Transaction{scan1}, ready)
+	assert.False(t, sb.hold(scan1))
+
+	// the second scan still waits for the txn between the scans
+	ready = sb.done(scan1)
+	assert.Equal(t, []*pb.Transaction{between}, ready)
+	assert.False(t, sb.hold(between))
+
+	ready = sb.done(between)
+	assert.Equal(t, []*pb.Transaction{scan2}, ready)
+	assert.False(t, sb.hold(scan2))
+
+	ready = sb.done(scan2)
+	assert.Equal(t, []*pb.Transaction{after}, ready)
+	assert.False(t, sb.hold(after))
+	assert.Equal(t, 0, len(sb.done(after)))
+	assert.Equal(t, 0, len(sb.outstanding))
+	assert.Equal(t, 0, len(sb.seqs))
+
+	// txns the barrier doesn't know about are never held
+	assert.False(t, sb.hold(&pb.Transaction{}))
+}
@@ -17,11 +17,13 @@
 package scheduler
 
 import (
+	"context"
 	"io"
 	"sync"
 
 	"github.com/mhelmich/calvin/pb"
 	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 )
@@ -32,10 +34,23 @@ type Scheduler struct {
 	doneTxnChan       <-chan *pb.Transaction
 	lockMgr           *lockManager
 	lowIsolationReads *sync.Map
-	logger            *log.Entry
+	storedProcs       *util.StoredProcedureRegistry
+	batchTracker      *batchTracker
+	epochMerger       *epochMerger
+	retriedTxns       *retriedTxns
+	batchBoundary     *batchBoundary
+	scanBarrier       *scanBarrier
+	// only one boundary is active at a time
+	boundaryMutex *sync.Mutex
+	server        *schedulerServer
+	logger        *log.Entry
 }
 
-func NewScheduler(sequencerChan chan *pb.TransactionBatch, readyTxnsChan chan<- *pb.Transaction, doneTxnChan <-chan *pb.Transaction, srvr *grpc.Server, logger *log.Entry) *Scheduler {
+// The scheduler publishes the index of the last batch whose txns are all done on checkpointChan.
+// Only the latest index is kept in the channel, which needs to be buffered for that reason.
+// If checkpointChan is nil, nothing is published.
+// Batches of numSequencerGroups sequencer groups are merged by epoch before they are scheduled.
+func NewScheduler(sequencerChan chan *pb.TransactionBatch, readyTxnsChan chan<- *pb.Transaction, doneTxnChan <-chan *pb.Transaction, checkpointChan chan uint64, numSequencerGroups int, storedProcs *util.StoredProcedureRegistry, srvr *grpc.Server, logger *log.Entry) *Scheduler {
 	lowIsolationReads := &sync.Map{}
 	s := &Scheduler{
 		sequencerChan:     sequencerChan,
@@ -43,11 +58,18 @@ func NewScheduler(sequencerChan chan *pb.TransactionBatch, readyTxnsChan chan<-
 		doneTxnChan:       doneTxnChan,
 		lockMgr:           newLockManager(),
 		lowIsolationReads: lowIsolationReads,
+		storedProcs:       storedProcs,
+		batchTracker:      newBatchTracker(checkpointChan),
+		epochMerger:       newEpochMerger(numSequencerGroups),
+		retriedTxns:       newRetriedTxns(),
+		batchBoundary:     newBatchBoundary(),
+		scanBarrier:       newScanBarrier(),
+		boundaryMutex:     &sync.Mutex{},
 		logger:            logger,
 	}
 
-	ss := newServer(sequencerChan, lowIsolationReads, logger)
-	pb.RegisterLowIsolationReadServer(srvr, ss)
+	s.server = newServer(sequencerChan, lowIsolationReads, logger)
+	pb.RegisterLowIsolationReadServer(srvr, s.server)
 
 	go s.runLocker()
 	go s.runReleaser()
@@ -65,26 +87,105 @@ func (s *Scheduler) runLocker() {
 			return
 		} else if batch == nil {
 			s.logger.Warningf("Received nil txn batch")
+			continue
+		} else if batch.Epoch > 0 && int(batch.SequencerGroup) >= s.epochMerger.numGroups {
+			s.logger.Panicf("batch of sequencer group [%d] but there are only [%d] groups", batch.SequencerGroup, s.epochMerger.numGroups)
 		}
 
-		for idx := range batch.Transactions {
-			txn := batch.Transactions[idx]
-			if log.GetLevel() == log.DebugLevel {
-				id, _ := ulid.ParseIdFromProto(txn.Id)
-				s.logger.Debugf("getting locks for txn [%s]", id.String())
-			}
+		// locks are taken in merged order
+		// that way all nodes end up with the same lock chains
+		batches := s.epochMerger.add(batch)
+		for idx := range batches {
+			s.scheduleBatch(batches[idx])
+		}
+	}
+}
+
+func (s *Scheduler) scheduleBatch(batch *pb.TransactionBatch) {
+	if batch.ReplaceStoredProcedures {
+		s.storedProcs.Reset()
+	}
+
+	// procedure changes become visible at the position in the log they were sequenced at
+	// transactions in this batch already see them
+	for idx := range batch.StoredProcedures {
+		s.applyStoredProcedureChange(batch.StoredProcedures[idx], batch.Index)
+	}
+
+	// the batch needs to be known before any of its txns can be done
+	s.batchTracker.scheduled(batch)
+	s.batchBoundary.scheduled(batch)
+
+	for idx := range batch.Transactions {
+		txn := batch.Transactions[idx]
+		if log.GetLevel() == log.DebugLevel {
+			id, _ := ulid.ParseIdFromProto(txn.Id)
+			s.logger.Debugf("getting locks for txn [%s]", id.String())
+		}
 
-			numLocksNotAcquired := s.lockMgr.lock(txn)
+		// pin the procedure version that is current at this point in the log
+		// the procedure might be replaced before this txn gets to run
+		if txn.StoredProcedureVersion == 0 {
+			txn.StoredProcedureVersion = s.storedProcs.CurrentVersion(txn.StoredProcedure)
+		}
+		s.storedProcs.Acquire(txn.StoredProcedure, txn.StoredProcedureVersion)
+		s.scanBarrier.scheduled(txn)
 
-			if numLocksNotAcquired == 0 {
-				if log.GetLevel() == log.DebugLevel {
-					id, _ := ulid.ParseIdFromProto(txn.Id)
-					s.logger.Debugf("txn [%s] became ready\n", id.String())
-				}
-				s.readyTxnsChan <- txn
+		s.retriedTxns.scheduled(txn)
+		s.lock(txn)
+	}
+}
+
+func (s *Scheduler) lock(txn *pb.Transaction) {
+	numLocksNotAcquired := s.lockMgr.lock(txn)
+
+	if numLocksNotAcquired == 0 {
+		if log.GetLevel() == log.DebugLevel {
+			id, _ := ulid.ParseIdFromProto(txn.Id)
+			s.logger.Debugf("txn [%s] became ready\n", id.String())
+		}
+		s.ready(txn)
+	}
+}
+
+func (s *Scheduler) ready(txn *pb.Transaction) {
+	if s.retriedTxns.hold(txn) || s.scanBarrier.hold(txn) || s.batchBoundary.hold(txn) {
+		return
+	}
+	s.readyTxnsChan <- txn
+}
+
+// AtBatchBoundary calls fn once all batches up to the last batch that was scheduled are done
+// and before any txn of a later batch runs. fn gets index and term of that batch.
+// Txns of later batches that become ready in the meantime wait until fn returns.
+func (s *Scheduler) AtBatchBoundary(ctx context.Context, fn func(uint64, uint64) error) error {
+	s.boundaryMutex.Lock()
+	defer s.boundaryMutex.Unlock()
+	index, term := s.batchBoundary.start()
+	defer func() {
+		held := s.batchBoundary.stop()
+		for idx := range held {
+			if s.readyTxnsChan != nil {
+				s.readyTxnsChan <- held[idx]
 			}
 		}
+	}()
+
+	done := s.batchTracker.waitFor(index)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.batchTracker.cancelWait(done)
+		return ctx.Err()
 	}
+	return fn(index, term)
+}
+
+// LastDoneBatch returns index and term of the last batch that is done on this node
+// along with all batches before it.
+// Both are zero if no batch was done since the scheduler started.
+func (s *Scheduler) LastDoneBatch() (uint64, uint64) {
+	return s.batchTracker.lastDone()
 }
 
 func (s *Scheduler) runReleaser() {
@@ -115,7 +216,11 @@ func (s *Scheduler) runReleaser() {
 			s.lowIsolationReads.Delete(txnID)
 		}
 
+		s.storedProcs.Release(txn.StoredProcedure, txn.StoredProcedureVersion)
 		newOwners := s.lockMgr.release(txn)
+		s.batchTracker.done(txn)
+		retry := s.retriedTxns.done(txn)
+		newOwners = append(newOwners, s.scanBarrier.done(txn)...)
 
 		for idx := range newOwners {
 			if log.GetLevel() == log.DebugLevel {
@@ -124,12 +229,47 @@ func (s *Scheduler) runReleaser() {
 			}
 
 			if s.readyTxnsChan != nil {
-				s.readyTxnsChan <- newOwners[idx]
+				s.ready(newOwners[idx])
 			}
 		}
+
+		if retry != nil && s.readyTxnsChan != nil {
+			s.ready(retry)
+		}
+	}
+}
+
+// invalid changes are ignored
+// every replica comes to the same conclusion about that
+func (s *Scheduler) applyStoredProcedureChange(proc *pb.StoredProcedure, batchIndex uint64) {
+	err := s.storedProcs.Apply(proc, batchIndex)
+	if err != nil {
+		s.logger.Errorf("can't apply stored procedure change: %s", err.Error())
+		return
+	}
+
+	switch proc.Op {
+	case pb.INSTALL_PROCEDURE:
+		s.logger.Infof("installed stored procedure [%s] version [%d]", proc.Name, proc.Version)
+	case pb.SET_CURRENT_PROCEDURE:
+		s.logger.Infof("stored procedure [%s] is at version [%d] now", proc.Name, proc.Version)
+	case pb.REMOVE_PROCEDURE:
+		s.logger.Infof("removed stored procedure [%s] version [%d]", proc.Name, proc.Version)
 	}
 }
 
+// LowIsolationRead reads the provided keys from this node without going through another node.
+// The keys need to be local.
+func (s *Scheduler) LowIsolationRead(ctx context.Context, req *pb.LowIsolationReadRequest) (*pb.LowIsolationReadResponse, error) {
+	return s.server.LowIsolationRead(ctx, req)
+}
+
+// SetReadBarrier enables linearizable reads.
+// It needs to be called before the scheduler serves requests.
+func (s *Scheduler) SetReadBarrier(readBarrier ReadBarrier) {
+	s.server.readBarrier = readBarrier
+}
+
 func (s *Scheduler) LockChainToASCII(out io.Writer) {
 	s.lockMgr.lockChainToASCII(out)
 }
@@ -18,6 +18,7 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/mhelmich/calvin/pb"
@@ -33,9 +34,14 @@ func newServer(sequencerChan chan<- *pb.TransactionBatch, lowIsolationReads *syn
 	}
 }
 
+// A ReadBarrier blocks until this node executed everything that was committed
+// before the call and returns index and term of the last batch reads reflect afterwards.
+type ReadBarrier func(ctx context.Context) (uint64, uint64, error)
+
 type schedulerServer struct {
 	sequencerChan     chan<- *pb.TransactionBatch
 	lowIsolationReads *sync.Map
+	readBarrier       ReadBarrier
 	logger            *log.Entry
 }
 
@@ -49,7 +55,22 @@ type schedulerServer struct {
 // * the execution package knows how to identify and treat a low isolation read txn (the main part is: do the read and attach a LowIsolationReadResponse)
 // * the txn is sent back via the done channel like any other txn
 // * the scheduler looks for the low iso flag, finds the response channel, sends on it, and is done
+// linearizable reads pass the read barrier first and are served like low isolation reads afterwards
 func (ss *schedulerServer) LowIsolationRead(ctx context.Context, req *pb.LowIsolationReadRequest) (*pb.LowIsolationReadResponse, error) {
+	var index uint64
+	var term uint64
+	if req.Linearizable {
+		if ss.readBarrier == nil {
+			return nil, fmt.Errorf("linearizable reads aren't supported")
+		}
+
+		var err error
+		index, term, err = ss.readBarrier(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	id, _ := ulid.NewId()
 	txnID := id.String()
 
@@ -67,5 +88,7 @@ func (ss *schedulerServer) LowIsolationRead(ctx context.Context, req *pb.LowIsol
 	}
 
 	resp := <-c
+	resp.Index = index
+	resp.Term = term
 	return resp, nil
 }
@@ -17,6 +17,7 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/mhelmich/calvin/pb"
 	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -34,7 +36,7 @@ func TestSchedulerBasic(t *testing.T) {
 	sequencerChan := make(chan *pb.TransactionBatch, 1)
 	readyTxns := make(chan *pb.Transaction, 1)
 	doneTxnChan := make(chan *pb.Transaction, 1)
-	NewScheduler(sequencerChan, readyTxns, doneTxnChan, grpc.NewServer(), log.WithFields(log.Fields{
+	NewScheduler(sequencerChan, readyTxns, doneTxnChan, nil, 1, util.NewStoredProcedureRegistry(), grpc.NewServer(), log.WithFields(log.Fields{
 		"component": "scheduler",
 	}))
 	close(sequencerChan)
@@ -44,7 +46,7 @@ func TestSchedulerLocking(t *testing.T) {
 	sequencerChan := make(chan *pb.TransactionBatch, 3)
 	readyTxns := make(chan *pb.Transaction, 3)
 	doneTxnChan := make(chan *pb.Transaction, 3)
-	NewScheduler(sequencerChan, readyTxns, doneTxnChan, grpc.NewServer(), log.WithFields(log.Fields{
+	NewScheduler(sequencerChan, readyTxns, doneTxnChan, nil, 1, util.NewStoredProcedureRegistry(), grpc.NewServer(), log.WithFields(log.Fields{
 		"component": "scheduler",
 	}))
 
@@ -97,7 +99,7 @@ func TestSchedulerConcurrentLocking(t *testing.T) {
 	sequencerChan := make(chan *pb.TransactionBatch, 1)
 	readyTxns := make(chan *pb.Transaction, 1)
 	doneTxnChan := make(chan *pb.Transaction, 1)
-	NewScheduler(sequencerChan, readyTxns, doneTxnChan, grpc.NewServer(), log.WithFields(log.Fields{
+	NewScheduler(sequencerChan, readyTxns, doneTxnChan, nil, 1, util.NewStoredProcedureRegistry(), grpc.NewServer(), log.WithFields(log.Fields{
 		"component": "scheduler",
 	}))
 
@@ -182,3 +184,215 @@ func TestSchedulerConcurrentLocking(t *testing.T) {
 	close(sequencerChan)
 	close(doneTxnChan)
 }
+
+func TestSchedulerInstallsStoredProcedures(t *testing.T) {
+	sequencerChan := make(chan *pb.TransactionBatch, 3)
+	readyTxns := make(chan *pb.Transaction, 3)
+	doneTxnChan := make(chan *pb.Transaction, 3)
+	storedProcs := util.NewStoredProcedureRegistry()
+	NewScheduler(sequencerChan, readyTxns, doneTxnChan, nil, 1, storedProcs, grpc.NewServer(), log.WithFields(log.Fields{
+		"component": "scheduler",
+	}))
+
+	id1, err := ulid.NewId()
+	assert.Nil(t, err)
+	txn1 := &pb.Transaction{
+		Id:              id1.ToProto(),
+		StoredProcedure: "narf",
+		ReadWriteSet:    [][]byte{[]byte("key1")},
+	}
+	sequencerChan <- &pb.TransactionBatch{
+		Index:        uint64(1),
+		Transactions: []*pb.Transaction{txn1},
+		StoredProcedures: []*pb.StoredProcedure{
+			&pb.StoredProcedure{
+				Name:   "narf",
+				Source: "return 1",
+			},
+		},
+	}
+
+	readyTxn1 := <-readyTxns
+	assert.Equal(t, uint64(1), readyTxn1.StoredProcedureVersion)
+
+	id2, err := ulid.NewId()
+	assert.Nil(t, err)
+	txn2 := &pb.Transaction{
+		Id:              id2.ToProto(),
+		StoredProcedure: "narf",
+		ReadWriteSet:    [][]byte{[]byte("key2")},
+	}
+	sequencerChan <- &pb.TransactionBatch{
+		Index:        uint64(2),
+		Transactions: []*pb.Transaction{txn2},
+		StoredProcedures: []*pb.StoredProcedure{
+			&pb.StoredProcedure{
+				Name:   "narf",
+				Source: "return 2",
+			},
+		},
+	}
+
+	readyTxn2 := <-readyTxns
+	assert.Equal(t, uint64(2), readyTxn2.StoredProcedureVersion)
+	proc, ok := storedProcs.Get("narf", 1, 0)
+	assert.True(t, ok)
+	assert.Equal(t, "return 1", proc.Source)
+	proc, ok = storedProcs.Get("narf", 0, 0)
+	assert.True(t, ok)
+	assert.Equal(t, "return 2", proc.Source)
+
+	// roll back to version 1 and remove version 2
+	// while txn2 is still running with version 2
+	id3, err := ulid.NewId()
+	assert.Nil(t, err)
+	txn3 := &pb.Transaction{
+		Id:              id3.ToProto(),
+		StoredProcedure: "narf",
+		ReadWriteSet:    [][]byte{[]byte("key3")},
+	}
+	sequencerChan <- &pb.TransactionBatch{
+		Index:        uint64(3),
+		Transactions: []*pb.Transaction{txn3},
+		StoredProcedures: []*pb.StoredProcedure{
+			&pb.StoredProcedure{
+				Name:    "narf",
+				Version: uint64(1),
+				Op:      pb.SET_CURRENT_PROCEDURE,
+			},
+			&pb.StoredProcedure{
+				Name:    "narf",
+				Version: uint64(2),
+				Op:      pb.REMOVE_PROCEDURE,
+			},
+		},
+	}
+
+	readyTxn3 := <-readyTxns
+	assert.Equal(t, uint64(1), readyTxn3.StoredProcedureVersion)
+	assert.Equal(t, 1, len(storedProcs.List()))
+	assert.True(t, storedProcs.List()[0].Current)
+	// txn2 was sequenced before the removal and can still run
+	_, ok = storedProcs.Get("narf", 2, 2)
+	assert.True(t, ok)
+	_, ok = storedProcs.Get("narf", 2, 3)
+	assert.False(t, ok)
+
+	// once txn2 is done, version 2 is gone for good
+	doneTxnChan <- txn2
+	for i := 0; i < 100; i++ {
+		_, ok = storedProcs.Get("narf", 2, 2)
+		if !ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, ok)
+
+	close(sequencerChan)
+	close(doneTxnChan)
+}
+
+func TestSchedulerReplacesStoredProcedures(t *testing.T) {
+	sequencerChan := make(chan *pb.TransactionBatch, 3)
+	readyTxns := make(chan *pb.Transaction, 3)
+	doneTxnChan := make(chan *pb.Transaction, 3)
+	storedProcs := util.NewStoredProcedureRegistry()
+	storedProcs.InstallBuiltIn("builtin", "return 0")
+	NewScheduler(sequencerChan, readyTxns, doneTxnChan, nil, 1, storedProcs, grpc.NewServer(), log.WithFields(log.Fields{
+		"component": "scheduler",
+	}))
+
+	sequencerChan <- &pb.TransactionBatch{
+		StoredProcedures: []*pb.StoredProcedure{
+			&pb.StoredProcedure{
+				Name:   "narf",
+				Source: "return 1",
+			},
+		},
+	}
+
+	// procedures out of a snapshot replace everything that was installed through the log
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	txn := &pb.Transaction{
+		Id:              id.ToProto(),
+		StoredProcedure: "moep",
+		ReadWriteSet:    [][]byte{[]byte("key1")},
+	}
+	sequencerChan <- &pb.TransactionBatch{
+		Transactions:            []*pb.Transaction{txn},
+		ReplaceStoredProcedures: true,
+		StoredProcedures: []*pb.StoredProcedure{
+			&pb.StoredProcedure{
+				Name:   "moep",
+				Source: "return 2",
+			},
+		},
+	}
+
+	readyTxn := <-readyTxns
+	assert.Equal(t, uint64(1), readyTxn.StoredProcedureVersion)
+	_, ok := storedProcs.Get("narf", 0, 0)
+	assert.False(t, ok)
+	_, ok = storedProcs.Get("moep", 0, 0)
+	assert.True(t, ok)
+	_, ok = storedProcs.Get("builtin", 0, 0)
+	assert.True(t, ok)
+
+	close(sequencerChan)
+	close(doneTxnChan)
+}
+
+func TestSchedulerAtBatchBoundary(t *testing.T) {
+	sequencerChan := make(chan *pb.TransactionBatch, 3)
+	readyTxns := make(chan *pb.Transaction, 3)
+	doneTxnChan := make(chan *pb.Transaction, 3)
+	s := NewScheduler(sequencerChan, readyTxns, doneTxnChan, nil, 1, util.NewStoredProcedureRegistry(), grpc.NewServer(), log.WithFields(log.Fields{
+		"component": "scheduler",
+	}))
+
+	newBatch := func(index uint64) *pb.TransactionBatch {
+		id, err := ulid.NewId()
+		assert.Nil(t, err)
+		return &pb.TransactionBatch{
+			Index: index,
+			Term:  uint64(1),
+			Transactions: []*pb.Transaction{&pb.Transaction{
+				Id:           id.ToProto(),
+				ReadWriteSet: [][]byte{[]byte("key1")},
+				BatchIndex:   index,
+			}},
+		}
+	}
+
+	sequencerChan <- newBatch(uint64(1))
+	doneTxnChan <- <-readyTxns
+
+	// txns of later batches don't run while the boundary is active
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.AtBatchBoundary(ctx, func(index uint64, term uint64) error {
+		assert.Equal(t, uint64(1), index)
+		assert.Equal(t, uint64(1), term)
+		sequencerChan <- newBatch(uint64(2))
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 0, len(readyTxns))
+		return nil
+	})
+	assert.Nil(t, err)
+	txn2 := <-readyTxns
+	assert.Equal(t, uint64(2), txn2.BatchIndex)
+
+	// the boundary waits for the batch that isn't done yet
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer shortCancel()
+	err = s.AtBatchBoundary(shortCtx, func(index uint64, term uint64) error {
+		assert.Fail(t, "the boundary isn't reached")
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	close(sequencerChan)
+	close(doneTxnChan)
+}
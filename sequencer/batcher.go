@@ -0,0 +1,174 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/mhelmich/calvin/pb"
+)
+
+type batchCutReason int
+
+const (
+	batchNotFull batchCutReason = iota
+	batchFullByCount
+	batchFullByBytes
+	batchCutByDelay
+)
+
+func newBatcher(maxTxns int, maxBytes int) *batcher {
+	return &batcher{
+		batch:    &pb.TransactionBatch{},
+		maxTxns:  maxTxns,
+		maxBytes: maxBytes,
+	}
+}
+
+// collects txns and stored procedure changes until the batch is full
+// the size of a batch is the size of its marshaled parts including their framing
+// the epoch and the raft metadata aren't counted
+type batcher struct {
+	batch    *pb.TransactionBatch
+	numBytes int
+	maxTxns  int
+	maxBytes int
+	// proposers of the stored procedure changes in the batch wait for these
+	waiters []chan<- error
+}
+
+// callers check whether a txn fits before adding it
+func (b *batcher) addTxn(txn *pb.Transaction) batchCutReason {
+	b.batch.Transactions = append(b.batch.Transactions, txn)
+	b.numBytes += fieldSize(txn.Size())
+	return b.isFull()
+}
+
+func (b *batcher) addStoredProcedure(proc *pb.StoredProcedure, waiter chan<- error) batchCutReason {
+	b.batch.StoredProcedures = append(b.batch.StoredProcedures, proc)
+	b.waiters = append(b.waiters, waiter)
+	b.numBytes += fieldSize(proc.Size())
+	return b.isFull()
+}
+
+// whether a part of the given size can be added without growing the batch beyond the max size
+// an empty batch takes everything
+// that way a single txn that is bigger than the max size ends up in a batch of its own
+func (b *batcher) fits(size int) bool {
+	return b.isEmpty() || b.numBytes+fieldSize(size) <= b.maxBytes
+}
+
+// the size of a repeated message field in the marshaled batch
+// one byte for the tag, the length prefix and the message itself
+func fieldSize(size int) int {
+	return 1 + proto.SizeVarint(uint64(size)) + size
+}
+
+func (b *batcher) isFull() batchCutReason {
+	if len(b.batch.Transactions) >= b.maxTxns {
+		return batchFullByCount
+	} else if b.numBytes >= b.maxBytes {
+		return batchFullByBytes
+	}
+	return batchNotFull
+}
+
+func (b *batcher) isEmpty() bool {
+	return len(b.batch.Transactions) == 0 && len(b.batch.StoredProcedures) == 0
+}
+
+// hands out the current batch along with the waiters of its stored procedure changes
+// and starts a new one
+func (b *batcher) take() (*pb.TransactionBatch, []chan<- error) {
+	batch := b.batch
+	waiters := b.waiters
+	b.batch = &pb.TransactionBatch{}
+	b.numBytes = 0
+	b.waiters = nil
+	return batch, waiters
+}
+
+// BatchStats describes the batches a sequencer cut since it was started.
+type BatchStats struct {
+	NumBatches      uint64
+	NumTxns         uint64
+	NumBytes        uint64
+	MaxTxnsInBatch  uint64
+	MaxBytesInBatch uint64
+	// why batches were cut
+	NumFullByCount uint64
+	NumFullByBytes uint64
+	NumCutByDelay  uint64
+}
+
+// AvgTxnsPerBatch returns the average number of txns in a batch.
+func (bs BatchStats) AvgTxnsPerBatch() float64 {
+	if bs.NumBatches == 0 {
+		return 0
+	}
+	return float64(bs.NumTxns) / float64(bs.NumBatches)
+}
+
+// AvgBytesPerBatch returns the average size of a marshaled batch.
+func (bs BatchStats) AvgBytesPerBatch() float64 {
+	if bs.NumBatches == 0 {
+		return 0
+	}
+	return float64(bs.NumBytes) / float64(bs.NumBatches)
+}
+
+func newBatchStatsTracker() *batchStatsTracker {
+	return &batchStatsTracker{
+		mutex: &sync.Mutex{},
+	}
+}
+
+type batchStatsTracker struct {
+	mutex *sync.Mutex
+	stats BatchStats
+}
+
+func (t *batchStatsTracker) record(numTxns int, numBytes int, reason batchCutReason) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.stats.NumBatches++
+	t.stats.NumTxns += uint64(numTxns)
+	t.stats.NumBytes += uint64(numBytes)
+	if uint64(numTxns) > t.stats.MaxTxnsInBatch {
+		t.stats.MaxTxnsInBatch = uint64(numTxns)
+	}
+	if uint64(numBytes) > t.stats.MaxBytesInBatch {
+		t.stats.MaxBytesInBatch = uint64(numBytes)
+	}
+
+	switch reason {
+	case batchFullByCount:
+		t.stats.NumFullByCount++
+	case batchFullByBytes:
+		t.stats.NumFullByBytes++
+	case batchCutByDelay:
+		t.stats.NumCutByDelay++
+	}
+}
+
+func (t *batchStatsTracker) get() BatchStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.stats
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"testing"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatcherBasic(t *testing.T) {
+	txn := &pb.Transaction{
+		ReadWriteSet: [][]byte{[]byte("narf")},
+	}
+	b := newBatcher(3, 10*txn.Size())
+	assert.True(t, b.isEmpty())
+	assert.Equal(t, batchNotFull, b.addTxn(txn))
+	done := make(chan error, 1)
+	assert.Equal(t, batchNotFull, b.addStoredProcedure(&pb.StoredProcedure{Name: "moep"}, done))
+	assert.False(t, b.isEmpty())
+	assert.Equal(t, batchNotFull, b.addTxn(txn))
+	assert.Equal(t, batchFullByCount, b.addTxn(txn))
+
+	batch, waiters := b.take()
+	assert.Equal(t, 3, len(batch.Transactions))
+	assert.Equal(t, 1, len(batch.StoredProcedures))
+	assert.Equal(t, 1, len(waiters))
+	assert.True(t, b.isEmpty())
+
+	// a txn that is too big on its own fills up a batch
+	bigTxn := &pb.Transaction{
+		StoredProcedureArgs: [][]byte{make([]byte, 10*txn.Size())},
+	}
+	assert.True(t, b.fits(bigTxn.Size()))
+	assert.Equal(t, batchFullByBytes, b.addTxn(bigTxn))
+	batch, waiters = b.take()
+	assert.Equal(t, 1, len(batch.Transactions))
+	assert.Equal(t, 0, len(waiters))
+
+	// a txn that would grow the batch beyond the max size doesn't fit
+	b.addTxn(txn)
+	assert.False(t, b.fits(bigTxn.Size()))
+	assert.True(t, b.fits(txn.Size()))
+	batch, _ = b.take()
+	assert.Equal(t, 1, len(batch.Transactions))
+	assert.Equal(t, batch.Size(), fieldSize(txn.Size()))
+}
+
+func TestBatchStats(t *testing.T) {
+	tracker := newBatchStatsTracker()
+	assert.Equal(t, float64(0), tracker.get().AvgTxnsPerBatch())
+
+	tracker.record(10, 100, batchFullByCount)
+	tracker.record(2, 300, batchFullByBytes)
+	tracker.record(3, 20, batchCutByDelay)
+	tracker.record(1, 10, batchCutByDelay)
+
+	stats := tracker.get()
+	assert.Equal(t, uint64(4), stats.NumBatches)
+	assert.Equal(t, uint64(16), stats.NumTxns)
+	assert.Equal(t, uint64(430), stats.NumBytes)
+	assert.Equal(t, uint64(10), stats.MaxTxnsInBatch)
+	assert.Equal(t, uint64(300), stats.MaxBytesInBatch)
+	assert.Equal(t, uint64(1), stats.NumFullByCount)
+	assert.Equal(t, uint64(1), stats.NumFullByBytes)
+	assert.Equal(t, uint64(2), stats.NumCutByDelay)
+	assert.Equal(t, float64(4), stats.AvgTxnsPerBatch())
+	assert.Equal(t, float64(107.5), stats.AvgBytesPerBatch())
+}
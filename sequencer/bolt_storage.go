@@ -35,7 +35,14 @@ type localRaftStore interface {
 	saveEntriesAndState(entries []raftpb.Entry, hardState raftpb.HardState) error
 	dropLogEntriesBeforeIndex(index uint64) error
 	saveSnap(snap raftpb.Snapshot) error
+	applySnap(snap raftpb.Snapshot) error
 	dropOldSnapshots(numberOfSnapshotsToKeep int) error
+	saveAppliedIndex(index uint64) error
+	loadAppliedIndex() (uint64, error)
+	savePeerAddress(nodeID uint64, address string) error
+	removePeer(nodeID uint64) error
+	loadPeers() (map[uint64]string, error)
+	hasExistingState() (bool, error)
 	close()
 }
 
@@ -48,7 +55,12 @@ var (
 	entriesBucket      = []byte("entries")
 	hardStateKey       = []byte("hardstate")
 	confStateKey       = []byte("conf")
-	snapshotsBucket    = []byte("snapshots")
+	appliedIndexKey    = []byte("applied")
+	// index and term of the latest snapshot
+	snapshotMetadataKey = []byte("snapmeta")
+	snapshotsBucket     = []byte("snapshots")
+	// addresses of all members of the raft group
+	peersBucket = []byte("peers")
 )
 
 type boltStorage struct {
@@ -117,6 +129,10 @@ func initBoltStorage(db *bolt.DB) error {
 			return err
 		}
 
+		if _, err = tx.CreateBucketIfNotExists([]byte(peersBucket)); err != nil {
+			return err
+		}
+
 		return nil
 	})
 }
@@ -249,6 +265,16 @@ func (bs *boltStorage) Term(i uint64) (uint64, error) {
 	}
 
 	defer tx.Rollback()
+	snapIndex, snapTerm, err := bs.loadSnapshotMetadata(tx)
+	if err != nil {
+		return 0, err
+	} else if snapIndex > 0 && i == snapIndex {
+		// the entry at the snapshot index might be gone but its term is retained
+		return snapTerm, nil
+	} else if i < snapIndex {
+		return 0, raft.ErrCompacted
+	}
+
 	bites := tx.Bucket(entriesBucket).Get(util.Uint64ToBytes(i))
 	if bites == nil || len(bites) == 0 {
 		// return 0, raft.ErrUnavailable
@@ -272,12 +298,17 @@ func (bs *boltStorage) LastIndex() (uint64, error) {
 	}
 
 	defer tx.Rollback()
+	snapIndex, _, err := bs.loadSnapshotMetadata(tx)
+	if err != nil {
+		return 0, err
+	}
+
 	curs := tx.Bucket(entriesBucket).Cursor()
 	last, _ := curs.Last()
-	if last == nil {
+	if last == nil || util.BytesToUint64(last) < snapIndex {
 		// if we can't find a key in the bucket,
-		// return 0 as last index
-		return 0, nil
+		// the log ends with the latest snapshot (or is empty)
+		return snapIndex, nil
 	}
 
 	return util.BytesToUint64(last), nil
@@ -295,12 +326,18 @@ func (bs *boltStorage) FirstIndex() (uint64, error) {
 	}
 
 	defer tx.Rollback()
+	snapIndex, _, err := bs.loadSnapshotMetadata(tx)
+	if err != nil {
+		return 0, err
+	}
+
 	curs := tx.Bucket(entriesBucket).Cursor()
 	first, _ := curs.First()
-	if first == nil {
-		// if we can't find a key in the bucket,
+	if first == nil || (snapIndex > 0 && util.BytesToUint64(first) <= snapIndex) {
+		// entries up to the latest snapshot are part of the snapshot
+		// if there is no snapshot and we can't find a key in the bucket,
 		// return 1 as frist index
-		return 1, nil
+		return snapIndex + 1, nil
 	}
 
 	return util.BytesToUint64(first), nil
@@ -450,6 +487,18 @@ func (bs *boltStorage) saveSnap(snap raftpb.Snapshot) error {
 		return err
 	}
 
+	snapIndex, _, err := bs.loadSnapshotMetadata(tx)
+	if err != nil {
+		return err
+	}
+
+	if snap.Metadata.Index >= snapIndex {
+		err = bs.saveSnapshotMetadata(tx, snap.Metadata)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -458,6 +507,78 @@ func (bs *boltStorage) saveSnap(snap raftpb.Snapshot) error {
 	return nil
 }
 
+// Installs a snapshot received from the leader the way raft.MemoryStorage.ApplySnapshot does.
+// The snapshot replaces the entire log. Entries that are part of the snapshot
+// are covered by it and entries after it are from a log that diverged from the leader's.
+// The log continues right after the snapshot.
+func (bs *boltStorage) applySnap(snap raftpb.Snapshot) error {
+	if raft.IsEmptySnap(snap) {
+		return nil
+	}
+
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		snapIndex, _, err := bs.loadSnapshotMetadata(tx)
+		if err != nil {
+			return err
+		} else if snap.Metadata.Index < snapIndex {
+			return raft.ErrSnapOutOfDate
+		}
+
+		bites, err := proto.Marshal(&snap)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Bucket(snapshotsBucket).Put(util.Uint64ToBytes(snap.Metadata.Index), bites)
+		if err != nil {
+			return err
+		}
+
+		err = bs.saveSnapshotMetadata(tx, snap.Metadata)
+		if err != nil {
+			return err
+		}
+
+		// recreating the bucket is cheaper than deleting entries one by one
+		err = tx.DeleteBucket(entriesBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucket(entriesBucket)
+		return err
+	})
+}
+
+func (bs *boltStorage) saveSnapshotMetadata(tx *bolt.Tx, metadata raftpb.SnapshotMetadata) error {
+	bites := make([]byte, 16)
+	util.Uint64ToBytesInto(metadata.Index, bites[:8])
+	util.Uint64ToBytesInto(metadata.Term, bites[8:])
+	return tx.Bucket(staticFieldsBucket).Put(snapshotMetadataKey, bites)
+}
+
+// returns index and term of the latest snapshot or zeros if there is no snapshot
+// stores that were written before the metadata was kept read it from the latest snapshot
+func (bs *boltStorage) loadSnapshotMetadata(tx *bolt.Tx) (uint64, uint64, error) {
+	bites := tx.Bucket(staticFieldsBucket).Get(snapshotMetadataKey)
+	if len(bites) == 16 {
+		return util.BytesToUint64(bites[:8]), util.BytesToUint64(bites[8:]), nil
+	}
+
+	_, bites = tx.Bucket(snapshotsBucket).Cursor().Last()
+	if len(bites) == 0 {
+		return 0, 0, nil
+	}
+
+	snap := &raftpb.Snapshot{}
+	err := proto.Unmarshal(bites, snap)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return snap.Metadata.Index, snap.Metadata.Term, nil
+}
+
 // Drops all existing indexes and only keeps the latest snapshots.
 func (bs *boltStorage) dropOldSnapshots(numberOfSnapshotsToKeep int) error {
 	var k []byte
@@ -515,6 +636,72 @@ func (bs *boltStorage) saveConfigState(confState raftpb.ConfState) error {
 	return nil
 }
 
+// Saves the index of the last log entry that was handed to the scheduler.
+func (bs *boltStorage) saveAppliedIndex(index uint64) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(staticFieldsBucket).Put(appliedIndexKey, util.Uint64ToBytes(index))
+	})
+}
+
+func (bs *boltStorage) loadAppliedIndex() (uint64, error) {
+	var index uint64
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		bites := tx.Bucket(staticFieldsBucket).Get(appliedIndexKey)
+		if bites != nil && len(bites) > 0 {
+			index = util.BytesToUint64(bites)
+		}
+		return nil
+	})
+	return index, err
+}
+
+func (bs *boltStorage) savePeerAddress(nodeID uint64, address string) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(peersBucket).Put(util.Uint64ToBytes(nodeID), []byte(address))
+	})
+}
+
+func (bs *boltStorage) removePeer(nodeID uint64) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(peersBucket).Delete(util.Uint64ToBytes(nodeID))
+	})
+}
+
+func (bs *boltStorage) loadPeers() (map[uint64]string, error) {
+	peers := make(map[uint64]string)
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(peersBucket).ForEach(func(k, v []byte) error {
+			peers[util.BytesToUint64(k)] = string(v)
+			return nil
+		})
+	})
+	return peers, err
+}
+
+// A storage has existing state if raft wrote a hard state, log entries or snapshots into it.
+// In that case the raft node needs to be restarted instead of bootstrapped.
+func (bs *boltStorage) hasExistingState() (bool, error) {
+	var exists bool
+	err := bs.db.View(func(tx *bolt.Tx) error {
+		bites := tx.Bucket(staticFieldsBucket).Get(hardStateKey)
+		if bites != nil && len(bites) > 0 {
+			exists = true
+			return nil
+		}
+
+		k, _ := tx.Bucket(entriesBucket).Cursor().First()
+		if k != nil {
+			exists = true
+			return nil
+		}
+
+		k, _ = tx.Bucket(snapshotsBucket).Cursor().First()
+		exists = k != nil
+		return nil
+	})
+	return exists, err
+}
+
 func (bs *boltStorage) close() {
 	bs.db.Close()
 }
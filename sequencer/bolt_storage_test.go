@@ -24,6 +24,7 @@ import (
 	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft"
 	"go.etcd.io/etcd/raft/raftpb"
 )
 
@@ -266,6 +267,63 @@ func TestDropOldLogEntries(t *testing.T) {
 	store.close()
 }
 
+func TestBoltStorageApplySnapshot(t *testing.T) {
+	dir := "./test-TestBoltStorageApplySnapshot-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	store, err := openBoltStorage(dir, log.WithFields(log.Fields{}))
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	entries := make([]raftpb.Entry, 10)
+	for i := range entries {
+		entries[i] = makeRandomEntry(i + 1)
+	}
+	err = store.saveEntriesAndState(entries, raftpb.HardState{})
+	assert.Nil(t, err)
+
+	// the snapshot replaces the log
+	snap := raftpb.Snapshot{
+		Metadata: raftpb.SnapshotMetadata{
+			Index: uint64(100),
+			Term:  uint64(7),
+		},
+		Data: []byte("narf"),
+	}
+	err = store.applySnap(snap)
+	assert.Nil(t, err)
+
+	firstIndex, err := store.FirstIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(101), firstIndex)
+	lastIndex, err := store.LastIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), lastIndex)
+	term, err := store.Term(uint64(100))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), term)
+	_, err = store.Term(uint64(5))
+	assert.Equal(t, raft.ErrCompacted, err)
+	numItems, err := numItemsInBucket(store, entriesBucket)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, numItems)
+
+	// the log continues after the snapshot
+	entry := makeRandomEntry(101)
+	err = store.saveEntriesAndState([]raftpb.Entry{entry}, raftpb.HardState{})
+	assert.Nil(t, err)
+	firstIndex, err = store.FirstIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(101), firstIndex)
+	lastIndex, err = store.LastIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(101), lastIndex)
+
+	// older snapshots can't be applied anymore
+	snap.Metadata.Index = uint64(50)
+	assert.Equal(t, raft.ErrSnapOutOfDate, store.applySnap(snap))
+
+	store.close()
+}
+
 func makeRandomSnapshot(index int) raftpb.Snapshot {
 	metadata := raftpb.SnapshotMetadata{
 		Index: uint64(index),
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"sync/atomic"
+
+	"github.com/mhelmich/calvin/pb"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// Epochs aren't stored anywhere.
+// Every replica of a raft group hands them out when it publishes batches
+// and works them out again from the epoch of the last snapshot after a restart.
+// That's deterministic because it only depends on the log.
+
+// a batch gets the epoch it asked for unless that one is taken already
+func nextEpoch(lastEpoch uint64, requestedEpoch uint64) uint64 {
+	if requestedEpoch > lastEpoch {
+		return requestedEpoch
+	}
+	return lastEpoch + 1
+}
+
+// with a single raft group batches are numbered by their raft index
+// with more groups they are numbered by their position in the merged order of all groups
+// the position is the same on all nodes no matter in which order the groups deliver their batches
+func (rb *raftBackend) batchIndex(entryIndex uint64, epoch uint64) uint64 {
+	if rb.numGroups <= 1 {
+		return entryIndex
+	}
+	return epoch*uint64(rb.numGroups) + uint64(rb.groupID)
+}
+
+func (rb *raftBackend) getLastEpoch() uint64 {
+	return atomic.LoadUint64(&rb.lastEpoch)
+}
+
+func (rb *raftBackend) setLastEpoch(epoch uint64) {
+	atomic.StoreUint64(&rb.lastEpoch, epoch)
+}
+
+// a batch in the log and the epoch it was published with
+type loggedBatch struct {
+	entry raftpb.Entry
+	batch *pb.TransactionBatch
+	epoch uint64
+	// the batch was applied before and nothing in it was scheduled
+	duplicate bool
+}
+
+// returns the batches in (lo, hi] of the log
+// epoch is the epoch of the last batch at or before lo
+func (rb *raftBackend) batchesBetween(lo uint64, hi uint64, epoch uint64) ([]loggedBatch, error) {
+	if hi <= lo {
+		return nil, nil
+	}
+
+	entries, err := rb.store.Entries(lo+1, hi+1, 1024*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+	return batchesOf(entries, lo, epoch)
+}
+
+// entries at or before lo are skipped
+func batchesOf(entries []raftpb.Entry, lo uint64, epoch uint64) ([]loggedBatch, error) {
+	batches := make([]loggedBatch, 0)
+	for idx := range entries {
+		if entries[idx].Index <= lo || entries[idx].Type != raftpb.EntryNormal || len(entries[idx].Data) <= 0 {
+			continue
+		}
+
+		batch := &pb.TransactionBatch{}
+		err := batch.Unmarshal(entries[idx].Data)
+		if err != nil {
+			return nil, err
+		}
+
+		epoch = nextEpoch(epoch, batch.Epoch)
+		batches = append(batches, loggedBatch{
+			entry: entries[idx],
+			batch: batch,
+			epoch: epoch,
+		})
+	}
+	return batches, nil
+}
+
+// the epoch of the last batch at or before index
+func epochAt(batches []loggedBatch, index uint64, epoch uint64) uint64 {
+	for idx := range batches {
+		if batches[idx].entry.Index > index {
+			break
+		}
+		epoch = batches[idx].epoch
+	}
+	return epoch
+}
+
+// with more than one raft group checkpoints are positions in the merged order of all groups
+// this finds the last index of this group's log that is covered by such a position
+// it returns zero if the position is before the snapshot
+func (rb *raftBackend) raftIndexOf(position uint64, snapshotIndex uint64, snapshotEpoch uint64, batches []loggedBatch, hi uint64) uint64 {
+	if rb.numGroups <= 1 {
+		return position
+	} else if snapshotEpoch > 0 && rb.batchIndex(snapshotIndex, snapshotEpoch) > position {
+		return 0
+	}
+
+	for idx := range batches {
+		if rb.batchIndex(batches[idx].entry.Index, batches[idx].epoch) > position {
+			return batches[idx].entry.Index - 1
+		}
+	}
+	return hi
+}
+
+func snapshotEpoch(snap raftpb.Snapshot) (uint64, error) {
+	if raft.IsEmptySnap(snap) {
+		return 0, nil
+	}
+
+	rs := &pb.RaftSnapshot{}
+	err := rs.Unmarshal(snap.Data)
+	if err != nil {
+		return 0, err
+	}
+	return rs.Epoch, nil
+}
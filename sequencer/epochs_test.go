@@ -0,0 +1,142 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestNextEpoch(t *testing.T) {
+	assert.Equal(t, uint64(1), nextEpoch(0, 0))
+	assert.Equal(t, uint64(8), nextEpoch(7, 3))
+	assert.Equal(t, uint64(8), nextEpoch(7, 7))
+	assert.Equal(t, uint64(12), nextEpoch(7, 12))
+}
+
+func TestRaftBackendEpochsAfterRestart(t *testing.T) {
+	raftID := uint64(1)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestRaftBackendEpochsAfterRestart-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	logger := log.WithFields(log.Fields{})
+
+	// the second of three groups
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	txnBatchChan := make(chan *pb.TransactionBatch)
+	newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		groupID:               1,
+		numGroups:             3,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		logger:                logger,
+	})
+
+	requestedEpochs := []uint64{0, 5, 3, 0}
+	expectedEpochs := []uint64{1, 5, 6, 7}
+	for idx := range requestedEpochs {
+		proposeChan <- proposal{data: newTestBatchWithEpoch(t, requestedEpochs[idx])}
+		batch := <-txnBatchChan
+		assert.Equal(t, expectedEpochs[idx], batch.Epoch)
+		assert.Equal(t, uint32(1), batch.SequencerGroup)
+		assert.Equal(t, expectedEpochs[idx]*3+1, batch.Index)
+		assert.Equal(t, batch.Index, batch.Transactions[0].BatchIndex)
+	}
+
+	close(proposeChan)
+	for range txnBatchChan {
+	}
+
+	// everything up to epoch 5 is durable
+	// the checkpoint is a position in the merged order of all groups and doesn't need to be one of this group
+	proposeChan = make(chan proposal)
+	proposeConfChangeChan = make(chan raftpb.ConfChange)
+	txnBatchChan = make(chan *pb.TransactionBatch)
+	rb := newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		groupID:               1,
+		numGroups:             3,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		durableIndex:          uint64(17),
+		logger:                logger,
+	})
+	assert.Equal(t, uint64(5), rb.startEpoch)
+	assert.Equal(t, uint64(7*3+1), rb.replayIndex)
+
+	// the scheduler learns where this group continues
+	batch := <-txnBatchChan
+	assert.Equal(t, uint64(5), batch.Epoch)
+	assert.Equal(t, uint64(5*3+1), batch.Index)
+	assert.Equal(t, 0, len(batch.Transactions))
+
+	// batches after the checkpoint are published again with the same epochs
+	for _, epoch := range expectedEpochs[2:] {
+		batch = <-txnBatchChan
+		assert.Equal(t, epoch, batch.Epoch)
+		assert.Equal(t, epoch*3+1, batch.Index)
+		assert.Equal(t, 1, len(batch.Transactions))
+	}
+
+	proposeChan <- proposal{data: newTestBatchWithEpoch(t, 0)}
+	batch = <-txnBatchChan
+	assert.Equal(t, uint64(8), batch.Epoch)
+
+	close(proposeChan)
+	for range txnBatchChan {
+	}
+}
+
+func newTestBatchWithEpoch(t *testing.T, epoch uint64) []byte {
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	batch := &pb.TransactionBatch{
+		Transactions: []*pb.Transaction{&pb.Transaction{
+			Id: id.ToProto(),
+		}},
+		Epoch: epoch,
+	}
+	bites, err := batch.Marshal()
+	assert.Nil(t, err)
+	return bites
+}
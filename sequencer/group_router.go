@@ -0,0 +1,66 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mhelmich/calvin/pb"
+	log "github.com/sirupsen/logrus"
+)
+
+// all raft groups of a node share the same gRPC server
+// requests are handed to the group they are addressed to
+type groupRouter struct {
+	backends []*raftBackend
+	logger   *log.Entry
+}
+
+func (r *groupRouter) findGroup(groupID uint32) (*raftBackend, error) {
+	if int(groupID) >= len(r.backends) {
+		return nil, fmt.Errorf("there is no raft group [%d]", groupID)
+	}
+	return r.backends[groupID], nil
+}
+
+func (r *groupRouter) StepStream(stream pb.RaftTransport_StepStreamServer) error {
+	return serveStepStream(stream, r.findGroup, r.logger)
+}
+
+func (r *groupRouter) SnapshotStream(stream pb.RaftTransport_SnapshotStreamServer) error {
+	header, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	rb, err := r.findGroup(header.GroupId)
+	if err != nil {
+		return stream.Send(&pb.SnapshotChunkResponse{
+			Error: err.Error(),
+		})
+	}
+	return rb.serveSnapshotStream(stream, header)
+}
+
+func (r *groupRouter) Propose(ctx context.Context, req *pb.ProposeRequest) (*pb.ProposeResponse, error) {
+	rb, err := r.findGroup(req.GroupId)
+	if err != nil {
+		return &pb.ProposeResponse{Error: err.Error()}, nil
+	}
+	return rb.Propose(ctx, req)
+}
@@ -0,0 +1,281 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"net"
+	"sort"
+
+	"github.com/mhelmich/calvin/pb"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// raft.StartNode can only bootstrap voters
+// for clusters with learners, the conf changes raft.StartNode would append are written into the storage instead
+// voters come before learners and both are sorted by id
+// that way all nodes start with the same log no matter which order they got their peers in
+func (rb *raftBackend) bootstrapMembers(voters []raft.Peer, learners []raft.Peer) error {
+	entries := make([]raftpb.Entry, 0, len(voters)+len(learners))
+	confState := raftpb.ConfState{}
+	appendConfChanges := func(peers []raft.Peer, ccType raftpb.ConfChangeType) error {
+		peers = append([]raft.Peer{}, peers...)
+		sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+		for _, peer := range peers {
+			cc := raftpb.ConfChange{Type: ccType, NodeID: peer.ID, Context: peer.Context}
+			data, err := cc.Marshal()
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, raftpb.Entry{
+				Type:  raftpb.EntryConfChange,
+				Term:  1,
+				Index: uint64(len(entries) + 1),
+				Data:  data,
+			})
+		}
+		return nil
+	}
+
+	err := appendConfChanges(voters, raftpb.ConfChangeAddNode)
+	if err != nil {
+		return err
+	}
+
+	err = appendConfChanges(learners, raftpb.ConfChangeAddLearnerNode)
+	if err != nil {
+		return err
+	}
+
+	for _, peer := range voters {
+		confState.Nodes = append(confState.Nodes, peer.ID)
+	}
+	for _, peer := range learners {
+		confState.Learners = append(confState.Learners, peer.ID)
+	}
+
+	// the conf changes are committed right away just like raft.StartNode does it
+	// they are applied once raft hands them out as committed entries
+	err = rb.store.saveEntriesAndState(entries, raftpb.HardState{Term: 1, Commit: uint64(len(entries))})
+	if err != nil {
+		return err
+	}
+
+	return rb.store.saveConfigState(confState)
+}
+
+// the context of a conf change that adds a node carries the address of the node
+// every member of the raft group updates its address book when applying the change
+func (rb *raftBackend) updateAddressBook(cc raftpb.ConfChange) {
+	switch cc.Type {
+	case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
+		if len(cc.Context) > 0 {
+			rb.setPeerAddress(cc.NodeID, string(cc.Context))
+		}
+	case raftpb.ConfChangeRemoveNode:
+		rb.removePeer(cc.NodeID)
+	}
+}
+
+func (rb *raftBackend) setPeerAddress(nodeID uint64, address string) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		rb.logger.Warningf("ignoring address [%s] of node [%d]: %s", address, nodeID, err.Error())
+		return
+	}
+
+	err := rb.store.savePeerAddress(nodeID, address)
+	if err != nil {
+		rb.logger.Errorf("can't persist address of node [%d]: %s", nodeID, err.Error())
+	}
+
+	if rb.cip == nil || rb.cip.GetAddressFor(nodeID) == address {
+		return
+	}
+
+	err = rb.cip.SetAddressFor(nodeID, address)
+	if err != nil {
+		rb.logger.Errorf("can't set address of node [%d]: %s", nodeID, err.Error())
+		return
+	}
+
+	// existing connections go to the old address
+	rb.connCache.CloseConnection(nodeID)
+	rb.logger.Infof("node [%d] is at [%s] now", nodeID, address)
+}
+
+func (rb *raftBackend) removePeer(nodeID uint64) {
+	if nodeID == rb.raftID {
+		rb.logger.Warningf("this node was removed from the raft group")
+	}
+
+	pt, ok := rb.peerTransports[nodeID]
+	if ok {
+		pt.stop()
+		delete(rb.peerTransports, nodeID)
+	}
+
+	err := rb.store.removePeer(nodeID)
+	if err != nil {
+		rb.logger.Errorf("can't remove address of node [%d]: %s", nodeID, err.Error())
+	}
+
+	if rb.cip != nil {
+		rb.cip.RemoveNode(nodeID)
+		rb.connCache.CloseConnection(nodeID)
+	}
+	rb.logger.Infof("removed node [%d]", nodeID)
+}
+
+// nodes that joined after the cluster info was written are only known to the raft storage
+func (rb *raftBackend) restorePeers() error {
+	peers, err := rb.store.loadPeers()
+	if err != nil {
+		return err
+	}
+
+	for nodeID, address := range peers {
+		rb.setPeerAddress(nodeID, address)
+	}
+	return nil
+}
+
+func (rb *raftBackend) snapshotPeers() ([]*pb.RaftPeer, error) {
+	peers, err := rb.store.loadPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	raftPeers := make([]*pb.RaftPeer, 0, len(peers))
+	for nodeID, address := range peers {
+		raftPeers = append(raftPeers, &pb.RaftPeer{
+			RaftNodeId:  nodeID,
+			PeerAddress: address,
+		})
+	}
+	return raftPeers, nil
+}
+
+// the snapshot replaces the address book
+// members that left before the snapshot was taken aren't part of the conf state anymore
+func (rb *raftBackend) installSnapshotPeers(confState raftpb.ConfState, peers []*pb.RaftPeer) {
+	if len(peers) == 0 {
+		// the snapshot was taken before addresses were part of snapshots
+		return
+	}
+
+	members := make(map[uint64]bool)
+	for _, nodeID := range confState.Nodes {
+		members[nodeID] = true
+	}
+	for _, nodeID := range confState.Learners {
+		members[nodeID] = true
+	}
+
+	for idx := range peers {
+		if members[peers[idx].RaftNodeId] {
+			rb.setPeerAddress(peers[idx].RaftNodeId, peers[idx].PeerAddress)
+		}
+	}
+
+	knownPeers, err := rb.store.loadPeers()
+	if err != nil {
+		rb.logger.Errorf("can't load peers: %s", err.Error())
+		return
+	}
+
+	for nodeID := range knownPeers {
+		if !members[nodeID] {
+			rb.removePeer(nodeID)
+		}
+	}
+}
+
+// the returned channel receives nil once the conf change with the provided id was applied
+// or an error if the change was dropped
+func (rb *raftBackend) waitForConfChange(id uint64) <-chan error {
+	c := make(chan error, 1)
+	rb.confChangeWaitersMutex.Lock()
+	defer rb.confChangeWaitersMutex.Unlock()
+	rb.confChangeWaiters[id] = c
+	return c
+}
+
+func (rb *raftBackend) cancelConfChangeWaiter(id uint64) {
+	rb.confChangeWaitersMutex.Lock()
+	defer rb.confChangeWaitersMutex.Unlock()
+	delete(rb.confChangeWaiters, id)
+}
+
+func (rb *raftBackend) notifyConfChangeWaiter(id uint64, err error) {
+	rb.confChangeWaitersMutex.Lock()
+	defer rb.confChangeWaitersMutex.Unlock()
+	c, ok := rb.confChangeWaiters[id]
+	if ok {
+		c <- err
+		delete(rb.confChangeWaiters, id)
+	}
+}
+
+// the conf state is written by the state machine and read by proposers of conf changes
+// the cluster info learns who is a learner from the conf state
+// that way the execution engine treats added and promoted nodes accordingly
+func (rb *raftBackend) setConfState(confState *raftpb.ConfState) {
+	rb.confStateMutex.Lock()
+	rb.confState = confState
+	rb.confStateMutex.Unlock()
+
+	if rb.cip != nil {
+		rb.cip.SetLearners(confState.Learners)
+	}
+}
+
+func (rb *raftBackend) isLearner(nodeID uint64) bool {
+	rb.confStateMutex.RLock()
+	defer rb.confStateMutex.RUnlock()
+	for _, learnerID := range rb.confState.Learners {
+		if learnerID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (rb *raftBackend) isVoter(nodeID uint64) bool {
+	rb.confStateMutex.RLock()
+	defer rb.confStateMutex.RUnlock()
+	for _, voterID := range rb.confState.Nodes {
+		if voterID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// a conf change that is applied already doesn't need to be proposed again
+// that way retrying a membership change that failed half way only touches the missing groups
+func (rb *raftBackend) hasConfChange(cc raftpb.ConfChange) bool {
+	switch cc.Type {
+	case raftpb.ConfChangeAddLearnerNode:
+		return rb.isLearner(cc.NodeID) || rb.isVoter(cc.NodeID)
+	case raftpb.ConfChangeAddNode:
+		return rb.isVoter(cc.NodeID)
+	case raftpb.ConfChangeRemoveNode:
+		return !rb.isLearner(cc.NodeID) && !rb.isVoter(cc.NodeID)
+	}
+	return false
+}
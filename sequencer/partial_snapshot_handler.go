@@ -0,0 +1,271 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func newPartitionedSnapshotHandler(partialHandler PartialSnapshotHandler, cip util.ClusterInfoProvider, logger *log.Entry) *partitionedSnapshotHandler {
+	return &partitionedSnapshotHandler{
+		partialHandler: partialHandler,
+		cip:            cip,
+		logger:         logger,
+	}
+}
+
+// the partitioned snapshot handler assembles raft snapshots partition by partition
+// the data of a snapshot is a PartitionedSnapshot with one entry per partition of this node
+// when a snapshot is consumed, each partition is handed to the partial handler on its own
+type partitionedSnapshotHandler struct {
+	partialHandler PartialSnapshotHandler
+	cip            util.ClusterInfoProvider
+	logger         *log.Entry
+}
+
+// a snapshot needs to contain all partitions
+// otherwise the log can't be compacted
+func (h *partitionedSnapshotHandler) Provide(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) ([]byte, error) {
+	partitionIDs := append([]int{}, h.cip.MyPartitions()...)
+	sort.Ints(partitionIDs)
+
+	ps := &pb.PartitionedSnapshot{
+		PartitionIDs: make([]uint64, len(partitionIDs)),
+		Snapshots:    make([][]byte, len(partitionIDs)),
+	}
+
+	for idx := range partitionIDs {
+		data, err := h.partialHandler.Provide(partitionIDs[idx], lastSnapshot, entriesAppliedSinceLastSnapshot)
+		if err != nil {
+			return nil, fmt.Errorf("can't provide snapshot for partition [%d]: %s", partitionIDs[idx], err.Error())
+		}
+
+		ps.PartitionIDs[idx] = uint64(partitionIDs[idx])
+		ps.Snapshots[idx] = data
+	}
+
+	return ps.Marshal()
+}
+
+// only partitions owned by this node are consumed
+// each partition is handed to the partial handler on its own
+func (h *partitionedSnapshotHandler) Consume(snapshotData []byte) error {
+	return ConsumePartitionedSnapshot(bytes.NewReader(snapshotData), int64(len(snapshotData)), h.cip.MyPartitions(), h.consumePartition)
+}
+
+// each partition is read from the file on its own
+func (h *partitionedSnapshotHandler) ConsumeFile(snapshotPath string) error {
+	f, err := os.Open(snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return ConsumePartitionedSnapshot(f, info.Size(), h.cip.MyPartitions(), h.consumePartition)
+}
+
+func (h *partitionedSnapshotHandler) consumePartition(partitionID int, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return h.partialHandler.Consume(partitionID, data)
+}
+
+// a follower only needs the partitions it holds
+func (h *partitionedSnapshotHandler) Target(snapshotData []byte, recipientID uint64) ([]byte, error) {
+	return TargetPartitionedSnapshot(snapshotData, h.cip.PartitionsForNode(recipientID))
+}
+
+// TargetPartitionedSnapshot drops all partitions from a marshaled PartitionedSnapshot that aren't provided.
+// It fails if the snapshot doesn't contain all provided partitions. Nodes own different partitions
+// and a snapshot without all partitions of the receiver would lose their data.
+func TargetPartitionedSnapshot(snapshotData []byte, partitionIDs []int) ([]byte, error) {
+	ps := &pb.PartitionedSnapshot{}
+	err := ps.Unmarshal(snapshotData)
+	if err != nil {
+		return nil, err
+	} else if len(ps.PartitionIDs) != len(ps.Snapshots) {
+		return nil, fmt.Errorf("snapshot contains [%d] partition ids but [%d] snapshots", len(ps.PartitionIDs), len(ps.Snapshots))
+	}
+
+	wanted := make(map[uint64]bool, len(partitionIDs))
+	for _, partitionID := range partitionIDs {
+		wanted[uint64(partitionID)] = true
+	}
+
+	targeted := &pb.PartitionedSnapshot{}
+	for idx := range ps.PartitionIDs {
+		if wanted[ps.PartitionIDs[idx]] {
+			delete(wanted, ps.PartitionIDs[idx])
+			targeted.PartitionIDs = append(targeted.PartitionIDs, ps.PartitionIDs[idx])
+			targeted.Snapshots = append(targeted.Snapshots, ps.Snapshots[idx])
+		}
+	}
+
+	if len(wanted) > 0 {
+		missing := make([]int, 0, len(wanted))
+		for partitionID := range wanted {
+			missing = append(missing, int(partitionID))
+		}
+		sort.Ints(missing)
+		return nil, fmt.Errorf("snapshot doesn't contain partitions %v", missing)
+	}
+	return targeted.Marshal()
+}
+
+// ConsumePartitionedSnapshot hands the provided partitions of a marshaled PartitionedSnapshot to consume one by one.
+// Partitions of the snapshot that aren't provided are skipped. A provided partition that's missing
+// from the snapshot fails the snapshot.
+// A partition that can't be consumed doesn't prevent the others from being consumed,
+// but the snapshot as a whole fails and so raft doesn't consider it installed.
+// Each partition is read from r while it's consumed. The partitions don't need to fit into memory together.
+func ConsumePartitionedSnapshot(r io.ReaderAt, size int64, partitionIDs []int, consume func(partitionID int, r io.Reader) error) error {
+	partitions, err := indexPartitionedSnapshot(r, size)
+	if err != nil {
+		return err
+	}
+
+	failures := make([]string, 0)
+	for _, partitionID := range partitionIDs {
+		section, ok := partitions[partitionID]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("partition [%d]: missing from snapshot", partitionID))
+			continue
+		}
+
+		err = consume(partitionID, section)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("partition [%d]: %s", partitionID, err.Error()))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("can't consume snapshot: %s", strings.Join(failures, ", "))
+	}
+	return nil
+}
+
+// finds the data of each partition in a marshaled PartitionedSnapshot without reading it
+func indexPartitionedSnapshot(r io.ReaderAt, size int64) (map[int]*io.SectionReader, error) {
+	br := &byteReaderAt{r: r}
+	partitionIDs := make([]uint64, 0)
+	sections := make([]*io.SectionReader, 0)
+	for br.offset < size {
+		tag, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+
+		fieldNum, wireType := tag>>3, tag&0x7
+		switch {
+		case fieldNum == 1 && wireType == proto.WireVarint:
+			partitionID, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+			partitionIDs = append(partitionIDs, partitionID)
+
+		case wireType == proto.WireBytes:
+			length, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			} else if length > uint64(size-br.offset) {
+				return nil, fmt.Errorf("field [%d] of partitioned snapshot exceeds snapshot size [%d]", fieldNum, size)
+			}
+
+			end := br.offset + int64(length)
+			if fieldNum == 1 {
+				// packed partition ids
+				for br.offset < end {
+					partitionID, err := binary.ReadUvarint(br)
+					if err != nil {
+						return nil, err
+					}
+					partitionIDs = append(partitionIDs, partitionID)
+				}
+			} else if fieldNum == 2 {
+				sections = append(sections, io.NewSectionReader(r, br.offset, int64(length)))
+			}
+			br.offset = end
+
+		case wireType == proto.WireVarint:
+			_, err = binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+
+		case wireType == proto.WireFixed64:
+			br.offset += 8
+
+		case wireType == proto.WireFixed32:
+			br.offset += 4
+
+		default:
+			return nil, fmt.Errorf("unexpected wire type [%d] in partitioned snapshot", wireType)
+		}
+	}
+
+	if br.offset != size {
+		return nil, fmt.Errorf("partitioned snapshot is truncated")
+	} else if len(partitionIDs) != len(sections) {
+		return nil, fmt.Errorf("snapshot contains [%d] partition ids but [%d] snapshots", len(partitionIDs), len(sections))
+	}
+
+	partitions := make(map[int]*io.SectionReader, len(partitionIDs))
+	for idx := range partitionIDs {
+		partitions[int(partitionIDs[idx])] = sections[idx]
+	}
+	return partitions, nil
+}
+
+type byteReaderAt struct {
+	r      io.ReaderAt
+	offset int64
+}
+
+func (br *byteReaderAt) ReadByte() (byte, error) {
+	b := make([]byte, 1)
+	n, err := br.r.ReadAt(b, br.offset)
+	if n < 1 {
+		if err == nil || err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+
+	br.offset++
+	return b[0], nil
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestPartitionedSnapshotHandler(t *testing.T) {
+	srcCip := &mocks.ClusterInfoProvider{}
+	srcCip.On("MyPartitions").Return([]int{3, 1, 2})
+	srcPartialHandler := &mocks.PartialSnapshotHandler{}
+	for _, partitionID := range []int{1, 2, 3} {
+		srcPartialHandler.On("Provide", partitionID, mock.Anything, mock.Anything).Return([]byte(fmt.Sprintf("narf_%d", partitionID)), nil)
+	}
+	srcHandler := newPartitionedSnapshotHandler(srcPartialHandler, srcCip, log.WithFields(log.Fields{}))
+
+	data, err := srcHandler.Provide(raftpb.Snapshot{}, nil)
+	assert.Nil(t, err)
+	ps := &pb.PartitionedSnapshot{}
+	err = ps.Unmarshal(data)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, ps.PartitionIDs)
+	assert.Equal(t, "narf_2", string(ps.Snapshots[1]))
+
+	// a follower that holds partition 2 only gets partition 2
+	srcCip.On("PartitionsForNode", uint64(7)).Return([]int{2})
+	targeted, err := srcHandler.Target(data, uint64(7))
+	assert.Nil(t, err)
+	ps = &pb.PartitionedSnapshot{}
+	err = ps.Unmarshal(targeted)
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{2}, ps.PartitionIDs)
+	assert.Equal(t, "narf_2", string(ps.Snapshots[0]))
+
+	// a follower that holds a partition the snapshot doesn't have can't get the snapshot
+	srcCip.On("PartitionsForNode", uint64(8)).Return([]int{2, 4})
+	_, err = srcHandler.Target(data, uint64(8))
+	assert.NotNil(t, err)
+
+	// this node owns partition 2 and 3 and the restore of partition 2 fails
+	// partition 3 is consumed anyway but the snapshot fails
+	// partition 1 isn't consumed at all
+	dstCip := &mocks.ClusterInfoProvider{}
+	dstCip.On("MyPartitions").Return([]int{2, 3})
+	dstPartialHandler := &mocks.PartialSnapshotHandler{}
+	dstPartialHandler.On("Consume", 2, []byte("narf_2")).Return(fmt.Errorf("narf"))
+	dstPartialHandler.On("Consume", 3, []byte("narf_3")).Return(nil)
+	dstHandler := newPartitionedSnapshotHandler(dstPartialHandler, dstCip, log.WithFields(log.Fields{}))
+
+	err = dstHandler.Consume(data)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "partition [2]")
+	dstPartialHandler.AssertNumberOfCalls(t, "Consume", 2)
+	dstPartialHandler.AssertCalled(t, "Consume", 3, []byte("narf_3"))
+
+	err = dstHandler.Consume(data[:len(data)-1])
+	assert.NotNil(t, err)
+
+	// a partition that's missing from the snapshot fails the snapshot
+	dstCip = &mocks.ClusterInfoProvider{}
+	dstCip.On("MyPartitions").Return([]int{3, 4})
+	dstPartialHandler = &mocks.PartialSnapshotHandler{}
+	dstPartialHandler.On("Consume", 3, []byte("narf_3")).Return(nil)
+	dstHandler = newPartitionedSnapshotHandler(dstPartialHandler, dstCip, log.WithFields(log.Fields{}))
+	err = dstHandler.Consume(data)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "partition [4]")
+	dstPartialHandler.AssertNumberOfCalls(t, "Consume", 1)
+
+	// a snapshot can't be taken if one partition fails
+	srcPartialHandler = &mocks.PartialSnapshotHandler{}
+	srcPartialHandler.On("Provide", 1, mock.Anything, mock.Anything).Return([]byte("narf_1"), nil)
+	srcPartialHandler.On("Provide", 2, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("narf"))
+	srcHandler = newPartitionedSnapshotHandler(srcPartialHandler, srcCip, log.WithFields(log.Fields{}))
+	_, err = srcHandler.Provide(raftpb.Snapshot{}, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"time"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+const (
+	// messages that don't fit into the queue are dropped
+	// raft sends them again
+	peerQueueSize = 4096
+	// the number of messages that were sent to a peer but not answered yet
+	maxInflightSteps        = 256
+	peerReconnectBackoff    = 100 * time.Millisecond
+	maxPeerReconnectBackoff = 5 * time.Second
+)
+
+func newPeerTransport(peerID uint64, groupID uint32, connCache util.ConnectionCache, reportUnreachable func(uint64), logger *log.Entry) *peerTransport {
+	pt := &peerTransport{
+		peerID:            peerID,
+		groupID:           groupID,
+		connCache:         connCache,
+		reportUnreachable: reportUnreachable,
+		queue:             make(chan raftpb.Message, peerQueueSize),
+		stopChan:          make(chan struct{}),
+		logger:            logger,
+	}
+
+	go pt.run()
+	return pt
+}
+
+// a peer transport sends all messages to a particular peer in order over a single stream
+// the stream is kept open for as long as possible and opened again if it breaks
+// the raft state machine only puts messages into the queue and never waits for a peer
+type peerTransport struct {
+	peerID            uint64
+	groupID           uint32
+	connCache         util.ConnectionCache
+	reportUnreachable func(uint64)
+	queue             chan raftpb.Message
+	stopChan          chan struct{}
+	logger            *log.Entry
+}
+
+func (pt *peerTransport) send(msg raftpb.Message) {
+	select {
+	case pt.queue <- msg:
+	default:
+		// the peer can't keep up
+		// raft backs off when it thinks the peer is unreachable
+		pt.logger.Warningf("queue for peer [%d] is full, dropping message", pt.peerID)
+		pt.reportUnreachable(pt.peerID)
+	}
+}
+
+func (pt *peerTransport) stop() {
+	close(pt.stopChan)
+}
+
+func (pt *peerTransport) run() {
+	backoff := peerReconnectBackoff
+	for {
+		sent, err := pt.stream()
+		if err == nil {
+			// stopped
+			return
+		}
+
+		pt.logger.Errorf("stream to peer [%d] broke: %s", pt.peerID, err.Error())
+		pt.reportUnreachable(pt.peerID)
+		if sent {
+			backoff = peerReconnectBackoff
+		}
+
+		select {
+		case <-pt.stopChan:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = 2 * backoff
+		if backoff > maxPeerReconnectBackoff {
+			backoff = maxPeerReconnectBackoff
+		}
+	}
+}
+
+// sends messages until the transport is stopped or the stream breaks
+// returns whether any message made it to the peer
+func (pt *peerTransport) stream() (bool, error) {
+	client, err := pt.connCache.GetRaftTransportClient(pt.peerID)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.StepStream(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// every response frees up room for another message
+	inflight := make(chan struct{}, maxInflightSteps)
+	recvErrChan := make(chan error, 1)
+	go pt.receive(stream, inflight, recvErrChan)
+
+	sent := false
+	for {
+		select {
+		case <-pt.stopChan:
+			stream.CloseSend()
+			return sent, nil
+		case err = <-recvErrChan:
+			return sent, err
+		case msg := <-pt.queue:
+			select {
+			case inflight <- struct{}{}:
+			case <-pt.stopChan:
+				stream.CloseSend()
+				return sent, nil
+			case err = <-recvErrChan:
+				return sent, err
+			}
+
+			err = stream.Send(&pb.StepRequest{
+				Message: &msg,
+				GroupId: pt.groupID,
+			})
+			if err != nil {
+				return sent, err
+			}
+			sent = true
+		}
+	}
+}
+
+func (pt *peerTransport) receive(stream pb.RaftTransport_StepStreamClient, inflight <-chan struct{}, recvErrChan chan<- error) {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			recvErrChan <- err
+			return
+		} else if resp.Error != "" {
+			pt.logger.Errorf("peer [%d] can't step message: %s", pt.peerID, resp.Error)
+		}
+		<-inflight
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/raftpb"
+	"google.golang.org/grpc"
+)
+
+func TestPeerTransportBasic(t *testing.T) {
+	server := &testStepServer{
+		msgs: make(chan raftpb.Message, 100),
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	srvr := grpc.NewServer()
+	defer srvr.Stop()
+	pb.RegisterRaftTransportServer(srvr, server)
+	go srvr.Serve(lis)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	// the first connection attempt fails
+	mockCC := new(mocks.ConnectionCache)
+	mockCC.On("GetRaftTransportClient", uint64(2)).Return(nil, fmt.Errorf("narf")).Once()
+	mockCC.On("GetRaftTransportClient", uint64(2)).Return(pb.NewRaftTransportClient(conn), nil)
+	var numUnreachable int32
+	reportUnreachable := func(peerID uint64) {
+		atomic.AddInt32(&numUnreachable, 1)
+	}
+
+	pt := newPeerTransport(uint64(2), 0, mockCC, reportUnreachable, log.WithFields(log.Fields{}))
+	for i := 1; i <= 100; i++ {
+		pt.send(raftpb.Message{To: uint64(2), Index: uint64(i)})
+	}
+
+	for i := 1; i <= 100; i++ {
+		select {
+		case msg := <-server.msgs:
+			assert.Equal(t, uint64(i), msg.Index)
+		case <-time.After(5 * time.Second):
+			assert.FailNow(t, "message didn't arrive")
+		}
+	}
+
+	// all messages go through the same stream
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.numStreams))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numUnreachable))
+	pt.stop()
+}
+
+func TestPeerTransportDoesntBlock(t *testing.T) {
+	mockCC := new(mocks.ConnectionCache)
+	mockCC.On("GetRaftTransportClient", uint64(2)).Return(nil, fmt.Errorf("narf"))
+	var numUnreachable int32
+	reportUnreachable := func(peerID uint64) {
+		atomic.AddInt32(&numUnreachable, 1)
+	}
+
+	pt := newPeerTransport(uint64(2), 0, mockCC, reportUnreachable, log.WithFields(log.Fields{}))
+	start := time.Now()
+	for i := 0; i < peerQueueSize+10; i++ {
+		pt.send(raftpb.Message{To: uint64(2)})
+	}
+
+	assert.True(t, time.Since(start) < time.Second)
+	assert.True(t, atomic.LoadInt32(&numUnreachable) >= int32(10))
+	pt.stop()
+}
+
+type testStepServer struct {
+	msgs       chan raftpb.Message
+	numStreams int32
+}
+
+func (s *testStepServer) StepStream(stream pb.RaftTransport_StepStreamServer) error {
+	atomic.AddInt32(&s.numStreams, 1)
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		s.msgs <- *req.Message
+		err = stream.Send(&pb.StepResponse{})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *testStepServer) SnapshotStream(stream pb.RaftTransport_SnapshotStreamServer) error {
+	return nil
+}
+
+func (s *testStepServer) Propose(ctx context.Context, req *pb.ProposeRequest) (*pb.ProposeResponse, error) {
+	return &pb.ProposeResponse{}, nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"fmt"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// A batch can end up in the log more than once if a proposal times out
+// after raft appended it already and the proposer can't tell.
+// Every replica remembers the proposal ids of the batches it applied recently
+// and drops the txns and stored procedure changes of batches it saw before.
+// Which batches are dropped only depends on the log and the last snapshot
+// so all replicas (and a restarted replica) agree on them.
+
+// the number of log entries a proposal id is remembered for
+// it needs to be the same on all replicas
+const proposalDedupWindow = uint64(10000)
+
+// each applied proposal is stored as its id followed by its raft index
+const appliedProposalSize = 16
+
+type appliedProposal struct {
+	id    uint64
+	index uint64
+}
+
+type appliedProposals struct {
+	indexes map[uint64]uint64
+	// in the order they were applied
+	order []appliedProposal
+}
+
+func newAppliedProposals() *appliedProposals {
+	return &appliedProposals{
+		indexes: make(map[uint64]uint64),
+		order:   make([]appliedProposal, 0),
+	}
+}
+
+// returns false if the proposal was applied before
+// otherwise it's remembered as applied at index
+// batches without a proposal id are never dropped
+func (ap *appliedProposals) apply(proposalID uint64, index uint64) bool {
+	if proposalID == 0 {
+		return true
+	} else if _, ok := ap.indexes[proposalID]; ok {
+		return false
+	}
+
+	for len(ap.order) > 0 && ap.order[0].index+proposalDedupWindow <= index {
+		delete(ap.indexes, ap.order[0].id)
+		ap.order = ap.order[1:]
+	}
+
+	ap.indexes[proposalID] = index
+	ap.order = append(ap.order, appliedProposal{id: proposalID, index: index})
+	return true
+}
+
+// marks the batches up to hi that were applied before
+// and remembers all others
+func (ap *appliedProposals) applyBatches(batches []loggedBatch, hi uint64) {
+	for idx := range batches {
+		if batches[idx].entry.Index > hi {
+			return
+		}
+		batches[idx].duplicate = !ap.apply(batches[idx].batch.ProposalId, batches[idx].entry.Index)
+	}
+}
+
+func (ap *appliedProposals) marshal() []byte {
+	buf := make([]byte, len(ap.order)*appliedProposalSize)
+	for idx := range ap.order {
+		offset := idx * appliedProposalSize
+		util.Uint64ToBytesInto(ap.order[idx].id, buf[offset:offset+8])
+		util.Uint64ToBytesInto(ap.order[idx].index, buf[offset+8:offset+appliedProposalSize])
+	}
+	return buf
+}
+
+func unmarshalAppliedProposals(data []byte) (*appliedProposals, error) {
+	if len(data)%appliedProposalSize != 0 {
+		return nil, fmt.Errorf("applied proposals have an invalid length [%d]", len(data))
+	}
+
+	ap := newAppliedProposals()
+	for offset := 0; offset < len(data); offset += appliedProposalSize {
+		id := util.BytesToUint64(data[offset : offset+8])
+		index := util.BytesToUint64(data[offset+8 : offset+appliedProposalSize])
+		ap.indexes[id] = index
+		ap.order = append(ap.order, appliedProposal{id: id, index: index})
+	}
+	return ap, nil
+}
+
+// the proposals applied at the index of the snapshot
+func snapshotAppliedProposals(snap raftpb.Snapshot) (*appliedProposals, error) {
+	if raft.IsEmptySnap(snap) {
+		return newAppliedProposals(), nil
+	}
+
+	rs := &pb.RaftSnapshot{}
+	err := rs.Unmarshal(snap.Data)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAppliedProposals(rs.AppliedProposals)
+}
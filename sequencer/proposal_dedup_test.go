@@ -0,0 +1,122 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestAppliedProposals(t *testing.T) {
+	ap := newAppliedProposals()
+	assert.True(t, ap.apply(uint64(11), uint64(1)))
+	assert.True(t, ap.apply(uint64(22), uint64(2)))
+	assert.False(t, ap.apply(uint64(11), uint64(3)))
+	// batches without proposal id are never dropped
+	assert.True(t, ap.apply(uint64(0), uint64(4)))
+	assert.True(t, ap.apply(uint64(0), uint64(5)))
+
+	copied, err := unmarshalAppliedProposals(ap.marshal())
+	assert.Nil(t, err)
+	assert.Equal(t, ap.order, copied.order)
+	assert.False(t, copied.apply(uint64(22), uint64(6)))
+
+	// proposals are forgotten once they fall out of the window
+	assert.True(t, ap.apply(uint64(33), proposalDedupWindow+1))
+	assert.Equal(t, 2, len(ap.order))
+	assert.True(t, ap.apply(uint64(11), proposalDedupWindow+1))
+	assert.False(t, ap.apply(uint64(22), proposalDedupWindow+1))
+
+	_, err = unmarshalAppliedProposals([]byte("narf"))
+	assert.NotNil(t, err)
+}
+
+func TestRaftBackendDropsDuplicateBatches(t *testing.T) {
+	raftID := uint64(1)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestRaftBackendDropsDuplicateBatches-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	logger := log.WithFields(log.Fields{})
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	batch := &pb.TransactionBatch{
+		Transactions: []*pb.Transaction{&pb.Transaction{
+			Id: id.ToProto(),
+		}},
+		ProposalId: util.RandomRaftId(),
+	}
+	bites, err := batch.Marshal()
+	assert.Nil(t, err)
+
+	start := func(durableIndex uint64) (chan proposal, chan *pb.TransactionBatch) {
+		proposeChan := make(chan proposal)
+		txnBatchChan := make(chan *pb.TransactionBatch)
+		newRaftBackend(raftBackendOpts{
+			raftID:                raftID,
+			proposeChan:           proposeChan,
+			proposeConfChangeChan: make(chan raftpb.ConfChange),
+			txnBatchChan:          txnBatchChan,
+			peers:                 peers,
+			storeDir:              storeDir,
+			connCache:             mockCC,
+			tickInterval:          10 * time.Millisecond,
+			durableIndex:          durableIndex,
+			logger:                logger,
+		})
+		return proposeChan, txnBatchChan
+	}
+
+	// the same batch ends up in the log twice
+	// but its txns are only scheduled once
+	proposeChan, txnBatchChan := start(uint64(0))
+	proposeChan <- proposal{data: bites}
+	first := <-txnBatchChan
+	assert.Equal(t, 1, len(first.Transactions))
+	proposeChan <- proposal{data: bites}
+	second := <-txnBatchChan
+	assert.Equal(t, 0, len(second.Transactions))
+	// the duplicate still takes up an epoch like on all other replicas
+	assert.Equal(t, first.Epoch+1, second.Epoch)
+	close(proposeChan)
+	for range txnBatchChan {
+	}
+
+	// the first batch is durable and isn't published again after a restart
+	// the restarted node still knows that it was applied
+	proposeChan, txnBatchChan = start(first.Index)
+	replayed := <-txnBatchChan
+	assert.Equal(t, second.Index, replayed.Index)
+	assert.Equal(t, 0, len(replayed.Transactions))
+	close(proposeChan)
+	for range txnBatchChan {
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// a proposal is given up after this many attempts
+	maxProposalAttempts = 10
+	minProposalBackoff  = 50 * time.Millisecond
+	maxProposalBackoff  = 2 * time.Second
+	proposalTimeout     = 5 * time.Second
+	// a batch the leader accepted but that didn't show up in the log by then
+	// is reported as lost, the leader might have lost its leadership in the meantime
+	pendingProposalTimeout = 10 * time.Second
+)
+
+// raft hands out the soft state whenever the leader changes
+// everybody waiting for a new leader is woken up by closing the change channel
+func (rb *raftBackend) updateLeader(softState *raft.SoftState) {
+	rb.leaderMutex.Lock()
+	defer rb.leaderMutex.Unlock()
+	if softState.Lead == rb.leaderID {
+		return
+	}
+
+	rb.logger.Infof("leader changed from [%d] to [%d]", rb.leaderID, softState.Lead)
+	rb.leaderID = softState.Lead
+	close(rb.leaderChangedChan)
+	rb.leaderChangedChan = make(chan struct{})
+}
+
+// returns the current leader (raft.None if there is none)
+// and a channel that is closed once the leader changes
+func (rb *raftBackend) currentLeader() (uint64, <-chan struct{}) {
+	rb.leaderMutex.Lock()
+	defer rb.leaderMutex.Unlock()
+	return rb.leaderID, rb.leaderChangedChan
+}
+
+// proposals are handed to the leader directly
+// raft doesn't forward proposals itself because it drops them silently if that fails
+// proposals that were dropped are tried again as soon as a new leader is known or after a backoff
+// if dropped is true, the proposal definitely didn't make it into the log
+// otherwise an error means it's unknown whether the proposal made it
+func (rb *raftBackend) propose(data []byte, isConfChange bool) (dropped bool, err error) {
+	backoff := minProposalBackoff
+	for attempt := 1; ; attempt++ {
+		leaderID, leaderChangedChan := rb.currentLeader()
+		retry, err := rb.tryPropose(leaderID, data, isConfChange)
+		if err == nil {
+			return false, nil
+		} else if !retry {
+			return false, err
+		} else if attempt >= maxProposalAttempts {
+			return true, err
+		}
+
+		rb.logger.Warningf("proposal attempt [%d] failed: %s", attempt, err.Error())
+		timer := time.NewTimer(backoff)
+		select {
+		case <-leaderChangedChan:
+		case <-timer.C:
+		case <-rb.stopChan:
+			timer.Stop()
+			return true, fmt.Errorf("raft node [%d] stopped", rb.raftID)
+		}
+		timer.Stop()
+
+		backoff = backoff * 2
+		if backoff > maxProposalBackoff {
+			backoff = maxProposalBackoff
+		}
+	}
+}
+
+// retry is true if the proposal definitely wasn't accepted and can be tried again
+// proposals that failed in any other way might be in the log already
+// trying them again could append them twice
+func (rb *raftBackend) tryPropose(leaderID uint64, data []byte, isConfChange bool) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), proposalTimeout)
+	defer cancel()
+
+	if leaderID == raft.None {
+		return true, fmt.Errorf("there is no leader")
+	} else if leaderID == rb.raftID {
+		err = rb.proposeLocally(ctx, data, isConfChange)
+		return err == raft.ErrProposalDropped, err
+	}
+
+	client, err := rb.connCache.GetRaftTransportClient(leaderID)
+	if err != nil {
+		return true, err
+	}
+
+	resp, err := client.Propose(ctx, &pb.ProposeRequest{
+		From:         rb.raftID,
+		Data:         data,
+		IsConfChange: isConfChange,
+		GroupId:      rb.groupID,
+	})
+	if err != nil {
+		// the request didn't reach the leader if it was unavailable
+		// in all other cases the leader might have accepted the proposal
+		return status.Code(err) == codes.Unavailable, err
+	} else if resp.Error != "" {
+		return resp.Dropped, fmt.Errorf("leader [%d] didn't accept proposal: %s", leaderID, resp.Error)
+	}
+
+	return false, nil
+}
+
+// raft refuses proposals if this node isn't the leader (anymore)
+func (rb *raftBackend) proposeLocally(ctx context.Context, data []byte, isConfChange bool) error {
+	if !isConfChange {
+		return rb.raftNode.Propose(ctx, data)
+	}
+
+	var cc raftpb.ConfChange
+	err := cc.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	return rb.raftNode.ProposeConfChange(ctx, cc)
+}
+
+// a marshaled batch on its way from the sequencer to the raft backend
+// id is the proposal id of the batch or zero for batches that aren't tracked
+type proposal struct {
+	id   uint64
+	data []byte
+}
+
+// a batch this node proposed that didn't show up in the log yet
+type pendingProposal struct {
+	batch *pb.TransactionBatch
+	// zero until the leader accepted the batch
+	accepted time.Time
+	// proposers of the stored procedure changes in the batch wait for these
+	waiters []chan<- error
+}
+
+// batches with txns or stored procedure changes are tracked from the moment they are cut
+// until they show up in the log or it's clear they won't
+// the proposal id is stamped onto the batch before it's marshaled
+func (rb *raftBackend) trackProposal(batch *pb.TransactionBatch, waiters []chan<- error) {
+	batch.ProposalId = util.RandomRaftId()
+	rb.pendingProposalsMutex.Lock()
+	defer rb.pendingProposalsMutex.Unlock()
+	rb.pendingProposals[batch.ProposalId] = &pendingProposal{
+		batch:   batch,
+		waiters: waiters,
+	}
+}
+
+func (rb *raftBackend) proposalAccepted(proposalID uint64) {
+	rb.pendingProposalsMutex.Lock()
+	defer rb.pendingProposalsMutex.Unlock()
+	p, ok := rb.pendingProposals[proposalID]
+	if ok {
+		p.accepted = time.Now()
+	}
+}
+
+// returns nil if the proposal isn't tracked (anymore)
+func (rb *raftBackend) untrackProposal(proposalID uint64) *pendingProposal {
+	rb.pendingProposalsMutex.Lock()
+	defer rb.pendingProposalsMutex.Unlock()
+	p, ok := rb.pendingProposals[proposalID]
+	if !ok {
+		return nil
+	}
+	delete(rb.pendingProposals, proposalID)
+	return p
+}
+
+// called by the state machine once a batch shows up in the log
+func (rb *raftBackend) proposalApplied(proposalID uint64) {
+	p := rb.untrackProposal(proposalID)
+	if p == nil {
+		return
+	}
+
+	for idx := range p.waiters {
+		p.waiters[idx] <- nil
+	}
+}
+
+// accepted batches that don't show up in the log in time are reported as lost
+// they might still show up later, that's why their status is unknown
+func (rb *raftBackend) expireProposals(now time.Time) {
+	expired := make([]*pendingProposal, 0)
+	rb.pendingProposalsMutex.Lock()
+	for proposalID, p := range rb.pendingProposals {
+		if !p.accepted.IsZero() && now.Sub(p.accepted) > pendingProposalTimeout {
+			expired = append(expired, p)
+			delete(rb.pendingProposals, proposalID)
+		}
+	}
+	rb.pendingProposalsMutex.Unlock()
+
+	for idx := range expired {
+		rb.reportLostBatch(expired[idx].batch, expired[idx].waiters, pb.UNKNOWN, fmt.Errorf("batch didn't show up in the log within %s", pendingProposalTimeout.String()))
+	}
+}
+
+// the batch didn't make it into the log (DROPPED) or it's unknown whether it did (UNKNOWN)
+func (rb *raftBackend) reportDroppedBatch(data []byte, status pb.TransactionStatus, err error) {
+	batch := &pb.TransactionBatch{}
+	err2 := batch.Unmarshal(data)
+	if err2 != nil {
+		rb.logger.Errorf("can't read dropped batch: %s", err2.Error())
+		return
+	}
+
+	var waiters []chan<- error
+	p := rb.untrackProposal(batch.ProposalId)
+	if p != nil {
+		waiters = p.waiters
+	}
+	rb.reportLostBatch(batch, waiters, status, err)
+}
+
+// the nodes the txns of a lost batch were submitted to learn
+// that their txns might not have made it into the log
+// proposers of stored procedure changes learn the same
+func (rb *raftBackend) reportLostBatch(batch *pb.TransactionBatch, waiters []chan<- error, status pb.TransactionStatus, err error) {
+	rb.logger.Errorf("lost batch with [%d] txns and [%d] stored procedure changes (%s): %s", len(batch.Transactions), len(batch.StoredProcedures), status.String(), err.Error())
+	procErr := fmt.Errorf("stored procedure change was dropped: %s", err.Error())
+	if status == pb.UNKNOWN {
+		procErr = fmt.Errorf("stored procedure change might not have made it into the log: %s", err.Error())
+	}
+	for idx := range waiters {
+		waiters[idx] <- procErr
+	}
+
+	if rb.droppedTxnChan == nil {
+		return
+	}
+
+	for _, txn := range batch.Transactions {
+		txn.Outcome = &pb.TransactionOutcome{
+			TxnId:  txn.Id,
+			Status: status,
+			Error:  err.Error(),
+			NodeId: rb.raftID,
+		}
+		rb.droppedTxnChan <- txn
+	}
+}
+
+func (rb *raftBackend) Propose(ctx context.Context, req *pb.ProposeRequest) (*pb.ProposeResponse, error) {
+	leaderID, _ := rb.currentLeader()
+	resp := &pb.ProposeResponse{
+		LeaderId: leaderID,
+	}
+
+	if leaderID != rb.raftID {
+		resp.Error = fmt.Sprintf("node [%d] isn't the leader", rb.raftID)
+		resp.Dropped = true
+		return resp, nil
+	}
+
+	err := rb.proposeLocally(ctx, req.Data, req.IsConfChange)
+	if err != nil {
+		resp.Error = err.Error()
+		// raft might have appended the proposal before a timeout for example
+		resp.Dropped = err == raft.ErrProposalDropped
+	}
+	return resp, nil
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/ulid"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"google.golang.org/grpc"
+)
+
+func TestRaftBackendForwardProposals(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	storeDir := "./test-TestRaftBackendForwardProposals-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+
+	// the leader is a single raft node
+	// the follower only knows who the leader is
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{ID: uint64(1)}}
+	leader := newRaftBackend(raftBackendOpts{
+		raftID:                uint64(1),
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             new(mocks.ConnectionCache),
+		tickInterval:          10 * time.Millisecond,
+		logger:                logger,
+	})
+	defer close(proposeChan)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	srvr := grpc.NewServer()
+	defer srvr.Stop()
+	pb.RegisterRaftTransportServer(srvr, leader)
+	go srvr.Serve(lis)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	followerCC := new(mocks.ConnectionCache)
+	followerCC.On("GetRaftTransportClient", uint64(1)).Return(pb.NewRaftTransportClient(conn), nil)
+	droppedTxnChan := make(chan *pb.Transaction, 16)
+	follower := &raftBackend{
+		raftID:                uint64(2),
+		connCache:             followerCC,
+		droppedTxnChan:        droppedTxnChan,
+		leaderMutex:           &sync.Mutex{},
+		leaderChangedChan:     make(chan struct{}),
+		stopChan:              make(chan struct{}),
+		pendingProposals:      make(map[uint64]*pendingProposal),
+		pendingProposalsMutex: &sync.Mutex{},
+		logger:                logger,
+	}
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	batch := &pb.TransactionBatch{
+		Transactions: []*pb.Transaction{&pb.Transaction{
+			Id: id.ToProto(),
+		}},
+	}
+	bites, err := batch.Marshal()
+	assert.Nil(t, err)
+
+	// the proposal is retried once the follower learns about the leader
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		follower.updateLeader(&raft.SoftState{Lead: uint64(1)})
+	}()
+	dropped, err := follower.propose(bites, false)
+	assert.Nil(t, err)
+	assert.False(t, dropped)
+
+	// the leader might need to elect itself first
+	var receivedBatch *pb.TransactionBatch
+	for receivedBatch = range txnBatchChan {
+		if len(receivedBatch.Transactions) > 0 {
+			break
+		}
+	}
+	receivedID, err := ulid.ParseIdFromProto(receivedBatch.Transactions[0].Id)
+	assert.Nil(t, err)
+	assert.Equal(t, id.String(), receivedID.String())
+
+	// nodes that aren't leaders turn proposals down
+	resp, err := follower.Propose(context.Background(), &pb.ProposeRequest{Data: bites})
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), resp.LeaderId)
+	assert.NotEqual(t, "", resp.Error)
+	// the follower can tell that the proposal isn't in the log
+	assert.True(t, resp.Dropped)
+
+	// without a leader, the batch is dropped eventually
+	follower.updateLeader(&raft.SoftState{Lead: raft.None})
+	close(follower.stopChan)
+	dropped, err = follower.propose(bites, false)
+	assert.NotNil(t, err)
+	assert.True(t, dropped)
+	follower.reportDroppedBatch(bites, pb.DROPPED, err)
+	droppedTxn := <-droppedTxnChan
+	droppedID, err := ulid.ParseIdFromProto(droppedTxn.Id)
+	assert.Nil(t, err)
+	assert.Equal(t, id.String(), droppedID.String())
+	assert.Equal(t, pb.DROPPED, droppedTxn.Outcome.Status)
+	assert.Equal(t, uint64(2), droppedTxn.Outcome.NodeId)
+	assert.Equal(t, fmt.Sprintf("raft node [%d] stopped", 2), droppedTxn.Outcome.Error)
+}
+
+func TestRaftBackendReportsLostProposals(t *testing.T) {
+	droppedTxnChan := make(chan *pb.Transaction, 16)
+	rb := &raftBackend{
+		raftID:                uint64(2),
+		droppedTxnChan:        droppedTxnChan,
+		pendingProposals:      make(map[uint64]*pendingProposal),
+		pendingProposalsMutex: &sync.Mutex{},
+		logger:                log.WithFields(log.Fields{}),
+	}
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	newBatch := func() *pb.TransactionBatch {
+		return &pb.TransactionBatch{
+			Transactions: []*pb.Transaction{&pb.Transaction{
+				Id:           id.ToProto(),
+				ReadWriteSet: [][]byte{[]byte("narf")},
+			}},
+			StoredProcedures: []*pb.StoredProcedure{&pb.StoredProcedure{Name: "moep"}},
+			Index:            uint64(99),
+		}
+	}
+
+	// batches that show up in the log release their waiters
+	batch := newBatch()
+	done := make(chan error, 1)
+	rb.trackProposal(batch, []chan<- error{done})
+	assert.NotEqual(t, uint64(0), batch.ProposalId)
+	rb.proposalAccepted(batch.ProposalId)
+	rb.proposalApplied(batch.ProposalId)
+	assert.Nil(t, <-done)
+	assert.Equal(t, 0, len(rb.pendingProposals))
+
+	// accepted batches that don't show up in the log are unknown
+	batch = newBatch()
+	rb.trackProposal(batch, []chan<- error{done})
+	rb.expireProposals(time.Now().Add(time.Hour))
+	assert.Equal(t, 1, len(rb.pendingProposals))
+	rb.proposalAccepted(batch.ProposalId)
+	rb.expireProposals(time.Now().Add(pendingProposalTimeout + time.Second))
+	assert.Equal(t, 0, len(rb.pendingProposals))
+	assert.NotNil(t, <-done)
+	lostTxn := <-droppedTxnChan
+	assert.Equal(t, pb.UNKNOWN, lostTxn.Outcome.Status)
+	assert.Equal(t, uint64(2), lostTxn.Outcome.NodeId)
+
+	// dropped batches fail their waiters too
+	batch = newBatch()
+	rb.trackProposal(batch, []chan<- error{done})
+	bites, err := batch.Marshal()
+	assert.Nil(t, err)
+	rb.reportDroppedBatch(bites, pb.DROPPED, fmt.Errorf("narf"))
+	assert.NotNil(t, <-done)
+	lostTxn = <-droppedTxnChan
+	assert.Equal(t, pb.DROPPED, lostTxn.Outcome.Status)
+	assert.Equal(t, 0, len(rb.pendingProposals))
+}
@@ -18,6 +18,7 @@ package sequencer
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"sync"
 	"time"
@@ -30,109 +31,444 @@ import (
 	"go.etcd.io/etcd/raft/raftpb"
 )
 
-func newRaftBackend(raftID uint64, proposeChan <-chan []byte, proposeConfChangeChan <-chan raftpb.ConfChange, txnBatchChan chan<- *pb.TransactionBatch, peers []raft.Peer, storeDir string, connCache util.ConnectionCache, snapshotHandler SnapshotHandler, logger *log.Entry) *raftBackend {
-	bs, err := openBoltStorage(storeDir, logger)
+type raftBackendOpts struct {
+	raftID    uint64
+	groupID   uint32
+	numGroups uint32
+	// the sequencer proposes batches and conf changes through these
+	proposeChan           <-chan proposal
+	proposeConfChangeChan <-chan raftpb.ConfChange
+	txnBatchChan          chan<- *pb.TransactionBatch
+	// if nil, dropped txns are only logged
+	droppedTxnChan chan<- *pb.Transaction
+	// peers and learners are only used to bootstrap a new raft group
+	peers     []raft.Peer
+	learners  []raft.Peer
+	storeDir  string
+	connCache util.ConnectionCache
+	cip       util.ClusterInfoProvider
+	// if nil, no snapshots are taken
+	snapshotHandler SnapshotHandler
+	// shared by all raft groups of a sequencer with more than one group
+	snapshots    *snapshotCoordinator
+	tickInterval time.Duration
+	// batches up to this index are executed already
+	durableIndex uint64
+	logger       *log.Entry
+}
+
+func newRaftBackend(opts raftBackendOpts) *raftBackend {
+	logger := opts.logger
+	bs, err := openBoltStorage(opts.storeDir, logger)
 	if err != nil {
 		logger.Panicf("%s", err.Error())
 	}
 
 	c := &raft.Config{
-		ID:              raftID,
+		ID:              opts.raftID,
 		ElectionTick:    11,
 		HeartbeatTick:   3,
 		Storage:         bs,
 		MaxSizePerMsg:   1024 * 1024 * 1024, // 1 GB (!!!)
 		MaxInflightMsgs: 256,
-		Logger:          logger,
-	}
-
-	startFromExistingState := false
-	var raftNode raft.Node
-	if startFromExistingState {
-		hardState, _, _ := bs.InitialState()
-		c.Applied = hardState.Commit
-		raftNode = raft.RestartNode(c)
-	} else {
-		for idx := range peers {
-			logger.Infof("raftID: %d", peers[idx].ID)
-		}
-		raftNode = raft.StartNode(c, peers)
+		// followers hand proposals to the leader themselves
+		DisableProposalForwarding: true,
+		Logger:                    logger,
 	}
 
 	rb := &raftBackend{
-		raftID:                  raftID,
-		raftNode:                raftNode,
-		proposeChan:             proposeChan,
-		proposeConfChangeChan:   proposeConfChangeChan,
-		txnBatchChan:            txnBatchChan,
-		connCache:               connCache,
+		raftID:                  opts.raftID,
+		groupID:                 opts.groupID,
+		numGroups:               opts.numGroups,
+		proposeChan:             opts.proposeChan,
+		proposeConfChangeChan:   opts.proposeConfChangeChan,
+		txnBatchChan:            opts.txnBatchChan,
+		droppedTxnChan:          opts.droppedTxnChan,
+		connCache:               opts.connCache,
+		cip:                     opts.cip,
 		store:                   bs,
 		confState:               &raftpb.ConfState{},
 		snapshotFrequency:       1000,
 		numberOfSnapshotsToKeep: 2,
+		snapshotHandler:         opts.snapshotHandler,
+		snapshots:               opts.snapshots,
+		storeDir:                opts.storeDir,
+		snapshotChunkSize:       defaultSnapshotChunkSize,
+		snapshotReceiveMutex:    &sync.Mutex{},
+		snapshotInstallChan:     make(chan snapshotInstall),
+		snapshotWaiters:         make(map[uint64][]chan error),
+		snapshotWaitersMutex:    &sync.Mutex{},
+		peerTransports:          make(map[uint64]*peerTransport),
+		confStateMutex:          &sync.RWMutex{},
+		confChangeWaiters:       make(map[uint64]chan error),
+		confChangeWaitersMutex:  &sync.Mutex{},
+		pendingProposals:        make(map[uint64]*pendingProposal),
+		pendingProposalsMutex:   &sync.Mutex{},
+		appliedProposals:        newAppliedProposals(),
+		leaderMutex:             &sync.Mutex{},
+		leaderChangedChan:       make(chan struct{}),
+		readIndexWaiters:        make(map[string]chan readPosition),
+		readIndexMutex:          &sync.Mutex{},
+		tickInterval:            opts.tickInterval,
+		stopChan:                make(chan struct{}),
 		logger:                  logger,
 	}
 
+	startFromExistingState, err := bs.hasExistingState()
+	if err != nil {
+		logger.Panicf("can't read raft storage: %s", err.Error())
+	}
+
+	if startFromExistingState {
+		err = rb.restoreState(opts.durableIndex)
+		if err != nil {
+			logger.Panicf("can't restore raft state: %s", err.Error())
+		}
+
+		err = rb.restorePeers()
+		if err != nil {
+			logger.Panicf("can't restore peers: %s", err.Error())
+		}
+		// raft only hands out committed entries after this index
+		// that way batches are only scheduled again if their effects aren't durable
+		c.Applied = rb.lastAppliedIndex
+		logger.Infof("restarting raft node from existing state at applied index [%d] epoch [%d] replaying up to [%d]", rb.lastAppliedIndex, rb.lastEpoch, rb.replayIndex)
+		rb.raftNode = raft.RestartNode(c)
+	} else {
+		for idx := range opts.peers {
+			logger.Infof("raftID: %d", opts.peers[idx].ID)
+		}
+		for idx := range opts.learners {
+			logger.Infof("learner raftID: %d", opts.learners[idx].ID)
+		}
+
+		if len(opts.learners) > 0 {
+			err = rb.bootstrapMembers(opts.peers, opts.learners)
+			if err != nil {
+				logger.Panicf("can't bootstrap raft members: %s", err.Error())
+			}
+			rb.raftNode = raft.RestartNode(c)
+		} else {
+			rb.raftNode = raft.StartNode(c, opts.peers)
+		}
+	}
+
 	go rb.runRaftStateMachine()
 	go rb.serveProposalChannels()
 	return rb
 }
 
 type raftBackend struct {
-	raftID                  uint64
-	raftNode                raft.Node
-	proposeChan             <-chan []byte
-	proposeConfChangeChan   <-chan raftpb.ConfChange
-	txnBatchChan            chan<- *pb.TransactionBatch
+	raftID                uint64
+	groupID               uint32 // The raft group of the sequencer this backend runs.
+	numGroups             uint32 // The number of raft groups of the sequencer.
+	raftNode              raft.Node
+	proposeChan           <-chan proposal
+	proposeConfChangeChan <-chan raftpb.ConfChange
+	txnBatchChan          chan<- *pb.TransactionBatch
+	// txns of batches that didn't make it into the log go here
+	droppedTxnChan          chan<- *pb.Transaction
 	store                   *boltStorage
 	lastAppliedIndex        uint64 // The last index that has been applied. It helps us figuring out which entries to publish.
 	lastSnapshotIndex       uint64 // The index of the last snapshot
+	snapshotAttemptIndex    uint64 // The applied index when a snapshot was attempted last.
+	replayIndex             uint64 // The index of the last batch that is published again after a restart.
+	lastEpoch               uint64 // The epoch of the last published batch. Read by the sequencer, use atomics.
+	startEpoch              uint64 // The epoch this node continues after when it restarts.
 	snapshotFrequency       uint64
 	numberOfSnapshotsToKeep int
 	confState               *raftpb.ConfState
+	confStateMutex          *sync.RWMutex
 	connCache               util.ConnectionCache
+	cip                     util.ClusterInfoProvider
 	startChan               chan interface{}
 	snapshotHandler         SnapshotHandler
-	logger                  *log.Entry
+	snapshots               *snapshotCoordinator
+	storeDir                string
+	snapshotChunkSize       int
+	snapshotReceiveMutex    *sync.Mutex
+	// the data of streamed snapshots is installed by the state machine go routine
+	snapshotInstallChan chan snapshotInstall
+	// the last snapshot was streamed from another node and can't be sent on
+	// this node takes a snapshot of its own as soon as it can
+	lastSnapshotWithoutData bool
+	// receivers of streamed snapshots wait for them to be installed
+	snapshotWaiters      map[uint64][]chan error
+	snapshotWaitersMutex *sync.Mutex
+	// only used by the state machine go routine
+	peerTransports map[uint64]*peerTransport
+	// proposers of conf changes wait for them to be applied
+	confChangeWaiters      map[uint64]chan error
+	confChangeWaitersMutex *sync.Mutex
+	appliedConfChanges     []uint64
+	// batches this node proposed that didn't show up in the log yet keyed by their proposal id
+	pendingProposals      map[uint64]*pendingProposal
+	pendingProposalsMutex *sync.Mutex
+	// batches that show up in the log again are dropped, only used by the state machine go routine
+	appliedProposals *appliedProposals
+	leaderID         uint64
+	leaderMutex      *sync.Mutex
+	// closed when the leader changes
+	leaderChangedChan chan struct{}
+	// position of the last published batch, only used by the state machine go routine
+	lastBatchIndex uint64
+	lastBatchTerm  uint64
+	// readers waiting for their read index keyed by request context
+	readIndexWaiters map[string]chan readPosition
+	readIndexMutex   *sync.Mutex
+	readIndexCounter uint64
+	// only used by the state machine go routine
+	pendingReads []pendingRead
+	tickInterval time.Duration
+	stopChan     chan struct{}
+	logger       *log.Entry
+}
+
+// restores the positions in the log this node reached before it was shut down
+// entries after the durable index are published again
+// their effects might not have made it into the data store before the node went down
+func (rb *raftBackend) restoreState(durableIndex uint64) error {
+	// batches up to the durable index were executed already
+	durablePosition := durableIndex
+	snap, err := rb.store.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	snapEpoch, err := snapshotEpoch(snap)
+	if err != nil {
+		return err
+	}
+
+	hardState, confState, err := rb.store.InitialState()
+	if err != nil {
+		return err
+	}
+
+	appliedIndex, err := rb.store.loadAppliedIndex()
+	if err != nil {
+		return err
+	}
+
+	lastPublishedIndex := appliedIndex
+	if lastPublishedIndex > hardState.Commit {
+		lastPublishedIndex = hardState.Commit
+	}
+
+	batches, err := rb.batchesBetween(snap.Metadata.Index, lastPublishedIndex, snapEpoch)
+	if err != nil {
+		return err
+	}
+
+	durableIndex = rb.raftIndexOf(durableIndex, snap.Metadata.Index, snapEpoch, batches, lastPublishedIndex)
+	if appliedIndex > durableIndex {
+		appliedIndex = durableIndex
+	}
+	// everything up to the snapshot was applied by consuming the snapshot
+	if appliedIndex < snap.Metadata.Index {
+		if durableIndex < snap.Metadata.Index {
+			rb.logger.Warningf("durable index [%d] is behind snapshot [%d] and can't be replayed", durableIndex, snap.Metadata.Index)
+		}
+		appliedIndex = snap.Metadata.Index
+	}
+	// raft refuses to start if applied is ahead of committed
+	if appliedIndex > hardState.Commit {
+		appliedIndex = hardState.Commit
+	}
+
+	// batches after the applied index remember their proposal ids when they are published again
+	appliedProposals, err := snapshotAppliedProposals(snap)
+	if err != nil {
+		return err
+	}
+	appliedProposals.applyBatches(batches, appliedIndex)
+
+	// the last batch that is published again
+	var replayIndex uint64
+	lastBatchTerm := snap.Metadata.Term
+	for idx := range batches {
+		if batches[idx].entry.Index > appliedIndex {
+			replayIndex = rb.batchIndex(batches[idx].entry.Index, batches[idx].epoch)
+		} else {
+			lastBatchTerm = batches[idx].entry.Term
+		}
+	}
+
+	if len(confState.Nodes) == 0 && len(confState.Learners) == 0 {
+		confState = snap.Metadata.ConfState
+	}
+
+	if !raft.IsEmptySnap(snap) {
+		rs := &pb.RaftSnapshot{}
+		err = rs.Unmarshal(snap.Data)
+		if err != nil {
+			return err
+		}
+		rb.lastSnapshotWithoutData = rs.WithoutData
+	}
+
+	rb.setConfState(&confState)
+	rb.lastSnapshotIndex = snap.Metadata.Index
+	rb.lastAppliedIndex = appliedIndex
+	rb.replayIndex = replayIndex
+	rb.lastBatchIndex = durablePosition
+	rb.lastBatchTerm = lastBatchTerm
+	rb.appliedProposals = appliedProposals
+	rb.startEpoch = epochAt(batches, appliedIndex, snapEpoch)
+	rb.setLastEpoch(rb.startEpoch)
+	return nil
+}
+
+// the stored procedure registry only lives in memory
+// after a restart the changes contained in the last snapshot and
+// the changes of all entries that aren't published again
+// are sent to the scheduler to rebuild it
+// they don't carry an epoch because they are only there to restore the registry
+func (rb *raftBackend) republishStoredProcedures() {
+	snap, err := rb.store.Snapshot()
+	if err != nil {
+		rb.logger.Panicf("can't read snapshot: %s", err.Error())
+	}
+
+	var epoch uint64
+	if !raft.IsEmptySnap(snap) {
+		rs := &pb.RaftSnapshot{}
+		err = rs.Unmarshal(snap.Data)
+		if err != nil {
+			rb.logger.Panicf("can't read snapshot: %s", err.Error())
+		}
+
+		rb.publishSnapshotStoredProcedures(snap.Metadata, rs, false)
+		epoch = rs.Epoch
+	}
+
+	batches, err := rb.batchesBetween(snap.Metadata.Index, rb.lastAppliedIndex, epoch)
+	if err != nil {
+		rb.logger.Panicf("can't read entries: %s", err.Error())
+	}
+
+	appliedProposals, err := snapshotAppliedProposals(snap)
+	if err != nil {
+		rb.logger.Panicf("can't read snapshot: %s", err.Error())
+	}
+	appliedProposals.applyBatches(batches, rb.lastAppliedIndex)
+
+	for idx := range batches {
+		if len(batches[idx].batch.StoredProcedures) > 0 && !batches[idx].duplicate {
+			rb.txnBatchChan <- &pb.TransactionBatch{
+				StoredProcedures: batches[idx].batch.StoredProcedures,
+				Index:            rb.batchIndex(batches[idx].entry.Index, batches[idx].epoch),
+				Term:             batches[idx].entry.Term,
+			}
+		}
+	}
+}
+
+// the procedures of a snapshot replace whatever the scheduler knew about before
+// with more than one raft group procedures are only sequenced in the first group
+// the snapshots of all other groups don't know about any procedures
+// if withEpoch is set, the batch moves the epoch of this group forward in the scheduler
+func (rb *raftBackend) publishSnapshotStoredProcedures(metadata raftpb.SnapshotMetadata, rs *pb.RaftSnapshot, withEpoch bool) {
+	batch := &pb.TransactionBatch{
+		Index: rb.batchIndex(metadata.Index, rs.Epoch),
+		Term:  metadata.Term,
+	}
+	if rb.groupID == 0 {
+		batch.StoredProcedures = rs.StoredProcedures
+		batch.ReplaceStoredProcedures = true
+	}
+	if withEpoch {
+		batch.Epoch = rs.Epoch
+		batch.SequencerGroup = rb.groupID
+	}
+
+	if len(batch.StoredProcedures) > 0 || batch.ReplaceStoredProcedures || batch.Epoch > 0 {
+		rb.txnBatchChan <- batch
+	}
+}
+
+// with more than one raft group the scheduler needs to know where this group continues after a restart
+// otherwise it waits for batches that were executed before
+func (rb *raftBackend) publishStartEpoch() {
+	if rb.numGroups <= 1 || rb.startEpoch == 0 {
+		return
+	}
+
+	rb.txnBatchChan <- &pb.TransactionBatch{
+		Index:          rb.batchIndex(rb.lastAppliedIndex, rb.startEpoch),
+		Epoch:          rb.startEpoch,
+		SequencerGroup: rb.groupID,
+	}
 }
 
 func (rb *raftBackend) serveProposalChannels() {
+	expiryTicker := time.NewTicker(time.Second)
+	defer expiryTicker.Stop()
 	for {
 		select {
-		case prop := <-rb.proposeChan:
-			if prop == nil {
-				rb.raftNode.Stop()
-				close(rb.txnBatchChan)
+		case prop, ok := <-rb.proposeChan:
+			if !ok {
+				rb.stop()
 				return
 			}
 
-			// blocks until accepted by raft state machine
-			err := rb.raftNode.Propose(context.Background(), prop)
-			if err != nil {
-				rb.logger.Panicf("%s\n", err.Error())
+			dropped, err := rb.propose(prop.data, false)
+			if err != nil && dropped {
+				rb.reportDroppedBatch(prop.data, pb.DROPPED, err)
+			} else if err != nil {
+				// the leader might have accepted the batch anyway
+				rb.reportDroppedBatch(prop.data, pb.UNKNOWN, err)
+			} else if prop.id != 0 {
+				rb.proposalAccepted(prop.id)
 			}
 
+		case <-expiryTicker.C:
+			rb.expireProposals(time.Now())
+
 		case cc, ok := <-rb.proposeConfChangeChan:
 			if !ok {
-				rb.raftNode.Stop()
-				close(rb.txnBatchChan)
+				rb.stop()
 				return
 			}
 
-			// blocks until accepted by raft state machine
-			err := rb.raftNode.ProposeConfChange(context.Background(), cc)
+			// conf changes are proposed by users and raft might refuse them
+			// raft also drops them silently if another conf change is pending
+			// the proposer gives up waiting for the change eventually
+			data, err := cc.Marshal()
+			if err == nil {
+				_, err = rb.propose(data, true)
+			}
 			if err != nil {
-				rb.logger.Panicf("%s\n", err.Error())
+				rb.logger.Errorf("conf change [%s] for node [%d] was dropped: %s", cc.Type.String(), cc.NodeID, err.Error())
+				rb.notifyConfChangeWaiter(cc.ID, fmt.Errorf("conf change [%s] for node [%d] was dropped: %s", cc.Type.String(), cc.NodeID, err.Error()))
 			}
 		}
 	}
 }
 
+// the state machine owns the batch channel and the storage
+// that's why it closes them when it stops
+func (rb *raftBackend) stop() {
+	rb.raftNode.Stop()
+	close(rb.stopChan)
+}
+
 func (rb *raftBackend) runRaftStateMachine() {
-	ticker := time.NewTicker(100 * time.Millisecond)
+	if rb.lastAppliedIndex > 0 {
+		rb.republishStoredProcedures()
+		rb.publishStartEpoch()
+	}
+
+	ticker := time.NewTicker(rb.tickInterval)
 	defer ticker.Stop()
 	for {
 		select {
+		case <-rb.stopChan:
+			rb.stopPeerTransports()
+			rb.store.close()
+			close(rb.txnBatchChan)
+			return
+
 		case <-ticker.C:
 			rb.raftNode.Tick()
 
@@ -143,109 +479,68 @@ func (rb *raftBackend) runRaftStateMachine() {
 			}
 			rb.processReady(rd)
 
+		case si := <-rb.snapshotInstallChan:
+			rb.installSnapshotData(si)
 		}
 	}
 }
 
 func (rb *raftBackend) processReady(rd raft.Ready) {
-	rb.store.saveEntriesAndState(rd.Entries, rd.HardState)
+	if rd.SoftState != nil {
+		rb.updateLeader(rd.SoftState)
+	}
+	// the snapshot replaces the log
+	// entries in the same ready follow the snapshot and go in afterwards
 	if !raft.IsEmptySnap(rd.Snapshot) {
 		rb.publishSnapshot(rd.Snapshot)
 	}
+	rb.store.saveEntriesAndState(rd.Entries, rd.HardState)
 
 	rb.broadcastMessages(rd.Messages)
 	rb.publishEntries(rb.entriesToApply(rd.CommittedEntries))
+	rb.serveReadStates(rd.ReadStates)
+	rb.notifySnapshotWaiters(rb.lastAppliedIndex, nil)
 	rb.maybeTriggerSnapshot()
 	rb.raftNode.Advance()
+
+	// raft only accepts the next conf change once the last one was advanced past
+	// waiters are told afterwards so they can propose the next one right away
+	for _, id := range rb.appliedConfChanges {
+		rb.notifyConfChangeWaiter(id, nil)
+	}
+	rb.appliedConfChanges = rb.appliedConfChanges[:0]
 }
 
+// messages are queued up for each peer and sent in the background
+// snapshots are streamed on their own
 func (rb *raftBackend) broadcastMessages(msgs []raftpb.Message) {
-	// spawn a go routine for every recipient
-	// every go rountine is seeded with a particular recipientID
-	// and will only send messages for this recipient to this recipient
-	// this only works if msgs is not modified
-	wg := &sync.WaitGroup{}
-	peers := make(map[uint64]bool)
 	for idx := range msgs {
-		_, ok := peers[msgs[idx].To]
-		if !ok {
-			peers[msgs[idx].To] = true
-			wg.Add(1)
-			go rb.innerBroadcastMessages(msgs[idx].To, idx, msgs, wg)
+		if msgs[idx].Type == raftpb.MsgSnap {
+			go rb.sendSnapshot(msgs[idx].To, msgs[idx])
+		} else {
+			rb.getPeerTransport(msgs[idx].To).send(msgs[idx])
 		}
 	}
-
-	wg.Wait()
 }
 
-func (rb *raftBackend) innerBroadcastMessages(recipientID uint64, startIdx int, msgs []raftpb.Message, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	client, err := rb.connCache.GetRaftTransportClient(recipientID)
-	if err != nil {
-		rb.raftNode.ReportUnreachable(recipientID)
-		rb.logger.Errorf("%s", err.Error())
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	stream, err := client.StepStream(ctx)
-	if err != nil {
-		rb.raftNode.ReportUnreachable(recipientID)
-		rb.logger.Errorf("%s", err.Error())
-		return
-	}
-
-	indexesToMsgsSent := []int{}
-
-	for idx := startIdx; idx < len(msgs); idx++ {
-		if msgs[idx].To == recipientID {
-			msg := msgs[idx]
-			req := &pb.StepRequest{
-				Message: &msg,
-			}
-
-			err := stream.Send(req)
-			if err != nil {
-				rb.raftNode.ReportUnreachable(recipientID)
-				if msg.Type == raftpb.MsgSnap {
-					rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFailure)
-				}
-				rb.logger.Errorf("%s", err.Error())
-			}
-
-			indexesToMsgsSent = append(indexesToMsgsSent, idx)
-		}
+func (rb *raftBackend) getPeerTransport(peerID uint64) *peerTransport {
+	pt, ok := rb.peerTransports[peerID]
+	if !ok {
+		pt = newPeerTransport(peerID, rb.groupID, rb.connCache, rb.reportUnreachable, rb.logger)
+		rb.peerTransports[peerID] = pt
 	}
+	return pt
+}
 
-	err = stream.CloseSend()
-	if err != nil {
-		rb.raftNode.ReportUnreachable(recipientID)
-		rb.logger.Errorf("%s", err.Error())
-		return
+func (rb *raftBackend) stopPeerTransports() {
+	for peerID, pt := range rb.peerTransports {
+		pt.stop()
+		delete(rb.peerTransports, peerID)
 	}
+}
 
-	for idx := 0; idx < len(indexesToMsgsSent); idx++ {
-		resp, err := stream.Recv()
-		if err != nil {
-			rb.raftNode.ReportUnreachable(recipientID)
-			rb.logger.Errorf("%s", err.Error())
-			if msgs[indexesToMsgsSent[idx]].Type == raftpb.MsgSnap {
-				rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFailure)
-			}
-		} else if resp.Error != "" {
-			rb.raftNode.ReportUnreachable(recipientID)
-			rb.logger.Errorf("%s", resp.Error)
-			if msgs[indexesToMsgsSent[idx]].Type == raftpb.MsgSnap {
-				rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFailure)
-			}
-		}
-
-		if msgs[indexesToMsgsSent[idx]].Type == raftpb.MsgSnap {
-			rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFinish)
-		}
-	}
+func (rb *raftBackend) reportUnreachable(peerID uint64) {
+	rb.raftNode.ReportUnreachable(peerID)
 }
 
 func (rb *raftBackend) entriesToApply(ents []raftpb.Entry) []raftpb.Entry {
@@ -275,6 +570,16 @@ func (rb *raftBackend) publishEntries(ents []raftpb.Entry) {
 
 		rb.lastAppliedIndex = ents[idx].Index
 	}
+
+	// remember how far this node got
+	// a restarted node continues after this index
+	// if the node crashes before this is saved, the entries are published again
+	if len(ents) > 0 {
+		err := rb.store.saveAppliedIndex(rb.lastAppliedIndex)
+		if err != nil {
+			rb.logger.Errorf("Couldn't persist applied index: %s", err.Error())
+		}
+	}
 }
 
 func (rb *raftBackend) publishTransactionBatch(entry raftpb.Entry) {
@@ -288,45 +593,104 @@ func (rb *raftBackend) publishTransactionBatch(entry raftpb.Entry) {
 		rb.logger.Panicf(err.Error())
 	}
 
+	epoch := nextEpoch(rb.getLastEpoch(), batch.Epoch)
+	rb.setLastEpoch(epoch)
+	batch.Epoch = epoch
+	batch.SequencerGroup = rb.groupID
+
+	// stamp the raft position onto the batch and all txns
+	// so that outcomes can be tied back to the log
+	batch.Index = rb.batchIndex(entry.Index, epoch)
+	batch.Term = entry.Term
+	for idx := range batch.Transactions {
+		batch.Transactions[idx].BatchIndex = batch.Index
+		batch.Transactions[idx].BatchTerm = entry.Term
+		batch.Transactions[idx].BatchPosition = uint64(idx)
+	}
+	rb.lastBatchIndex = batch.Index
+	rb.lastBatchTerm = batch.Term
+
+	// a batch that was applied before keeps its epoch but nothing in it is scheduled again
+	if !rb.appliedProposals.apply(batch.ProposalId, entry.Index) {
+		rb.logger.Warningf("dropping batch [%d] at index [%d] because it was applied before", batch.ProposalId, entry.Index)
+		batch.Transactions = nil
+		batch.StoredProcedures = nil
+	} else if batch.ProposalId != 0 {
+		rb.proposalApplied(batch.ProposalId)
+	}
+
 	rb.txnBatchChan <- batch
 }
 
 func (rb *raftBackend) publishConfigChange(entry raftpb.Entry) {
 	var cc raftpb.ConfChange
 	cc.Unmarshal(entry.Data)
-	rb.confState = rb.raftNode.ApplyConfChange(cc)
-	rb.store.saveConfigState(*rb.confState)
+	confState := rb.raftNode.ApplyConfChange(cc)
+	rb.setConfState(confState)
+	rb.store.saveConfigState(*confState)
+	rb.updateAddressBook(cc)
+	rb.appliedConfChanges = append(rb.appliedConfChanges, cc.ID)
 }
 
 func (rb *raftBackend) publishSnapshot(snap raftpb.Snapshot) {
-	if rb.snapshotHandler == nil {
-		return
+	err := rb.installSnapshot(snap)
+	if err != nil {
+		rb.logger.Errorf("%s", err.Error())
+		// the sender of the snapshot is waiting for the outcome
+		rb.notifySnapshotWaiters(snap.Metadata.Index, err)
+	}
+}
+
+// the data of the snapshot was installed before raft got to see the snapshot
+// only the metadata is left to install
+func (rb *raftBackend) installSnapshot(snap raftpb.Snapshot) error {
+	rs := &pb.RaftSnapshot{}
+	err := rs.Unmarshal(snap.Data)
+	if err != nil {
+		return fmt.Errorf("can't read snapshot: %s", err.Error())
 	}
 
-	// call consumer first
-	err := rb.snapshotHandler.Consume(snap.Data)
+	appliedProposals, err := unmarshalAppliedProposals(rs.AppliedProposals)
 	if err != nil {
-		rb.logger.Errorf("Snapshot handler consume failed: %s", err.Error())
-		return
+		return fmt.Errorf("can't read snapshot: %s", err.Error())
 	}
 
-	// store the snapshot on disk
-	err = rb.store.saveSnap(snap)
+	// store the snapshot on disk and start the log after it
+	err = rb.store.applySnap(snap)
 	if err != nil {
-		rb.logger.Errorf("Couldn't persist snapshot: %s", err.Error())
-		return
+		return fmt.Errorf("couldn't persist snapshot: %s", err.Error())
 	}
 
-	rb.confState = &snap.Metadata.ConfState
+	rb.setConfState(&snap.Metadata.ConfState)
+	rb.installSnapshotPeers(snap.Metadata.ConfState, rs.Peers)
 	rb.lastSnapshotIndex = snap.Metadata.Index
+	rb.lastSnapshotWithoutData = rs.WithoutData
 	rb.lastAppliedIndex = snap.Metadata.Index
+	rb.appliedProposals = appliedProposals
+	rb.setLastEpoch(rs.Epoch)
+	err = rb.store.saveAppliedIndex(rb.lastAppliedIndex)
+	if err != nil {
+		rb.logger.Errorf("Couldn't persist applied index: %s", err.Error())
+	}
+
+	rb.publishSnapshotStoredProcedures(snap.Metadata, rs, true)
+	return nil
 }
 
 func (rb *raftBackend) maybeTriggerSnapshot() {
-	if rb.lastAppliedIndex-rb.lastSnapshotIndex < rb.snapshotFrequency || rb.snapshotHandler == nil {
+	lastIndex := rb.lastSnapshotIndex
+	if rb.snapshotAttemptIndex > lastIndex {
+		lastIndex = rb.snapshotAttemptIndex
+	}
+
+	if rb.snapshotHandler == nil {
+		return
+	} else if rb.lastAppliedIndex-lastIndex < rb.snapshotFrequency && !(rb.lastSnapshotWithoutData && rb.lastAppliedIndex > lastIndex) {
 		// we didn't collect enough entries yet to warrant a new snapshot
 		return
 	}
+	// don't try again right away if the snapshot can't be taken
+	rb.snapshotAttemptIndex = rb.lastAppliedIndex
 
 	lastSnapshot, err := rb.store.Snapshot()
 	if err != nil {
@@ -341,14 +705,59 @@ func (rb *raftBackend) maybeTriggerSnapshot() {
 	}
 
 	// get snapshot from data structure
-	data, err := rb.snapshotHandler.Provide(lastSnapshot, entriesSinceLastSnapshot)
+	snapshotIndex := rb.lastAppliedIndex
+	var data []byte
+	err = rb.snapshots.provide(func() error {
+		var err error
+		if csh, ok := rb.snapshotHandler.(CheckpointedSnapshotHandler); ok {
+			snapshotIndex, data, err = csh.ProvideCheckpoint(lastSnapshot, entriesSinceLastSnapshot)
+		} else {
+			data, err = rb.snapshotHandler.Provide(lastSnapshot, entriesSinceLastSnapshot)
+		}
+		return err
+	})
 	if err != nil {
 		rb.logger.Errorf("Snapshot handler provide failed: %s", err.Error())
 		return
 	}
 
+	lastSnapshotEpoch, err := snapshotEpoch(lastSnapshot)
+	if err != nil {
+		rb.logger.Errorf("Can't read last snapshot: %s", err.Error())
+		return
+	}
+
+	batches, err := batchesOf(entriesSinceLastSnapshot, lastSnapshot.Metadata.Index, lastSnapshotEpoch)
+	if err != nil {
+		rb.logger.Errorf("Can't read entries since last snapshot: %s", err.Error())
+		return
+	}
+
+	// the checkpoint of the snapshot handler is a position in the merged order of all groups
+	snapshotIndex = rb.raftIndexOf(snapshotIndex, lastSnapshot.Metadata.Index, lastSnapshotEpoch, batches, rb.lastAppliedIndex)
+	if snapshotIndex > rb.lastAppliedIndex {
+		rb.logger.Errorf("Snapshot index [%d] is ahead of applied index [%d]", snapshotIndex, rb.lastAppliedIndex)
+		return
+	} else if snapshotIndex <= lastSnapshot.Metadata.Index {
+		rb.logger.Debugf("Nothing new to snapshot at index [%d]", snapshotIndex)
+		return
+	}
+
+	appliedProposals, err := snapshotAppliedProposals(lastSnapshot)
+	if err != nil {
+		rb.logger.Errorf("Can't read last snapshot: %s", err.Error())
+		return
+	}
+	appliedProposals.applyBatches(batches, snapshotIndex)
+
+	procs, err := rb.storedProceduresUpTo(lastSnapshot, batches, snapshotIndex)
+	if err != nil {
+		rb.logger.Errorf("Can't collect stored procedures for new snapshot: %s", err.Error())
+		return
+	}
+
 	// bake snapshot object by tossing all the metadata and byte arrays in there
-	snap, err := rb.bakeNewSnapshot(data)
+	snap, err := rb.bakeNewSnapshot(data, procs, appliedProposals, snapshotIndex, epochAt(batches, snapshotIndex, lastSnapshotEpoch))
 	if err != nil {
 		rb.logger.Errorf("Can't bake new snapshot: %s", err.Error())
 		return
@@ -360,6 +769,8 @@ func (rb *raftBackend) maybeTriggerSnapshot() {
 		rb.logger.Errorf("Can't save new snapshot: %s", err.Error())
 		return
 	}
+	rb.lastSnapshotIndex = snapshotIndex
+	rb.lastSnapshotWithoutData = false
 
 	// drop all log entries before the snapshot index
 	err = rb.store.dropLogEntriesBeforeIndex(snap.Metadata.Index)
@@ -374,25 +785,85 @@ func (rb *raftBackend) maybeTriggerSnapshot() {
 	}
 }
 
-func (rb *raftBackend) bakeNewSnapshot(data []byte) (raftpb.Snapshot, error) {
-	hardState, confState, err := rb.store.InitialState()
+// the procedures of the last snapshot plus all changes up to the index of the new snapshot
+// the changes are applied to a registry of their own and compacted
+// that way snapshots only carry the versions that are still around
+// batches that were applied before have to be marked as duplicates already
+func (rb *raftBackend) storedProceduresUpTo(lastSnapshot raftpb.Snapshot, batches []loggedBatch, index uint64) ([]*pb.StoredProcedure, error) {
+	rs := &pb.RaftSnapshot{}
+	err := rs.Unmarshal(lastSnapshot.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := util.NewStoredProcedureRegistry()
+	for idx := range rs.StoredProcedures {
+		registry.Apply(rs.StoredProcedures[idx], lastSnapshot.Metadata.Index)
+	}
+
+	for idx := range batches {
+		entry := batches[idx].entry
+		if entry.Index <= lastSnapshot.Metadata.Index || entry.Index > index {
+			continue
+		} else if batches[idx].duplicate {
+			continue
+		}
+
+		// invalid changes are ignored by the scheduler as well
+		batch := batches[idx].batch
+		for procIdx := range batch.StoredProcedures {
+			registry.Apply(batch.StoredProcedures[procIdx], entry.Index)
+		}
+	}
+	return registry.Compact(), nil
+}
+
+func (rb *raftBackend) bakeNewSnapshot(data []byte, procs []*pb.StoredProcedure, appliedProposals *appliedProposals, index uint64, epoch uint64) (raftpb.Snapshot, error) {
+	_, confState, err := rb.store.InitialState()
+	if err != nil {
+		return raftpb.Snapshot{}, err
+	}
+
+	term, err := rb.store.Term(index)
+	if err != nil {
+		return raftpb.Snapshot{}, err
+	}
+
+	peers, err := rb.snapshotPeers()
+	if err != nil {
+		return raftpb.Snapshot{}, err
+	}
+
+	rs := &pb.RaftSnapshot{
+		Data:             data,
+		StoredProcedures: procs,
+		Peers:            peers,
+		Epoch:            epoch,
+		AppliedProposals: appliedProposals.marshal(),
+	}
+	bites, err := rs.Marshal()
 	if err != nil {
 		return raftpb.Snapshot{}, err
 	}
 
 	metadata := raftpb.SnapshotMetadata{
 		ConfState: confState,
-		Index:     rb.lastAppliedIndex,
-		Term:      hardState.Term,
+		Index:     index,
+		Term:      term,
 	}
 
 	return raftpb.Snapshot{
-		Data:     data,
+		Data:     bites,
 		Metadata: metadata,
 	}, nil
 }
 
 func (rb *raftBackend) StepStream(stream pb.RaftTransport_StepStreamServer) error {
+	return serveStepStream(stream, func(groupID uint32) (*raftBackend, error) { return rb, nil }, rb.logger)
+}
+
+// every message is stepped by the raft group it's addressed to
+func serveStepStream(stream pb.RaftTransport_StepStreamServer, findGroup func(uint32) (*raftBackend, error), logger *log.Entry) error {
 	for {
 		req, err := stream.Recv()
 		if err == io.EOF {
@@ -402,14 +873,20 @@ func (rb *raftBackend) StepStream(stream pb.RaftTransport_StepStreamServer) erro
 		}
 
 		resp := &pb.StepResponse{}
-		err = rb.step(stream.Context(), *req.Message)
+		rb, err := findGroup(req.GroupId)
+		if err == nil && req.Message.Type == raftpb.MsgSnap {
+			// the data of a snapshot needs to be installed before raft sees the snapshot
+			err = fmt.Errorf("snapshots need to be sent through a snapshot stream")
+		} else if err == nil {
+			err = rb.step(stream.Context(), *req.Message)
+		}
 		if err != nil {
 			resp.Error = err.Error()
 		}
 
 		err = stream.Send(resp)
 		if err != nil {
-			rb.logger.Errorf("%s", err.Error())
+			logger.Errorf("%s", err.Error())
 		}
 	}
 }
@@ -18,6 +18,7 @@ package sequencer
 
 import (
 	"crypto/rand"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -34,7 +35,7 @@ import (
 
 func TestRaftBackendBasic(t *testing.T) {
 	raftID := uint64(1)
-	proposeChan := make(chan []byte)
+	proposeChan := make(chan proposal)
 	proposeConfChangeChan := make(chan raftpb.ConfChange)
 	txnBatchChan := make(chan *pb.TransactionBatch)
 	peers := []raft.Peer{raft.Peer{
@@ -47,7 +48,19 @@ func TestRaftBackendBasic(t *testing.T) {
 	mockSH := new(mocks.SnapshotHandler)
 	logger := log.WithFields(log.Fields{})
 
-	newRaftBackend(raftID, proposeChan, proposeConfChangeChan, txnBatchChan, peers, storeDir, mockCC, mockSH, logger)
+	newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		snapshotHandler:       mockSH,
+		tickInterval:          DefaultRaftTickInterval,
+		logger:                logger,
+	})
 	id, err := ulid.NewId()
 	assert.Nil(t, err)
 	batch := &pb.TransactionBatch{
@@ -58,7 +71,7 @@ func TestRaftBackendBasic(t *testing.T) {
 	bites, err := batch.Marshal()
 	assert.Nil(t, err)
 
-	proposeChan <- bites
+	proposeChan <- proposal{data: bites}
 	txnBatch := <-txnBatchChan
 	assert.NotNil(t, txnBatch)
 	assert.Equal(t, 1, len(txnBatch.Transactions))
@@ -69,6 +82,245 @@ func TestRaftBackendBasic(t *testing.T) {
 	close(proposeConfChangeChan)
 }
 
+func TestRaftBackendBootstrapLearners(t *testing.T) {
+	raftID := uint64(1)
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	txnBatchChan := make(chan *pb.TransactionBatch)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	learners := []raft.Peer{raft.Peer{
+		ID:      uint64(2),
+		Context: []byte("moep"),
+	}}
+	storeDir := "./test-TestRaftBackendBootstrapLearners-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	// the learner is never reachable
+	mockCC.On("GetRaftTransportClient", uint64(2)).Return(nil, fmt.Errorf("narf"))
+	logger := log.WithFields(log.Fields{})
+
+	rb := newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		learners:              learners,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		logger:                logger,
+	})
+
+	// the learner doesn't count towards the quorum
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	batch := &pb.TransactionBatch{
+		Transactions: []*pb.Transaction{&pb.Transaction{
+			Id: id.ToProto(),
+		}},
+	}
+	bites, err := batch.Marshal()
+	assert.Nil(t, err)
+	proposeChan <- proposal{data: bites}
+	txnBatch := <-txnBatchChan
+	assert.NotNil(t, txnBatch)
+
+	_, confState, err := rb.store.InitialState()
+	assert.Nil(t, err)
+	assert.Equal(t, []uint64{1}, confState.Nodes)
+	assert.Equal(t, []uint64{2}, confState.Learners)
+
+	entries, err := rb.store.Entries(1, 3, 1024*1024)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	var cc raftpb.ConfChange
+	assert.Nil(t, cc.Unmarshal(entries[0].Data))
+	assert.Equal(t, raftpb.ConfChangeAddNode, cc.Type)
+	assert.Equal(t, uint64(1), cc.NodeID)
+	assert.Nil(t, cc.Unmarshal(entries[1].Data))
+	assert.Equal(t, raftpb.ConfChangeAddLearnerNode, cc.Type)
+	assert.Equal(t, uint64(2), cc.NodeID)
+	close(proposeChan)
+	close(proposeConfChangeChan)
+}
+
+func TestRaftBackendRestartFromExistingState(t *testing.T) {
+	raftID := uint64(1)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestRaftBackendRestartFromExistingState-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	logger := log.WithFields(log.Fields{})
+
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	txnBatchChan := make(chan *pb.TransactionBatch)
+	rb := newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		logger:                logger,
+	})
+
+	var lastIndex uint64
+	for i := 0; i < 3; i++ {
+		proposeChan <- proposal{data: newTestBatch(t)}
+		txnBatch := <-txnBatchChan
+		assert.True(t, txnBatch.Index > lastIndex)
+		lastIndex = txnBatch.Index
+	}
+
+	// shutting down closes the batch channel and the storage
+	close(proposeChan)
+	_, ok := <-txnBatchChan
+	assert.False(t, ok)
+	assert.Equal(t, lastIndex, rb.lastAppliedIndex)
+
+	proposeChan = make(chan proposal)
+	proposeConfChangeChan = make(chan raftpb.ConfChange)
+	txnBatchChan = make(chan *pb.TransactionBatch)
+	rb = newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		durableIndex:          lastIndex,
+		logger:                logger,
+	})
+	assert.Equal(t, lastIndex, rb.lastAppliedIndex)
+	assert.Equal(t, uint64(0), rb.replayIndex)
+	assert.Equal(t, 1, len(rb.confState.Nodes))
+
+	// batches that were published before aren't published again
+	proposeChan <- proposal{data: newTestBatch(t)}
+	txnBatch := <-txnBatchChan
+	assert.True(t, txnBatch.Index > lastIndex)
+	assert.Equal(t, 1, len(txnBatch.Transactions))
+
+	close(proposeChan)
+	_, ok = <-txnBatchChan
+	assert.False(t, ok)
+}
+
+func TestRaftBackendReplayAfterDurableIndex(t *testing.T) {
+	raftID := uint64(1)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestRaftBackendReplayAfterDurableIndex-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	logger := log.WithFields(log.Fields{})
+
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	txnBatchChan := make(chan *pb.TransactionBatch)
+	newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		logger:                logger,
+	})
+
+	procBatch := &pb.TransactionBatch{
+		StoredProcedures: []*pb.StoredProcedure{&pb.StoredProcedure{
+			Name:   "narf",
+			Source: "return 1",
+		}},
+	}
+	bites, err := procBatch.Marshal()
+	assert.Nil(t, err)
+	proposeChan <- proposal{data: bites}
+	durableIndex := (<-txnBatchChan).Index
+
+	indexes := make([]uint64, 2)
+	for i := 0; i < 2; i++ {
+		proposeChan <- proposal{data: newTestBatch(t)}
+		indexes[i] = (<-txnBatchChan).Index
+	}
+
+	close(proposeChan)
+	_, ok := <-txnBatchChan
+	assert.False(t, ok)
+
+	proposeChan = make(chan proposal)
+	proposeConfChangeChan = make(chan raftpb.ConfChange)
+	txnBatchChan = make(chan *pb.TransactionBatch)
+	rb := newRaftBackend(raftBackendOpts{
+		raftID:                raftID,
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             mockCC,
+		tickInterval:          10 * time.Millisecond,
+		durableIndex:          durableIndex,
+		logger:                logger,
+	})
+	assert.Equal(t, durableIndex, rb.lastAppliedIndex)
+	assert.Equal(t, indexes[1], rb.replayIndex)
+
+	// stored procedures before the durable index are published first
+	txnBatch := <-txnBatchChan
+	assert.Equal(t, durableIndex, txnBatch.Index)
+	assert.Equal(t, 0, len(txnBatch.Transactions))
+	assert.Equal(t, 1, len(txnBatch.StoredProcedures))
+	assert.Equal(t, "narf", txnBatch.StoredProcedures[0].Name)
+
+	// batches after the durable index are published again
+	for i := 0; i < 2; i++ {
+		txnBatch = <-txnBatchChan
+		assert.Equal(t, indexes[i], txnBatch.Index)
+		assert.Equal(t, 1, len(txnBatch.Transactions))
+		assert.Equal(t, indexes[i], txnBatch.Transactions[0].BatchIndex)
+	}
+
+	close(proposeChan)
+	for range txnBatchChan {
+	}
+}
+
+func newTestBatch(t *testing.T) []byte {
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	batch := &pb.TransactionBatch{
+		Transactions: []*pb.Transaction{&pb.Transaction{
+			Id: id.ToProto(),
+		}},
+	}
+	bites, err := batch.Marshal()
+	assert.Nil(t, err)
+	return bites
+}
+
 func TestRaftBackendTriggerSnapshotBasic(t *testing.T) {
 	storeDir := "./test-TestRaftBackendTriggerSnapshotBasic-" + util.Uint64ToString(util.RandomRaftId()) + "/"
 	store, err := openBoltStorage(storeDir, log.WithFields(log.Fields{}))
@@ -91,7 +343,7 @@ func TestRaftBackendTriggerSnapshotBasic(t *testing.T) {
 	snap, err = store.Snapshot()
 	assert.Nil(t, err)
 	assert.Equal(t, rb.lastAppliedIndex, snap.Metadata.Index)
-	assert.Equal(t, 2048, len(snap.Data))
+	assert.Equal(t, 2048, len(unwrapSnapshot(t, snap).Data))
 
 	store.close()
 	removeAll(storeDir)
@@ -126,8 +378,11 @@ func TestRaftBackendTriggerSnapshotExistingSnapAndEntries(t *testing.T) {
 		Term:      uint64(3),
 	}
 
-	oldSnapBites := make([]byte, 1024)
-	rand.Read(oldSnapBites)
+	oldData := make([]byte, 1024)
+	rand.Read(oldData)
+	oldRaftSnap := &pb.RaftSnapshot{Data: oldData}
+	oldSnapBites, err := oldRaftSnap.Marshal()
+	assert.Nil(t, err)
 	oldSnap := raftpb.Snapshot{
 		Metadata: metadata,
 		Data:     oldSnapBites,
@@ -158,12 +413,156 @@ func TestRaftBackendTriggerSnapshotExistingSnapAndEntries(t *testing.T) {
 	snap, err = store.Snapshot()
 	assert.Nil(t, err)
 	assert.Equal(t, rb.lastAppliedIndex, snap.Metadata.Index)
-	assert.Equal(t, 2048, len(snap.Data))
+	assert.Equal(t, 2048, len(unwrapSnapshot(t, snap).Data))
 
 	store.close()
 	removeAll(storeDir)
 }
 
+func TestRaftBackendTriggerSnapshotAtCheckpoint(t *testing.T) {
+	storeDir := "./test-TestRaftBackendTriggerSnapshotAtCheckpoint-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	store, err := openBoltStorage(storeDir, log.WithFields(log.Fields{}))
+	assert.Nil(t, err)
+
+	rb := &raftBackend{
+		lastAppliedIndex:  uint64(3),
+		snapshotFrequency: uint64(2),
+		snapshotHandler: &testCheckpointedSnapshotHandler{
+			checkpoint: uint64(2),
+		},
+		store:                   store,
+		numberOfSnapshotsToKeep: 1,
+		logger:                  log.WithFields(log.Fields{}),
+	}
+
+	entries := make([]raftpb.Entry, 3)
+	for idx := range entries {
+		batch := &pb.TransactionBatch{
+			StoredProcedures: []*pb.StoredProcedure{&pb.StoredProcedure{
+				Name:   fmt.Sprintf("proc_%d", idx+1),
+				Source: "return 1",
+				Op:     pb.INSTALL_PROCEDURE,
+			}},
+			ProposalId: uint64(idx + 11),
+		}
+		bites, err := batch.Marshal()
+		assert.Nil(t, err)
+		entries[idx] = raftpb.Entry{
+			Index: uint64(idx + 1),
+			Term:  uint64(1),
+			Data:  bites,
+		}
+	}
+	err = store.saveEntriesAndState(entries, raftpb.HardState{})
+	assert.Nil(t, err)
+
+	rb.maybeTriggerSnapshot()
+
+	// the snapshot is taken at the checkpoint of the handler and not at the applied index
+	snap, err := store.Snapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), snap.Metadata.Index)
+	assert.Equal(t, uint64(2), rb.lastSnapshotIndex)
+	rs := unwrapSnapshot(t, snap)
+	assert.Equal(t, "narf", string(rs.Data))
+	assert.Equal(t, 2, len(rs.StoredProcedures))
+	assert.Equal(t, "proc_1", rs.StoredProcedures[0].Name)
+	assert.Equal(t, "proc_2", rs.StoredProcedures[1].Name)
+	// the snapshot remembers the proposals applied up to the checkpoint
+	appliedProposals, err := unmarshalAppliedProposals(rs.AppliedProposals)
+	assert.Nil(t, err)
+	assert.Equal(t, []appliedProposal{{id: uint64(11), index: uint64(1)}, {id: uint64(12), index: uint64(2)}}, appliedProposals.order)
+
+	// entries after the checkpoint are still around
+	firstIndex, err := store.FirstIndex()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), firstIndex)
+	remaining, err := store.Entries(uint64(3), uint64(4), 1024*1024)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(remaining))
+
+	store.close()
+	removeAll(storeDir)
+}
+
+func TestRaftBackendCompactsStoredProcedures(t *testing.T) {
+	rb := &raftBackend{}
+	lastSnapshot, err := (&pb.RaftSnapshot{
+		StoredProcedures: []*pb.StoredProcedure{
+			&pb.StoredProcedure{Name: "narf", Source: "return 1", Op: pb.INSTALL_PROCEDURE},
+			&pb.StoredProcedure{Name: "narf", Source: "return 2", Op: pb.INSTALL_PROCEDURE},
+		},
+	}).Marshal()
+	assert.Nil(t, err)
+
+	changes := [][]*pb.StoredProcedure{
+		{&pb.StoredProcedure{Name: "narf", Source: "return 3", Op: pb.INSTALL_PROCEDURE}},
+		{&pb.StoredProcedure{Name: "narf", Version: uint64(1), Op: pb.SET_CURRENT_PROCEDURE}},
+		{
+			&pb.StoredProcedure{Name: "narf", Version: uint64(3), Op: pb.REMOVE_PROCEDURE},
+			&pb.StoredProcedure{Name: "narf", Version: uint64(2), Op: pb.REMOVE_PROCEDURE},
+			&pb.StoredProcedure{Name: "moep", Source: "return 4", Op: pb.INSTALL_PROCEDURE},
+		},
+		// after the snapshot index
+		{&pb.StoredProcedure{Name: "zort", Source: "return 5", Op: pb.INSTALL_PROCEDURE}},
+	}
+	entries := make([]raftpb.Entry, len(changes))
+	for idx := range changes {
+		bites, err := (&pb.TransactionBatch{StoredProcedures: changes[idx]}).Marshal()
+		assert.Nil(t, err)
+		entries[idx] = raftpb.Entry{Index: uint64(idx + 3), Data: bites}
+	}
+
+	batches, err := batchesOf(entries, uint64(2), uint64(0))
+	assert.Nil(t, err)
+
+	procs, err := rb.storedProceduresUpTo(raftpb.Snapshot{Data: lastSnapshot, Metadata: raftpb.SnapshotMetadata{Index: uint64(2)}}, batches, uint64(5))
+	assert.Nil(t, err)
+	// only the live versions are left
+	// the removed latest version of narf is kept around so that it isn't assigned again
+	assert.Equal(t, 5, len(procs))
+	assert.Equal(t, "moep", procs[0].Name)
+	assert.Equal(t, uint64(1), procs[0].Version)
+	assert.Equal(t, "return 1", procs[1].Source)
+	assert.Equal(t, uint64(1), procs[1].Version)
+	assert.Equal(t, pb.INSTALL_PROCEDURE, procs[2].Op)
+	assert.Equal(t, uint64(3), procs[2].Version)
+	assert.Equal(t, pb.SET_CURRENT_PROCEDURE, procs[3].Op)
+	assert.Equal(t, pb.REMOVE_PROCEDURE, procs[4].Op)
+
+	// a registry rebuilt from the compacted changes is the same as before
+	registry := util.NewStoredProcedureRegistry()
+	for idx := range procs {
+		assert.Nil(t, registry.Apply(procs[idx], uint64(5)))
+	}
+	assert.Equal(t, 2, len(registry.List()))
+	assert.Equal(t, uint64(1), registry.CurrentVersion("narf"))
+	assert.Equal(t, uint64(4), registry.Install("narf", "return 6"))
+}
+
+func unwrapSnapshot(t *testing.T, snap raftpb.Snapshot) *pb.RaftSnapshot {
+	rs := &pb.RaftSnapshot{}
+	err := rs.Unmarshal(snap.Data)
+	assert.Nil(t, err)
+	return rs
+}
+
+type testCheckpointedSnapshotHandler struct {
+	checkpoint uint64
+}
+
+func (h *testCheckpointedSnapshotHandler) Consume(snapshotData []byte) error {
+	return nil
+}
+
+func (h *testCheckpointedSnapshotHandler) Provide(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) ([]byte, error) {
+	return []byte("narf"), nil
+}
+
+func (h *testCheckpointedSnapshotHandler) ProvideCheckpoint(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) (uint64, []byte, error) {
+	return h.checkpoint, []byte("narf"), nil
+}
+
 type testSnapshotHandler struct {
 	t                           *testing.T
 	entriesThatShouldBeProvided []uint64
@@ -23,7 +23,29 @@ type SnapshotHandler interface {
 	Provide(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) ([]byte, error)
 }
 
+// CheckpointedSnapshotHandler provides snapshots that don't necessarily contain
+// everything up to the last applied index.
+// The snapshot is taken at the returned index and the log is only compacted up to there.
+// Entries after that index are replayed on top of the snapshot.
+type CheckpointedSnapshotHandler interface {
+	SnapshotHandler
+	ProvideCheckpoint(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) (uint64, []byte, error)
+}
+
 type PartialSnapshotHandler interface {
 	Consume(partitionID int, snapshotData []byte) error
 	Provide(partitionID int, lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) ([]byte, error)
 }
+
+// TargetedSnapshotHandler cuts a snapshot down to what the receiving node needs before it's sent there.
+type TargetedSnapshotHandler interface {
+	SnapshotHandler
+	Target(snapshotData []byte, recipientID uint64) ([]byte, error)
+}
+
+// FileSnapshotHandler consumes snapshots straight from the file they were streamed into.
+// That way a snapshot doesn't need to fit into memory.
+type FileSnapshotHandler interface {
+	SnapshotHandler
+	ConsumeFile(snapshotPath string) error
+}
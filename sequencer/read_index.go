@@ -0,0 +1,119 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/mhelmich/calvin/util"
+	"go.etcd.io/etcd/raft"
+)
+
+const (
+	// the leader drops read index requests until it committed an entry in its term
+	// and followers drop them while they don't know a leader
+	readIndexRetryInterval = 500 * time.Millisecond
+)
+
+// position of the last batch a read reflects
+type readPosition struct {
+	index uint64
+	term  uint64
+}
+
+// a read index that is known but wasn't applied on this node yet
+type pendingRead struct {
+	raftIndex uint64
+	c         chan readPosition
+}
+
+// readIndex asks the leader for its commit index and waits until this node applied everything up to that index.
+// It returns the position of the last batch this node published at that point.
+// Batches up to that position contain everything that was committed before this call.
+func (rb *raftBackend) readIndex(ctx context.Context) (uint64, uint64, error) {
+	requestCtx := util.Uint64ToBytes(atomic.AddUint64(&rb.readIndexCounter, uint64(1)))
+	c := make(chan readPosition, 1)
+	rb.readIndexMutex.Lock()
+	rb.readIndexWaiters[string(requestCtx)] = c
+	rb.readIndexMutex.Unlock()
+	defer func() {
+		rb.readIndexMutex.Lock()
+		delete(rb.readIndexWaiters, string(requestCtx))
+		rb.readIndexMutex.Unlock()
+	}()
+
+	for {
+		_, leaderChangedChan := rb.currentLeader()
+		err := rb.raftNode.ReadIndex(ctx, requestCtx)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		timer := time.NewTimer(readIndexRetryInterval)
+		select {
+		case pos := <-c:
+			timer.Stop()
+			return pos.index, pos.term, nil
+		case <-leaderChangedChan:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, 0, ctx.Err()
+		case <-rb.stopChan:
+			timer.Stop()
+			return 0, 0, fmt.Errorf("raft node [%d] stopped", rb.raftID)
+		}
+		timer.Stop()
+	}
+}
+
+// read states carry the commit index of the leader at the time it received the request
+// readers are answered as soon as this node applied up to that index
+// only called by the state machine go routine
+func (rb *raftBackend) serveReadStates(readStates []raft.ReadState) {
+	if len(readStates) > 0 {
+		rb.readIndexMutex.Lock()
+		for idx := range readStates {
+			c, ok := rb.readIndexWaiters[string(readStates[idx].RequestCtx)]
+			if ok {
+				rb.pendingReads = append(rb.pendingReads, pendingRead{
+					raftIndex: readStates[idx].Index,
+					c:         c,
+				})
+			}
+		}
+		rb.readIndexMutex.Unlock()
+	}
+
+	pending := rb.pendingReads[:0]
+	for idx := range rb.pendingReads {
+		if rb.pendingReads[idx].raftIndex > rb.lastAppliedIndex {
+			pending = append(pending, rb.pendingReads[idx])
+			continue
+		}
+
+		// the channel is buffered and the reader might have given up already
+		select {
+		case rb.pendingReads[idx].c <- readPosition{index: rb.lastBatchIndex, term: rb.lastBatchTerm}:
+		default:
+		}
+	}
+	rb.pendingReads = pending
+}
@@ -17,9 +17,12 @@
 package sequencer
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mhelmich/calvin/pb"
@@ -32,49 +35,235 @@ import (
 )
 
 const (
-	sequencerBatchFrequencyMs = 100
+	DefaultBatchFrequency   = 100 * time.Millisecond
+	DefaultMaxBatchTxns     = 1024
+	DefaultMaxBatchBytes    = 1024 * 1024
+	DefaultRaftTickInterval = 100 * time.Millisecond
+	// a heartbeat that didn't show up in the log by then is proposed again
+	heartbeatRetryInterval = time.Second
+	// proposers of conf changes that didn't bring their own deadline give up after this
+	// raft drops conf changes silently while another one is pending
+	confChangeTimeout = 10 * time.Second
 )
 
-func NewSequencer(raftID uint64, txnBatchChan chan<- *pb.TransactionBatch, peers []raft.Peer, storeDir string, connCache util.ConnectionCache, cip util.ClusterInfoProvider, srvr *grpc.Server, snapshotHandler SnapshotHandler, logger *log.Entry) *Sequencer {
-	proposeChan := make(chan []byte)
-	proposeConfChangeChan := make(chan raftpb.ConfChange)
+type SequencerOpts struct {
+	RaftID       uint64
+	TxnBatchChan chan<- *pb.TransactionBatch
+	// txns that couldn't be sequenced are sent here with a DROPPED outcome
+	// txns of accepted batches that got lost on the way into the log are sent here with an UNKNOWN outcome
+	// if nil, dropped txns are only logged
+	DroppedTxnChan chan<- *pb.Transaction
+	Peers          []raft.Peer
+	// learners receive the log but don't vote
+	// a node can be a learner itself
+	Learners        []raft.Peer
+	StoreDir        string
+	ConnCache       util.ConnectionCache
+	Cip             util.ClusterInfoProvider
+	Srvr            *grpc.Server
+	SnapshotHandler SnapshotHandler
+	// if set, snapshots are assembled and installed partition by partition
+	// and SnapshotHandler is ignored
+	PartialSnapshotHandler PartialSnapshotHandler
+	// a batch is cut on whichever comes first: it's full by count, it's full by bytes
+	// or its first txn waited for BatchFrequency
+	// defaults to 100ms if not set
+	BatchFrequency time.Duration
+	// defaults to 1024 txns if not set
+	MaxBatchTxns int
+	// a txn that doesn't fit into the current batch anymore goes into the next one
+	// defaults to 1MB if not set
+	MaxBatchBytes int
+	// how often the raft state machine ticks
+	// defaults to 100ms if not set
+	RaftTickInterval time.Duration
+	// the index of the last batch whose effects are durable in the data store
+	// after a restart all batches after this index are published again
+	DurableIndex uint64
+	// the number of raft groups that sequence batches
+	// all nodes are members of all groups but every group elects its own leader
+	// batches are numbered by their position in the merged order of all groups
+	// if there is more than one group
+	// defaults to 1 if not set
+	NumGroups int
+	Logger    *log.Entry
+}
+
+func NewSequencer(opts SequencerOpts) *Sequencer {
+	if opts.BatchFrequency <= 0 {
+		opts.BatchFrequency = DefaultBatchFrequency
+	}
+	if opts.MaxBatchTxns <= 0 {
+		opts.MaxBatchTxns = DefaultMaxBatchTxns
+	}
+	if opts.MaxBatchBytes <= 0 {
+		opts.MaxBatchBytes = DefaultMaxBatchBytes
+	}
+	if opts.RaftTickInterval <= 0 {
+		opts.RaftTickInterval = DefaultRaftTickInterval
+	}
+	if opts.NumGroups <= 0 {
+		opts.NumGroups = 1
+	}
+
+	snapshotHandler := opts.SnapshotHandler
+	if opts.PartialSnapshotHandler != nil {
+		snapshotHandler = newPartitionedSnapshotHandler(opts.PartialSnapshotHandler, opts.Cip, opts.Logger)
+	}
+	// the data of a snapshot needs to be tied to a position in the merged order of all groups
+	if _, ok := snapshotHandler.(CheckpointedSnapshotHandler); opts.NumGroups > 1 && snapshotHandler != nil && !ok {
+		opts.Logger.Panicf("snapshot handlers of sequencers with [%d] raft groups need to provide checkpoints", opts.NumGroups)
+	}
+
 	writerChan := make(chan *pb.Transaction)
+	procChan := make(chan storedProcedureChange)
 	s := &Sequencer{
+		groups:         make([]*raftGroup, opts.NumGroups),
+		writerChan:     writerChan,
+		procChan:       procChan,
+		cip:            opts.Cip,
+		batchFrequency: opts.BatchFrequency,
+		maxBatchTxns:   opts.MaxBatchTxns,
+		maxBatchBytes:  opts.MaxBatchBytes,
+		batchStats:     newBatchStatsTracker(),
+		logger:         opts.Logger,
+	}
+
+	if opts.NumGroups == 1 {
+		s.groups[0] = newRaftGroup(opts, 0, opts.StoreDir, opts.TxnBatchChan, snapshotHandler, nil)
+		pb.RegisterRaftTransportServer(opts.Srvr, s.groups[0].rb)
+	} else {
+		// every group closes its own batch channel when it stops
+		batchChans := make([]chan *pb.TransactionBatch, opts.NumGroups)
+		router := &groupRouter{
+			backends: make([]*raftBackend, opts.NumGroups),
+			logger:   opts.Logger,
+		}
+		// all groups snapshot and restore the same data store
+		snapshots := newSnapshotCoordinator(opts.Logger)
+		for idx := range s.groups {
+			batchChans[idx] = make(chan *pb.TransactionBatch, cap(opts.TxnBatchChan))
+			storeDir := fmt.Sprintf("%sgroup-%d/", opts.StoreDir, idx)
+			s.groups[idx] = newRaftGroup(opts, uint32(idx), storeDir, batchChans[idx], snapshotHandler, snapshots)
+			router.backends[idx] = s.groups[idx].rb
+		}
+		pb.RegisterRaftTransportServer(opts.Srvr, router)
+		go forwardBatches(batchChans, opts.TxnBatchChan)
+	}
+
+	go s.serveTxnBatches()
+	return s
+}
+
+func newRaftGroup(opts SequencerOpts, groupID uint32, storeDir string, txnBatchChan chan<- *pb.TransactionBatch, snapshotHandler SnapshotHandler, snapshots *snapshotCoordinator) *raftGroup {
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	logger := opts.Logger
+	if opts.NumGroups > 1 {
+		logger = logger.WithField("raftGroup", groupID)
+	}
+
+	rb := newRaftBackend(raftBackendOpts{
+		raftID:                opts.RaftID,
+		groupID:               groupID,
+		numGroups:             uint32(opts.NumGroups),
 		proposeChan:           proposeChan,
 		proposeConfChangeChan: proposeConfChangeChan,
-		writerChan:            writerChan,
-		cip:                   cip,
-		rb:                    newRaftBackend(raftID, proposeChan, proposeConfChangeChan, txnBatchChan, peers, storeDir, connCache, snapshotHandler, logger),
+		txnBatchChan:          txnBatchChan,
+		droppedTxnChan:        opts.DroppedTxnChan,
+		peers:                 opts.Peers,
+		learners:              opts.Learners,
+		storeDir:              storeDir,
+		connCache:             opts.ConnCache,
+		cip:                   opts.Cip,
+		snapshotHandler:       snapshotHandler,
+		snapshots:             snapshots,
+		tickInterval:          opts.RaftTickInterval,
+		durableIndex:          opts.DurableIndex,
 		logger:                logger,
+	})
+
+	return &raftGroup{
+		rb:                    rb,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
 	}
+}
 
-	pb.RegisterRaftTransportServer(srvr, s.rb)
-	go s.serveTxnBatches()
-	return s
+// hands the batches of all groups to the scheduler
+// the batch channel is closed once all groups stopped
+func forwardBatches(from []chan *pb.TransactionBatch, to chan<- *pb.TransactionBatch) {
+	wg := &sync.WaitGroup{}
+	for idx := range from {
+		wg.Add(1)
+		go func(c <-chan *pb.TransactionBatch) {
+			defer wg.Done()
+			for batch := range c {
+				to <- batch
+			}
+		}(from[idx])
+	}
+
+	wg.Wait()
+	close(to)
 }
 
-type Sequencer struct {
+// a raft group and the channels to propose to it
+type raftGroup struct {
 	rb                    *raftBackend
-	proposeChan           chan<- []byte
+	proposeChan           chan<- proposal
 	proposeConfChangeChan chan<- raftpb.ConfChange
-	writerChan            chan *pb.Transaction
-	cip                   util.ClusterInfoProvider
-	logger                *log.Entry
+	// the epoch of the last heartbeat this node proposed and when that was
+	heartbeatEpoch uint64
+	heartbeatTime  time.Time
+}
+
+type Sequencer struct {
+	groups []*raftGroup
+	// batches without stored procedure changes go to the groups in turn
+	nextGroup      int
+	writerChan     chan *pb.Transaction
+	procChan       chan storedProcedureChange
+	cip            util.ClusterInfoProvider
+	batchFrequency time.Duration
+	maxBatchTxns   int
+	maxBatchBytes  int
+	batchStats     *batchStatsTracker
+	logger         *log.Entry
 }
 
 // transactions and distributed snapshot reads go here
+// the delay timer only runs while there is something in the batch
+// with more than one raft group the heartbeat ticker makes sure no group falls behind
 func (s *Sequencer) serveTxnBatches() {
-	batch := &pb.TransactionBatch{}
-	batchTicker := time.NewTicker(sequencerBatchFrequencyMs * time.Millisecond)
-	defer batchTicker.Stop()
+	b := newBatcher(s.maxBatchTxns, s.maxBatchBytes)
+	var delayTimer *time.Timer
+	var delayChan <-chan time.Time
+	cut := func(reason batchCutReason) {
+		if delayTimer != nil {
+			delayTimer.Stop()
+			delayTimer = nil
+			delayChan = nil
+		}
+		s.cutBatch(b, reason)
+	}
+	var heartbeatChan <-chan time.Time
+	if len(s.groups) > 1 {
+		heartbeatTicker := time.NewTicker(s.batchFrequency)
+		defer heartbeatTicker.Stop()
+		heartbeatChan = heartbeatTicker.C
+	}
 
 	for {
+		reason := batchNotFull
 		select {
 		case txn, ok := <-s.writerChan:
 			if !ok {
 				s.logger.Warningf("Stop serving txn batches")
-				close(s.proposeChan)
-				close(s.proposeConfChangeChan)
+				for idx := range s.groups {
+					close(s.groups[idx].proposeChan)
+					close(s.groups[idx].proposeConfChangeChan)
+				}
 				return
 			}
 
@@ -84,7 +273,10 @@ func (s *Sequencer) serveTxnBatches() {
 			}
 
 			s.findParticipants(txn)
-			batch.Transactions = append(batch.Transactions, txn)
+			if !b.fits(txn.Size()) {
+				cut(batchFullByBytes)
+			}
+			reason = b.addTxn(txn)
 			if log.GetLevel() == log.DebugLevel {
 				id, _ := ulid.ParseIdFromProto(txn.Id)
 				s.logger.Debugf("Appended txn [%s]", id.String())
@@ -101,19 +293,103 @@ func (s *Sequencer) serveTxnBatches() {
 				s.logger.Debugf("[%s] ReaderNodes: %s", id.String(), strings.Join(a, ", "))
 			}
 
-		case <-batchTicker.C:
-			if len(batch.Transactions) > 0 {
-				bites, err := batch.Marshal()
-				if err != nil {
-					s.logger.Panicf("%s", err)
-				}
-
-				s.proposeChan <- bites
-				batch = &pb.TransactionBatch{}
+		case change := <-s.procChan:
+			// procedures are sequenced just like txns
+			// every replica installs them at the same position in the log
+			proc := change.proc
+			if !b.fits(proc.Size()) {
+				cut(batchFullByBytes)
 			}
+			reason = b.addStoredProcedure(proc, change.done)
+			s.logger.Infof("Appended stored procedure change [%s] for [%s]", proc.Op.String(), proc.Name)
+
+		case <-delayChan:
+			reason = batchCutByDelay
+
+		case <-heartbeatChan:
+			s.proposeHeartbeats()
+
+		}
+
+		if reason != batchNotFull {
+			cut(reason)
+		} else if delayTimer == nil && !b.isEmpty() {
+			delayTimer = time.NewTimer(s.batchFrequency)
+			delayChan = delayTimer.C
+		}
+	}
+}
+
+// with more than one raft group batches ask for an epoch after all epochs this node has seen
+// that keeps the epochs of all groups close to each other
+func (s *Sequencer) cutBatch(b *batcher, reason batchCutReason) {
+	batch, waiters := b.take()
+	group := s.groups[0]
+	if len(s.groups) > 1 {
+		batch.Epoch = s.maxEpoch() + 1
+		// the procedures contained in snapshots of the first group are complete that way
+		if len(batch.StoredProcedures) == 0 {
+			group = s.groups[s.nextGroup]
+			s.nextGroup = (s.nextGroup + 1) % len(s.groups)
+		}
+	}
+
+	// the batch is tracked until it shows up in the log
+	// if it doesn't, its txns and stored procedure changes are reported as lost
+	group.rb.trackProposal(batch, waiters)
+	bites, err := batch.Marshal()
+	if err != nil {
+		s.logger.Panicf("%s", err)
+	}
+
+	s.batchStats.record(len(batch.Transactions), len(bites), reason)
+	group.proposeChan <- proposal{
+		id:   batch.ProposalId,
+		data: bites,
+	}
+}
+
+// the scheduler only moves on to the next epoch once all groups got there
+// leaders of groups that fall behind the other groups propose empty batches to catch up
+func (s *Sequencer) proposeHeartbeats() {
+	maxEpoch := s.maxEpoch()
+	for _, group := range s.groups {
+		lastEpoch := group.rb.getLastEpoch()
+		leaderID, _ := group.rb.currentLeader()
+		if lastEpoch >= maxEpoch || leaderID != group.rb.raftID {
+			continue
+		} else if group.heartbeatEpoch > lastEpoch && time.Since(group.heartbeatTime) < heartbeatRetryInterval {
+			// the last heartbeat is still on its way
+			continue
+		}
+
+		batch := &pb.TransactionBatch{Epoch: maxEpoch}
+		bites, err := batch.Marshal()
+		if err != nil {
+			s.logger.Panicf("%s", err)
+		}
+
+		group.heartbeatEpoch = maxEpoch
+		group.heartbeatTime = time.Now()
+		group.proposeChan <- proposal{data: bites}
+	}
+}
 
+// the highest epoch any group published on this node
+func (s *Sequencer) maxEpoch() uint64 {
+	var maxEpoch uint64
+	for idx := range s.groups {
+		epoch := s.groups[idx].rb.getLastEpoch()
+		if epoch > maxEpoch {
+			maxEpoch = epoch
 		}
 	}
+	return maxEpoch
+}
+
+// BatchStats returns statistics about the batches this sequencer cut so far.
+func (s *Sequencer) BatchStats() BatchStats {
+	return s.batchStats.get()
 }
 
 func (s *Sequencer) findParticipants(txn *pb.Transaction) {
@@ -150,14 +426,215 @@ func (s *Sequencer) findParticipants(txn *pb.Transaction) {
 	txn.ReaderNodes = readers
 }
 
+// ReplayIndex returns the index of the last batch that is published again after a restart.
+// It is zero if no batches are replayed.
+func (s *Sequencer) ReplayIndex() uint64 {
+	var replayIndex uint64
+	for idx := range s.groups {
+		if s.groups[idx].rb.replayIndex > replayIndex {
+			replayIndex = s.groups[idx].rb.replayIndex
+		}
+	}
+	return replayIndex
+}
+
+// ReadIndex returns the index and term of the last batch this node published
+// once it caught up with everything the leaders committed before this call.
+// With more than one raft group every group is asked and the latest batch wins.
+// Once all batches up to the returned index are executed, reads are linearizable.
+func (s *Sequencer) ReadIndex(ctx context.Context) (uint64, uint64, error) {
+	var index uint64
+	var term uint64
+	for idx := range s.groups {
+		groupIndex, groupTerm, err := s.groups[idx].rb.readIndex(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if groupIndex > index {
+			index = groupIndex
+			term = groupTerm
+		}
+	}
+	return index, term, nil
+}
+
 func (s *Sequencer) SubmitTransaction(txn *pb.Transaction) {
 	s.writerChan <- txn
 }
 
+// a stored procedure change and where to tell the proposer whether it made it into the log
+type storedProcedureChange struct {
+	proc *pb.StoredProcedure
+	done chan<- error
+}
+
+// RegisterStoredProcedure sequences a new version of a stored procedure.
+// It returns once the change is in the log. Transactions submitted after that run with the new version.
+// If the change was dropped or it's unknown whether it made it into the log, an error is returned.
+func (s *Sequencer) RegisterStoredProcedure(ctx context.Context, name string, source string) error {
+	return s.proposeStoredProcedure(ctx, &pb.StoredProcedure{
+		Name:   name,
+		Source: source,
+		Op:     pb.INSTALL_PROCEDURE,
+	})
+}
+
+// SetCurrentStoredProcedure sequences switching the current version of a stored procedure.
+// It returns once the change is in the log just like RegisterStoredProcedure.
+func (s *Sequencer) SetCurrentStoredProcedure(ctx context.Context, name string, version uint64) error {
+	return s.proposeStoredProcedure(ctx, &pb.StoredProcedure{
+		Name:    name,
+		Version: version,
+		Op:      pb.SET_CURRENT_PROCEDURE,
+	})
+}
+
+// RemoveStoredProcedure sequences the removal of a version of a stored procedure.
+// It returns once the change is in the log just like RegisterStoredProcedure.
+func (s *Sequencer) RemoveStoredProcedure(ctx context.Context, name string, version uint64) error {
+	return s.proposeStoredProcedure(ctx, &pb.StoredProcedure{
+		Name:    name,
+		Version: version,
+		Op:      pb.REMOVE_PROCEDURE,
+	})
+}
+
+func (s *Sequencer) proposeStoredProcedure(ctx context.Context, proc *pb.StoredProcedure) error {
+	// buffered so that the raft backend never waits for the proposer
+	done := make(chan error, 1)
+	select {
+	case s.procChan <- storedProcedureChange{proc: proc, done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// AddNode adds a node to the raft group as a learner.
+// Learners receive the log but don't vote. That way a new node can catch up
+// without affecting the availability of the cluster.
+// It returns once the change was applied on this node.
+func (s *Sequencer) AddNode(ctx context.Context, nodeID uint64, address string) error {
+	return s.proposeConfChange(ctx, raftpb.ConfChange{
+		Type:    raftpb.ConfChangeAddLearnerNode,
+		NodeID:  nodeID,
+		Context: []byte(address),
+	})
+}
+
+// PromoteLearner makes a learner a voting member of the raft group.
+// It returns once the change was applied on this node.
+// Nodes that aren't members of all raft groups or that are voters in all of them already can't be promoted.
+// Groups in which the node is a voter already are skipped. That way a promotion that failed half way can be retried.
+func (s *Sequencer) PromoteLearner(ctx context.Context, nodeID uint64) error {
+	isLearner := false
+	for idx := range s.groups {
+		if s.groups[idx].rb.isLearner(nodeID) {
+			isLearner = true
+		} else if !s.groups[idx].rb.isVoter(nodeID) {
+			return fmt.Errorf("node [%d] isn't a member of raft group [%d]", nodeID, idx)
+		}
+	}
+	if !isLearner {
+		return fmt.Errorf("node [%d] isn't a learner in any raft group", nodeID)
+	}
+
+	return s.proposeConfChange(ctx, raftpb.ConfChange{
+		Type:   raftpb.ConfChangeAddNode,
+		NodeID: nodeID,
+	})
+}
+
+// RemoveNode removes a node from the raft group.
+// It returns once the change was applied on this node.
+// Groups the node isn't a member of are skipped. That way a removal that failed half way can be retried.
+func (s *Sequencer) RemoveNode(ctx context.Context, nodeID uint64) error {
+	return s.proposeConfChange(ctx, raftpb.ConfChange{
+		Type:   raftpb.ConfChangeRemoveNode,
+		NodeID: nodeID,
+	})
+}
+
+// membership changes apply to all raft groups one after the other
+// raft has no way to change several groups at once
+// a node that was added to some groups only is removed from them again
+// promotions and removals can't be undone (raft can't demote voters and removed nodes are forgotten)
+// they are retried instead and skip the groups that have the change already
+func (s *Sequencer) proposeConfChange(ctx context.Context, cc raftpb.ConfChange) error {
+	applied := make([]int, 0, len(s.groups))
+	for idx := range s.groups {
+		if s.groups[idx].rb.hasConfChange(cc) {
+			continue
+		}
+
+		err := s.groups[idx].proposeConfChange(ctx, cc)
+		if err != nil {
+			if cc.Type == raftpb.ConfChangeAddLearnerNode {
+				s.rollbackConfChange(cc, applied)
+			}
+			return fmt.Errorf("raft group [%d]: %s", idx, err.Error())
+		}
+		applied = append(applied, idx)
+	}
+	return nil
+}
+
+// the context of the failed change might be done already
+// the rollback gets its own timeout
+func (s *Sequencer) rollbackConfChange(cc raftpb.ConfChange, groups []int) {
+	rollback := raftpb.ConfChange{
+		Type:   raftpb.ConfChangeRemoveNode,
+		NodeID: cc.NodeID,
+	}
+
+	for idx := len(groups) - 1; idx >= 0; idx-- {
+		err := s.groups[groups[idx]].proposeConfChange(context.Background(), rollback)
+		if err != nil {
+			s.logger.Errorf("can't roll back [%s] for node [%d] in raft group [%d]: %s", cc.Type.String(), cc.NodeID, groups[idx], err.Error())
+		}
+	}
+}
+
+// raft drops a conf change if another one is still pending
+// in that case the change never shows up and the context needs to time out
+// contexts without a deadline time out after confChangeTimeout
+func (g *raftGroup) proposeConfChange(ctx context.Context, cc raftpb.ConfChange) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, confChangeTimeout)
+		defer cancel()
+	}
+
+	cc.ID = util.RandomRaftId()
+	applied := g.rb.waitForConfChange(cc.ID)
+	defer g.rb.cancelConfChangeWaiter(cc.ID)
+
+	select {
+	case g.proposeConfChangeChan <- cc:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-applied:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("conf change [%s] for node [%d] wasn't applied, it might have been dropped: %s", cc.Type.String(), cc.NodeID, ctx.Err().Error())
+	}
+}
+
 func (s *Sequencer) Stop() {
 	close(s.writerChan)
 }
 
+// LogToJSON writes the last n entries of the log of the first raft group.
 func (s *Sequencer) LogToJSON(out io.Writer, n int) error {
-	return s.rb.logToJSON(out, n)
+	return s.groups[0].rb.logToJSON(out, n)
 }
@@ -17,8 +17,11 @@
 package sequencer
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/mhelmich/calvin/mocks"
 	"github.com/mhelmich/calvin/pb"
@@ -26,7 +29,9 @@ import (
 	"github.com/mhelmich/calvin/util"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
 	"google.golang.org/grpc"
 )
 
@@ -40,12 +45,23 @@ func TestSequencerBasic(t *testing.T) {
 	storeDir := "./test-TestSequencerBasic-" + util.Uint64ToString(util.RandomRaftId()) + "/"
 	defer os.RemoveAll(storeDir)
 	mockCC := new(mocks.ConnectionCache)
-	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP := newMockClusterInfoProvider()
 	mockSH := new(mocks.SnapshotHandler)
 	srvr := grpc.NewServer()
 	logger := log.WithFields(log.Fields{})
 
-	s := NewSequencer(raftID, txnBatchChan, peers, storeDir, mockCC, mockCIP, srvr, mockSH, logger)
+	s := NewSequencer(SequencerOpts{
+		RaftID:          raftID,
+		TxnBatchChan:    txnBatchChan,
+		Peers:           peers,
+		StoreDir:        storeDir,
+		ConnCache:       mockCC,
+		Cip:             mockCIP,
+		Srvr:            srvr,
+		SnapshotHandler: mockSH,
+		BatchFrequency:  10 * time.Millisecond,
+		Logger:          logger,
+	})
 	id, err := ulid.NewId()
 	assert.Nil(t, err)
 
@@ -59,3 +75,291 @@ func TestSequencerBasic(t *testing.T) {
 	assert.Equal(t, id.String(), sequencedTxnID.String())
 	s.Stop()
 }
+
+func TestSequencerBatchCutting(t *testing.T) {
+	raftID := uint64(1)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestSequencerBatchCutting-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	logger := log.WithFields(log.Fields{})
+
+	// batches are only ever cut because they're full
+	s := NewSequencer(SequencerOpts{
+		RaftID:          raftID,
+		TxnBatchChan:    txnBatchChan,
+		Peers:           peers,
+		StoreDir:        storeDir,
+		ConnCache:       new(mocks.ConnectionCache),
+		Cip:             newMockClusterInfoProvider(),
+		Srvr:            grpc.NewServer(),
+		SnapshotHandler: new(mocks.SnapshotHandler),
+		BatchFrequency:  time.Hour,
+		MaxBatchTxns:    3,
+		Logger:          logger,
+	})
+
+	for i := 0; i < 6; i++ {
+		id, err := ulid.NewId()
+		assert.Nil(t, err)
+		s.SubmitTransaction(&pb.Transaction{
+			Id: id.ToProto(),
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		batch := <-txnBatchChan
+		assert.Equal(t, 3, len(batch.Transactions))
+	}
+
+	stats := s.BatchStats()
+	assert.Equal(t, uint64(2), stats.NumBatches)
+	assert.Equal(t, uint64(6), stats.NumTxns)
+	assert.Equal(t, uint64(2), stats.NumFullByCount)
+	assert.Equal(t, uint64(0), stats.NumCutByDelay)
+	assert.Equal(t, float64(3), stats.AvgTxnsPerBatch())
+	s.Stop()
+}
+
+func TestSequencerMembership(t *testing.T) {
+	raftID := uint64(1)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestSequencerMembership-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	mockCC.On("GetRaftTransportClient", uint64(2)).Return(nil, fmt.Errorf("narf"))
+	mockCC.On("CloseConnection", uint64(2)).Return()
+	mockCIP := newMockClusterInfoProvider()
+	mockCIP.On("GetAddressFor", uint64(2)).Return("")
+	mockCIP.On("SetAddressFor", uint64(2), "127.0.0.1:1234").Return(nil)
+	mockCIP.On("RemoveNode", uint64(2)).Return()
+	logger := log.WithFields(log.Fields{})
+
+	s := NewSequencer(SequencerOpts{
+		RaftID:           raftID,
+		TxnBatchChan:     txnBatchChan,
+		Peers:            peers,
+		StoreDir:         storeDir,
+		ConnCache:        mockCC,
+		Cip:              mockCIP,
+		Srvr:             grpc.NewServer(),
+		RaftTickInterval: 10 * time.Millisecond,
+		Logger:           logger,
+	})
+
+	// learners don't count towards the quorum
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.AddNode(ctx, uint64(2), "127.0.0.1:1234")
+	assert.Nil(t, err)
+	mockCIP.AssertCalled(t, "SetAddressFor", uint64(2), "127.0.0.1:1234")
+	peerAddresses, err := s.groups[0].rb.store.loadPeers()
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:1234", peerAddresses[uint64(2)])
+	assert.True(t, s.groups[0].rb.isLearner(uint64(2)))
+	mockCIP.AssertCalled(t, "SetLearners", []uint64{2})
+
+	// only learners can be promoted
+	assert.NotNil(t, s.PromoteLearner(ctx, uint64(1)))
+	assert.NotNil(t, s.PromoteLearner(ctx, uint64(3)))
+
+	// dropped conf changes are reported to the proposer
+	applied := s.groups[0].rb.waitForConfChange(uint64(99))
+	s.groups[0].rb.notifyConfChangeWaiter(uint64(99), fmt.Errorf("narf"))
+	assert.NotNil(t, <-applied)
+
+	err = s.RemoveNode(ctx, uint64(2))
+	assert.Nil(t, err)
+	mockCIP.AssertCalled(t, "RemoveNode", uint64(2))
+	peerAddresses, err = s.groups[0].rb.store.loadPeers()
+	assert.Nil(t, err)
+	_, ok := peerAddresses[uint64(2)]
+	assert.False(t, ok)
+	assert.False(t, s.groups[0].rb.isLearner(uint64(2)))
+
+	// changes that are applied already are skipped
+	assert.Nil(t, s.RemoveNode(ctx, uint64(2)))
+	s.Stop()
+}
+
+func TestSequencerMembershipGroups(t *testing.T) {
+	raftID := uint64(1)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestSequencerMembershipGroups-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	mockCC := new(mocks.ConnectionCache)
+	mockCC.On("GetRaftTransportClient", uint64(2)).Return(nil, fmt.Errorf("narf"))
+	mockCC.On("CloseConnection", uint64(2)).Return()
+	mockCIP := newMockClusterInfoProvider()
+	mockCIP.On("GetAddressFor", uint64(2)).Return("")
+	mockCIP.On("SetAddressFor", uint64(2), "127.0.0.1:1234").Return(nil)
+	mockCIP.On("RemoveNode", uint64(2)).Return()
+	logger := log.WithFields(log.Fields{})
+
+	s := NewSequencer(SequencerOpts{
+		RaftID:           raftID,
+		TxnBatchChan:     txnBatchChan,
+		Peers:            peers,
+		StoreDir:         storeDir,
+		ConnCache:        mockCC,
+		Cip:              mockCIP,
+		Srvr:             grpc.NewServer(),
+		BatchFrequency:   10 * time.Millisecond,
+		RaftTickInterval: 10 * time.Millisecond,
+		NumGroups:        2,
+		Logger:           logger,
+	})
+
+	batchesDone := make(chan struct{})
+	go func() {
+		for range txnBatchChan {
+		}
+		close(batchesDone)
+	}()
+
+	// the second group never sees the change
+	// the node is removed from the first group again
+	proposeConfChangeChan := s.groups[1].proposeConfChangeChan
+	s.groups[1].proposeConfChangeChan = make(chan raftpb.ConfChange)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	err := s.AddNode(ctx, uint64(2), "127.0.0.1:1234")
+	assert.NotNil(t, err)
+	assert.False(t, s.groups[0].rb.isLearner(uint64(2)))
+	assert.False(t, s.groups[1].rb.isLearner(uint64(2)))
+
+	s.groups[1].proposeConfChangeChan = proposeConfChangeChan
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err = s.AddNode(ctx, uint64(2), "127.0.0.1:1234")
+	assert.Nil(t, err)
+	assert.True(t, s.groups[0].rb.isLearner(uint64(2)))
+	assert.True(t, s.groups[1].rb.isLearner(uint64(2)))
+
+	s.Stop()
+	<-batchesDone
+}
+
+func TestSequencerGroups(t *testing.T) {
+	raftID := uint64(1)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestSequencerGroups-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	logger := log.WithFields(log.Fields{})
+
+	s := NewSequencer(SequencerOpts{
+		RaftID:           raftID,
+		TxnBatchChan:     txnBatchChan,
+		Peers:            peers,
+		StoreDir:         storeDir,
+		ConnCache:        new(mocks.ConnectionCache),
+		Cip:              newMockClusterInfoProvider(),
+		Srvr:             grpc.NewServer(),
+		BatchFrequency:   10 * time.Millisecond,
+		MaxBatchTxns:     1,
+		RaftTickInterval: 10 * time.Millisecond,
+		NumGroups:        2,
+		Logger:           logger,
+	})
+
+	// batches go to the groups in turn
+	txnsPerGroup := make([]int, 2)
+	var lastEpoch uint64
+	for i := 0; i < 5; i++ {
+		id, err := ulid.NewId()
+		assert.Nil(t, err)
+		s.SubmitTransaction(&pb.Transaction{
+			Id: id.ToProto(),
+		})
+
+		for {
+			batch := <-txnBatchChan
+			assert.Equal(t, batch.Epoch*2+uint64(batch.SequencerGroup), batch.Index)
+			if len(batch.Transactions) > 0 {
+				txnsPerGroup[batch.SequencerGroup]++
+				lastEpoch = batch.Epoch
+				break
+			}
+		}
+	}
+	assert.Equal(t, 3, txnsPerGroup[0])
+	assert.Equal(t, 2, txnsPerGroup[1])
+
+	// the second group catches up with an empty batch
+	for {
+		batch := <-txnBatchChan
+		if batch.SequencerGroup == 1 && batch.Epoch >= lastEpoch {
+			assert.Equal(t, 0, len(batch.Transactions))
+			break
+		}
+	}
+
+	s.Stop()
+	// both groups stop
+	for range txnBatchChan {
+	}
+}
+
+func TestSequencerReadIndex(t *testing.T) {
+	raftID := uint64(1)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{
+		ID:      raftID,
+		Context: []byte("narf"),
+	}}
+	storeDir := "./test-TestSequencerReadIndex-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+	logger := log.WithFields(log.Fields{})
+
+	s := NewSequencer(SequencerOpts{
+		RaftID:           raftID,
+		TxnBatchChan:     txnBatchChan,
+		Peers:            peers,
+		StoreDir:         storeDir,
+		ConnCache:        new(mocks.ConnectionCache),
+		Cip:              newMockClusterInfoProvider(),
+		Srvr:             grpc.NewServer(),
+		SnapshotHandler:  new(mocks.SnapshotHandler),
+		BatchFrequency:   10 * time.Millisecond,
+		RaftTickInterval: 10 * time.Millisecond,
+		Logger:           logger,
+	})
+
+	id, err := ulid.NewId()
+	assert.Nil(t, err)
+	s.SubmitTransaction(&pb.Transaction{
+		Id: id.ToProto(),
+	})
+	batch := <-txnBatchChan
+
+	// the read reflects the batch that was committed before
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	index, term, err := s.ReadIndex(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, batch.Index, index)
+	assert.Equal(t, batch.Term, term)
+	s.Stop()
+}
+
+// raft groups tell the cluster info about learners whenever they apply a conf state
+func newMockClusterInfoProvider() *mocks.ClusterInfoProvider {
+	mockCIP := new(mocks.ClusterInfoProvider)
+	mockCIP.On("SetLearners", mock.Anything).Return()
+	return mockCIP
+}
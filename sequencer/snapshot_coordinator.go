@@ -0,0 +1,79 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func newSnapshotCoordinator(logger *log.Entry) *snapshotCoordinator {
+	return &snapshotCoordinator{
+		mutex:  &sync.Mutex{},
+		logger: logger,
+	}
+}
+
+// the raft groups of a sequencer share one data store
+// a snapshot of it is taken at a checkpoint in the merged order of all groups
+// and every group compacts its log up to its part of that checkpoint
+// the coordinator makes sure only one group takes or restores a snapshot at a time
+// data is only restored if it's ahead of the data that was restored last
+// the batches of all groups before that position are part of the restored data already
+// a nil coordinator (a sequencer with a single group) doesn't coordinate anything
+type snapshotCoordinator struct {
+	mutex *sync.Mutex
+	// the position in the merged order of all groups the data store was restored to last
+	restoredIndex uint64
+	restored      bool
+	logger        *log.Entry
+}
+
+func (sc *snapshotCoordinator) provide(fn func() error) error {
+	if sc == nil {
+		return fn()
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	return fn()
+}
+
+// index is the position of the last batch of the snapshot in the merged order of all groups
+func (sc *snapshotCoordinator) restore(index uint64, fn func() error) error {
+	if sc == nil {
+		return fn()
+	}
+
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	if sc.restored && index <= sc.restoredIndex {
+		// restoring older data would undo the batches of other groups
+		sc.logger.Infof("not restoring snapshot data at [%d], the data store was restored to [%d] already", index, sc.restoredIndex)
+		return nil
+	}
+
+	err := fn()
+	if err != nil {
+		return err
+	}
+
+	sc.restored = true
+	sc.restoredIndex = index
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"fmt"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotCoordinatorRestoresNewerData(t *testing.T) {
+	sc := newSnapshotCoordinator(log.WithFields(log.Fields{}))
+	restored := make([]uint64, 0)
+	restore := func(index uint64) error {
+		return sc.restore(index, func() error {
+			restored = append(restored, index)
+			return nil
+		})
+	}
+
+	assert.Nil(t, restore(uint64(0)))
+	assert.Nil(t, restore(uint64(12)))
+	// the data store contains everything up to 12 already
+	assert.Nil(t, restore(uint64(9)))
+	assert.Nil(t, restore(uint64(12)))
+	assert.Equal(t, []uint64{0, 12}, restored)
+
+	// failed restores don't count
+	err := sc.restore(uint64(15), func() error { return fmt.Errorf("narf") })
+	assert.NotNil(t, err)
+	assert.Nil(t, restore(uint64(15)))
+	assert.Equal(t, []uint64{0, 12, 15}, restored)
+
+	// sequencers with a single group don't coordinate
+	var nilCoordinator *snapshotCoordinator
+	called := false
+	assert.Nil(t, nilCoordinator.restore(uint64(1), func() error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+}
@@ -0,0 +1,464 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mhelmich/calvin/pb"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+const (
+	defaultSnapshotChunkSize    = 1024 * 1024 // 1 MB
+	maxSnapshotTransferAttempts = 5
+	snapshotTransferBackoff     = 100 * time.Millisecond
+	maxSnapshotTransferBackoff  = 5 * time.Second
+	snapshotTransferTimeout     = 10 * time.Minute
+)
+
+// snapshots can be too big to be sent as a single message
+// they are streamed to the receiver in chunks in a separate go routine
+// raft doesn't send another snapshot to the receiver before this one is reported
+func (rb *raftBackend) sendSnapshot(recipientID uint64, msg raftpb.Message) {
+	data, err := rb.splitSnapshot(&msg, recipientID)
+	if err != nil {
+		rb.logger.Errorf("can't send snapshot at index [%d] to [%d]: %s", msg.Snapshot.Metadata.Index, recipientID, err.Error())
+		rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFailure)
+		return
+	}
+
+	backoff := snapshotTransferBackoff
+	for attempt := 1; attempt <= maxSnapshotTransferAttempts; attempt++ {
+		err := rb.streamSnapshot(recipientID, msg, data)
+		if err == nil {
+			rb.logger.Infof("sent snapshot at index [%d] to [%d]", msg.Snapshot.Metadata.Index, recipientID)
+			rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFinish)
+			return
+		}
+
+		rb.logger.Errorf("attempt [%d] to send snapshot at index [%d] to [%d] failed: %s", attempt, msg.Snapshot.Metadata.Index, recipientID, err.Error())
+		time.Sleep(backoff)
+		backoff = 2 * backoff
+		if backoff > maxSnapshotTransferBackoff {
+			backoff = maxSnapshotTransferBackoff
+		}
+	}
+
+	rb.raftNode.ReportUnreachable(recipientID)
+	rb.raftNode.ReportSnapshot(recipientID, raft.SnapshotFailure)
+}
+
+// the data of the snapshot handler is taken out of the snapshot and streamed on its own
+// the snapshot that goes to raft on the receiver only keeps the metadata
+// the receiver only gets the part of the data it needs if the snapshot handler knows how to cut it down
+func (rb *raftBackend) splitSnapshot(msg *raftpb.Message, recipientID uint64) ([]byte, error) {
+	rs := &pb.RaftSnapshot{}
+	err := rs.Unmarshal(msg.Snapshot.Data)
+	if err != nil {
+		return nil, err
+	} else if rs.WithoutData {
+		// the next snapshot this node takes can be sent again
+		return nil, fmt.Errorf("snapshot was streamed from another node and doesn't contain its data")
+	}
+
+	data := rs.Data
+	if tsh, ok := rb.snapshotHandler.(TargetedSnapshotHandler); ok {
+		data, err = tsh.Target(data, recipientID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rs.Data = nil
+	rs.WithoutData = true
+	msg.Snapshot.Data, err = rs.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// returns nil only after the receiver installed the snapshot
+func (rb *raftBackend) streamSnapshot(recipientID uint64, msg raftpb.Message, data []byte) error {
+	client, err := rb.connCache.GetRaftTransportClient(recipientID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTransferTimeout)
+	defer cancel()
+	stream, err := client.SnapshotStream(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&pb.SnapshotChunk{
+		Message:   &msg,
+		TotalSize: uint64(len(data)),
+		Checksum:  crc32.ChecksumIEEE(data),
+		GroupId:   rb.groupID,
+	})
+	if err != nil {
+		return snapshotStreamError(stream, err)
+	}
+
+	// the receiver might have kept parts of the snapshot from an earlier attempt
+	resp, err := recvSnapshotChunkResponse(stream)
+	if err != nil {
+		return err
+	} else if resp.Offset > uint64(len(data)) {
+		return fmt.Errorf("receiver wants to continue at [%d] but the snapshot has [%d] bytes", resp.Offset, len(data))
+	}
+
+	offset := resp.Offset
+	for offset < uint64(len(data)) {
+		end := offset + uint64(rb.snapshotChunkSize)
+		if end > uint64(len(data)) {
+			end = uint64(len(data))
+		}
+
+		chunk := data[offset:end]
+		err = stream.Send(&pb.SnapshotChunk{
+			Offset:        offset,
+			Data:          chunk,
+			ChunkChecksum: crc32.ChecksumIEEE(chunk),
+		})
+		if err != nil {
+			return snapshotStreamError(stream, err)
+		}
+		offset = end
+	}
+
+	err = stream.CloseSend()
+	if err != nil {
+		return err
+	}
+
+	resp, err = recvSnapshotChunkResponse(stream)
+	if err != nil {
+		return err
+	} else if !resp.Installed {
+		return fmt.Errorf("receiver didn't install snapshot")
+	}
+	return nil
+}
+
+// a failed send only says that the stream is gone
+// the reason is in the response of the receiver
+func snapshotStreamError(stream pb.RaftTransport_SnapshotStreamClient, err error) error {
+	if err != io.EOF {
+		return err
+	}
+
+	_, err = recvSnapshotChunkResponse(stream)
+	if err == nil {
+		return fmt.Errorf("receiver closed snapshot stream")
+	}
+	return err
+}
+
+func recvSnapshotChunkResponse(stream pb.RaftTransport_SnapshotStreamClient) (*pb.SnapshotChunkResponse, error) {
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	} else if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (rb *raftBackend) SnapshotStream(stream pb.RaftTransport_SnapshotStreamServer) error {
+	header, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	return rb.serveSnapshotStream(stream, header)
+}
+
+func (rb *raftBackend) serveSnapshotStream(stream pb.RaftTransport_SnapshotStreamServer, header *pb.SnapshotChunk) error {
+	if header.Message == nil || header.Message.Type != raftpb.MsgSnap {
+		return stream.Send(&pb.SnapshotChunkResponse{
+			Error: "snapshot stream needs to start with a snapshot message",
+		})
+	}
+
+	// one snapshot at a time
+	rb.snapshotReceiveMutex.Lock()
+	defer rb.snapshotReceiveMutex.Unlock()
+
+	err := rb.receiveSnapshot(stream, header)
+	if err != nil {
+		rb.logger.Errorf("can't receive snapshot at index [%d] from [%d]: %s", header.Message.Snapshot.Metadata.Index, header.Message.From, err.Error())
+		return stream.Send(&pb.SnapshotChunkResponse{
+			Error: err.Error(),
+		})
+	}
+
+	return stream.Send(&pb.SnapshotChunkResponse{
+		Offset:    header.TotalSize,
+		Installed: true,
+	})
+}
+
+// the snapshot data is written into a file next to the raft log first
+// the file is kept until the snapshot is installed
+// that way an interrupted transfer continues where it left off
+func (rb *raftBackend) receiveSnapshot(stream pb.RaftTransport_SnapshotStreamServer, header *pb.SnapshotChunk) error {
+	msg := *header.Message
+	path := rb.partialSnapshotPath(msg, header.Checksum)
+	rb.removePartialSnapshots(path)
+
+	err := receiveSnapshotChunks(stream, path, header.TotalSize)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	} else if checksum != header.Checksum {
+		os.Remove(path)
+		return fmt.Errorf("checksum mismatch of snapshot at index [%d]", msg.Snapshot.Metadata.Index)
+	}
+
+	// the state machine installs the data and hands the snapshot to raft afterwards
+	installed := rb.waitForSnapshot(msg.Snapshot.Metadata.Index)
+	select {
+	case rb.snapshotInstallChan <- snapshotInstall{msg: msg, path: path}:
+	case <-rb.stopChan:
+		return fmt.Errorf("raft backend stopped")
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	}
+
+	select {
+	case err = <-installed:
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	}
+	if err != nil {
+		return err
+	}
+
+	os.Remove(path)
+	return nil
+}
+
+type snapshotInstall struct {
+	msg  raftpb.Message
+	path string
+}
+
+// raft only learns about a streamed snapshot once its data is in the data store
+// if the data can't be installed, raft keeps going as if the snapshot never arrived
+// runs on the state machine go routine so that nothing is applied while the data is restored
+func (rb *raftBackend) installSnapshotData(si snapshotInstall) {
+	index := si.msg.Snapshot.Metadata.Index
+	needsData, err := rb.snapshotNeedsData(si.msg)
+	if err == nil && needsData {
+		err = rb.restoreSnapshotData(si)
+	}
+	if err == nil {
+		err = rb.raftNode.Step(context.Background(), si.msg)
+	}
+	if err != nil {
+		rb.notifySnapshotWaiters(index, err)
+	}
+}
+
+// raft ignores snapshots it has the entries for already
+// their data must not overwrite the data store
+func (rb *raftBackend) snapshotNeedsData(msg raftpb.Message) (bool, error) {
+	hardState, _, err := rb.store.InitialState()
+	if err != nil {
+		return false, err
+	} else if msg.Term < hardState.Term {
+		return false, fmt.Errorf("snapshot at index [%d] is from term [%d] but this node is at term [%d]", msg.Snapshot.Metadata.Index, msg.Term, hardState.Term)
+	} else if msg.Snapshot.Metadata.Index <= hardState.Commit {
+		return false, nil
+	}
+
+	// raft fast-forwards to the snapshot if the log contains its last entry
+	term, err := rb.store.Term(msg.Snapshot.Metadata.Index)
+	if err == nil && term == msg.Snapshot.Metadata.Term {
+		return false, nil
+	}
+	return true, nil
+}
+
+// the data store is shared with the other raft groups of the sequencer
+// their batches before the position of the snapshot are part of its data
+func (rb *raftBackend) restoreSnapshotData(si snapshotInstall) error {
+	epoch, err := snapshotEpoch(si.msg.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	return rb.snapshots.restore(rb.batchIndex(si.msg.Snapshot.Metadata.Index, epoch), func() error {
+		return rb.consumeSnapshotFile(si.path)
+	})
+}
+
+// the snapshot handler reads the snapshot from the file if it can
+func (rb *raftBackend) consumeSnapshotFile(path string) error {
+	if rb.snapshotHandler == nil {
+		return fmt.Errorf("can't install snapshot without a snapshot handler")
+	}
+
+	var err error
+	if fsh, ok := rb.snapshotHandler.(FileSnapshotHandler); ok {
+		err = fsh.ConsumeFile(path)
+	} else {
+		var data []byte
+		data, err = ioutil.ReadFile(path)
+		if err == nil {
+			err = rb.snapshotHandler.Consume(data)
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("snapshot handler consume failed: %s", err.Error())
+	}
+	return nil
+}
+
+func fileChecksum(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	hash := crc32.NewIEEE()
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return 0, err
+	}
+	return hash.Sum32(), nil
+}
+
+func receiveSnapshotChunks(stream pb.RaftTransport_SnapshotStreamServer, path string, totalSize uint64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := uint64(info.Size())
+	if offset > totalSize {
+		offset = 0
+	}
+
+	err = f.Truncate(int64(offset))
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Seek(int64(offset), io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&pb.SnapshotChunkResponse{
+		Offset: offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	for offset < totalSize {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return err
+		} else if chunk.Offset != offset {
+			return fmt.Errorf("expected chunk at offset [%d] but got [%d]", offset, chunk.Offset)
+		} else if crc32.ChecksumIEEE(chunk.Data) != chunk.ChunkChecksum {
+			return fmt.Errorf("checksum mismatch of chunk at offset [%d]", chunk.Offset)
+		} else if offset+uint64(len(chunk.Data)) > totalSize {
+			return fmt.Errorf("chunk at offset [%d] exceeds snapshot size [%d]", chunk.Offset, totalSize)
+		}
+
+		_, err = f.Write(chunk.Data)
+		if err != nil {
+			return err
+		}
+		offset += uint64(len(chunk.Data))
+	}
+
+	return f.Sync()
+}
+
+func (rb *raftBackend) partialSnapshotPath(msg raftpb.Message, checksum uint32) string {
+	return fmt.Sprintf("%ssnapshot-%d-%d-%d-%08x.part", rb.storeDir, msg.From, msg.Snapshot.Metadata.Term, msg.Snapshot.Metadata.Index, checksum)
+}
+
+// only the latest snapshot is worth continuing
+func (rb *raftBackend) removePartialSnapshots(keep string) {
+	paths, err := filepath.Glob(rb.storeDir + "snapshot-*.part")
+	if err != nil {
+		rb.logger.Errorf("can't find partial snapshots: %s", err.Error())
+		return
+	}
+
+	for idx := range paths {
+		if filepath.Clean(paths[idx]) != filepath.Clean(keep) {
+			os.Remove(paths[idx])
+		}
+	}
+}
+
+// the returned channel receives the outcome of installing the snapshot at the provided index
+func (rb *raftBackend) waitForSnapshot(index uint64) <-chan error {
+	c := make(chan error, 1)
+	rb.snapshotWaitersMutex.Lock()
+	defer rb.snapshotWaitersMutex.Unlock()
+	rb.snapshotWaiters[index] = append(rb.snapshotWaiters[index], c)
+	return c
+}
+
+// without an error, everybody waiting for a snapshot up to the provided index is done
+// raft ignores snapshots that are older than what a node already has
+// with an error, only the waiters of the failed snapshot are notified
+func (rb *raftBackend) notifySnapshotWaiters(index uint64, err error) {
+	rb.snapshotWaitersMutex.Lock()
+	defer rb.snapshotWaitersMutex.Unlock()
+	for waiterIndex, waiters := range rb.snapshotWaiters {
+		if waiterIndex > index || (err != nil && waiterIndex != index) {
+			continue
+		}
+
+		for idx := range waiters {
+			waiters[idx] <- err
+		}
+		delete(rb.snapshotWaiters, waiterIndex)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sequencer
+
+import (
+	"crypto/rand"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mhelmich/calvin/mocks"
+	"github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"google.golang.org/grpc"
+)
+
+func TestRaftBackendSnapshotTransfer(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	storeDir := "./test-TestRaftBackendSnapshotTransfer-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	defer os.RemoveAll(storeDir)
+
+	// the receiver is a single raft node
+	// the sender pretends to be the leader of a newer term
+	rs := &pb.RaftSnapshot{Data: make([]byte, 300*1024)}
+	rand.Read(rs.Data)
+	receiverCC := new(mocks.ConnectionCache)
+	receiverCC.On("GetRaftTransportClient", uint64(2)).Return(nil, fmt.Errorf("narf"))
+	receiverSH := new(mocks.SnapshotHandler)
+	receiverSH.On("Consume", rs.Data).Return(nil)
+	proposeChan := make(chan proposal)
+	proposeConfChangeChan := make(chan raftpb.ConfChange)
+	txnBatchChan := make(chan *pb.TransactionBatch, 16)
+	peers := []raft.Peer{raft.Peer{ID: uint64(1)}}
+	receiver := newRaftBackend(raftBackendOpts{
+		raftID:                uint64(1),
+		numGroups:             1,
+		proposeChan:           proposeChan,
+		proposeConfChangeChan: proposeConfChangeChan,
+		txnBatchChan:          txnBatchChan,
+		peers:                 peers,
+		storeDir:              storeDir,
+		connCache:             receiverCC,
+		snapshotHandler:       receiverSH,
+		tickInterval:          10 * time.Millisecond,
+		logger:                logger,
+	})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	srvr := grpc.NewServer()
+	defer srvr.Stop()
+	pb.RegisterRaftTransportServer(srvr, receiver)
+	go srvr.Serve(lis)
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	senderCC := new(mocks.ConnectionCache)
+	senderCC.On("GetRaftTransportClient", uint64(1)).Return(pb.NewRaftTransportClient(conn), nil)
+	sender := &raftBackend{
+		connCache:         senderCC,
+		snapshotChunkSize: 64 * 1024,
+		logger:            logger,
+	}
+
+	bites, err := rs.Marshal()
+	assert.Nil(t, err)
+	msg := raftpb.Message{
+		Type: raftpb.MsgSnap,
+		From: uint64(2),
+		To:   uint64(1),
+		Term: uint64(100),
+		Snapshot: raftpb.Snapshot{
+			Data: bites,
+			Metadata: raftpb.SnapshotMetadata{
+				Index:     uint64(100),
+				Term:      uint64(100),
+				ConfState: raftpb.ConfState{Nodes: []uint64{1, 2}},
+			},
+		},
+	}
+	// the data of the snapshot is streamed on its own
+	data, err := sender.splitSnapshot(&msg, uint64(1))
+	assert.Nil(t, err)
+	assert.Equal(t, rs.Data, data)
+	path := receiver.partialSnapshotPath(msg, crc32.ChecksumIEEE(data))
+
+	// the receiver continues with whatever is in the partial snapshot
+	// garbage in there is detected and thrown away
+	garbage := make([]byte, 64*1024)
+	rand.Read(garbage)
+	assert.Nil(t, ioutil.WriteFile(path, garbage, 0600))
+	err = sender.streamSnapshot(uint64(1), msg, data)
+	assert.NotNil(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	receiverSH.AssertNotCalled(t, "Consume", mock.Anything)
+
+	// an interrupted transfer left parts of the snapshot behind
+	assert.Nil(t, ioutil.WriteFile(path, data[:100*1024], 0600))
+	err = sender.streamSnapshot(uint64(1), msg, data)
+	assert.Nil(t, err)
+	receiverSH.AssertCalled(t, "Consume", rs.Data)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// the snapshot was installed before the sender heard back
+	var batch *pb.TransactionBatch
+	for batch = range txnBatchChan {
+		if batch.ReplaceStoredProcedures {
+			break
+		}
+	}
+	assert.Equal(t, uint64(100), batch.Index)
+
+	// the receiver doesn't keep the data of the snapshot and can't send it on
+	snap, err := receiver.store.Snapshot()
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), snap.Metadata.Index)
+	fwd := raftpb.Message{Type: raftpb.MsgSnap, Snapshot: snap}
+	_, err = receiver.splitSnapshot(&fwd, uint64(3))
+	assert.NotNil(t, err)
+
+	// raft doesn't hear about a snapshot whose data can't be installed
+	failed := &pb.RaftSnapshot{Data: []byte("narf")}
+	bites, err = failed.Marshal()
+	assert.Nil(t, err)
+	receiverSH.On("Consume", failed.Data).Return(fmt.Errorf("narf"))
+	msg.Term = uint64(101)
+	msg.Snapshot.Data = bites
+	msg.Snapshot.Metadata.Index = uint64(200)
+	msg.Snapshot.Metadata.Term = uint64(101)
+	data, err = sender.splitSnapshot(&msg, uint64(1))
+	assert.Nil(t, err)
+	err = sender.streamSnapshot(uint64(1), msg, data)
+	assert.NotNil(t, err)
+	receiverSH.AssertCalled(t, "Consume", failed.Data)
+	assert.Equal(t, uint64(100), receiver.raftNode.Status().Commit)
+
+	close(proposeChan)
+	close(proposeConfChangeChan)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/mhelmich/calvin/execution"
+	"github.com/mhelmich/calvin/sequencer"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// the snapshot handler calvin uses if none is provided in the options
+// snapshots contain all partitions of the data store
+func newDataStoreSnapshotHandler(partitionedDataStore util.PartitionedDataStore, cip util.ClusterInfoProvider, logger *log.Entry) *dataStoreSnapshotHandler {
+	return &dataStoreSnapshotHandler{
+		partitionedDataStore: partitionedDataStore,
+		cip:                  cip,
+		logger:               logger,
+	}
+}
+
+type dataStoreSnapshotHandler struct {
+	partitionedDataStore util.PartitionedDataStore
+	cip                  util.ClusterInfoProvider
+	logger               *log.Entry
+}
+
+func (h *dataStoreSnapshotHandler) Provide(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) ([]byte, error) {
+	_, data, err := h.ProvideCheckpoint(lastSnapshot, entriesAppliedSinceLastSnapshot)
+	return data, err
+}
+
+// txns don't finish in log order
+// the data store only contains everything up to the checkpoint for sure
+// checkpoints only move forward and that's why the checkpoint is read before
+// the partitions are written into the snapshot
+func (h *dataStoreSnapshotHandler) ProvideCheckpoint(lastSnapshot raftpb.Snapshot, entriesAppliedSinceLastSnapshot []raftpb.Entry) (uint64, []byte, error) {
+	checkpoint, err := execution.ReadCheckpoint(h.partitionedDataStore, h.cip.MyPartitions())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	err = h.partitionedDataStore.Snapshot(buf)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	h.logger.Infof("created data store snapshot at checkpoint [%d] with [%d] bytes", checkpoint, buf.Len())
+	return checkpoint, buf.Bytes(), nil
+}
+
+// only partitions owned by this node are restored
+// the snapshot might come from a node that owns other partitions as well
+func (h *dataStoreSnapshotHandler) Consume(snapshotData []byte) error {
+	return h.consume(bytes.NewReader(snapshotData), int64(len(snapshotData)))
+}
+
+// partitions are restored straight from the file
+// the snapshot doesn't need to fit into memory
+func (h *dataStoreSnapshotHandler) ConsumeFile(snapshotPath string) error {
+	f, err := os.Open(snapshotPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return h.consume(f, info.Size())
+}
+
+func (h *dataStoreSnapshotHandler) consume(r io.ReaderAt, size int64) error {
+	partitionIDs := h.cip.MyPartitions()
+	err := sequencer.ConsumePartitionedSnapshot(r, size, partitionIDs, h.restorePartition)
+	if err != nil {
+		return err
+	}
+
+	h.logger.Infof("restored partitions %v from snapshot", partitionIDs)
+	return nil
+}
+
+// a follower only needs the partitions it holds
+func (h *dataStoreSnapshotHandler) Target(snapshotData []byte, recipientID uint64) ([]byte, error) {
+	return sequencer.TargetPartitionedSnapshot(snapshotData, h.cip.PartitionsForNode(recipientID))
+}
+
+func (h *dataStoreSnapshotHandler) restorePartition(partitionID int, r io.Reader) error {
+	txnProvider, err := h.partitionedDataStore.GetPartition(partitionID)
+	if err != nil {
+		txnProvider, err = h.partitionedDataStore.CreatePartition(partitionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return txnProvider.Restore(r)
+}
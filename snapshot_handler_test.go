@@ -0,0 +1,129 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mhelmich/calvin/mocks"
+	calvinpb "github.com/mhelmich/calvin/pb"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestDataStoreSnapshotHandlerRoundTrip(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	srcDir := fmt.Sprintf("./test-TestDataStoreSnapshotHandlerRoundTrip-src-%d/", util.RandomRaftId())
+	dstDir := fmt.Sprintf("./test-TestDataStoreSnapshotHandlerRoundTrip-dst-%d/", util.RandomRaftId())
+	defer os.RemoveAll(srcDir)
+	defer os.RemoveAll(dstDir)
+	assert.Nil(t, os.MkdirAll(srcDir, os.ModePerm))
+	assert.Nil(t, os.MkdirAll(dstDir, os.ModePerm))
+
+	src := newPartitionedBoltStore(srcDir, logger)
+	defer src.Close()
+	for _, partitionID := range []int{1, 2} {
+		bds, err := src.CreatePartition(partitionID)
+		assert.Nil(t, err)
+		writeBoltKey(t, bds, "narf", fmt.Sprintf("narf_%d", partitionID))
+		writeBoltKey(t, bds, "\x00calvin/checkpoint", string(util.Uint64ToBytes(uint64(10+partitionID))))
+	}
+
+	srcCip := &mocks.ClusterInfoProvider{}
+	srcCip.On("MyPartitions").Return([]int{1, 2})
+	srcHandler := newDataStoreSnapshotHandler(src, srcCip, logger)
+	index, data, err := srcHandler.ProvideCheckpoint(raftpb.Snapshot{}, nil)
+	assert.Nil(t, err)
+	ps := &calvinpb.PartitionedSnapshot{}
+	assert.Nil(t, ps.Unmarshal(data))
+	// the lowest checkpoint of all partitions
+	assert.Equal(t, uint64(11), index)
+
+	// this node only owns partition 2
+	dst := newPartitionedBoltStore(dstDir, logger)
+	defer dst.Close()
+	bds, err := dst.CreatePartition(2)
+	assert.Nil(t, err)
+	writeBoltKey(t, bds, "moep", "moep_2")
+
+	dstCip := &mocks.ClusterInfoProvider{}
+	dstCip.On("MyPartitions").Return([]int{2})
+	dstHandler := newDataStoreSnapshotHandler(dst, dstCip, logger)
+	// streamed snapshots are restored from the file they were received into
+	snapshotPath := dstDir + "snapshot"
+	assert.Nil(t, ioutil.WriteFile(snapshotPath, data, 0600))
+	err = dstHandler.ConsumeFile(snapshotPath)
+	assert.Nil(t, err)
+
+	bds, err = dst.GetPartition(2)
+	assert.Nil(t, err)
+	txn, err := bds.StartTxn(false)
+	assert.Nil(t, err)
+	assert.Equal(t, "narf_2", string(txn.Get([]byte("narf"))))
+	assert.Nil(t, txn.Get([]byte("moep")))
+	assert.Nil(t, txn.Rollback())
+
+	_, err = dst.GetPartition(1)
+	assert.NotNil(t, err)
+
+	err = dstHandler.Consume([]byte("narf"))
+	assert.NotNil(t, err)
+
+	// the data of a partition this node owns can't be lost because the sender doesn't have it
+	dstCip = &mocks.ClusterInfoProvider{}
+	dstCip.On("MyPartitions").Return([]int{2, 4})
+	err = newDataStoreSnapshotHandler(dst, dstCip, logger).Consume(data)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "partition [4]")
+
+	// a partition that can't be restored keeps its data
+	// and doesn't prevent other partitions from being restored
+	// the snapshot fails nonetheless
+	bds, err = dst.CreatePartition(3)
+	assert.Nil(t, err)
+	writeBoltKey(t, bds, "moep", "moep_3")
+	broken := &calvinpb.PartitionedSnapshot{
+		PartitionIDs: []uint64{2, 3},
+		Snapshots:    [][]byte{ps.Snapshots[1], []byte("narf")},
+	}
+	data, err = broken.Marshal()
+	assert.Nil(t, err)
+	dstCip = &mocks.ClusterInfoProvider{}
+	dstCip.On("MyPartitions").Return([]int{2, 3})
+	dstHandler = newDataStoreSnapshotHandler(dst, dstCip, logger)
+	writeBoltKey(t, bds, "zort", "zort_3")
+	bds2, err := dst.GetPartition(2)
+	assert.Nil(t, err)
+	writeBoltKey(t, bds2, "zort", "zort_2")
+	err = dstHandler.Consume(data)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "partition [3]")
+
+	txn, err = bds2.StartTxn(false)
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Get([]byte("zort")))
+	assert.Nil(t, txn.Rollback())
+	txn, err = bds.StartTxn(false)
+	assert.Nil(t, err)
+	assert.Equal(t, "zort_3", string(txn.Get([]byte("zort"))))
+	assert.Nil(t, txn.Rollback())
+}
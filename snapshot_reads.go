@@ -0,0 +1,167 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mhelmich/calvin/execution"
+	"github.com/mhelmich/calvin/scheduler"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultSnapshotViewMaxAge is how long snapshot reads share a view of the data store by default.
+const DefaultSnapshotViewMaxAge = 100 * time.Millisecond
+
+const (
+	// batch boundaries are at least this far apart
+	// that way reads don't keep later batches from running most of the time
+	snapshotBoundaryInterval = 100 * time.Millisecond
+	// how long later batches are held back at most while the running batches finish
+	snapshotBoundaryTimeout = 100 * time.Millisecond
+)
+
+func newSnapshotReader(sched *scheduler.Scheduler, engine *execution.Engine, partitionedStore util.PartitionedDataStore, cip util.ClusterInfoProvider, maxViewAge time.Duration, logger *log.Entry) *snapshotReader {
+	return &snapshotReader{
+		sched:            sched,
+		engine:           engine,
+		partitionedStore: partitionedStore,
+		cip:              cip,
+		maxViewAge:       maxViewAge,
+		mutex:            &sync.Mutex{},
+		logger:           logger,
+	}
+}
+
+// the snapshot reader hands out views of the local partitions as of a batch boundary
+// unless the partitions keep older versions, opening a view pauses the execution of later batches for a moment
+// that's why views are shared for a while
+// a view holds a bolt read txn on every local partition for as long as it's around
+// writers that need to grow the mmap of a partition block until all read txns on it are done
+// so views are only shared for a short time
+type snapshotReader struct {
+	sched            *scheduler.Scheduler
+	engine           *execution.Engine
+	partitionedStore util.PartitionedDataStore
+	cip              util.ClusterInfoProvider
+	// how long a view is shared by all reads
+	maxViewAge time.Duration
+	mutex      *sync.Mutex
+	current    *execution.SnapshotView
+	// when the last batch boundary started
+	lastBoundary time.Time
+	logger       *log.Entry
+}
+
+// returns a view that reflects all batches up to minIndex
+// minIndex is a lower bound, the view might reflect later batches as well
+// the index of the view tells which batch it reflects exactly
+// the view needs to be released after use
+func (sr *snapshotReader) acquireViewAtOrAfter(ctx context.Context, minIndex uint64) (*execution.SnapshotView, error) {
+	select {
+	case <-sr.engine.WaitForCheckpoint(minIndex):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	if sr.current != nil && sr.current.Index() >= minIndex && sr.current.Acquire() {
+		return sr.current, nil
+	}
+
+	view, err := sr.openView(ctx, minIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	if sr.current != nil {
+		sr.current.Retire()
+	}
+	sr.current = view
+	view.Acquire()
+	time.AfterFunc(sr.maxViewAge, view.Retire)
+	return view, nil
+}
+
+// versioned partitions are read as of the last batch that is done without holding anything back
+// otherwise txns of later batches wait at a batch boundary while the view is opened
+// needs to be called with the lock held
+func (sr *snapshotReader) openView(ctx context.Context, minIndex uint64) (*execution.SnapshotView, error) {
+	if sr.isVersioned() {
+		index, term := sr.sched.LastDoneBatch()
+		// right after a restart no batch might be done yet
+		if index > 0 && index >= minIndex {
+			return execution.OpenVersionedSnapshotView(sr.partitionedStore, sr.cip, index, term, sr.logger)
+		}
+	}
+
+	wait := snapshotBoundaryInterval - time.Since(sr.lastBoundary)
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+
+	// the running batches might take a while to finish
+	// later batches aren't held back for longer than the timeout
+	boundaryCtx, cancel := context.WithTimeout(ctx, snapshotBoundaryTimeout)
+	defer cancel()
+	sr.lastBoundary = time.Now()
+	var view *execution.SnapshotView
+	err := sr.sched.AtBatchBoundary(boundaryCtx, func(index uint64, term uint64) error {
+		var err error
+		view, err = execution.OpenSnapshotView(sr.partitionedStore, sr.cip, index, term, sr.logger)
+		return err
+	})
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return nil, fmt.Errorf("running batches didn't finish within %s", snapshotBoundaryTimeout.String())
+	}
+	return view, err
+}
+
+func (sr *snapshotReader) isVersioned() bool {
+	for _, partitionID := range sr.cip.MyPartitions() {
+		txnProvider, err := sr.partitionedStore.GetPartition(partitionID)
+		if err != nil {
+			return false
+		}
+
+		_, ok := txnProvider.(util.VersionedDataStoreTxnProvider)
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (sr *snapshotReader) stop() {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+	if sr.current != nil {
+		sr.current.Retire()
+		sr.current = nil
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -79,25 +81,29 @@ func (bdsp *partitionedBadgerStore) GetPartition(partitionID int) (calvinutil.Da
 }
 
 func (bdsp *partitionedBadgerStore) Snapshot(w io.Writer) error {
-	var err error
-	ps := &calvinpb.PartitionedSnapshot{}
+	partitionIDs := make([]int, 0)
 	bdsp.partitions.Range(func(key, value interface{}) bool {
-		partitionID := key.(uint64)
-		bds := value.(*badgerDataStore)
+		partitionIDs = append(partitionIDs, key.(int))
+		return true
+	})
+	sort.Ints(partitionIDs)
+
+	ps := &calvinpb.PartitionedSnapshot{}
+	for _, partitionID := range partitionIDs {
+		v, ok := bdsp.partitions.Load(partitionID)
+		if !ok {
+			continue
+		}
+
 		buf := new(bytes.Buffer)
-		err = bds.Snapshot(buf)
+		err := v.(*badgerDataStore).Snapshot(buf)
 		if err != nil {
-			return false
+			bdsp.logger.Errorf("can't collect partitioned snapshot: %s", err.Error())
+			return err
 		}
 
-		ps.PartitionIDs = append(ps.PartitionIDs, partitionID)
+		ps.PartitionIDs = append(ps.PartitionIDs, uint64(partitionID))
 		ps.Snapshots = append(ps.Snapshots, buf.Bytes())
-		return true
-	})
-
-	if err != nil {
-		bdsp.logger.Errorf("can't collect partitioned snapshot: %s", err.Error())
-		return err
 	}
 
 	data, err := ps.Marshal()
@@ -115,6 +121,40 @@ func (bdsp *partitionedBadgerStore) Snapshot(w io.Writer) error {
 	return nil
 }
 
+func (bdsp *partitionedBadgerStore) Restore(r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	ps := &calvinpb.PartitionedSnapshot{}
+	err = ps.Unmarshal(data)
+	if err != nil {
+		return err
+	} else if len(ps.PartitionIDs) != len(ps.Snapshots) {
+		return fmt.Errorf("partitioned snapshot has [%d] partition ids but [%d] snapshots", len(ps.PartitionIDs), len(ps.Snapshots))
+	}
+
+	for idx := range ps.PartitionIDs {
+		partitionID := int(ps.PartitionIDs[idx])
+		bds, err := bdsp.GetPartition(partitionID)
+		if err != nil {
+			bds, err = bdsp.CreatePartition(partitionID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = bds.Restore(bytes.NewReader(ps.Snapshots[idx]))
+		if err != nil {
+			bdsp.logger.Errorf("can't restore partition [%d]: %s", partitionID, err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (bdsp *partitionedBadgerStore) Close() {
 	bdsp.partitions.Range(func(key, value interface{}) bool {
 		bds := value.(*badgerDataStore)
@@ -155,6 +195,22 @@ func (bds *badgerDataStore) Snapshot(w io.Writer) error {
 	return err
 }
 
+// drops everything in the partition and loads the backup
+func (bds *badgerDataStore) Restore(r io.Reader) error {
+	err := bds.db.DropAll()
+	if err != nil {
+		return err
+	}
+
+	err = bds.db.Load(r, 256)
+	if err != nil {
+		return err
+	}
+
+	bds.logger.Infof("Restored backup on partition %d", bds.partitionID)
+	return nil
+}
+
 func (bds *badgerDataStore) StartTxn(writable bool) (calvinutil.DataStoreTxn, error) {
 	txn := bds.db.NewTransaction(writable)
 	return &badgerDataStoreTxn{
@@ -200,6 +256,19 @@ func (t *badgerDataStoreTxn) Set(key []byte, value []byte) error {
 	return t.txn.Set(key, value)
 }
 
+func (t *badgerDataStoreTxn) Delete(key []byte) error {
+	return t.txn.Delete(key)
+}
+
+func (t *badgerDataStoreTxn) Iterate(start []byte, end []byte) calvinutil.DataStoreIterator {
+	return &badgerDataStoreIterator{
+		it:     t.txn.NewIterator(badger.DefaultIteratorOptions),
+		start:  start,
+		end:    end,
+		logger: t.logger,
+	}
+}
+
 func (t *badgerDataStoreTxn) Commit() error {
 	return t.txn.Commit()
 }
@@ -208,3 +277,54 @@ func (t *badgerDataStoreTxn) Rollback() error {
 	t.txn.Discard()
 	return nil
 }
+
+// writable badger txns only support one iterator at a time
+type badgerDataStoreIterator struct {
+	it      *badger.Iterator
+	start   []byte
+	end     []byte
+	started bool
+	key     []byte
+	value   []byte
+	logger  *log.Entry
+}
+
+func (it *badgerDataStoreIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		it.it.Seek(it.start)
+	} else if it.key != nil {
+		it.it.Next()
+	}
+
+	if !it.it.Valid() {
+		it.key, it.value = nil, nil
+		return false
+	}
+
+	item := it.it.Item()
+	if it.end != nil && bytes.Compare(item.Key(), it.end) >= 0 {
+		it.key, it.value = nil, nil
+		return false
+	}
+
+	var err error
+	it.key = item.Key()
+	it.value, err = item.ValueCopy(it.value[:0])
+	if err != nil {
+		it.logger.Panicf("%s", err.Error())
+	}
+	return true
+}
+
+func (it *badgerDataStoreIterator) Key() []byte {
+	return it.key
+}
+
+func (it *badgerDataStoreIterator) Value() []byte {
+	return it.value
+}
+
+func (it *badgerDataStoreIterator) Close() {
+	it.it.Close()
+}
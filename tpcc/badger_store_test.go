@@ -121,3 +121,44 @@ func pathExists(path string) (bool, error) {
 	}
 	return true, err
 }
+
+func TestBadgerStoreIterate(t *testing.T) {
+	baseDir := "./test-TestBadgerStoreIterate-" + util.Uint64ToString(util.RandomRaftId()) + "/"
+	err := os.MkdirAll(baseDir, os.ModePerm)
+	assert.Nil(t, err)
+	defer os.RemoveAll(baseDir)
+	logger := log.WithFields(log.Fields{})
+	storeProvider := newPartitionedBadgerStore(baseDir, logger)
+	defer storeProvider.Close()
+
+	store, err := storeProvider.CreatePartition(1)
+	assert.Nil(t, err)
+	txn, err := store.StartTxn(true)
+	assert.Nil(t, err)
+	for i := 9; i >= 0; i-- {
+		txn.Set([]byte(fmt.Sprintf("key_%d", i)), []byte(fmt.Sprintf("value_%d", i)))
+	}
+	txn.Set([]byte("narf"), []byte("narf"))
+	err = txn.Commit()
+	assert.Nil(t, err)
+
+	txn, err = store.StartTxn(false)
+	assert.Nil(t, err)
+	defer txn.Rollback()
+	it := txn.Iterate([]byte("key_"), util.PrefixEnd([]byte("key_")))
+	for i := 0; i < 10; i++ {
+		assert.True(t, it.Next())
+		assert.Equal(t, fmt.Sprintf("key_%d", i), string(it.Key()))
+		assert.Equal(t, fmt.Sprintf("value_%d", i), string(it.Value()))
+	}
+	assert.False(t, it.Next())
+	it.Close()
+
+	it = txn.Iterate([]byte("key_3"), []byte("key_6"))
+	numKeys := 0
+	for it.Next() {
+		numKeys++
+	}
+	it.Close()
+	assert.Equal(t, 3, numKeys)
+}
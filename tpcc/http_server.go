@@ -113,6 +113,11 @@ func startNewHttpServer(port int, c *calvin.Calvin, logger *log.Entry) *httpServ
 	return srvr
 }
 
+func drainTxns(txnChan <-chan *calvinpb.Transaction) {
+	for range txnChan {
+	}
+}
+
 type httpServer struct {
 	http.Server
 	c      *calvin.Calvin
@@ -133,7 +138,13 @@ func (s *httpServer) initStore(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 		size += uint64(txn.Size())
-		s.c.SubmitTransaction(txn)
+		err := s.c.SubmitTransaction(txn)
+		if err != nil {
+			// let the generator finish
+			go drainTxns(txnChan)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 	}
 	s.logger.Infof("Done submitting txns [%d]", size)
 	a = append(a, fmt.Sprintf("Done generating txns [%d]", size))
@@ -161,7 +172,13 @@ func (s *httpServer) createTxns(w http.ResponseWriter, r *http.Request) {
 
 		i++
 		size += uint64(txn.Size())
-		s.c.SubmitTransaction(txn)
+		err := s.c.SubmitTransaction(txn)
+		if err != nil {
+			// let the generator finish
+			go drainTxns(txnChan)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 	}
 
 	a := []string{fmt.Sprintf("Done generating txns [%d] txns for [%d] bytes", i, size)}
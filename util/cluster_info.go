@@ -19,7 +19,11 @@ package util
 import (
 	"fmt"
 	"hash/fnv"
+	"net"
 	"os"
+	"sort"
+	"strconv"
+	"sync"
 
 	"github.com/naoina/toml"
 	log "github.com/sirupsen/logrus"
@@ -32,19 +36,41 @@ type ClusterInfoProvider interface {
 	AmIWriter(writerNodes []uint64) bool
 	GetAddressFor(nodeID uint64) string
 	MyPartitions() []int
+	// PartitionsForNode returns the partitions the provided node holds.
+	PartitionsForNode(nodeID uint64) []int
 	FindOwnerForPartition(partitionID int) uint64
+	// SetAddressFor adds a node to the address book or changes the address of a known node.
+	// The address needs to be of the form host:port.
+	SetAddressFor(nodeID uint64, address string) error
+	// RemoveNode drops a node from the address book.
+	// The partitions the node led move to the nodes that replicate them.
+	RemoveNode(nodeID uint64)
+	// IsLearner returns true if the node is a learner of the raft groups.
+	// Learners replicate the partitions they hold without taking part in the quorum.
+	// Until SetLearners is called, a node that isn't a leader but holds partitions a leader owns is a learner.
+	IsLearner(nodeID uint64) bool
+	// SetLearners replaces the learners with the learners of the applied raft conf state.
+	// That way nodes that were added or promoted are learners or members on all nodes at the same position in the log.
+	SetLearners(learners []uint64)
+	// FindLearnersForNode returns the sorted ids of all learners that hold partitions of the provided node.
+	FindLearnersForNode(nodeID uint64) []uint64
 }
 
 func NewClusterInfoProvider(ownNodeID uint64, pathToClusterInfo string) ClusterInfoProvider {
 	return &cip{
 		ownNodeID: ownNodeID,
 		ci:        readClusterInfo(pathToClusterInfo),
+		mutex:     &sync.RWMutex{},
 	}
 }
 
+// the address book changes when nodes join or leave the cluster
+// learners are nil until raft applied a conf state
 type cip struct {
 	ownNodeID uint64
 	ci        clusterInfo
+	learners  map[uint64]bool
+	mutex     *sync.RWMutex
 }
 
 func (c *cip) hashKeyToPartition(key []byte) int {
@@ -53,17 +79,29 @@ func (c *cip) hashKeyToPartition(key []byte) int {
 	return int(hasher.Sum64() % uint64(c.ci.NumberPartitions))
 }
 
+// if a partition is held by several nodes, the leader owns it
+// without a leader, the node with the lowest id owns the partition
+// with several leaders (after a leader was removed), the leader with the lowest id owns it
+// that way all nodes agree on the owner of a key
 func (c *cip) FindOwnerForKey(key []byte) uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	partition := c.hashKeyToPartition(key)
+	var ownerID uint64
+	ownerIsLeader := false
 	for nodeID, node := range c.ci.Nodes {
-		for idx := range node.Partitions {
-			if node.Partitions[idx] == partition {
-				return nodeID
-			}
+		if !node.hasPartition(partition) {
+			continue
+		} else if node.IsLeader && (!ownerIsLeader || nodeID < ownerID) {
+			ownerID = nodeID
+			ownerIsLeader = true
+		} else if !ownerIsLeader && (ownerID == 0 || nodeID < ownerID) {
+			ownerID = nodeID
 		}
 	}
 
-	return uint64(0)
+	return ownerID
 }
 
 func (c *cip) FindPartitionForKey(key []byte) int {
@@ -72,25 +110,39 @@ func (c *cip) FindPartitionForKey(key []byte) int {
 }
 
 func (c *cip) MyPartitions() []int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	node := c.ci.Nodes[c.ownNodeID]
 	return node.Partitions
 }
 
+func (c *cip) PartitionsForNode(nodeID uint64) []int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	node := c.ci.Nodes[nodeID]
+	return node.Partitions
+}
+
 func (c *cip) FindOwnerForPartition(partitionID int) uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	var ownerID uint64
 	for idx := range c.ci.Nodes {
 		node := c.ci.Nodes[idx]
-		if node.IsLeader {
-			for _, nodesPartitionID := range node.Partitions {
-				if nodesPartitionID == partitionID {
-					return c.ci.Nodes[idx].ID
-				}
-			}
+		if node.IsLeader && node.hasPartition(partitionID) && (ownerID == 0 || node.ID < ownerID) {
+			ownerID = node.ID
 		}
 	}
-	return uint64(0)
+	return ownerID
 }
 
 func (c *cip) IsLocal(key []byte) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	partition := c.hashKeyToPartition(key)
 	node := c.ci.Nodes[c.ownNodeID]
 	for idx := range node.Partitions {
@@ -111,6 +163,9 @@ func (c *cip) AmIWriter(writerNodes []uint64) bool {
 }
 
 func (c *cip) GetAddressFor(nodeID uint64) string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	node, ok := c.ci.Nodes[nodeID]
 	if !ok {
 		return ""
@@ -119,6 +174,152 @@ func (c *cip) GetAddressFor(nodeID uint64) string {
 	return fmt.Sprintf("%s:%d", node.Hostname, node.Port)
 }
 
+func (c *cip) SetAddressFor(nodeID uint64, address string) error {
+	hostname, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return fmt.Errorf("invalid port in address [%s]: %s", address, err.Error())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// a new node doesn't own any partitions
+	n := c.ci.Nodes[nodeID]
+	n.ID = nodeID
+	n.Hostname = hostname
+	n.Port = port
+	if c.ci.Nodes == nil {
+		c.ci.Nodes = make(map[uint64]node)
+	}
+	c.ci.Nodes[nodeID] = n
+	return nil
+}
+
+func (c *cip) RemoveNode(nodeID uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	removed, ok := c.ci.Nodes[nodeID]
+	if !ok {
+		return
+	}
+
+	delete(c.ci.Nodes, nodeID)
+	c.reassignPartitions(removed)
+}
+
+// the replica with the lowest id takes over the partitions of a removed leader
+// partitions nobody else holds go to the leader with the fewest partitions, their data is gone though
+// all nodes remove nodes in log order and end up with the same assignment
+func (c *cip) reassignPartitions(removed node) {
+	for _, partitionID := range removed.Partitions {
+		var replicaID uint64
+		hasLeader := false
+		for otherID, other := range c.ci.Nodes {
+			if !other.hasPartition(partitionID) {
+				continue
+			} else if other.IsLeader {
+				hasLeader = true
+				break
+			} else if replicaID == 0 || otherID < replicaID {
+				replicaID = otherID
+			}
+		}
+
+		if hasLeader {
+			continue
+		} else if replicaID != 0 {
+			replica := c.ci.Nodes[replicaID]
+			replica.IsLeader = true
+			c.ci.Nodes[replicaID] = replica
+			log.Infof("node [%d] leads partition [%d] of removed node [%d]", replicaID, partitionID, removed.ID)
+			continue
+		}
+
+		leaderID := c.leaderWithFewestPartitions()
+		if leaderID == 0 {
+			log.Errorf("no node left to take over partition [%d] of removed node [%d]", partitionID, removed.ID)
+			continue
+		}
+
+		leader := c.ci.Nodes[leaderID]
+		leader.Partitions = append(append([]int{}, leader.Partitions...), partitionID)
+		sort.Ints(leader.Partitions)
+		c.ci.Nodes[leaderID] = leader
+		log.Warningf("node [%d] owns partition [%d] of removed node [%d] but nobody replicated its data", leaderID, partitionID, removed.ID)
+	}
+}
+
+func (c *cip) leaderWithFewestPartitions() uint64 {
+	var leaderID uint64
+	for nodeID, node := range c.ci.Nodes {
+		if !node.IsLeader {
+			continue
+		} else if leaderID == 0 || len(node.Partitions) < len(c.ci.Nodes[leaderID].Partitions) ||
+			(len(node.Partitions) == len(c.ci.Nodes[leaderID].Partitions) && nodeID < leaderID) {
+			leaderID = nodeID
+		}
+	}
+	return leaderID
+}
+
+func (c *cip) IsLearner(nodeID uint64) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.isLearner(nodeID)
+}
+
+func (c *cip) SetLearners(learners []uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.learners = make(map[uint64]bool, len(learners))
+	for _, nodeID := range learners {
+		c.learners[nodeID] = true
+	}
+}
+
+func (c *cip) isLearner(nodeID uint64) bool {
+	if c.learners != nil {
+		return c.learners[nodeID]
+	}
+
+	// the cluster info decides who is a learner before raft does
+	node, ok := c.ci.Nodes[nodeID]
+	if !ok || node.IsLeader {
+		return false
+	}
+
+	for _, other := range c.ci.Nodes {
+		if other.IsLeader && other.sharesPartitionWith(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cip) FindLearnersForNode(nodeID uint64) []uint64 {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	learners := make([]uint64, 0)
+	node, ok := c.ci.Nodes[nodeID]
+	if !ok {
+		return learners
+	}
+
+	for otherID, other := range c.ci.Nodes {
+		if otherID != nodeID && c.isLearner(otherID) && other.sharesPartitionWith(node) {
+			learners = append(learners, otherID)
+		}
+	}
+
+	sort.Slice(learners, func(i, j int) bool { return learners[i] < learners[j] })
+	return learners
+}
+
 type clusterInfo struct {
 	NumberPrimaries  int
 	NumberPartitions int
@@ -133,6 +334,24 @@ type node struct {
 	IsLeader   bool
 }
 
+func (n node) hasPartition(partitionID int) bool {
+	for idx := range n.Partitions {
+		if n.Partitions[idx] == partitionID {
+			return true
+		}
+	}
+	return false
+}
+
+func (n node) sharesPartitionWith(other node) bool {
+	for idx := range other.Partitions {
+		if n.hasPartition(other.Partitions[idx]) {
+			return true
+		}
+	}
+	return false
+}
+
 func readClusterInfo(path string) clusterInfo {
 	f, err := os.Open(path)
 	if err != nil {
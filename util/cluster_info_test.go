@@ -45,4 +45,74 @@ func TestClusterInfoFindOwnerAndLeaderForPartition(t *testing.T) {
 
 	nodeID = cip1.FindOwnerForPartition(1)
 	assert.Equal(t, uint64(2), nodeID)
+
+	assert.Equal(t, cip3.MyPartitions(), cip1.PartitionsForNode(3))
+	assert.Nil(t, cip1.PartitionsForNode(99))
+}
+
+func TestClusterInfoSetAddressAndRemoveNode(t *testing.T) {
+	cip := NewClusterInfoProvider(uint64(1), "../tpcc/cluster_info.toml")
+	err := cip.SetAddressFor(uint64(99), "narf:1234")
+	assert.Nil(t, err)
+	assert.Equal(t, "narf:1234", cip.GetAddressFor(uint64(99)))
+
+	// partitions of known nodes stay where they are
+	partitions := cip.MyPartitions()
+	err = cip.SetAddressFor(uint64(1), "moep:5678")
+	assert.Nil(t, err)
+	assert.Equal(t, "moep:5678", cip.GetAddressFor(uint64(1)))
+	assert.Equal(t, partitions, cip.MyPartitions())
+
+	err = cip.SetAddressFor(uint64(99), "narf")
+	assert.NotNil(t, err)
+
+	cip.RemoveNode(uint64(99))
+	assert.Equal(t, "", cip.GetAddressFor(uint64(99)))
+}
+
+func TestClusterInfoLearners(t *testing.T) {
+	cip := NewClusterInfoProvider(uint64(1), "../tpcc/cluster_info.toml")
+	assert.False(t, cip.IsLearner(uint64(1)))
+	assert.False(t, cip.IsLearner(uint64(2)))
+	assert.True(t, cip.IsLearner(uint64(3)))
+	assert.False(t, cip.IsLearner(uint64(99)))
+
+	assert.Equal(t, []uint64{}, cip.FindLearnersForNode(uint64(1)))
+	assert.Equal(t, []uint64{3}, cip.FindLearnersForNode(uint64(2)))
+	assert.Equal(t, []uint64{}, cip.FindLearnersForNode(uint64(3)))
+
+	// the learner holds the same partitions but the leader owns them
+	key := "mrmoep"
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, uint64(2), cip.FindOwnerForKey([]byte(key)))
+	}
+	cip3 := NewClusterInfoProvider(uint64(3), "../tpcc/cluster_info.toml")
+	assert.True(t, cip3.IsLocal([]byte(key)))
+
+	// once raft applied a conf state, it decides who is a learner
+	cip.SetLearners([]uint64{})
+	assert.False(t, cip.IsLearner(uint64(3)))
+	assert.Equal(t, []uint64{}, cip.FindLearnersForNode(uint64(2)))
+	cip.SetLearners([]uint64{3, 99})
+	assert.True(t, cip.IsLearner(uint64(3)))
+	assert.True(t, cip.IsLearner(uint64(99)))
+	assert.Equal(t, []uint64{3}, cip.FindLearnersForNode(uint64(2)))
+}
+
+func TestClusterInfoRemoveNodeReassignsPartitions(t *testing.T) {
+	// the learner takes over the partitions of its leader
+	cip := NewClusterInfoProvider(uint64(1), "../tpcc/cluster_info.toml")
+	cip.RemoveNode(uint64(2))
+	assert.Equal(t, uint64(3), cip.FindOwnerForPartition(1))
+	assert.Equal(t, uint64(3), cip.FindOwnerForKey([]byte("mrmoep")))
+	assert.False(t, cip.IsLearner(uint64(3)))
+	assert.Equal(t, []int{2, 4, 6}, cip.MyPartitions())
+
+	// without replicas the partitions go to the remaining leader
+	cip.RemoveNode(uint64(1))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, cip.PartitionsForNode(uint64(3)))
+	assert.Equal(t, uint64(3), cip.FindOwnerForPartition(2))
+
+	cip.RemoveNode(uint64(3))
+	assert.Equal(t, uint64(0), cip.FindOwnerForPartition(1))
 }
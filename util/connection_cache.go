@@ -36,6 +36,10 @@ type ConnectionCache interface {
 	GetLowIsolationReadClient(nodeID uint64) (pb.LowIsolationReadClient, error)
 	GetRemoteReadClient(nodeID uint64) (pb.RemoteReadClient, error)
 	GetRaftTransportClient(nodeID uint64) (pb.RaftTransportClient, error)
+	GetTransactionOutcomesClient(nodeID uint64) (pb.TransactionOutcomesClient, error)
+	// CloseConnection closes the connection to a node
+	// the next client for the node connects to the address that is known then
+	CloseConnection(nodeID uint64)
 	Close()
 }
 
@@ -71,6 +75,15 @@ func (cc *connCache) GetLowIsolationReadClient(nodeID uint64) (pb.LowIsolationRe
 	return pb.NewLowIsolationReadClient(conn), nil
 }
 
+func (cc *connCache) GetTransactionOutcomesClient(nodeID uint64) (pb.TransactionOutcomesClient, error) {
+	conn, err := cc.getConn(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pb.NewTransactionOutcomesClient(conn), nil
+}
+
 func (cc *connCache) getConn(nodeID uint64) (*grpc.ClientConn, error) {
 	addr := cc.getAddressFor(nodeID)
 	c, ok := cc.nodeIDToConn.Load(nodeID)
@@ -91,6 +104,14 @@ func (cc *connCache) getConn(nodeID uint64) (*grpc.ClientConn, error) {
 	return c.(*grpc.ClientConn), nil
 }
 
+func (cc *connCache) CloseConnection(nodeID uint64) {
+	c, ok := cc.nodeIDToConn.Load(nodeID)
+	if ok {
+		cc.nodeIDToConn.Delete(nodeID)
+		c.(*grpc.ClientConn).Close()
+	}
+}
+
 func (cc *connCache) Close() {
 	cc.nodeIDToConn.Range(func(key, value interface{}) bool {
 		conn := value.(*grpc.ClientConn)
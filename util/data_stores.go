@@ -21,13 +21,20 @@ import "io"
 type PartitionedDataStore interface {
 	CreatePartition(partitionID int) (DataStoreTxnProvider, error)
 	GetPartition(partitionID int) (DataStoreTxnProvider, error)
+	// writes a PartitionedSnapshot of all partitions
 	Snapshot(w io.Writer) error
+	// rebuilds all partitions contained in a PartitionedSnapshot
+	// partitions that don't exist yet are created
+	Restore(r io.Reader) error
 	Close()
 }
 
 type DataStoreTxnProvider interface {
 	StartTxn(writable bool) (DataStoreTxn, error)
+	// writes a consistent copy of the partition
 	Snapshot(w io.Writer) error
+	// replaces the contents of the partition with a copy written by Snapshot
+	Restore(r io.Reader) error
 	Close()
 	Delete()
 }
@@ -35,6 +42,65 @@ type DataStoreTxnProvider interface {
 type DataStoreTxn interface {
 	Get(key []byte) []byte
 	Set(key []byte, value []byte) error
+	Delete(key []byte) error
+	// iterates over all keys from start (inclusive) to end (exclusive) in the order of their bytes
+	// a nil end iterates to the last key
+	// iterators need to be closed before the txn ends
+	Iterate(start []byte, end []byte) DataStoreIterator
 	Commit() error
 	Rollback() error
 }
+
+// A DataStoreIterator walks over the keys of a txn in order.
+// It starts out before the first key.
+// Keys and values are only valid until the iterator moves on.
+type DataStoreIterator interface {
+	// moves to the next key and returns false if there is none
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Close()
+}
+
+// PrefixEnd returns the first key after all keys that start with prefix.
+// It returns nil if no such key exists.
+func PrefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for idx := len(end) - 1; idx >= 0; idx-- {
+		if end[idx] < 0xFF {
+			end[idx]++
+			return end[:idx+1]
+		}
+	}
+	return nil
+}
+
+// A Version identifies the txn that wrote a value.
+// Versions are ordered by the index of the batch first and the position of the txn in its batch second.
+type Version struct {
+	BatchIndex  uint64
+	TxnPosition uint64
+}
+
+// Less returns true if v was written before other.
+func (v Version) Less(other Version) bool {
+	if v.BatchIndex != other.BatchIndex {
+		return v.BatchIndex < other.BatchIndex
+	}
+	return v.TxnPosition < other.TxnPosition
+}
+
+// VersionedDataStoreTxnProvider is a partition that keeps older versions of its values around.
+// Txns started with StartTxn read the latest versions
+// and their writes go on top of the latest version that was written.
+type VersionedDataStoreTxnProvider interface {
+	DataStoreTxnProvider
+	// starts a writable txn whose writes are stamped with the provided version
+	StartVersionedTxn(version Version) (DataStoreTxn, error)
+	// starts a read-only txn that sees the latest versions at or before the provided version
+	StartTxnAt(version Version) (DataStoreTxn, error)
+	// drops all versions that aren't visible to reads at or after the horizon anymore
+	// reads before the horizon fail afterwards
+	CollectGarbage(horizon Version) error
+}
@@ -0,0 +1,365 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mhelmich/calvin/pb"
+)
+
+func NewStoredProcedureRegistry() *StoredProcedureRegistry {
+	return &StoredProcedureRegistry{
+		procs: make(map[string]*storedProcedureVersions),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+// StoredProcedureRegistry holds all versions of all stored procedures known to a node.
+// Changes to the registry are applied by the scheduler in log order which makes sure
+// every replica assigns the same version to the same procedure and
+// flips the current version at the same position in the log.
+// Workers read from it concurrently.
+type StoredProcedureRegistry struct {
+	procs map[string]*storedProcedureVersions
+	mutex *sync.RWMutex
+}
+
+type storedProcedureVersions struct {
+	// built-in procedures don't go through the log and survive a reset
+	builtIn  bool
+	current  uint64
+	latest   uint64
+	versions map[uint64]*storedProcedureVersion
+}
+
+type storedProcedureVersion struct {
+	proc *pb.StoredProcedure
+	// number of queued txns that run with this version
+	refs int
+	// index of the batch that removed this version
+	// txns sequenced in this batch or later can't use it anymore
+	removedAt uint64
+}
+
+// Install adds a new version of a stored procedure and makes it the current version.
+// It returns the version that was assigned to the procedure.
+// Versions are never reused, not even after they were removed.
+func (r *StoredProcedureRegistry) Install(name string, source string) uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	versions, ok := r.procs[name]
+	if !ok {
+		versions = &storedProcedureVersions{
+			versions: make(map[uint64]*storedProcedureVersion),
+		}
+		r.procs[name] = versions
+	}
+
+	versions.latest++
+	versions.current = versions.latest
+	versions.versions[versions.latest] = &storedProcedureVersion{
+		proc: &pb.StoredProcedure{
+			Name:    name,
+			Source:  source,
+			Version: versions.latest,
+		},
+	}
+	return versions.latest
+}
+
+// InstallVersion installs a procedure with the version it was assigned earlier and makes it the current version.
+// The changes of compacted snapshots install procedures that way.
+func (r *StoredProcedureRegistry) InstallVersion(name string, source string, version uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	versions, ok := r.procs[name]
+	if !ok {
+		versions = &storedProcedureVersions{
+			versions: make(map[uint64]*storedProcedureVersion),
+		}
+		r.procs[name] = versions
+	}
+
+	if version > versions.latest {
+		versions.latest = version
+	}
+	versions.current = version
+	versions.versions[version] = &storedProcedureVersion{
+		proc: &pb.StoredProcedure{
+			Name:    name,
+			Source:  source,
+			Version: version,
+		},
+	}
+}
+
+// InstallBuiltIn installs a procedure that every node knows about without it going through the log.
+func (r *StoredProcedureRegistry) InstallBuiltIn(name string, source string) uint64 {
+	version := r.Install(name, source)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.procs[name].builtIn = true
+	return version
+}
+
+// Reset drops all procedures that were installed through the log.
+// It's used before the procedures contained in a snapshot are installed again.
+func (r *StoredProcedureRegistry) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for name, versions := range r.procs {
+		if !versions.builtIn {
+			delete(r.procs, name)
+		}
+	}
+}
+
+// SetCurrent makes an existing version the current version of a procedure.
+// This is how deploys are rolled back.
+func (r *StoredProcedureRegistry) SetCurrent(name string, version uint64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, err := r.getLiveVersion(name, version)
+	if err != nil {
+		return err
+	}
+
+	r.procs[name].current = v.proc.Version
+	return nil
+}
+
+// Remove marks a version as removed as of the provided batch index.
+// The current version can't be removed.
+// The source of the procedure is dropped once no queued txn references it anymore.
+func (r *StoredProcedureRegistry) Remove(name string, version uint64, batchIndex uint64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, err := r.getLiveVersion(name, version)
+	if err != nil {
+		return err
+	} else if r.procs[name].current == version {
+		return fmt.Errorf("can't remove current version [%d] of stored procedure [%s]", version, name)
+	}
+
+	v.removedAt = batchIndex
+	r.maybeDrop(name, v)
+	return nil
+}
+
+// Apply applies a change that was sequenced at the provided batch index.
+// Installs that carry a version come out of compacted snapshots and keep their version.
+// Otherwise the version assigned to the procedure is set on the change.
+func (r *StoredProcedureRegistry) Apply(proc *pb.StoredProcedure, batchIndex uint64) error {
+	switch proc.Op {
+	case pb.INSTALL_PROCEDURE:
+		if proc.Version > 0 {
+			r.InstallVersion(proc.Name, proc.Source, proc.Version)
+		} else {
+			proc.Version = r.Install(proc.Name, proc.Source)
+		}
+		return nil
+	case pb.SET_CURRENT_PROCEDURE:
+		return r.SetCurrent(proc.Name, proc.Version)
+	case pb.REMOVE_PROCEDURE:
+		return r.Remove(proc.Name, proc.Version, batchIndex)
+	default:
+		return fmt.Errorf("unknown stored procedure op [%s]", proc.Op.String())
+	}
+}
+
+// Compact returns the changes that rebuild all procedures that went through the log.
+// Versions that were removed are left out and installs carry the version they were assigned.
+// If the latest version of a procedure was removed, it's installed and removed again
+// so that its version isn't assigned a second time.
+func (r *StoredProcedureRegistry) Compact() []*pb.StoredProcedure {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.procs))
+	for name, versions := range r.procs {
+		if !versions.builtIn {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := make([]*pb.StoredProcedure, 0)
+	for _, name := range names {
+		versions := r.procs[name]
+		live := make([]*pb.StoredProcedure, 0, len(versions.versions))
+		for _, v := range versions.versions {
+			if v.removedAt == 0 {
+				live = append(live, v.proc)
+			}
+		}
+		sort.Slice(live, func(i, j int) bool { return live[i].Version < live[j].Version })
+
+		for _, proc := range live {
+			changes = append(changes, &pb.StoredProcedure{
+				Name:    name,
+				Source:  proc.Source,
+				Version: proc.Version,
+				Op:      pb.INSTALL_PROCEDURE,
+			})
+		}
+
+		latestRemoved := len(live) == 0 || live[len(live)-1].Version != versions.latest
+		if latestRemoved {
+			changes = append(changes, &pb.StoredProcedure{
+				Name:    name,
+				Version: versions.latest,
+				Op:      pb.INSTALL_PROCEDURE,
+			})
+		}
+		if latestRemoved || versions.current != versions.latest {
+			changes = append(changes, &pb.StoredProcedure{
+				Name:    name,
+				Version: versions.current,
+				Op:      pb.SET_CURRENT_PROCEDURE,
+			})
+		}
+		if latestRemoved {
+			changes = append(changes, &pb.StoredProcedure{
+				Name:    name,
+				Version: versions.latest,
+				Op:      pb.REMOVE_PROCEDURE,
+			})
+		}
+	}
+	return changes
+}
+
+// CurrentVersion returns the current version of a procedure or zero if the procedure doesn't exist.
+func (r *StoredProcedureRegistry) CurrentVersion(name string) uint64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	versions, ok := r.procs[name]
+	if !ok {
+		return 0
+	}
+	return versions.current
+}
+
+// Acquire is called by the scheduler for every txn it locks.
+// It keeps the version around until the txn is done.
+func (r *StoredProcedureRegistry) Acquire(name string, version uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.getVersion(name, version)
+	if ok {
+		v.refs++
+	}
+}
+
+// Release is called by the scheduler for every txn that is done.
+func (r *StoredProcedureRegistry) Release(name string, version uint64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	v, ok := r.getVersion(name, version)
+	if ok {
+		v.refs--
+		r.maybeDrop(name, v)
+	}
+}
+
+// Get returns a particular version of a procedure for a txn sequenced at the provided batch index.
+// Version zero returns the current version.
+// Versions that were removed before the txn was sequenced are treated as if they don't exist.
+func (r *StoredProcedureRegistry) Get(name string, version uint64, batchIndex uint64) (*pb.StoredProcedure, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if version == 0 {
+		versions, ok := r.procs[name]
+		if !ok {
+			return nil, false
+		}
+		version = versions.current
+	}
+
+	v, ok := r.getVersion(name, version)
+	if !ok || (v.removedAt > 0 && batchIndex >= v.removedAt) {
+		return nil, false
+	}
+	return v.proc, true
+}
+
+// List returns all versions of all procedures that weren't removed.
+// The result is sorted by name and version.
+func (r *StoredProcedureRegistry) List() []*pb.StoredProcedure {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	procs := make([]*pb.StoredProcedure, 0)
+	for _, versions := range r.procs {
+		for _, v := range versions.versions {
+			if v.removedAt > 0 {
+				continue
+			}
+
+			procs = append(procs, &pb.StoredProcedure{
+				Name:    v.proc.Name,
+				Source:  v.proc.Source,
+				Version: v.proc.Version,
+				Current: v.proc.Version == versions.current,
+			})
+		}
+	}
+
+	sort.Slice(procs, func(i, j int) bool {
+		if procs[i].Name == procs[j].Name {
+			return procs[i].Version < procs[j].Version
+		}
+		return procs[i].Name < procs[j].Name
+	})
+	return procs
+}
+
+func (r *StoredProcedureRegistry) getVersion(name string, version uint64) (*storedProcedureVersion, bool) {
+	versions, ok := r.procs[name]
+	if !ok {
+		return nil, false
+	}
+
+	v, ok := versions.versions[version]
+	return v, ok
+}
+
+func (r *StoredProcedureRegistry) getLiveVersion(name string, version uint64) (*storedProcedureVersion, error) {
+	v, ok := r.getVersion(name, version)
+	if !ok || v.removedAt > 0 {
+		return nil, fmt.Errorf("stored procedure [%s] doesn't have version [%d]", name, version)
+	}
+	return v, nil
+}
+
+func (r *StoredProcedureRegistry) maybeDrop(name string, v *storedProcedureVersion) {
+	if v.removedAt > 0 && v.refs <= 0 {
+		delete(r.procs[name].versions, v.proc.Version)
+	}
+}
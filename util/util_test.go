@@ -35,6 +35,13 @@ func TestConversions(t *testing.T) {
 	assert.Equal(t, i, stringToUint64(str))
 }
 
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("narg"), PrefixEnd([]byte("narf")))
+	assert.Equal(t, []byte("nb"), PrefixEnd([]byte("na\xff\xff")))
+	assert.Nil(t, PrefixEnd([]byte("\xff\xff")))
+	assert.Nil(t, PrefixEnd(nil))
+}
+
 func sizeOfMap(m *sync.Map) int {
 	var i int
 	m.Range(func(key, value interface{}) bool {
@@ -0,0 +1,397 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+)
+
+// every version of a key is a separate entry in the bucket
+// the entry key is the escaped key, a terminator and the version
+// escaping keeps all versions of a key next to each other and in order of their versions
+// the first byte of each entry value tells values and deletes apart
+const (
+	metaBucketName = "meta"
+	versionLength  = 16
+	valueEntry     = byte(1)
+	deleteEntry    = byte(0)
+)
+
+var (
+	keyTerminator = []byte{0x00, 0x01}
+	// the version writes without a version go on top of
+	lastVersionKey = []byte("lastVersion")
+	// reads before this version fail
+	gcHorizonKey = []byte("gcHorizon")
+	// the number of entries collecting garbage walks with one write txn
+	gcChunkSize = 4096
+	maxVersion  = util.Version{BatchIndex: math.MaxUint64, TxnPosition: math.MaxUint64}
+)
+
+// zero bytes in keys are escaped as 0x00 0xFF
+func versionedKeyPrefix(key []byte) []byte {
+	return append(escapeKey(key), keyTerminator...)
+}
+
+func escapeKey(key []byte) []byte {
+	escaped := make([]byte, 0, len(key)+len(keyTerminator)+versionLength)
+	for idx := range key {
+		escaped = append(escaped, key[idx])
+		if key[idx] == 0x00 {
+			escaped = append(escaped, 0xFF)
+		}
+	}
+	return escaped
+}
+
+func unescapeKey(prefix []byte) []byte {
+	escaped := prefix[:len(prefix)-len(keyTerminator)]
+	key := make([]byte, 0, len(escaped))
+	for idx := 0; idx < len(escaped); idx++ {
+		key = append(key, escaped[idx])
+		if escaped[idx] == 0x00 {
+			idx++
+		}
+	}
+	return key
+}
+
+func versionedKey(prefix []byte, version util.Version) []byte {
+	key := make([]byte, 0, len(prefix)+versionLength)
+	key = append(key, prefix...)
+	key = append(key, util.Uint64ToBytes(version.BatchIndex)...)
+	return append(key, util.Uint64ToBytes(version.TxnPosition)...)
+}
+
+func marshalVersion(version util.Version) []byte {
+	return versionedKey(nil, version)
+}
+
+func unmarshalVersion(bites []byte) util.Version {
+	if len(bites) < versionLength {
+		return util.Version{}
+	}
+	return util.Version{
+		BatchIndex:  util.BytesToUint64(bites[len(bites)-versionLength : len(bites)-versionLength/2]),
+		TxnPosition: util.BytesToUint64(bites[len(bites)-versionLength/2:]),
+	}
+}
+
+func loadVersion(tx *bolt.Tx, key []byte) util.Version {
+	bucket := tx.Bucket([]byte(metaBucketName))
+	if bucket == nil {
+		return util.Version{}
+	}
+	return unmarshalVersion(bucket.Get(key))
+}
+
+func storeVersion(tx *bolt.Tx, key []byte, version util.Version) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(metaBucketName))
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, marshalVersion(version))
+}
+
+// a versioned bolt data store keeps all versions of a key until they are collected
+// snapshots and restores copy all versions
+// a partition can't switch between versioned and unversioned
+type versionedBoltDataStore struct {
+	*boltDataStore
+}
+
+func (vbds *versionedBoltDataStore) StartTxn(writable bool) (util.DataStoreTxn, error) {
+	return vbds.startTxn(writable, nil, maxVersion)
+}
+
+func (vbds *versionedBoltDataStore) StartVersionedTxn(version util.Version) (util.DataStoreTxn, error) {
+	return vbds.startTxn(true, &version, maxVersion)
+}
+
+func (vbds *versionedBoltDataStore) StartTxnAt(version util.Version) (util.DataStoreTxn, error) {
+	return vbds.startTxn(false, nil, version)
+}
+
+func (vbds *versionedBoltDataStore) startTxn(writable bool, writeVersion *util.Version, readVersion util.Version) (util.DataStoreTxn, error) {
+	vbds.lock.RLock()
+	defer vbds.lock.RUnlock()
+	if vbds.failed != nil {
+		return nil, vbds.failed
+	}
+
+	txn, err := vbds.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+
+	horizon := loadVersion(txn, gcHorizonKey)
+	if readVersion.Less(horizon) {
+		txn.Rollback()
+		return nil, fmt.Errorf("version [%d.%d] was collected already", readVersion.BatchIndex, readVersion.TxnPosition)
+	}
+
+	t := &versionedBoltDataStoreTxn{
+		txn:         txn,
+		bucket:      txn.Bucket([]byte(bucketName)),
+		readVersion: readVersion,
+	}
+	if writable {
+		t.lastVersion = loadVersion(txn, lastVersionKey)
+		if writeVersion != nil {
+			t.writeVersion = *writeVersion
+		} else {
+			t.writeVersion = t.lastVersion
+		}
+	}
+	return t, nil
+}
+
+// for each key the latest version at or before the horizon stays
+// unless it's a delete, then it goes as well
+// all earlier versions are deleted
+// the partition is walked in chunks with a write txn each
+// that way writers don't wait for the entire partition to be walked
+func (vbds *versionedBoltDataStore) CollectGarbage(horizon util.Version) error {
+	return vbds.collectGarbage(horizon, gcChunkSize)
+}
+
+func (vbds *versionedBoltDataStore) collectGarbage(horizon util.Version, chunkSize int) error {
+	// reads before the horizon fail from here on
+	// otherwise they could see keys whose earlier versions are gone already
+	err := vbds.update(func(tx *bolt.Tx) error {
+		if loadVersion(tx, gcHorizonKey).Less(horizon) {
+			return storeVersion(tx, gcHorizonKey, horizon)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var start []byte
+	for {
+		err = vbds.update(func(tx *bolt.Tx) error {
+			var err error
+			start, err = collectChunk(tx.Bucket([]byte(bucketName)), start, horizon, chunkSize)
+			return err
+		})
+		if err != nil {
+			return err
+		} else if start == nil {
+			return nil
+		}
+	}
+}
+
+func (vbds *versionedBoltDataStore) update(fn func(tx *bolt.Tx) error) error {
+	vbds.lock.RLock()
+	defer vbds.lock.RUnlock()
+	if vbds.failed != nil {
+		return vbds.failed
+	}
+	return vbds.db.Update(fn)
+}
+
+// walks at least chunkSize entries from start on
+// a chunk only ends where the versions of the next key start
+// returns where the next chunk starts or nil if the end of the bucket was reached
+func collectChunk(bucket *bolt.Bucket, start []byte, horizon util.Version, chunkSize int) ([]byte, error) {
+	// the cursor skips entries when deleting while iterating
+	toDelete := make([][]byte, 0)
+	var next []byte
+	var prevKey []byte
+	var prevPrefix []byte
+	var numEntries int
+	c := bucket.Cursor()
+	k, v := c.First()
+	if start != nil {
+		k, v = c.Seek(start)
+	}
+
+	for ; k != nil; k, v = c.Next() {
+		if len(k) < versionLength {
+			continue
+		}
+
+		prefix := k[:len(k)-versionLength]
+		numEntries++
+		if numEntries > chunkSize && !bytes.Equal(prevPrefix, prefix) {
+			next = append([]byte{}, k...)
+			break
+		}
+
+		if horizon.Less(unmarshalVersion(k)) {
+			prevPrefix = append([]byte{}, prefix...)
+			prevKey = nil
+			continue
+		}
+
+		// an earlier version of the same key is shadowed by this one
+		if prevKey != nil && bytes.Equal(prevPrefix, prefix) {
+			toDelete = append(toDelete, prevKey)
+		}
+
+		prevKey = append([]byte{}, k...)
+		prevPrefix = prevKey[:len(prevKey)-versionLength]
+		if len(v) > 0 && v[0] == deleteEntry {
+			// nothing before a delete is visible
+			// and the delete doesn't hide anything once the earlier versions are gone
+			toDelete = append(toDelete, prevKey)
+			prevKey = nil
+		}
+	}
+
+	for idx := range toDelete {
+		err := bucket.Delete(toDelete[idx])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+type versionedBoltDataStoreTxn struct {
+	txn    *bolt.Tx
+	bucket *bolt.Bucket
+	// reads see the latest version at or before this one
+	readVersion util.Version
+	// only set for writable txns
+	writeVersion util.Version
+	lastVersion  util.Version
+}
+
+func (t *versionedBoltDataStoreTxn) Get(key []byte) []byte {
+	prefix := versionedKeyPrefix(key)
+	target := versionedKey(prefix, t.readVersion)
+	c := t.bucket.Cursor()
+	k, v := c.Seek(target)
+	if k == nil {
+		k, v = c.Last()
+	} else if !bytes.Equal(k, target) {
+		k, v = c.Prev()
+	}
+
+	if k == nil || !bytes.HasPrefix(k, prefix) || len(v) == 0 || v[0] == deleteEntry {
+		return nil
+	}
+	return v[1:]
+}
+
+func (t *versionedBoltDataStoreTxn) Set(key []byte, value []byte) error {
+	entry := make([]byte, 0, len(value)+1)
+	entry = append(entry, valueEntry)
+	entry = append(entry, value...)
+	return t.bucket.Put(versionedKey(versionedKeyPrefix(key), t.writeVersion), entry)
+}
+
+func (t *versionedBoltDataStoreTxn) Delete(key []byte) error {
+	return t.bucket.Put(versionedKey(versionedKeyPrefix(key), t.writeVersion), []byte{deleteEntry})
+}
+
+// escaping keeps the order of keys
+// and an escaped key sorts before all versions of the key itself and all keys after it
+func (t *versionedBoltDataStoreTxn) Iterate(start []byte, end []byte) util.DataStoreIterator {
+	it := &versionedBoltDataStoreIterator{
+		c:           t.bucket.Cursor(),
+		start:       escapeKey(start),
+		readVersion: t.readVersion,
+	}
+	if end != nil {
+		it.end = escapeKey(end)
+	}
+	return it
+}
+
+func (t *versionedBoltDataStoreTxn) Commit() error {
+	if t.lastVersion.Less(t.writeVersion) {
+		err := storeVersion(t.txn, lastVersionKey, t.writeVersion)
+		if err != nil {
+			t.txn.Rollback()
+			return err
+		}
+	}
+	return t.txn.Commit()
+}
+
+func (t *versionedBoltDataStoreTxn) Rollback() error {
+	return t.txn.Rollback()
+}
+
+func newPartitionedVersionedBoltStore(baseDir string, logger *log.Entry) *partitionedBoltStore {
+	pbs := newPartitionedBoltStore(baseDir, logger)
+	pbs.versioned = true
+	return pbs
+}
+
+// the iterator visits all versions of a key and emits the latest one that's visible
+type versionedBoltDataStoreIterator struct {
+	c           *bolt.Cursor
+	start       []byte
+	end         []byte
+	readVersion util.Version
+	started     bool
+	// the entry the cursor points at
+	k     []byte
+	v     []byte
+	key   []byte
+	value []byte
+}
+
+func (it *versionedBoltDataStoreIterator) Next() bool {
+	if !it.started {
+		it.started = true
+		it.k, it.v = it.c.Seek(it.start)
+	}
+
+	for it.k != nil && len(it.k) >= versionLength && (it.end == nil || bytes.Compare(it.k, it.end) < 0) {
+		prefix := it.k[:len(it.k)-versionLength]
+		var entry []byte
+		// versions of a key are in order
+		for it.k != nil && len(it.k) >= versionLength && bytes.Equal(it.k[:len(it.k)-versionLength], prefix) {
+			if !it.readVersion.Less(unmarshalVersion(it.k)) {
+				entry = it.v
+			}
+			it.k, it.v = it.c.Next()
+		}
+
+		if len(entry) > 0 && entry[0] == valueEntry {
+			it.key = unescapeKey(prefix)
+			it.value = entry[1:]
+			return true
+		}
+	}
+
+	it.key, it.value = nil, nil
+	return false
+}
+
+func (it *versionedBoltDataStoreIterator) Key() []byte {
+	return it.key
+}
+
+func (it *versionedBoltDataStoreIterator) Value() []byte {
+	return it.value
+}
+
+// cursors go away with their txn
+func (it *versionedBoltDataStoreIterator) Close() {}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2019 Marco Helmich
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package calvin
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/mhelmich/calvin/util"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionedBoltStoreReadsAtVersions(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	baseDir := fmt.Sprintf("./test-TestVersionedBoltStoreReadsAtVersions-%d/", util.RandomRaftId())
+	defer os.RemoveAll(baseDir)
+	assert.Nil(t, os.MkdirAll(baseDir, os.ModePerm))
+
+	store := newPartitionedVersionedBoltStore(baseDir, logger)
+	defer store.Close()
+	txnProvider, err := store.CreatePartition(1)
+	assert.Nil(t, err)
+	vds := txnProvider.(util.VersionedDataStoreTxnProvider)
+
+	writeVersionedKey(t, vds, util.Version{BatchIndex: 3, TxnPosition: 1}, "narf", "narf_3")
+	writeVersionedKey(t, vds, util.Version{BatchIndex: 5, TxnPosition: 0}, "narf", "narf_5")
+	// keys with zero bytes don't mix with their prefixes
+	writeVersionedKey(t, vds, util.Version{BatchIndex: 4, TxnPosition: 0}, "narf\x00moep", "moep_4")
+	txn, err := vds.StartVersionedTxn(util.Version{BatchIndex: 7})
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Delete([]byte("narf")))
+	assert.Nil(t, txn.Commit())
+	// writes without a version go on top of the latest version
+	writeBoltKey(t, vds, "moep", "moep_7")
+
+	expected := map[util.Version][]string{
+		util.Version{BatchIndex: 2}:                 []string{"", "", ""},
+		util.Version{BatchIndex: 3}:                 []string{"", "", ""},
+		util.Version{BatchIndex: 3, TxnPosition: 1}: []string{"narf_3", "", ""},
+		util.Version{BatchIndex: 4}:                 []string{"narf_3", "moep_4", ""},
+		util.Version{BatchIndex: 6}:                 []string{"narf_5", "moep_4", ""},
+		util.Version{BatchIndex: 7}:                 []string{"", "moep_4", "moep_7"},
+		maxVersion:                                  []string{"", "moep_4", "moep_7"},
+	}
+	for version, values := range expected {
+		assertVersionedValues(t, vds, version, values)
+	}
+
+	txn, err = vds.StartTxn(false)
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Get([]byte("narf")))
+	assert.Equal(t, "moep_4", string(txn.Get([]byte("narf\x00moep"))))
+	assert.Nil(t, txn.Rollback())
+
+	// versions that are shadowed at the horizon are gone
+	// and the delete doesn't need to stay around either
+	err = vds.CollectGarbage(util.Version{BatchIndex: 6})
+	assert.Nil(t, err)
+	_, err = vds.StartTxnAt(util.Version{BatchIndex: 5})
+	assert.NotNil(t, err)
+	assertVersionedValues(t, vds, util.Version{BatchIndex: 6}, []string{"narf_5", "moep_4", ""})
+	err = vds.CollectGarbage(util.Version{BatchIndex: 8})
+	assert.Nil(t, err)
+	assertVersionedValues(t, vds, maxVersion, []string{"", "moep_4", "moep_7"})
+
+	numEntries := 0
+	err = vds.(*versionedBoltDataStore).db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).ForEach(func(k, v []byte) error {
+			numEntries++
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numEntries)
+}
+
+func TestVersionedBoltStoreIterate(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	baseDir := fmt.Sprintf("./test-TestVersionedBoltStoreIterate-%d/", util.RandomRaftId())
+	defer os.RemoveAll(baseDir)
+	assert.Nil(t, os.MkdirAll(baseDir, os.ModePerm))
+
+	store := newPartitionedVersionedBoltStore(baseDir, logger)
+	defer store.Close()
+	txnProvider, err := store.CreatePartition(1)
+	assert.Nil(t, err)
+	vds := txnProvider.(util.VersionedDataStoreTxnProvider)
+
+	for idx, key := range []string{"narf", "narf\x00moep", "narf\x01", "moep"} {
+		writeVersionedKey(t, vds, util.Version{BatchIndex: uint64(idx + 1)}, key, "value_"+key)
+	}
+	txn, err := vds.StartVersionedTxn(util.Version{BatchIndex: 5})
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Delete([]byte("narf\x01")))
+	assert.Nil(t, txn.Set([]byte("moep"), []byte("value_moep")))
+	// writes of the txn show up right away
+	assertIteratedKeys(t, txn.Iterate([]byte("narf"), nil), []string{"narf", "narf\x00moep"})
+	assert.Nil(t, txn.Commit())
+
+	// escaped keys keep their order
+	txn, err = vds.StartTxnAt(util.Version{BatchIndex: 3})
+	assert.Nil(t, err)
+	assertIteratedKeys(t, txn.Iterate(nil, nil), []string{"narf", "narf\x00moep", "narf\x01"})
+	assertIteratedKeys(t, txn.Iterate([]byte("narf\x00"), []byte("narf\x01")), []string{"narf\x00moep"})
+	assert.Nil(t, txn.Rollback())
+
+	txn, err = vds.StartTxn(false)
+	assert.Nil(t, err)
+	assertIteratedKeys(t, txn.Iterate(nil, nil), []string{"moep", "narf", "narf\x00moep"})
+	assertIteratedKeys(t, txn.Iterate([]byte("narf"), util.PrefixEnd([]byte("narf"))), []string{"narf", "narf\x00moep"})
+	assert.Nil(t, txn.Rollback())
+}
+
+func TestVersionedBoltStoreCollectsGarbageInChunks(t *testing.T) {
+	logger := log.WithFields(log.Fields{})
+	baseDir := fmt.Sprintf("./test-TestVersionedBoltStoreCollectsGarbageInChunks-%d/", util.RandomRaftId())
+	defer os.RemoveAll(baseDir)
+	assert.Nil(t, os.MkdirAll(baseDir, os.ModePerm))
+
+	store := newPartitionedVersionedBoltStore(baseDir, logger)
+	defer store.Close()
+	txnProvider, err := store.CreatePartition(1)
+	assert.Nil(t, err)
+	vds := txnProvider.(*versionedBoltDataStore)
+
+	for batchIndex := uint64(1); batchIndex <= 4; batchIndex++ {
+		for _, key := range []string{"narf", "narf\x00moep", "moep"} {
+			writeVersionedKey(t, vds, util.Version{BatchIndex: batchIndex}, key, fmt.Sprintf("%s_%d", key, batchIndex))
+		}
+	}
+
+	// chunks end in between keys only
+	// the versions of a key are always looked at together
+	err = vds.collectGarbage(util.Version{BatchIndex: 3}, 2)
+	assert.Nil(t, err)
+	_, err = vds.StartTxnAt(util.Version{BatchIndex: 2})
+	assert.NotNil(t, err)
+	assertVersionedValues(t, vds, util.Version{BatchIndex: 3}, []string{"narf_3", "narf\x00moep_3", "moep_3"})
+	assertVersionedValues(t, vds, maxVersion, []string{"narf_4", "narf\x00moep_4", "moep_4"})
+
+	numEntries := 0
+	err = vds.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucketName)).ForEach(func(k, v []byte) error {
+			numEntries++
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 6, numEntries)
+}
+
+func writeVersionedKey(t *testing.T, vds util.VersionedDataStoreTxnProvider, version util.Version, key string, value string) {
+	txn, err := vds.StartVersionedTxn(version)
+	assert.Nil(t, err)
+	assert.Nil(t, txn.Set([]byte(key), []byte(value)))
+	assert.Nil(t, txn.Commit())
+}
+
+func assertVersionedValues(t *testing.T, vds util.VersionedDataStoreTxnProvider, version util.Version, values []string) {
+	txn, err := vds.StartTxnAt(version)
+	assert.Nil(t, err)
+	for idx, key := range []string{"narf", "narf\x00moep", "moep"} {
+		assert.Equal(t, values[idx], string(txn.Get([]byte(key))), "key [%q] at version [%d.%d]", key, version.BatchIndex, version.TxnPosition)
+	}
+	assert.Nil(t, txn.Rollback())
+}